@@ -3,16 +3,33 @@ package main
 import (
 	"log"
 	"os"
+	"time"
 
+	"kakeibo-tanuki/internal/alerts"
 	"kakeibo-tanuki/internal/database"
+	"kakeibo-tanuki/internal/events"
 	"kakeibo-tanuki/internal/handlers"
 	"kakeibo-tanuki/internal/middleware"
-	"kakeibo-tanuki/internal/repositories"
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/notify"
+	"kakeibo-tanuki/internal/services"
+	"kakeibo-tanuki/internal/storage"
+	"kakeibo-tanuki/internal/stream"
+	"kakeibo-tanuki/internal/suggestions"
+	"kakeibo-tanuki/internal/webhooks"
 
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
+	// The migrate subcommand manages the schema instead of serving the API
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	// Initialize database
 	db, err := database.NewDatabase()
 	if err != nil {
@@ -20,14 +37,99 @@ func main() {
 	}
 	defer db.Close()
 
-	// Initialize repositories
-	repo := repositories.NewRepository(db.GetDB())
+	// Initialize repositories, and the unit of work that services run
+	// transactions with
+	uow := services.NewUnitOfWork(db.GetDB())
+	repo := uow.Repository()
+
+	// Initialize attachment storage
+	store, err := storage.NewStorageFromEnv()
+	if err != nil {
+		log.Fatal("Failed to initialize storage:", err)
+	}
+
+	// Category suggestions are learned from the expenses on first use
+	suggester := suggestions.NewModel(repo.Expense.GetHistory)
+
+	// Alerts are delivered in the app, by webhook and, when SMTP is
+	// configured, by email
+	notifiers := map[string]notify.Notifier{
+		models.ChannelInApp:   notify.NewInAppNotifier(repo.Notification),
+		models.ChannelWebhook: notify.NewWebhookNotifier(),
+	}
+	smtpConfig, hasSMTP, err := notify.SMTPConfigFromEnv()
+	if err != nil {
+		log.Fatal("Failed to configure email:", err)
+	}
+	if hasSMTP {
+		notifiers[models.ChannelEmail] = notify.NewSMTPNotifier(smtpConfig)
+	}
+	evaluator := alerts.NewEvaluator(repo.Alert, repo.Expense, repo.Book, notifiers)
+
+	// Alerts are also checked on a schedule, so that they fire for expenses
+	// that did not go through the API
+	alertInterval := time.Hour
+	if value := os.Getenv("ALERT_CHECK_INTERVAL"); value != "" {
+		alertInterval, err = time.ParseDuration(value)
+		if err != nil || alertInterval <= 0 {
+			log.Fatal("Invalid ALERT_CHECK_INTERVAL:", value)
+		}
+	}
+	stopAlerts := evaluator.Schedule(alertInterval)
+	defer stopAlerts()
+
+	// Changes of expenses are published to the parts that keep up with them
+	bus := events.NewBus()
+	bus.Subscribe(suggester.HandleEvent)
+	bus.Subscribe(evaluator.HandleEvent)
+
+	// Webhook events are queued as they are published and delivered by a
+	// worker that retries failed deliveries every minute
+	dispatcher := webhooks.NewDispatcher(repo.Webhook)
+	bus.Subscribe(dispatcher.HandleEvent)
+	stopWebhooks := dispatcher.Start(time.Minute)
+	defer stopWebhooks()
+
+	// Connected clients are sent events live and can resume from the last
+	// thousand
+	broker := stream.NewBroker(1000)
+	bus.Subscribe(broker.HandleEvent)
+
+	// Initialize services
+	cardService := services.NewCardService(uow)
+	categoryService := services.NewCategoryService(uow)
+	expenseService := services.NewExpenseService(uow)
+	refundService := services.NewRefundService(uow)
+	installmentService := services.NewInstallmentService(uow)
 
 	// Initialize handlers
-	cardHandler := handlers.NewCardHandler(repo.Card)
-	categoryHandler := handlers.NewCategoryHandler(repo.Category)
-	expenseHandler := handlers.NewExpenseHandler(repo.Expense)
-	reportHandler := handlers.NewReportHandler(repo.Expense)
+	cardHandler := handlers.NewCardHandler(cardService, bus)
+	categoryHandler := handlers.NewCategoryHandler(categoryService, bus)
+	payeeHandler := handlers.NewPayeeHandler(repo.Payee)
+	ruleHandler := handlers.NewRuleHandler(repo.Rule, repo.Card, repo.Category, repo.Payee, bus)
+	expenseHandler := handlers.NewExpenseHandler(expenseService, bus, store)
+	attachmentHandler := handlers.NewAttachmentHandler(repo.Attachment, repo.Expense, store)
+	installmentHandler := handlers.NewInstallmentHandler(installmentService)
+	refundHandler := handlers.NewRefundHandler(refundService)
+	bookHandler := handlers.NewBookHandler(repo.Book)
+	exchangeRateHandler := handlers.NewExchangeRateHandler(repo.ExchangeRate, repo.Book)
+	reportHandler := handlers.NewReportHandler(repo.Expense, repo.Book)
+	insightHandler := handlers.NewInsightHandler(repo.Expense, repo.Book)
+	suggestionHandler := handlers.NewSuggestionHandler(suggester, repo.Category, repo.Book)
+	alertHandler := handlers.NewAlertHandler(repo.Alert, repo.Card, repo.Category, evaluator)
+	notificationHandler := handlers.NewNotificationHandler(repo.Notification)
+	webhookHandler := handlers.NewWebhookHandler(repo.Webhook)
+	eventHandler := handlers.NewEventHandler(broker, 15*time.Second)
+	openAPIHandler := handlers.NewOpenAPIHandler()
+
+	// Queries of a request are canceled once it runs longer than this
+	requestTimeout := 30 * time.Second
+	if value := os.Getenv("REQUEST_TIMEOUT"); value != "" {
+		requestTimeout, err = time.ParseDuration(value)
+		if err != nil || requestTimeout <= 0 {
+			log.Fatal("Invalid REQUEST_TIMEOUT:", value)
+		}
+	}
 
 	// Initialize Gin router
 	router := gin.Default()
@@ -35,6 +137,8 @@ func main() {
 	// Add middleware
 	router.Use(middleware.CORSMiddleware())
 	router.Use(middleware.LoggingMiddleware())
+	router.Use(middleware.ErrorMiddleware())
+	router.Use(middleware.TimeoutMiddleware(requestTimeout, "/api/events"))
 
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
@@ -55,6 +159,9 @@ func main() {
 	// API routes group
 	api := router.Group("/api")
 	{
+		// API description
+		api.GET("/openapi.json", openAPIHandler.GetOpenAPI)
+
 		// Card routes
 		cards := api.Group("/cards")
 		{
@@ -75,14 +182,70 @@ func main() {
 			categories.DELETE("/:id", categoryHandler.DeleteCategory)
 		}
 
+		// Payee routes
+		payees := api.Group("/payees")
+		{
+			payees.GET("", payeeHandler.GetPayees)
+			payees.POST("", payeeHandler.CreatePayee)
+			payees.GET("/:id", payeeHandler.GetPayee)
+			payees.PUT("/:id", payeeHandler.UpdatePayee)
+			payees.DELETE("/:id", payeeHandler.DeletePayee)
+		}
+
+		// Rule routes
+		rules := api.Group("/rules")
+		{
+			rules.GET("", ruleHandler.GetRules)
+			rules.POST("", ruleHandler.CreateRule)
+			rules.POST("/dry-run", ruleHandler.DryRunRules)
+			rules.POST("/apply", ruleHandler.ApplyRules)
+			rules.GET("/:id", ruleHandler.GetRule)
+			rules.PUT("/:id", ruleHandler.UpdateRule)
+			rules.DELETE("/:id", ruleHandler.DeleteRule)
+		}
+
 		// Expense routes
 		expenses := api.Group("/expenses")
 		{
 			expenses.GET("", expenseHandler.GetExpenses)
 			expenses.POST("", expenseHandler.CreateExpense)
+			expenses.POST("/suggest-category", suggestionHandler.SuggestCategory)
 			expenses.GET("/:id", expenseHandler.GetExpense)
 			expenses.PUT("/:id", expenseHandler.UpdateExpense)
 			expenses.DELETE("/:id", expenseHandler.DeleteExpense)
+
+			// Receipt attachments
+			expenses.GET("/:id/attachments", attachmentHandler.GetAttachments)
+			expenses.POST("/:id/attachments", attachmentHandler.UploadAttachment)
+			expenses.GET("/:id/attachments/:attachmentId", attachmentHandler.DownloadAttachment)
+			expenses.GET("/:id/attachments/:attachmentId/thumbnail", attachmentHandler.DownloadThumbnail)
+			expenses.DELETE("/:id/attachments/:attachmentId", attachmentHandler.DeleteAttachment)
+
+			// Installment payments
+			expenses.GET("/:id/installment", installmentHandler.GetInstallmentPlan)
+			expenses.PUT("/:id/installment", installmentHandler.SetInstallmentPlan)
+			expenses.DELETE("/:id/installment", installmentHandler.DeleteInstallmentPlan)
+
+			// Refunds
+			expenses.GET("/:id/refunds", refundHandler.GetRefunds)
+			expenses.POST("/:id/refunds", refundHandler.CreateRefund)
+			expenses.DELETE("/:id/refunds/:refundId", refundHandler.DeleteRefund)
+		}
+
+		// Installment routes
+		api.GET("/installments/balances", installmentHandler.GetBalances)
+
+		// Book settings
+		api.GET("/book", bookHandler.GetBook)
+		api.PUT("/book", bookHandler.UpdateBook)
+
+		// Exchange rate routes
+		exchangeRates := api.Group("/exchange-rates")
+		{
+			exchangeRates.GET("", exchangeRateHandler.GetExchangeRates)
+			exchangeRates.POST("", exchangeRateHandler.CreateExchangeRate)
+			exchangeRates.POST("/import", exchangeRateHandler.ImportExchangeRates)
+			exchangeRates.DELETE("/:id", exchangeRateHandler.DeleteExchangeRate)
 		}
 
 		// Report routes
@@ -90,6 +253,48 @@ func main() {
 		{
 			reports.GET("/monthly", reportHandler.GetMonthlyReport)
 			reports.GET("/yearly", reportHandler.GetYearlyReport)
+			reports.GET("/range", reportHandler.GetRangeReport)
+			reports.GET("/compare", reportHandler.GetComparisonReport)
+			reports.GET("/forecast", reportHandler.GetForecastReport)
+			reports.GET("/daily", reportHandler.GetDailyReport)
+		}
+
+		// Alert routes
+		alertRoutes := api.Group("/alerts")
+		{
+			alertRoutes.GET("", alertHandler.GetAlerts)
+			alertRoutes.POST("", alertHandler.CreateAlert)
+			alertRoutes.POST("/check", alertHandler.CheckAlerts)
+			alertRoutes.GET("/:id", alertHandler.GetAlert)
+			alertRoutes.PUT("/:id", alertHandler.UpdateAlert)
+			alertRoutes.DELETE("/:id", alertHandler.DeleteAlert)
+		}
+
+		// Notification routes
+		notifications := api.Group("/notifications")
+		{
+			notifications.GET("", notificationHandler.GetNotifications)
+			notifications.PUT("/:id/read", notificationHandler.MarkNotificationRead)
+		}
+
+		// Webhook routes
+		webhookRoutes := api.Group("/webhooks")
+		{
+			webhookRoutes.GET("", webhookHandler.GetWebhooks)
+			webhookRoutes.POST("", webhookHandler.CreateWebhook)
+			webhookRoutes.GET("/:id", webhookHandler.GetWebhook)
+			webhookRoutes.PUT("/:id", webhookHandler.UpdateWebhook)
+			webhookRoutes.DELETE("/:id", webhookHandler.DeleteWebhook)
+			webhookRoutes.GET("/:id/deliveries", webhookHandler.GetDeliveries)
+		}
+
+		// Live updates
+		api.GET("/events", eventHandler.StreamEvents)
+
+		// Insight routes
+		insights := api.Group("/insights")
+		{
+			insights.GET("/anomalies", insightHandler.GetAnomalies)
 		}
 	}
 
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"kakeibo-tanuki/internal/database"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const migrateUsage = `usage: main migrate <command>
+
+commands:
+  status        list the migrations and whether they are applied
+  up            apply the migrations not applied yet
+  down [n]      roll back the last n migrations applied, 1 by default
+  to <version>  apply or roll back migrations to reach version, 0 for none`
+
+// runMigrate runs the migrate subcommand with its arguments.
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	var steps, version int
+	switch args[0] {
+	case "status", "up":
+		if len(args) != 1 {
+			return errors.New(migrateUsage)
+		}
+	case "down":
+		steps = 1
+		if len(args) > 2 {
+			return errors.New(migrateUsage)
+		}
+		if len(args) == 2 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				return fmt.Errorf("invalid number of migrations: %s", args[1])
+			}
+			steps = n
+		}
+	case "to":
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid version: %s", args[1])
+		}
+		version = n
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	db, err := database.Open()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	// Only the migrations are logged, not each statement
+	db.DB = db.DB.Session(&gorm.Session{Logger: logger.Default.LogMode(logger.Warn)})
+	migrator, err := db.Migrator()
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+		for _, status := range statuses {
+			state, appliedAt := "pending", ""
+			if status.Applied {
+				state = "applied"
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+			if status.Modified {
+				state = "modified"
+			}
+			if status.Missing {
+				state = "missing"
+			}
+			fmt.Fprintf(w, "%03d\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt)
+		}
+		return w.Flush()
+	case "up":
+		_, err = migrator.Up()
+	case "down":
+		_, err = migrator.Down(steps)
+	case "to":
+		_, err = migrator.To(version)
+	}
+	return err
+}
@@ -0,0 +1,277 @@
+// Package alerts watches spending against the alerts the user defined and
+// delivers an alert through its channels the first time the spending of a
+// period reaches the threshold.
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"kakeibo-tanuki/internal/events"
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
+	"kakeibo-tanuki/internal/notify"
+	"kakeibo-tanuki/internal/reports"
+	"kakeibo-tanuki/internal/repositories"
+)
+
+// Evaluator checks alerts when expenses change and on a schedule. It is safe
+// for concurrent use.
+type Evaluator struct {
+	alertRepo   repositories.AlertRepository
+	expenseRepo repositories.ExpenseRepository
+	bookRepo    repositories.BookRepository
+	notifiers   map[string]notify.Notifier
+
+	// mu serializes evaluations so that an alert fires once per period even
+	// when expenses are recorded concurrently
+	mu sync.Mutex
+}
+
+// NewEvaluator returns an evaluator delivering alerts through the notifiers
+// of the given channels. Alerts are not delivered through other channels.
+func NewEvaluator(alertRepo repositories.AlertRepository, expenseRepo repositories.ExpenseRepository, bookRepo repositories.BookRepository, notifiers map[string]notify.Notifier) *Evaluator {
+	return &Evaluator{
+		alertRepo:   alertRepo,
+		expenseRepo: expenseRepo,
+		bookRepo:    bookRepo,
+		notifiers:   notifiers,
+	}
+}
+
+// HasChannel reports whether alerts can be delivered through channel.
+func (e *Evaluator) HasChannel(channel string) bool {
+	_, ok := e.notifiers[channel]
+	return ok
+}
+
+// PeriodBounds returns the first and last day of the period containing t.
+// Weeks start on Monday.
+func PeriodBounds(t time.Time, period string) (time.Time, time.Time) {
+	if period == models.AlertPeriodYear {
+		start := time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(1, 0, -1)
+	}
+
+	start := reports.PeriodStart(t, period)
+	switch period {
+	case models.AlertPeriodWeek:
+		return start, start.AddDate(0, 0, 6)
+	case models.AlertPeriodQuarter:
+		return start, start.AddDate(0, 3, -1)
+	default:
+		return start, start.AddDate(0, 1, -1)
+	}
+}
+
+// Threshold returns the spending at which an alert fires.
+func Threshold(alert *models.Alert) money.Amount {
+	return alert.Amount * money.Amount(alert.Percent) / 100
+}
+
+// Check evaluates all enabled alerts for the periods containing now and
+// delivers the ones that fire, waiting for every channel. Failed channels
+// are listed in the Errors of the returned events.
+func (e *Evaluator) Check(ctx context.Context, now time.Time) ([]models.AlertEvent, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	alerts, err := e.alertRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	fired := []models.AlertEvent{}
+	for i := range alerts {
+		if !alerts[i].Enabled {
+			continue
+		}
+		event, err := e.evaluate(ctx, &alerts[i], now)
+		if err != nil {
+			return fired, fmt.Errorf("failed to evaluate alert %q: %w", alerts[i].Name, err)
+		}
+		if event != nil {
+			event.Errors = e.deliver(&alerts[i], event, alerts[i].Channels)
+			fired = append(fired, *event)
+		}
+	}
+	return fired, nil
+}
+
+// HandleEvent evaluates the alerts an expense counts toward when it is
+// created or updated within the current period. In-app notifications are
+// saved before it returns; email and webhooks are delivered in the
+// background so that recording the expense does not wait for them.
+func (e *Evaluator) HandleEvent(event events.Event) {
+	if event.Expense == nil || event.Type == events.ExpenseDeleted {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ctx := context.Background()
+	alerts, err := e.alertRepo.GetAll(ctx)
+	if err != nil {
+		log.Printf("Failed to load alerts: %v", err)
+		return
+	}
+	now := event.Time
+	for i := range alerts {
+		alert := &alerts[i]
+		if !alert.Enabled || !matches(alert, event.Expense, now) {
+			continue
+		}
+		alertEvent, err := e.evaluate(ctx, alert, now)
+		if err != nil {
+			log.Printf("Failed to evaluate alert %q: %v", alert.Name, err)
+			continue
+		}
+		if alertEvent == nil {
+			continue
+		}
+
+		if errs := e.deliver(alert, alertEvent, []string{models.ChannelInApp}); len(errs) > 0 {
+			log.Printf("Failed to deliver alert %q: %v", alert.Name, errs)
+		}
+		go func(alert models.Alert, alertEvent models.AlertEvent) {
+			if errs := e.deliver(&alert, &alertEvent, []string{models.ChannelEmail, models.ChannelWebhook}); len(errs) > 0 {
+				log.Printf("Failed to deliver alert %q: %v", alert.Name, errs)
+			}
+		}(*alert, *alertEvent)
+	}
+}
+
+// Schedule checks the alerts every interval until stop is called, so that
+// alerts fire even when expenses are imported without going through the API.
+func (e *Evaluator) Schedule(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case now := <-ticker.C:
+				if _, err := e.Check(context.Background(), now.UTC()); err != nil {
+					log.Printf("Failed to check alerts: %v", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
+
+// matches reports whether expense counts toward the spending of alert in the
+// period containing now.
+func matches(alert *models.Alert, expense *models.Expense, now time.Time) bool {
+	from, to := PeriodBounds(now, alert.Period)
+	if expense.Date.Before(from) || !expense.Date.Before(to.AddDate(0, 0, 1)) {
+		return false
+	}
+	if alert.CardID != nil && *alert.CardID != expense.CardID {
+		return false
+	}
+	if alert.CategoryID == nil || *alert.CategoryID == expense.CategoryID {
+		return true
+	}
+	for _, item := range expense.Items {
+		if item.CategoryID == *alert.CategoryID {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluate returns the event of alert when the spending of the period
+// containing now reached the threshold and the alert has not fired in the
+// period yet. The period is recorded as fired before the event is returned.
+func (e *Evaluator) evaluate(ctx context.Context, alert *models.Alert, now time.Time) (*models.AlertEvent, error) {
+	from, to := PeriodBounds(now, alert.Period)
+	periodStart := from.Format(reports.DateLayout)
+	if alert.LastTriggered == periodStart {
+		return nil, nil
+	}
+
+	book, err := e.bookRepo.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	spent, err := e.expenseRepo.GetSpendingTotal(ctx, &models.ReportFilters{
+		StartDate:  &from,
+		EndDate:    &to,
+		CardID:     alert.CardID,
+		CategoryID: alert.CategoryID,
+		Currency:   book.BaseCurrency,
+	})
+	if err != nil {
+		return nil, err
+	}
+	threshold := Threshold(alert)
+	if spent < threshold {
+		return nil, nil
+	}
+
+	if err := e.alertRepo.SetLastTriggered(ctx, alert.ID, periodStart); err != nil {
+		return nil, err
+	}
+	alert.LastTriggered = periodStart
+
+	event := &models.AlertEvent{
+		AlertID:   alert.ID,
+		AlertName: alert.Name,
+		From:      periodStart,
+		To:        to.Format(reports.DateLayout),
+		Currency:  book.BaseCurrency,
+		Spent:     spent,
+		Amount:    alert.Amount,
+		Threshold: threshold,
+		Percent:   spent.Float64() / alert.Amount.Float64() * 100,
+	}
+	event.Message = fmt.Sprintf("%s %s of %s %s spent from %s to %s (%.0f%%).",
+		event.Spent, event.Currency, event.Amount, event.Currency, event.From, event.To, event.Percent)
+	return event, nil
+}
+
+// deliver sends event through the channels of alert that are among channels
+// and returns the errors of the ones that failed, or nil.
+func (e *Evaluator) deliver(alert *models.Alert, event *models.AlertEvent, channels []string) map[string]string {
+	var errs map[string]string
+	for _, channel := range alert.Channels {
+		if !contains(channels, channel) {
+			continue
+		}
+		notifier, ok := e.notifiers[channel]
+		if !ok {
+			err := fmt.Errorf("channel %s is not configured", channel)
+			errs = setError(errs, channel, err)
+			continue
+		}
+		if err := notifier.Notify(alert, event); err != nil {
+			errs = setError(errs, channel, err)
+		}
+	}
+	return errs
+}
+
+func setError(errs map[string]string, channel string, err error) map[string]string {
+	if errs == nil {
+		errs = map[string]string{}
+	}
+	errs[channel] = err.Error()
+	return errs
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
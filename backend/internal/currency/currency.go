@@ -0,0 +1,67 @@
+// Package currency validates ISO 4217 currency codes and looks up their
+// minor units (0 decimals for JPY, 2 for USD, ...).
+package currency
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/currency"
+)
+
+// Default is the base currency of a book that has not configured one.
+const Default = "JPY"
+
+// MaxMinorUnits is the largest number of decimals that amounts are stored
+// with, so currencies such as BHD (3 decimals) cannot be recorded.
+const MaxMinorUnits = 2
+
+// Normalize validates an ISO 4217 code and returns it in upper case.
+func Normalize(code string) (string, error) {
+	unit, err := currency.ParseISO(strings.TrimSpace(code))
+	if err != nil {
+		return "", fmt.Errorf("unknown currency code: %q", code)
+	}
+	if scale, _ := currency.Standard.Rounding(unit); scale > MaxMinorUnits {
+		return "", fmt.Errorf("currency %s uses %d decimal places; at most %d are supported", unit, scale, MaxMinorUnits)
+	}
+	return unit.String(), nil
+}
+
+// MinorUnits returns the number of decimals used by the currency. Unknown
+// codes use two decimals.
+func MinorUnits(code string) int {
+	unit, err := currency.ParseISO(code)
+	if err != nil {
+		return 2
+	}
+	scale, _ := currency.Standard.Rounding(unit)
+	return scale
+}
+
+// byMinorUnits lists the known currency codes by their number of decimals.
+// There is no list of the currencies to read them from, so every
+// three-letter code is looked up once.
+var byMinorUnits = sync.OnceValue(func() map[int][]string {
+	codes := map[int][]string{}
+	for a := 'A'; a <= 'Z'; a++ {
+		for b := 'A'; b <= 'Z'; b++ {
+			for c := 'A'; c <= 'Z'; c++ {
+				unit, err := currency.ParseISO(string([]rune{a, b, c}))
+				if err != nil {
+					continue
+				}
+				scale, _ := currency.Standard.Rounding(unit)
+				codes[scale] = append(codes[scale], unit.String())
+			}
+		}
+	}
+	return codes
+})
+
+// WithMinorUnits returns the codes of the currencies that use n decimals, in
+// alphabetical order.
+func WithMinorUnits(n int) []string {
+	return byMinorUnits()[n]
+}
@@ -0,0 +1,119 @@
+package currency
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rate is an exchange rate read from CSV: the price of one unit of Currency
+// in BaseCurrency on Date.
+type Rate struct {
+	Currency     string
+	BaseCurrency string
+	Date         time.Time
+	Rate         float64
+}
+
+// ParseRatesCSV reads exchange rates from CSV with a header row naming the
+// columns date (YYYY-MM-DD), currency and rate, plus an optional base column.
+// Rows without a base currency are quoted in defaultBase. Column order does
+// not matter and errors report the CSV line number.
+func ParseRatesCSV(r io.Reader, defaultBase string) ([]Rate, error) {
+	reader := csv.NewReader(skipBOM(r))
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("CSV is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "date":
+			columns["date"] = i
+		case "currency":
+			columns["currency"] = i
+		case "base", "basecurrency", "base_currency":
+			columns["base"] = i
+		case "rate":
+			columns["rate"] = i
+		}
+	}
+	for _, required := range []string{"date", "currency", "rate"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV header must contain a %q column", required)
+		}
+	}
+
+	var rates []Rate
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		date, err := time.Parse("2006-01-02", field("date"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: date must be in YYYY-MM-DD format", line)
+		}
+		code, err := Normalize(field("currency"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		base := defaultBase
+		if value := field("base"); value != "" {
+			base = value
+		}
+		base, err = Normalize(base)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		if code == base {
+			return nil, fmt.Errorf("line %d: currency and base currency must differ", line)
+		}
+		rate, err := strconv.ParseFloat(field("rate"), 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("line %d: rate must be a positive number", line)
+		}
+
+		rates = append(rates, Rate{
+			Currency:     code,
+			BaseCurrency: base,
+			Date:         date,
+			Rate:         rate,
+		})
+	}
+
+	return rates, nil
+}
+
+// skipBOM drops the UTF-8 byte order mark that spreadsheet exports prepend.
+func skipBOM(r io.Reader) io.Reader {
+	buffered := bufio.NewReader(r)
+	if bom, err := buffered.Peek(3); err == nil && string(bom) == "\xef\xbb\xbf" {
+		buffered.Discard(3)
+	}
+	return buffered
+}
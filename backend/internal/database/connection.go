@@ -4,11 +4,14 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"time"
 
-	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/migrate"
+	"kakeibo-tanuki/migrations"
 
 	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
@@ -17,42 +20,55 @@ type Database struct {
 	DB *gorm.DB
 }
 
+// NewDatabase connects to the database and applies the migrations it has
+// not applied yet.
 func NewDatabase() (*Database, error) {
-	dbHost := os.Getenv("DB_HOST")
-	if dbHost == "" {
-		dbHost = "localhost"
+	database, err := Open()
+	if err != nil {
+		return nil, err
 	}
 
-	dbPort := os.Getenv("DB_PORT")
-	if dbPort == "" {
-		dbPort = "5432"
+	migrator, err := database.Migrator()
+	if err != nil {
+		return nil, err
 	}
-
-	dbUser := os.Getenv("DB_USER")
-	if dbUser == "" {
-		dbUser = "postgres"
+	if _, err := migrator.Up(); err != nil {
+		return nil, fmt.Errorf("failed to migrate: %w", err)
 	}
 
-	dbPassword := os.Getenv("DB_PASSWORD")
-	if dbPassword == "" {
-		dbPassword = "postgres"
-	}
+	log.Println("Database connection established successfully")
+	return database, nil
+}
 
-	dbName := os.Getenv("DB_NAME")
-	if dbName == "" {
-		dbName = "kakeibo"
+// Open connects to the database selected by DB_DRIVER without migrating it.
+// "postgres" (default) connects to the server configured through the DB_*
+// variables, "sqlite" opens the file at DB_PATH, which suits a single user
+// running the app on a small machine.
+func Open() (*Database, error) {
+	driver := os.Getenv("DB_DRIVER")
+	if driver == "" {
+		driver = "postgres"
 	}
 
-	dsn := fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=UTC",
-		dbHost, dbUser, dbPassword, dbName, dbPort,
-	)
+	var dialector gorm.Dialector
+	switch driver {
+	case "postgres":
+		dialector = postgres.Open(postgresDSN())
+	case "sqlite":
+		dsn, err := sqliteDSN()
+		if err != nil {
+			return nil, err
+		}
+		dialector = sqlite.Open(dsn)
+	default:
+		return nil, fmt.Errorf("unknown database driver: %s", driver)
+	}
 
 	config := &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
 	}
 
-	db, err := gorm.Open(postgres.Open(dsn), config)
+	db, err := gorm.Open(dialector, config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
@@ -72,25 +88,66 @@ func NewDatabase() (*Database, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	database := &Database{DB: db}
+	return &Database{DB: db}, nil
+}
 
-	// Auto-migrate tables
-	if err := database.AutoMigrate(); err != nil {
-		return nil, fmt.Errorf("failed to auto-migrate: %w", err)
+func postgresDSN() string {
+	dbHost := os.Getenv("DB_HOST")
+	if dbHost == "" {
+		dbHost = "localhost"
 	}
 
-	log.Println("Database connection established successfully")
-	return database, nil
-}
+	dbPort := os.Getenv("DB_PORT")
+	if dbPort == "" {
+		dbPort = "5432"
+	}
+
+	dbUser := os.Getenv("DB_USER")
+	if dbUser == "" {
+		dbUser = "postgres"
+	}
+
+	dbPassword := os.Getenv("DB_PASSWORD")
+	if dbPassword == "" {
+		dbPassword = "postgres"
+	}
 
-func (d *Database) AutoMigrate() error {
-	return d.DB.AutoMigrate(
-		&models.Card{},
-		&models.Category{},
-		&models.Expense{},
+	dbName := os.Getenv("DB_NAME")
+	if dbName == "" {
+		dbName = "kakeibo"
+	}
+
+	return fmt.Sprintf(
+		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=UTC",
+		dbHost, dbUser, dbPassword, dbName, dbPort,
 	)
 }
 
+// sqliteDSN returns the DSN of the SQLite file at DB_PATH, creating its
+// directory. Foreign keys are enforced as on PostgreSQL, WAL lets requests
+// read while another writes, and transactions take the write lock when they
+// begin so that two of them never deadlock upgrading their locks.
+func sqliteDSN() (string, error) {
+	dbPath := os.Getenv("DB_PATH")
+	if dbPath == "" {
+		dbPath = "./data/kakeibo.db"
+	}
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create database directory: %w", err)
+	}
+	return dbPath + "?_foreign_keys=on&_journal_mode=WAL&_busy_timeout=5000&_txlock=immediate", nil
+}
+
+// Migrator returns a migrator of the database with the migrations of its
+// dialect embedded in the binary.
+func (d *Database) Migrator() (*migrate.Migrator, error) {
+	fsys, err := migrations.For(d.DB.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	return migrate.New(d.DB, fsys)
+}
+
 func (d *Database) Close() error {
 	sqlDB, err := d.DB.DB()
 	if err != nil {
@@ -0,0 +1,101 @@
+// Package events lets the parts of the backend that react to changes of
+// expenses, cards and categories subscribe to them instead of being called by
+// every handler that changes them.
+package events
+
+import (
+	"sync"
+	"time"
+
+	"kakeibo-tanuki/internal/models"
+)
+
+const (
+	ExpenseCreated  = "expense.created"
+	ExpenseUpdated  = "expense.updated"
+	ExpenseDeleted  = "expense.deleted"
+	CardCreated     = "card.created"
+	CardUpdated     = "card.updated"
+	CardDeleted     = "card.deleted"
+	CategoryCreated = "category.created"
+	CategoryUpdated = "category.updated"
+	CategoryDeleted = "category.deleted"
+)
+
+// Types lists all event types in the order they are documented.
+var Types = []string{
+	ExpenseCreated, ExpenseUpdated, ExpenseDeleted,
+	CardCreated, CardUpdated, CardDeleted,
+	CategoryCreated, CategoryUpdated, CategoryDeleted,
+}
+
+// Event is a change of an expense, card or category; the field of the kind
+// that changed is set. It holds the value after the change, or before it when
+// it was deleted.
+type Event struct {
+	Type     string           `json:"type"`
+	Time     time.Time        `json:"time"`
+	Expense  *models.Expense  `json:"expense,omitempty"`
+	Card     *models.Card     `json:"card,omitempty"`
+	Category *models.Category `json:"category,omitempty"`
+}
+
+// Subject returns the expense, card or category that changed.
+func (e Event) Subject() interface{} {
+	switch {
+	case e.Expense != nil:
+		return e.Expense
+	case e.Card != nil:
+		return e.Card
+	default:
+		return e.Category
+	}
+}
+
+// Bus delivers events to the subscribers in the order they subscribed. It is
+// safe for concurrent use.
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers []func(Event)
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe calls handler with every event published from now on.
+func (b *Bus) Subscribe(handler func(Event)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers = append(b.subscribers, handler)
+}
+
+// PublishExpense publishes a change of an expense.
+func (b *Bus) PublishExpense(eventType string, expense *models.Expense) {
+	b.publish(Event{Type: eventType, Expense: expense})
+}
+
+// PublishCard publishes a change of a card.
+func (b *Bus) PublishCard(eventType string, card *models.Card) {
+	b.publish(Event{Type: eventType, Card: card})
+}
+
+// PublishCategory publishes a change of a category.
+func (b *Bus) PublishCategory(eventType string, category *models.Category) {
+	b.publish(Event{Type: eventType, Category: category})
+}
+
+// publish calls the subscribers with event before it returns. Subscribers
+// doing slow work should do it in the background.
+func (b *Bus) publish(event Event) {
+	event.Time = time.Now().UTC()
+
+	b.mu.RLock()
+	subscribers := make([]func(Event), len(b.subscribers))
+	copy(subscribers, b.subscribers)
+	b.mu.RUnlock()
+
+	for _, handler := range subscribers {
+		handler(event)
+	}
+}
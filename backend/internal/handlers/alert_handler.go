@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+	"kakeibo-tanuki/internal/alerts"
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// DefaultAlertPercent is the percentage of the amount at which alerts fire
+// unless they say otherwise.
+const DefaultAlertPercent = 100
+
+type AlertHandler struct {
+	alertRepo    repositories.AlertRepository
+	cardRepo     repositories.CardRepository
+	categoryRepo repositories.CategoryRepository
+	evaluator    *alerts.Evaluator
+}
+
+func NewAlertHandler(alertRepo repositories.AlertRepository, cardRepo repositories.CardRepository, categoryRepo repositories.CategoryRepository, evaluator *alerts.Evaluator) *AlertHandler {
+	return &AlertHandler{
+		alertRepo:    alertRepo,
+		cardRepo:     cardRepo,
+		categoryRepo: categoryRepo,
+		evaluator:    evaluator,
+	}
+}
+
+func (h *AlertHandler) GetAlerts(c *gin.Context) {
+	allAlerts, err := h.alertRepo.GetAll(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("Failed to retrieve alerts")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Alerts retrieved successfully", allAlerts))
+}
+
+func (h *AlertHandler) GetAlert(c *gin.Context) {
+	alert, ok := h.findAlert(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Alert retrieved successfully", alert))
+}
+
+// CreateAlert creates an alert. New alerts are enabled unless the request
+// says otherwise.
+func (h *AlertHandler) CreateAlert(c *gin.Context) {
+	var req models.CreateAlertRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	alert := &models.Alert{ID: uuid.New(), Enabled: true}
+	if !h.setAlert(c, alert, models.UpdateAlertRequest(req)) {
+		return
+	}
+
+	if err := h.alertRepo.Create(c.Request.Context(), alert); err != nil {
+		c.Error(err).SetMeta("Failed to create alert")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.NewSuccessResponse("Alert created successfully", alert))
+}
+
+// UpdateAlert replaces an alert. Since the threshold may have changed, the
+// alert may fire again in the current period.
+func (h *AlertHandler) UpdateAlert(c *gin.Context) {
+	alert, ok := h.findAlert(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateAlertRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if !h.setAlert(c, alert, req) {
+		return
+	}
+	alert.LastTriggered = ""
+
+	if err := h.alertRepo.Update(c.Request.Context(), alert); err != nil {
+		c.Error(err).SetMeta("Failed to update alert")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Alert updated successfully", alert))
+}
+
+func (h *AlertHandler) DeleteAlert(c *gin.Context) {
+	alert, ok := h.findAlert(c)
+	if !ok {
+		return
+	}
+
+	if err := h.alertRepo.Delete(c.Request.Context(), alert.ID); err != nil {
+		c.Error(err).SetMeta("Failed to delete alert")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Alert deleted successfully", nil))
+}
+
+// CheckAlerts evaluates the alerts now, as the scheduler does, and lists the
+// ones that fired with the channels that failed to deliver them.
+func (h *AlertHandler) CheckAlerts(c *gin.Context) {
+	fired, err := h.evaluator.Check(c.Request.Context(), time.Now().UTC())
+	if err != nil {
+		writeReportError(c, err, "Failed to check alerts")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Alerts checked successfully", fired))
+}
+
+// setAlert checks the request and copies it into alert. Every channel must
+// be available and have its address, and the card and category the alert is
+// limited to must exist.
+func (h *AlertHandler) setAlert(c *gin.Context, alert *models.Alert, req models.UpdateAlertRequest) bool {
+	for _, channel := range req.Channels {
+		if !h.evaluator.HasChannel(channel) {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"CHANNEL_UNAVAILABLE",
+				"Notification channel is not available",
+				fmt.Sprintf("The %s channel is not configured on this server", channel),
+				c.Request.URL.Path,
+			))
+			return false
+		}
+		// The email and webhook channels need an address to deliver to
+		if channel == models.ChannelEmail && req.Email == "" {
+			requiredField(c, "email", "required_if", "channels "+channel)
+			return false
+		}
+		if channel == models.ChannelWebhook && req.WebhookURL == "" {
+			requiredField(c, "webhookUrl", "required_if", "channels "+channel)
+			return false
+		}
+	}
+
+	cardID, ok := parseReference(c, "Alert", req.CardID, "card", func(id uuid.UUID) error {
+		_, err := h.cardRepo.GetByID(c.Request.Context(), id)
+		return err
+	})
+	if !ok {
+		return false
+	}
+	categoryID, ok := parseReference(c, "Alert", req.CategoryID, "category", func(id uuid.UUID) error {
+		_, err := h.categoryRepo.GetByID(c.Request.Context(), id)
+		return err
+	})
+	if !ok {
+		return false
+	}
+
+	alert.Name = req.Name
+	alert.CardID = cardID
+	alert.CategoryID = categoryID
+	alert.Period = req.Period
+	alert.Amount = req.Amount
+	alert.Percent = req.Percent
+	if alert.Percent == 0 {
+		alert.Percent = DefaultAlertPercent
+	}
+	alert.Channels = req.Channels
+	alert.Email = req.Email
+	alert.WebhookURL = req.WebhookURL
+	if req.Enabled != nil {
+		alert.Enabled = *req.Enabled
+	}
+	return true
+}
+
+func (h *AlertHandler) findAlert(c *gin.Context) (*models.Alert, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"INVALID_UUID",
+			"Invalid alert ID format",
+			err.Error(),
+			c.Request.URL.Path,
+		))
+		return nil, false
+	}
+
+	alert, err := h.alertRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("Failed to retrieve alert")
+		return nil, false
+	}
+	return alert, true
+}
@@ -0,0 +1,313 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/repositories"
+	"kakeibo-tanuki/internal/storage"
+	"log"
+	"net/http"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// MaxAttachmentSize is the largest receipt file accepted by the upload endpoint.
+const MaxAttachmentSize = 10 << 20
+
+var allowedAttachmentTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+}
+
+type AttachmentHandler struct {
+	attachmentRepo repositories.AttachmentRepository
+	expenseRepo    repositories.ExpenseRepository
+	storage        storage.Storage
+}
+
+func NewAttachmentHandler(attachmentRepo repositories.AttachmentRepository, expenseRepo repositories.ExpenseRepository, store storage.Storage) *AttachmentHandler {
+	return &AttachmentHandler{
+		attachmentRepo: attachmentRepo,
+		expenseRepo:    expenseRepo,
+		storage:        store,
+	}
+}
+
+func (h *AttachmentHandler) GetAttachments(c *gin.Context) {
+	expenseID, ok := h.findExpense(c)
+	if !ok {
+		return
+	}
+
+	attachments, err := h.attachmentRepo.GetByExpenseID(c.Request.Context(), expenseID)
+	if err != nil {
+		c.Error(err).SetMeta("Failed to retrieve attachments")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Attachments retrieved successfully", attachments))
+}
+
+func (h *AttachmentHandler) UploadAttachment(c *gin.Context) {
+	expenseID, ok := h.findExpense(c)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, MaxAttachmentSize+1<<20)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"INVALID_REQUEST",
+			"Invalid multipart request",
+			"A file must be sent in the 'file' form field",
+			c.Request.URL.Path,
+		))
+		return
+	}
+
+	if fileHeader.Size > MaxAttachmentSize {
+		c.JSON(http.StatusRequestEntityTooLarge, models.NewErrorResponse(
+			"FILE_TOO_LARGE",
+			"Attachment is too large",
+			fmt.Sprintf("Attachments must be %d bytes or smaller", MaxAttachmentSize),
+			c.Request.URL.Path,
+		))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"INVALID_REQUEST",
+			"Failed to read uploaded file",
+			err.Error(),
+			c.Request.URL.Path,
+		))
+		return
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"INVALID_REQUEST",
+			"Failed to read uploaded file",
+			err.Error(),
+			c.Request.URL.Path,
+		))
+		return
+	}
+
+	// Trust the file contents rather than the client-supplied header
+	contentType := http.DetectContentType(content)
+	if !allowedAttachmentTypes[contentType] {
+		c.JSON(http.StatusUnsupportedMediaType, models.NewErrorResponse(
+			"UNSUPPORTED_FILE_TYPE",
+			"Unsupported attachment type",
+			"Attachments must be JPEG, PNG, GIF, WebP or PDF files",
+			c.Request.URL.Path,
+		))
+		return
+	}
+
+	attachment := &models.Attachment{
+		ID:          uuid.New(),
+		ExpenseID:   expenseID,
+		FileName:    filepath.Base(fileHeader.Filename),
+		ContentType: contentType,
+		Size:        int64(len(content)),
+	}
+	attachment.StorageKey = fmt.Sprintf("expenses/%s/%s", expenseID, attachment.ID)
+
+	if err := h.storage.Put(attachment.StorageKey, bytes.NewReader(content), attachment.Size, contentType); err != nil {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"STORAGE_ERROR",
+			"Failed to store attachment",
+			err.Error(),
+			c.Request.URL.Path,
+		))
+		return
+	}
+
+	if storage.SupportsThumbnail(contentType) {
+		thumbnail, err := storage.GenerateThumbnail(bytes.NewReader(content), contentType, storage.ThumbnailMaxSize)
+		if err != nil {
+			// A broken image is still worth keeping as the original receipt
+			log.Printf("Failed to generate thumbnail for attachment %s: %v", attachment.ID, err)
+		} else {
+			thumbnailKey := attachment.StorageKey + "_thumb.jpg"
+			if err := h.storage.Put(thumbnailKey, bytes.NewReader(thumbnail), int64(len(thumbnail)), "image/jpeg"); err != nil {
+				log.Printf("Failed to store thumbnail for attachment %s: %v", attachment.ID, err)
+			} else {
+				attachment.ThumbnailKey = thumbnailKey
+				attachment.HasThumbnail = true
+			}
+		}
+	}
+
+	if err := h.attachmentRepo.Create(c.Request.Context(), attachment); err != nil {
+		h.removeObjects(attachment)
+		c.Error(err).SetMeta("Failed to create attachment")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.NewSuccessResponse("Attachment uploaded successfully", attachment))
+}
+
+func (h *AttachmentHandler) DownloadAttachment(c *gin.Context) {
+	attachment, ok := h.findAttachment(c)
+	if !ok {
+		return
+	}
+
+	h.serveObject(c, attachment.StorageKey, attachment.ContentType, attachment.FileName)
+}
+
+func (h *AttachmentHandler) DownloadThumbnail(c *gin.Context) {
+	attachment, ok := h.findAttachment(c)
+	if !ok {
+		return
+	}
+
+	if !attachment.HasThumbnail {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse(
+			"THUMBNAIL_NOT_FOUND",
+			"Thumbnail not available",
+			"Thumbnails are only generated for JPEG and PNG images",
+			c.Request.URL.Path,
+		))
+		return
+	}
+
+	h.serveObject(c, attachment.ThumbnailKey, "image/jpeg", "")
+}
+
+func (h *AttachmentHandler) DeleteAttachment(c *gin.Context) {
+	attachment, ok := h.findAttachment(c)
+	if !ok {
+		return
+	}
+
+	if err := h.attachmentRepo.Delete(c.Request.Context(), attachment.ID); err != nil {
+		c.Error(err).SetMeta("Failed to delete attachment")
+		return
+	}
+
+	h.removeObjects(attachment)
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Attachment deleted successfully", nil))
+}
+
+func (h *AttachmentHandler) findExpense(c *gin.Context) (uuid.UUID, bool) {
+	expenseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"INVALID_UUID",
+			"Invalid expense ID format",
+			err.Error(),
+			c.Request.URL.Path,
+		))
+		return uuid.Nil, false
+	}
+
+	if _, err := h.expenseRepo.GetByIDWithoutPreload(c.Request.Context(), expenseID); err != nil {
+		c.Error(err).SetMeta("Failed to retrieve expense")
+		return uuid.Nil, false
+	}
+
+	return expenseID, true
+}
+
+func (h *AttachmentHandler) findAttachment(c *gin.Context) (*models.Attachment, bool) {
+	expenseID, ok := h.findExpense(c)
+	if !ok {
+		return nil, false
+	}
+
+	attachmentID, err := uuid.Parse(c.Param("attachmentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"INVALID_UUID",
+			"Invalid attachment ID format",
+			err.Error(),
+			c.Request.URL.Path,
+		))
+		return nil, false
+	}
+
+	attachment, err := h.attachmentRepo.GetByID(c.Request.Context(), attachmentID)
+	if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		c.Error(err).SetMeta("Failed to retrieve attachment")
+		return nil, false
+	}
+
+	if attachment == nil || attachment.ExpenseID != expenseID {
+		c.JSON(http.StatusNotFound, models.NewErrorResponse(
+			"ATTACHMENT_NOT_FOUND",
+			"Attachment not found",
+			nil,
+			c.Request.URL.Path,
+		))
+		return nil, false
+	}
+
+	return attachment, true
+}
+
+func (h *AttachmentHandler) serveObject(c *gin.Context, key, contentType, fileName string) {
+	object, err := h.storage.Get(key)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			c.JSON(http.StatusNotFound, models.NewErrorResponse(
+				"ATTACHMENT_NOT_FOUND",
+				"Attachment file is missing from storage",
+				nil,
+				c.Request.URL.Path,
+			))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
+			"STORAGE_ERROR",
+			"Failed to read attachment",
+			err.Error(),
+			c.Request.URL.Path,
+		))
+		return
+	}
+	defer object.Close()
+
+	if fileName != "" {
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	}
+	c.Status(http.StatusOK)
+	c.Header("Content-Type", contentType)
+	if _, err := io.Copy(c.Writer, object); err != nil {
+		log.Printf("Failed to stream attachment %s: %v", key, err)
+	}
+}
+
+func (h *AttachmentHandler) removeObjects(attachment *models.Attachment) {
+	removeAttachmentObjects(h.storage, attachment)
+}
+
+// removeAttachmentObjects deletes the stored original and thumbnail of an
+// attachment. Failures are only logged because the database row is already gone.
+func removeAttachmentObjects(store storage.Storage, attachment *models.Attachment) {
+	if err := store.Delete(attachment.StorageKey); err != nil {
+		log.Printf("Failed to delete attachment file %s: %v", attachment.StorageKey, err)
+	}
+	if attachment.ThumbnailKey != "" {
+		if err := store.Delete(attachment.ThumbnailKey); err != nil {
+			log.Printf("Failed to delete thumbnail file %s: %v", attachment.ThumbnailKey, err)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"net/http"
+	"kakeibo-tanuki/internal/currency"
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BookHandler struct {
+	bookRepo repositories.BookRepository
+}
+
+func NewBookHandler(bookRepo repositories.BookRepository) *BookHandler {
+	return &BookHandler{
+		bookRepo: bookRepo,
+	}
+}
+
+func (h *BookHandler) GetBook(c *gin.Context) {
+	book, err := h.bookRepo.Get(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("Failed to retrieve book settings")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Book retrieved successfully", book))
+}
+
+func (h *BookHandler) UpdateBook(c *gin.Context) {
+	var req models.UpdateBookRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	baseCurrency, err := currency.Normalize(req.BaseCurrency)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"INVALID_CURRENCY",
+			"Invalid currency code",
+			err.Error(),
+			c.Request.URL.Path,
+		))
+		return
+	}
+
+	book, err := h.bookRepo.Get(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("Failed to retrieve book settings")
+		return
+	}
+
+	book.Name = req.Name
+	book.BaseCurrency = baseCurrency
+	book.RemainderMember = req.RemainderMember
+
+	if err := h.bookRepo.Update(c.Request.Context(), book); err != nil {
+		c.Error(err).SetMeta("Failed to update book settings")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Book updated successfully", book))
+}
@@ -2,35 +2,30 @@ package handlers
 
 import (
 	"net/http"
+	"kakeibo-tanuki/internal/events"
 	"kakeibo-tanuki/internal/models"
-	"kakeibo-tanuki/internal/repositories"
+	"kakeibo-tanuki/internal/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"github.com/go-playground/validator/v10"
 )
 
 type CardHandler struct {
-	cardRepo repositories.CardRepository
-	validator *validator.Validate
+	cardService *services.CardService
+	bus         *events.Bus
 }
 
-func NewCardHandler(cardRepo repositories.CardRepository) *CardHandler {
+func NewCardHandler(cardService *services.CardService, bus *events.Bus) *CardHandler {
 	return &CardHandler{
-		cardRepo:  cardRepo,
-		validator: validator.New(),
+		cardService: cardService,
+		bus:         bus,
 	}
 }
 
 func (h *CardHandler) GetCards(c *gin.Context) {
-	cards, err := h.cardRepo.GetAll()
+	cards, err := h.cardService.List(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			"INTERNAL_ERROR",
-			"Failed to retrieve cards",
-			err.Error(),
-			c.Request.URL.Path,
-		))
+		c.Error(err).SetMeta("Failed to retrieve cards")
 		return
 	}
 
@@ -38,35 +33,14 @@ func (h *CardHandler) GetCards(c *gin.Context) {
 }
 
 func (h *CardHandler) GetCard(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := uuid.Parse(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			"INVALID_UUID",
-			"Invalid card ID format",
-			err.Error(),
-			c.Request.URL.Path,
-		))
+	id, ok := h.parseID(c)
+	if !ok {
 		return
 	}
 
-	card, err := h.cardRepo.GetByID(id)
+	card, err := h.cardService.Get(c.Request.Context(), id)
 	if err != nil {
-		if err.Error() == "record not found" {
-			c.JSON(http.StatusNotFound, models.NewErrorResponse(
-				"CARD_NOT_FOUND",
-				"Card not found",
-				nil,
-				c.Request.URL.Path,
-			))
-			return
-		}
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			"INTERNAL_ERROR",
-			"Failed to retrieve card",
-			err.Error(),
-			c.Request.URL.Path,
-		))
+		c.Error(err).SetMeta("Failed to retrieve card")
 		return
 	}
 
@@ -75,181 +49,70 @@ func (h *CardHandler) GetCard(c *gin.Context) {
 
 func (h *CardHandler) CreateCard(c *gin.Context) {
 	var req models.CreateCardRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			"INVALID_REQUEST",
-			"Invalid request body",
-			err.Error(),
-			c.Request.URL.Path,
-		))
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	if err := h.validator.Struct(req); err != nil {
-		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			"VALIDATION_ERROR",
-			"Validation failed",
-			err.Error(),
-			c.Request.URL.Path,
-		))
+	card, err := h.cardService.Create(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err).SetMeta("Failed to create card")
 		return
 	}
 
-	card := &models.Card{
-		ID:    uuid.New(),
-		Name:  req.Name,
-		Color: req.Color,
-	}
-
-	if err := h.cardRepo.Create(card); err != nil {
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			"INTERNAL_ERROR",
-			"Failed to create card",
-			err.Error(),
-			c.Request.URL.Path,
-		))
-		return
-	}
+	h.bus.PublishCard(events.CardCreated, card)
 
 	c.JSON(http.StatusCreated, models.NewSuccessResponse("Card created successfully", card))
 }
 
 func (h *CardHandler) UpdateCard(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := uuid.Parse(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			"INVALID_UUID",
-			"Invalid card ID format",
-			err.Error(),
-			c.Request.URL.Path,
-		))
-		return
-	}
-
-	// Check if card exists
-	card, err := h.cardRepo.GetByID(id)
-	if err != nil {
-		if err.Error() == "record not found" {
-			c.JSON(http.StatusNotFound, models.NewErrorResponse(
-				"CARD_NOT_FOUND",
-				"Card not found",
-				nil,
-				c.Request.URL.Path,
-			))
-			return
-		}
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			"INTERNAL_ERROR",
-			"Failed to retrieve card",
-			err.Error(),
-			c.Request.URL.Path,
-		))
+	id, ok := h.parseID(c)
+	if !ok {
 		return
 	}
 
 	var req models.UpdateCardRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			"INVALID_REQUEST",
-			"Invalid request body",
-			err.Error(),
-			c.Request.URL.Path,
-		))
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	if err := h.validator.Struct(req); err != nil {
-		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			"VALIDATION_ERROR",
-			"Validation failed",
-			err.Error(),
-			c.Request.URL.Path,
-		))
+	card, err := h.cardService.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		c.Error(err).SetMeta("Failed to update card")
 		return
 	}
 
-	card.Name = req.Name
-	card.Color = req.Color
-
-	if err := h.cardRepo.Update(card); err != nil {
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			"INTERNAL_ERROR",
-			"Failed to update card",
-			err.Error(),
-			c.Request.URL.Path,
-		))
-		return
-	}
+	h.bus.PublishCard(events.CardUpdated, card)
 
 	c.JSON(http.StatusOK, models.NewSuccessResponse("Card updated successfully", card))
 }
 
 func (h *CardHandler) DeleteCard(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := uuid.Parse(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			"INVALID_UUID",
-			"Invalid card ID format",
-			err.Error(),
-			c.Request.URL.Path,
-		))
+	id, ok := h.parseID(c)
+	if !ok {
 		return
 	}
 
-	// Check if card exists
-	_, err = h.cardRepo.GetByID(id)
+	card, err := h.cardService.Delete(c.Request.Context(), id)
 	if err != nil {
-		if err.Error() == "record not found" {
-			c.JSON(http.StatusNotFound, models.NewErrorResponse(
-				"CARD_NOT_FOUND",
-				"Card not found",
-				nil,
-				c.Request.URL.Path,
-			))
-			return
-		}
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			"INTERNAL_ERROR",
-			"Failed to retrieve card",
-			err.Error(),
-			c.Request.URL.Path,
-		))
+		c.Error(err).SetMeta("Failed to delete card")
 		return
 	}
 
-	// Check if card has expenses
-	hasExpenses, err := h.cardRepo.HasExpenses(id)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			"INTERNAL_ERROR",
-			"Failed to check card dependencies",
-			err.Error(),
-			c.Request.URL.Path,
-		))
-		return
-	}
+	h.bus.PublishCard(events.CardDeleted, card)
 
-	if hasExpenses {
-		c.JSON(http.StatusConflict, models.NewErrorResponse(
-			"CARD_HAS_EXPENSES",
-			"Cannot delete card with associated expenses",
-			"This card has expenses associated with it. Please delete the expenses first or reassign them to another card.",
-			c.Request.URL.Path,
-		))
-		return
-	}
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Card deleted successfully", nil))
+}
 
-	if err := h.cardRepo.Delete(id); err != nil {
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			"INTERNAL_ERROR",
-			"Failed to delete card",
+func (h *CardHandler) parseID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"INVALID_UUID",
+			"Invalid card ID format",
 			err.Error(),
 			c.Request.URL.Path,
 		))
-		return
+		return uuid.Nil, false
 	}
-
-	c.JSON(http.StatusOK, models.NewSuccessResponse("Card deleted successfully", nil))
-}
\ No newline at end of file
+	return id, true
+}
@@ -2,36 +2,30 @@ package handlers
 
 import (
 	"net/http"
-	"strings"
+	"kakeibo-tanuki/internal/events"
 	"kakeibo-tanuki/internal/models"
-	"kakeibo-tanuki/internal/repositories"
+	"kakeibo-tanuki/internal/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"github.com/go-playground/validator/v10"
 )
 
 type CategoryHandler struct {
-	categoryRepo repositories.CategoryRepository
-	validator    *validator.Validate
+	categoryService *services.CategoryService
+	bus             *events.Bus
 }
 
-func NewCategoryHandler(categoryRepo repositories.CategoryRepository) *CategoryHandler {
+func NewCategoryHandler(categoryService *services.CategoryService, bus *events.Bus) *CategoryHandler {
 	return &CategoryHandler{
-		categoryRepo: categoryRepo,
-		validator:    validator.New(),
+		categoryService: categoryService,
+		bus:             bus,
 	}
 }
 
 func (h *CategoryHandler) GetCategories(c *gin.Context) {
-	categories, err := h.categoryRepo.GetAll()
+	categories, err := h.categoryService.List(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			"INTERNAL_ERROR",
-			"Failed to retrieve categories",
-			err.Error(),
-			c.Request.URL.Path,
-		))
+		c.Error(err).SetMeta("Failed to retrieve categories")
 		return
 	}
 
@@ -39,35 +33,14 @@ func (h *CategoryHandler) GetCategories(c *gin.Context) {
 }
 
 func (h *CategoryHandler) GetCategory(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := uuid.Parse(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			"INVALID_UUID",
-			"Invalid category ID format",
-			err.Error(),
-			c.Request.URL.Path,
-		))
+	id, ok := h.parseID(c)
+	if !ok {
 		return
 	}
 
-	category, err := h.categoryRepo.GetByID(id)
+	category, err := h.categoryService.Get(c.Request.Context(), id)
 	if err != nil {
-		if err.Error() == "record not found" {
-			c.JSON(http.StatusNotFound, models.NewErrorResponse(
-				"CATEGORY_NOT_FOUND",
-				"Category not found",
-				nil,
-				c.Request.URL.Path,
-			))
-			return
-		}
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			"INTERNAL_ERROR",
-			"Failed to retrieve category",
-			err.Error(),
-			c.Request.URL.Path,
-		))
+		c.Error(err).SetMeta("Failed to retrieve category")
 		return
 	}
 
@@ -76,205 +49,70 @@ func (h *CategoryHandler) GetCategory(c *gin.Context) {
 
 func (h *CategoryHandler) CreateCategory(c *gin.Context) {
 	var req models.CreateCategoryRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			"INVALID_REQUEST",
-			"Invalid request body",
-			err.Error(),
-			c.Request.URL.Path,
-		))
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	if err := h.validator.Struct(req); err != nil {
-		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			"VALIDATION_ERROR",
-			"Validation failed",
-			err.Error(),
-			c.Request.URL.Path,
-		))
+	category, err := h.categoryService.Create(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err).SetMeta("Failed to create category")
 		return
 	}
 
-	category := &models.Category{
-		ID:       uuid.New(),
-		Name:     req.Name,
-		Color:    req.Color,
-		IsShared: req.IsShared,
-	}
-
-	if err := h.categoryRepo.Create(category); err != nil {
-		// Check for unique constraint violation (SQLite)
-		if strings.Contains(err.Error(), "UNIQUE constraint failed") || 
-		   strings.Contains(err.Error(), "duplicate key") {
-			c.JSON(http.StatusConflict, models.NewErrorResponse(
-				"DUPLICATE_CATEGORY",
-				"Category with this name already exists",
-				"A category with this name already exists. Please choose a different name.",
-				c.Request.URL.Path,
-			))
-			return
-		}
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			"INTERNAL_ERROR",
-			"Failed to create category",
-			err.Error(),
-			c.Request.URL.Path,
-		))
-		return
-	}
+	h.bus.PublishCategory(events.CategoryCreated, category)
 
 	c.JSON(http.StatusCreated, models.NewSuccessResponse("Category created successfully", category))
 }
 
 func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := uuid.Parse(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			"INVALID_UUID",
-			"Invalid category ID format",
-			err.Error(),
-			c.Request.URL.Path,
-		))
-		return
-	}
-
-	// Check if category exists
-	category, err := h.categoryRepo.GetByID(id)
-	if err != nil {
-		if err.Error() == "record not found" {
-			c.JSON(http.StatusNotFound, models.NewErrorResponse(
-				"CATEGORY_NOT_FOUND",
-				"Category not found",
-				nil,
-				c.Request.URL.Path,
-			))
-			return
-		}
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			"INTERNAL_ERROR",
-			"Failed to retrieve category",
-			err.Error(),
-			c.Request.URL.Path,
-		))
+	id, ok := h.parseID(c)
+	if !ok {
 		return
 	}
 
 	var req models.UpdateCategoryRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			"INVALID_REQUEST",
-			"Invalid request body",
-			err.Error(),
-			c.Request.URL.Path,
-		))
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	if err := h.validator.Struct(req); err != nil {
-		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			"VALIDATION_ERROR",
-			"Validation failed",
-			err.Error(),
-			c.Request.URL.Path,
-		))
+	category, err := h.categoryService.Update(c.Request.Context(), id, &req)
+	if err != nil {
+		c.Error(err).SetMeta("Failed to update category")
 		return
 	}
 
-	category.Name = req.Name
-	category.Color = req.Color
-	category.IsShared = req.IsShared
-
-	if err := h.categoryRepo.Update(category); err != nil {
-		// Check for unique constraint violation (SQLite)
-		if strings.Contains(err.Error(), "UNIQUE constraint failed") || 
-		   strings.Contains(err.Error(), "duplicate key") {
-			c.JSON(http.StatusConflict, models.NewErrorResponse(
-				"DUPLICATE_CATEGORY",
-				"Category with this name already exists",
-				"A category with this name already exists. Please choose a different name.",
-				c.Request.URL.Path,
-			))
-			return
-		}
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			"INTERNAL_ERROR",
-			"Failed to update category",
-			err.Error(),
-			c.Request.URL.Path,
-		))
-		return
-	}
+	h.bus.PublishCategory(events.CategoryUpdated, category)
 
 	c.JSON(http.StatusOK, models.NewSuccessResponse("Category updated successfully", category))
 }
 
 func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := uuid.Parse(idStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			"INVALID_UUID",
-			"Invalid category ID format",
-			err.Error(),
-			c.Request.URL.Path,
-		))
+	id, ok := h.parseID(c)
+	if !ok {
 		return
 	}
 
-	// Check if category exists
-	_, err = h.categoryRepo.GetByID(id)
+	category, err := h.categoryService.Delete(c.Request.Context(), id)
 	if err != nil {
-		if err.Error() == "record not found" {
-			c.JSON(http.StatusNotFound, models.NewErrorResponse(
-				"CATEGORY_NOT_FOUND",
-				"Category not found",
-				nil,
-				c.Request.URL.Path,
-			))
-			return
-		}
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			"INTERNAL_ERROR",
-			"Failed to retrieve category",
-			err.Error(),
-			c.Request.URL.Path,
-		))
+		c.Error(err).SetMeta("Failed to delete category")
 		return
 	}
 
-	// Check if category has expenses
-	hasExpenses, err := h.categoryRepo.HasExpenses(id)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			"INTERNAL_ERROR",
-			"Failed to check category dependencies",
-			err.Error(),
-			c.Request.URL.Path,
-		))
-		return
-	}
+	h.bus.PublishCategory(events.CategoryDeleted, category)
 
-	if hasExpenses {
-		c.JSON(http.StatusConflict, models.NewErrorResponse(
-			"CATEGORY_HAS_EXPENSES",
-			"Cannot delete category with associated expenses",
-			"This category has expenses associated with it. Please delete the expenses first or reassign them to another category.",
-			c.Request.URL.Path,
-		))
-		return
-	}
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Category deleted successfully", nil))
+}
 
-	if err := h.categoryRepo.Delete(id); err != nil {
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			"INTERNAL_ERROR",
-			"Failed to delete category",
+func (h *CategoryHandler) parseID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"INVALID_UUID",
+			"Invalid category ID format",
 			err.Error(),
 			c.Request.URL.Path,
 		))
-		return
+		return uuid.Nil, false
 	}
-
-	c.JSON(http.StatusOK, models.NewSuccessResponse("Category deleted successfully", nil))
-}
\ No newline at end of file
+	return id, true
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/stream"
+)
+
+// EventRetry is how long clients wait before reconnecting, in milliseconds.
+const EventRetry = 3000
+
+type EventHandler struct {
+	broker    *stream.Broker
+	heartbeat time.Duration
+}
+
+// NewEventHandler streams the events of broker, with a ping comment every
+// heartbeat so that proxies keep idle connections open.
+func NewEventHandler(broker *stream.Broker, heartbeat time.Duration) *EventHandler {
+	return &EventHandler{
+		broker:    broker,
+		heartbeat: heartbeat,
+	}
+}
+
+// StreamEvents streams created, updated and deleted expenses, cards and
+// categories as server-sent events until the client disconnects. Clients
+// resume with the Last-Event-ID header, or the lastEventId query parameter
+// for the first connection of an EventSource.
+func (h *EventHandler) StreamEvents(c *gin.Context) {
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("lastEventId")
+	}
+	var lastID uint64
+	if lastEventID != "" {
+		id, err := strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"INVALID_EVENT_ID",
+				"Invalid last event ID",
+				err.Error(),
+				c.Request.URL.Path,
+			))
+			return
+		}
+		lastID = id
+	}
+
+	replay, messages, cancel := h.broker.Subscribe(lastID, lastEventID != "")
+	defer cancel()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// Keeps nginx from buffering the stream
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	w := c.Writer
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", EventRetry); err != nil {
+		return
+	}
+	for _, message := range replay {
+		if writeEvent(w, message) != nil {
+			return
+		}
+	}
+	w.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case message, ok := <-messages:
+			// Closed when the client was too slow; it resumes when it
+			// reconnects
+			if !ok || writeEvent(w, message) != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+		w.Flush()
+	}
+}
+
+func writeEvent(w io.Writer, message stream.Message) error {
+	return sse.Encode(w, sse.Event{
+		Id:    strconv.FormatUint(message.ID, 10),
+		Event: message.Event,
+		Data:  string(message.Data),
+	})
+}
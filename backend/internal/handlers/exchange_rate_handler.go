@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"kakeibo-tanuki/internal/currency"
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// MaxRateImportSize limits the size of an uploaded exchange rate CSV.
+const MaxRateImportSize = 5 << 20
+
+type ExchangeRateHandler struct {
+	rateRepo repositories.ExchangeRateRepository
+	bookRepo repositories.BookRepository
+}
+
+func NewExchangeRateHandler(rateRepo repositories.ExchangeRateRepository, bookRepo repositories.BookRepository) *ExchangeRateHandler {
+	return &ExchangeRateHandler{
+		rateRepo: rateRepo,
+		bookRepo: bookRepo,
+	}
+}
+
+func (h *ExchangeRateHandler) GetExchangeRates(c *gin.Context) {
+	filters := &models.ExchangeRateFilters{}
+
+	if code := c.Query("currency"); code != "" {
+		normalized, err := currency.Normalize(code)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"INVALID_CURRENCY",
+				"Invalid currency code",
+				err.Error(),
+				c.Request.URL.Path,
+			))
+			return
+		}
+		filters.Currency = normalized
+	}
+
+	if code := c.Query("baseCurrency"); code != "" {
+		normalized, err := currency.Normalize(code)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"INVALID_CURRENCY",
+				"Invalid base currency code",
+				err.Error(),
+				c.Request.URL.Path,
+			))
+			return
+		}
+		filters.BaseCurrency = normalized
+	}
+
+	if startDate := c.Query("startDate"); startDate != "" {
+		if date, err := time.Parse("2006-01-02", startDate); err == nil {
+			filters.StartDate = &date
+		}
+	}
+
+	if endDate := c.Query("endDate"); endDate != "" {
+		if date, err := time.Parse("2006-01-02", endDate); err == nil {
+			filters.EndDate = &date
+		}
+	}
+
+	rates, err := h.rateRepo.GetAll(c.Request.Context(), filters)
+	if err != nil {
+		c.Error(err).SetMeta("Failed to retrieve exchange rates")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Exchange rates retrieved successfully", rates))
+}
+
+// CreateExchangeRate stores a manually entered rate, replacing any rate of
+// the same currency pair and date.
+func (h *ExchangeRateHandler) CreateExchangeRate(c *gin.Context) {
+	var req models.CreateExchangeRateRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"INVALID_DATE",
+			"Invalid date format",
+			"Date must be in YYYY-MM-DD format",
+			c.Request.URL.Path,
+		))
+		return
+	}
+
+	code, err := currency.Normalize(req.Currency)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"INVALID_CURRENCY",
+			"Invalid currency code",
+			err.Error(),
+			c.Request.URL.Path,
+		))
+		return
+	}
+
+	baseCurrency, ok := h.baseCurrency(c, req.BaseCurrency)
+	if !ok {
+		return
+	}
+
+	if code == baseCurrency {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"INVALID_CURRENCY",
+			"Currency and base currency must differ",
+			nil,
+			c.Request.URL.Path,
+		))
+		return
+	}
+
+	rate := &models.ExchangeRate{
+		ID:           uuid.New(),
+		Currency:     code,
+		BaseCurrency: baseCurrency,
+		Date:         date,
+		Rate:         req.Rate,
+	}
+
+	if err := h.rateRepo.Save(c.Request.Context(), rate); err != nil {
+		c.Error(err).SetMeta("Failed to save exchange rate")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.NewSuccessResponse("Exchange rate saved successfully", rate))
+}
+
+// ImportExchangeRates imports rates from a CSV sent either as the "file"
+// field of a multipart form or as the raw request body. Rows without a base
+// column are quoted in the base currency of the book.
+func (h *ExchangeRateHandler) ImportExchangeRates(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, MaxRateImportSize)
+
+	var body io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"INVALID_REQUEST",
+				"Invalid multipart request",
+				"A CSV file must be sent in the 'file' form field",
+				c.Request.URL.Path,
+			))
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"INVALID_REQUEST",
+				"Failed to read uploaded file",
+				err.Error(),
+				c.Request.URL.Path,
+			))
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	baseCurrency, ok := h.baseCurrency(c, "")
+	if !ok {
+		return
+	}
+
+	parsed, err := currency.ParseRatesCSV(body, baseCurrency)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"INVALID_CSV",
+			"Invalid exchange rate CSV",
+			err.Error(),
+			c.Request.URL.Path,
+		))
+		return
+	}
+
+	rates := make([]models.ExchangeRate, 0, len(parsed))
+	for _, rate := range parsed {
+		rates = append(rates, models.ExchangeRate{
+			ID:           uuid.New(),
+			Currency:     rate.Currency,
+			BaseCurrency: rate.BaseCurrency,
+			Date:         rate.Date,
+			Rate:         rate.Rate,
+		})
+	}
+
+	if err := h.rateRepo.SaveAll(c.Request.Context(), rates); err != nil {
+		c.Error(err).SetMeta("Failed to import exchange rates")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Exchange rates imported successfully", models.ExchangeRateImportResult{
+		Imported: len(rates),
+	}))
+}
+
+func (h *ExchangeRateHandler) DeleteExchangeRate(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"INVALID_UUID",
+			"Invalid exchange rate ID format",
+			err.Error(),
+			c.Request.URL.Path,
+		))
+		return
+	}
+
+	if _, err := h.rateRepo.GetByID(c.Request.Context(), id); err != nil {
+		c.Error(err).SetMeta("Failed to retrieve exchange rate")
+		return
+	}
+
+	if err := h.rateRepo.Delete(c.Request.Context(), id); err != nil {
+		c.Error(err).SetMeta("Failed to delete exchange rate")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Exchange rate deleted successfully", nil))
+}
+
+// baseCurrency validates the requested base currency, defaulting to the base
+// currency of the book.
+func (h *ExchangeRateHandler) baseCurrency(c *gin.Context, code string) (string, bool) {
+	if code == "" {
+		book, err := h.bookRepo.Get(c.Request.Context())
+		if err != nil {
+			c.Error(err).SetMeta("Failed to retrieve book settings")
+			return "", false
+		}
+		return book.BaseCurrency, true
+	}
+
+	normalized, err := currency.Normalize(code)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"INVALID_CURRENCY",
+			"Invalid base currency code",
+			err.Error(),
+			c.Request.URL.Path,
+		))
+		return "", false
+	}
+	return normalized, true
+}
@@ -4,23 +4,26 @@ import (
 	"net/http"
 	"strconv"
 	"time"
+	"kakeibo-tanuki/internal/events"
 	"kakeibo-tanuki/internal/models"
-	"kakeibo-tanuki/internal/repositories"
+	"kakeibo-tanuki/internal/services"
+	"kakeibo-tanuki/internal/storage"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"github.com/go-playground/validator/v10"
 )
 
 type ExpenseHandler struct {
-	expenseRepo repositories.ExpenseRepository
-	validator   *validator.Validate
+	expenseService *services.ExpenseService
+	bus            *events.Bus
+	storage        storage.Storage
 }
 
-func NewExpenseHandler(expenseRepo repositories.ExpenseRepository) *ExpenseHandler {
+func NewExpenseHandler(expenseService *services.ExpenseService, bus *events.Bus, store storage.Storage) *ExpenseHandler {
 	return &ExpenseHandler{
-		expenseRepo: expenseRepo,
-		validator:   validator.New(),
+		expenseService: expenseService,
+		bus:            bus,
+		storage:        store,
 	}
 }
 
@@ -52,6 +55,12 @@ func (h *ExpenseHandler) GetExpenses(c *gin.Context) {
 		}
 	}
 
+	if payeeID := c.Query("payeeId"); payeeID != "" {
+		if id, err := uuid.Parse(payeeID); err == nil {
+			filters.PayeeID = &id
+		}
+	}
+
 	if page := c.Query("page"); page != "" {
 		if p, err := strconv.Atoi(page); err == nil && p > 0 {
 			filters.Page = p
@@ -64,14 +73,9 @@ func (h *ExpenseHandler) GetExpenses(c *gin.Context) {
 		}
 	}
 
-	expenses, totalCount, err := h.expenseRepo.GetAll(filters)
+	expenses, totalCount, err := h.expenseService.List(c.Request.Context(), filters)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			"INTERNAL_ERROR",
-			"Failed to retrieve expenses",
-			err.Error(),
-			c.Request.URL.Path,
-		))
+		c.Error(err).SetMeta("Failed to retrieve expenses")
 		return
 	}
 
@@ -109,23 +113,9 @@ func (h *ExpenseHandler) GetExpense(c *gin.Context) {
 		return
 	}
 
-	expense, err := h.expenseRepo.GetByID(id)
+	expense, err := h.expenseService.Get(c.Request.Context(), id)
 	if err != nil {
-		if err.Error() == "record not found" {
-			c.JSON(http.StatusNotFound, models.NewErrorResponse(
-				"EXPENSE_NOT_FOUND",
-				"Expense not found",
-				nil,
-				c.Request.URL.Path,
-			))
-			return
-		}
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			"INTERNAL_ERROR",
-			"Failed to retrieve expense",
-			err.Error(),
-			c.Request.URL.Path,
-		))
+		c.Error(err).SetMeta("Failed to retrieve expense")
 		return
 	}
 
@@ -134,106 +124,26 @@ func (h *ExpenseHandler) GetExpense(c *gin.Context) {
 
 func (h *ExpenseHandler) CreateExpense(c *gin.Context) {
 	var req models.CreateExpenseRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			"INVALID_REQUEST",
-			"Invalid request body",
-			err.Error(),
-			c.Request.URL.Path,
-		))
-		return
-	}
-
-
-	if err := h.validator.Struct(req); err != nil {
-		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			"VALIDATION_ERROR",
-			"Validation failed",
-			err.Error(),
-			c.Request.URL.Path,
-		))
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	// Parse date string (try YYYY-MM-DD format first)
-	parsedDate, err := time.Parse("2006-01-02", req.Date)
+	createdExpense, err := h.expenseService.Create(c.Request.Context(), &req)
 	if err != nil {
-		// Try parsing as RFC3339 format
-		parsedDate, err = time.Parse(time.RFC3339, req.Date)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-				"INVALID_DATE",
-				"Invalid date format",
-				"Date must be in YYYY-MM-DD or RFC3339 format",
-				c.Request.URL.Path,
-			))
-			return
-		}
-	}
-
-	// Check if date is in the future
-	if parsedDate.After(time.Now()) {
-		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			"FUTURE_DATE",
-			"Expense date cannot be in the future",
-			"Please select a current or past date",
-			c.Request.URL.Path,
-		))
+		c.Error(err).SetMeta("Failed to create expense")
 		return
 	}
 
-	// Parse UUIDs
-	cardID, err := uuid.Parse(req.CardID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			"INVALID_CARD_ID",
-			"Invalid card ID format",
-			err.Error(),
-			c.Request.URL.Path,
-		))
-		return
-	}
-
-	categoryID, err := uuid.Parse(req.CategoryID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			"INVALID_CATEGORY_ID",
-			"Invalid category ID format",
-			err.Error(),
-			c.Request.URL.Path,
-		))
-		return
-	}
-
-	expense := &models.Expense{
-		ID:          uuid.New(),
-		Amount:      req.Amount,
-		Date:        parsedDate,
-		Description: req.Description,
-		CardID:      cardID,
-		CategoryID:  categoryID,
-	}
+	h.bus.PublishExpense(events.ExpenseCreated, createdExpense)
 
-	if err := h.expenseRepo.Create(expense); err != nil {
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			"INTERNAL_ERROR",
-			"Failed to create expense",
-			err.Error(),
-			c.Request.URL.Path,
-		))
-		return
-	}
-
-	// Get the created expense with related data
-	createdExpense, err := h.expenseRepo.GetByID(expense.ID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			"INTERNAL_ERROR",
-			"Failed to retrieve created expense",
-			err.Error(),
-			c.Request.URL.Path,
-		))
-		return
+	// Point out unusual amounts so that typos and fraudulent charges are
+	// noticed while the receipt is at hand. Detection scans the history of
+	// the expense, so clients ask for it rather than every import paying for it.
+	if c.Query("checkAnomalies") == "true" {
+		if warnings := h.expenseService.Anomalies(c.Request.Context(), createdExpense); len(warnings) > 0 {
+			c.JSON(http.StatusCreated, models.NewSuccessResponseWithWarnings("Expense created successfully", createdExpense, warnings))
+			return
+		}
 	}
 
 	c.JSON(http.StatusCreated, models.NewSuccessResponse("Expense created successfully", createdExpense))
@@ -252,125 +162,18 @@ func (h *ExpenseHandler) UpdateExpense(c *gin.Context) {
 		return
 	}
 
-	// Check if expense exists (without preload to avoid relation conflicts during update)
-	expense, err := h.expenseRepo.GetByIDWithoutPreload(id)
-	if err != nil {
-		if err.Error() == "record not found" {
-			c.JSON(http.StatusNotFound, models.NewErrorResponse(
-				"EXPENSE_NOT_FOUND",
-				"Expense not found",
-				nil,
-				c.Request.URL.Path,
-			))
-			return
-		}
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			"INTERNAL_ERROR",
-			"Failed to retrieve expense",
-			err.Error(),
-			c.Request.URL.Path,
-		))
-		return
-	}
-
 	var req models.UpdateExpenseRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			"INVALID_REQUEST",
-			"Invalid request body",
-			err.Error(),
-			c.Request.URL.Path,
-		))
-		return
-	}
-
-	if err := h.validator.Struct(req); err != nil {
-		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			"VALIDATION_ERROR",
-			"Validation failed",
-			err.Error(),
-			c.Request.URL.Path,
-		))
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	// Parse date string (try YYYY-MM-DD format first)
-	parsedDate, err := time.Parse("2006-01-02", req.Date)
+	updatedExpense, err := h.expenseService.Update(c.Request.Context(), id, &req)
 	if err != nil {
-		// Try parsing as RFC3339 format
-		parsedDate, err = time.Parse(time.RFC3339, req.Date)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-				"INVALID_DATE",
-				"Invalid date format",
-				"Date must be in YYYY-MM-DD or RFC3339 format",
-				c.Request.URL.Path,
-			))
-			return
-		}
-	}
-
-	// Check if date is in the future
-	if parsedDate.After(time.Now()) {
-		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			"FUTURE_DATE",
-			"Expense date cannot be in the future",
-			"Please select a current or past date",
-			c.Request.URL.Path,
-		))
+		c.Error(err).SetMeta("Failed to update expense")
 		return
 	}
 
-	// Parse UUIDs
-	cardID, err := uuid.Parse(req.CardID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			"INVALID_CARD_ID",
-			"Invalid card ID format",
-			err.Error(),
-			c.Request.URL.Path,
-		))
-		return
-	}
-
-	categoryID, err := uuid.Parse(req.CategoryID)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
-			"INVALID_CATEGORY_ID",
-			"Invalid category ID format",
-			err.Error(),
-			c.Request.URL.Path,
-		))
-		return
-	}
-
-	expense.Amount = req.Amount
-	expense.Date = parsedDate
-	expense.Description = req.Description
-	expense.CardID = cardID
-	expense.CategoryID = categoryID
-
-	if err := h.expenseRepo.Update(expense); err != nil {
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			"INTERNAL_ERROR",
-			"Failed to update expense",
-			err.Error(),
-			c.Request.URL.Path,
-		))
-		return
-	}
-
-	// Get the updated expense with related data
-	updatedExpense, err := h.expenseRepo.GetByID(expense.ID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			"INTERNAL_ERROR",
-			"Failed to retrieve updated expense",
-			err.Error(),
-			c.Request.URL.Path,
-		))
-		return
-	}
+	h.bus.PublishExpense(events.ExpenseUpdated, updatedExpense)
 
 	c.JSON(http.StatusOK, models.NewSuccessResponse("Expense updated successfully", updatedExpense))
 }
@@ -388,36 +191,16 @@ func (h *ExpenseHandler) DeleteExpense(c *gin.Context) {
 		return
 	}
 
-	// Check if expense exists
-	_, err = h.expenseRepo.GetByID(id)
+	expense, attachments, err := h.expenseService.Delete(c.Request.Context(), id)
 	if err != nil {
-		if err.Error() == "record not found" {
-			c.JSON(http.StatusNotFound, models.NewErrorResponse(
-				"EXPENSE_NOT_FOUND",
-				"Expense not found",
-				nil,
-				c.Request.URL.Path,
-			))
-			return
-		}
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			"INTERNAL_ERROR",
-			"Failed to retrieve expense",
-			err.Error(),
-			c.Request.URL.Path,
-		))
+		c.Error(err).SetMeta("Failed to delete expense")
 		return
 	}
+	h.bus.PublishExpense(events.ExpenseDeleted, expense)
 
-	if err := h.expenseRepo.Delete(id); err != nil {
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			"INTERNAL_ERROR",
-			"Failed to delete expense",
-			err.Error(),
-			c.Request.URL.Path,
-		))
-		return
+	for i := range attachments {
+		removeAttachmentObjects(h.storage, &attachments[i])
 	}
 
 	c.JSON(http.StatusOK, models.NewSuccessResponse("Expense deleted successfully", nil))
-}
\ No newline at end of file
+}
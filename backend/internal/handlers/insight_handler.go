@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// anomalyDays is the number of days, up to today, searched for anomalies
+// when no date range is given.
+const anomalyDays = 30
+
+type InsightHandler struct {
+	expenseRepo repositories.ExpenseRepository
+	bookRepo    repositories.BookRepository
+}
+
+func NewInsightHandler(expenseRepo repositories.ExpenseRepository, bookRepo repositories.BookRepository) *InsightHandler {
+	return &InsightHandler{
+		expenseRepo: expenseRepo,
+		bookRepo:    bookRepo,
+	}
+}
+
+func (h *InsightHandler) GetAnomalies(c *gin.Context) {
+	filters := &models.AnomalyFilters{}
+
+	// Parse from and to parameters (optional, default to the last 30 days)
+	from, to, ok := parseReportDateRange(c, "from", "to")
+	if !ok {
+		return
+	}
+	if from == nil {
+		today := time.Now().UTC()
+		filters.EndDate = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+		filters.StartDate = filters.EndDate.AddDate(0, 0, -(anomalyDays - 1))
+	} else {
+		if to.Before(*from) {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"INVALID_DATE_RANGE",
+				"Invalid date range",
+				"The end date must not be before the start date",
+				c.Request.URL.Path,
+			))
+			return
+		}
+		filters.StartDate, filters.EndDate = *from, *to
+	}
+
+	// Parse card ID parameter (optional)
+	cardIDStr := c.Query("cardId")
+	if cardIDStr != "" {
+		cardID, err := uuid.Parse(cardIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"INVALID_CARD_ID",
+				"Invalid card ID format",
+				err.Error(),
+				c.Request.URL.Path,
+			))
+			return
+		}
+		filters.CardID = &cardID
+	}
+
+	// Compare amounts in the base currency of the book
+	book, err := h.bookRepo.Get(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("Failed to retrieve book settings")
+		return
+	}
+	filters.Currency = book.BaseCurrency
+
+	report, err := h.expenseRepo.GetAnomalies(c.Request.Context(), filters)
+	if err != nil {
+		writeReportError(c, err, "Failed to detect anomalies")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Anomalies detected successfully", report))
+}
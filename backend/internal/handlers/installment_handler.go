@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type InstallmentHandler struct {
+	installmentService *services.InstallmentService
+}
+
+func NewInstallmentHandler(installmentService *services.InstallmentService) *InstallmentHandler {
+	return &InstallmentHandler{
+		installmentService: installmentService,
+	}
+}
+
+func (h *InstallmentHandler) GetInstallmentPlan(c *gin.Context) {
+	expenseID, ok := h.parseExpenseID(c)
+	if !ok {
+		return
+	}
+
+	plan, err := h.installmentService.Get(c.Request.Context(), expenseID)
+	if err != nil {
+		c.Error(err).SetMeta("Failed to retrieve installment plan")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Installment plan retrieved successfully", plan))
+}
+
+func (h *InstallmentHandler) SetInstallmentPlan(c *gin.Context) {
+	expenseID, ok := h.parseExpenseID(c)
+	if !ok {
+		return
+	}
+
+	var req models.InstallmentPlanRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	plan, err := h.installmentService.Set(c.Request.Context(), expenseID, &req)
+	if err != nil {
+		c.Error(err).SetMeta("Failed to save installment plan")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Installment plan saved successfully", plan))
+}
+
+func (h *InstallmentHandler) DeleteInstallmentPlan(c *gin.Context) {
+	expenseID, ok := h.parseExpenseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.installmentService.Delete(c.Request.Context(), expenseID); err != nil {
+		c.Error(err).SetMeta("Failed to delete installment plan")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Installment plan deleted successfully", nil))
+}
+
+func (h *InstallmentHandler) GetBalances(c *gin.Context) {
+	asOf := time.Now()
+	if asOfStr := c.Query("asOf"); asOfStr != "" {
+		date, err := time.Parse("2006-01-02", asOfStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"INVALID_DATE",
+				"Invalid asOf date format",
+				"Date must be in YYYY-MM-DD format",
+				c.Request.URL.Path,
+			))
+			return
+		}
+		asOf = date
+	}
+
+	var cardID *uuid.UUID
+	if cardIDStr := c.Query("cardId"); cardIDStr != "" {
+		id, err := uuid.Parse(cardIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"INVALID_CARD_ID",
+				"Invalid card ID format",
+				err.Error(),
+				c.Request.URL.Path,
+			))
+			return
+		}
+		cardID = &id
+	}
+
+	balances, err := h.installmentService.Balances(c.Request.Context(), asOf, cardID)
+	if err != nil {
+		c.Error(err).SetMeta("Failed to retrieve installment balances")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Installment balances retrieved successfully", balances))
+}
+
+func (h *InstallmentHandler) parseExpenseID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"INVALID_UUID",
+			"Invalid expense ID format",
+			err.Error(),
+			c.Request.URL.Path,
+		))
+		return uuid.Nil, false
+	}
+	return id, true
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type NotificationHandler struct {
+	notificationRepo repositories.NotificationRepository
+}
+
+func NewNotificationHandler(notificationRepo repositories.NotificationRepository) *NotificationHandler {
+	return &NotificationHandler{notificationRepo: notificationRepo}
+}
+
+// GetNotifications lists the in-app notifications, the newest first, or only
+// the unread ones with ?unread=true.
+func (h *NotificationHandler) GetNotifications(c *gin.Context) {
+	unreadOnly := c.Query("unread") == "true"
+	notifications, err := h.notificationRepo.GetAll(c.Request.Context(), unreadOnly)
+	if err != nil {
+		c.Error(err).SetMeta("Failed to retrieve notifications")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Notifications retrieved successfully", notifications))
+}
+
+// MarkNotificationRead marks a notification as read. Marking it again keeps
+// the time it was first read.
+func (h *NotificationHandler) MarkNotificationRead(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"INVALID_UUID",
+			"Invalid notification ID format",
+			err.Error(),
+			c.Request.URL.Path,
+		))
+		return
+	}
+
+	notification, err := h.notificationRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("Failed to retrieve notification")
+		return
+	}
+
+	if notification.ReadAt == nil {
+		readAt := time.Now().UTC()
+		if err := h.notificationRepo.MarkRead(c.Request.Context(), id, readAt); err != nil {
+			c.Error(err).SetMeta("Failed to update notification")
+			return
+		}
+		notification.ReadAt = &readAt
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Notification marked as read", notification))
+}
@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"net/http"
+	"kakeibo-tanuki/internal/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OpenAPIHandler struct {
+	document *openapi.Document
+}
+
+// NewOpenAPIHandler builds the OpenAPI document once, as it only changes
+// with the code.
+func NewOpenAPIHandler() *OpenAPIHandler {
+	return &OpenAPIHandler{document: openapi.Build()}
+}
+
+// GetOpenAPI returns the OpenAPI document of the API as is, without a
+// response envelope, so that tools can read it.
+func (h *OpenAPIHandler) GetOpenAPI(c *gin.Context) {
+	c.JSON(http.StatusOK, h.document)
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/payees"
+	"kakeibo-tanuki/internal/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PayeeHandler struct {
+	payeeRepo repositories.PayeeRepository
+}
+
+func NewPayeeHandler(payeeRepo repositories.PayeeRepository) *PayeeHandler {
+	return &PayeeHandler{
+		payeeRepo: payeeRepo,
+	}
+}
+
+func (h *PayeeHandler) GetPayees(c *gin.Context) {
+	allPayees, err := h.payeeRepo.GetAll(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("Failed to retrieve payees")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Payees retrieved successfully", allPayees))
+}
+
+func (h *PayeeHandler) GetPayee(c *gin.Context) {
+	payee, ok := h.findPayee(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Payee retrieved successfully", payee))
+}
+
+// CreatePayee creates a payee and links the existing expenses without a
+// payee that match it.
+func (h *PayeeHandler) CreatePayee(c *gin.Context) {
+	var req models.CreatePayeeRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if !validatePayeePatterns(c, req.Patterns) {
+		return
+	}
+
+	payee := &models.Payee{
+		ID:       uuid.New(),
+		Name:     req.Name,
+		Aliases:  nonNil(req.Aliases),
+		Patterns: nonNil(req.Patterns),
+	}
+
+	if err := h.payeeRepo.Create(c.Request.Context(), payee); err != nil {
+		writePayeeSaveError(c, err, "Failed to create payee")
+		return
+	}
+
+	matched, ok := h.assignExpenses(c, payee)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.NewSuccessResponse("Payee created successfully", models.PayeeResult{
+		Payee:           *payee,
+		MatchedExpenses: matched,
+	}))
+}
+
+// UpdatePayee replaces the name, aliases and patterns of a payee. Expenses
+// already linked stay linked; expenses without a payee that now match are
+// linked to it.
+func (h *PayeeHandler) UpdatePayee(c *gin.Context) {
+	payee, ok := h.findPayee(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdatePayeeRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if !validatePayeePatterns(c, req.Patterns) {
+		return
+	}
+
+	payee.Name = req.Name
+	payee.Aliases = nonNil(req.Aliases)
+	payee.Patterns = nonNil(req.Patterns)
+
+	if err := h.payeeRepo.Update(c.Request.Context(), payee); err != nil {
+		writePayeeSaveError(c, err, "Failed to update payee")
+		return
+	}
+
+	matched, ok := h.assignExpenses(c, payee)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Payee updated successfully", models.PayeeResult{
+		Payee:           *payee,
+		MatchedExpenses: matched,
+	}))
+}
+
+// DeletePayee deletes a payee. Its expenses are kept without a payee.
+func (h *PayeeHandler) DeletePayee(c *gin.Context) {
+	payee, ok := h.findPayee(c)
+	if !ok {
+		return
+	}
+
+	if err := h.payeeRepo.Delete(c.Request.Context(), payee.ID); err != nil {
+		c.Error(err).SetMeta("Failed to delete payee")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Payee deleted successfully", nil))
+}
+
+func (h *PayeeHandler) findPayee(c *gin.Context) (*models.Payee, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"INVALID_UUID",
+			"Invalid payee ID format",
+			err.Error(),
+			c.Request.URL.Path,
+		))
+		return nil, false
+	}
+
+	payee, err := h.payeeRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("Failed to retrieve payee")
+		return nil, false
+	}
+	return payee, true
+}
+
+func (h *PayeeHandler) assignExpenses(c *gin.Context, payee *models.Payee) (int, bool) {
+	matched, err := h.payeeRepo.AssignExpenses(c.Request.Context(), payee)
+	if err != nil {
+		c.Error(err).SetMeta("Failed to link expenses to payee")
+		return 0, false
+	}
+	return matched, true
+}
+
+func validatePayeePatterns(c *gin.Context, patterns []string) bool {
+	for _, pattern := range patterns {
+		if _, err := payees.CompilePattern(pattern); err != nil {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"INVALID_PATTERN",
+				"Invalid payee pattern",
+				err.Error(),
+				c.Request.URL.Path,
+			))
+			return false
+		}
+	}
+	return true
+}
+
+func writePayeeSaveError(c *gin.Context, err error, message string) {
+	if errors.Is(err, repositories.ErrConflict) {
+		c.JSON(http.StatusConflict, models.NewErrorResponse(
+			"DUPLICATE_PAYEE",
+			"Payee with this name already exists",
+			"A payee with this name already exists. Please choose a different name.",
+			c.Request.URL.Path,
+		))
+		return
+	}
+	c.Error(err).SetMeta(message)
+}
+
+// nonNil keeps empty lists as [] rather than null in the database and the
+// API.
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"net/http"
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type RefundHandler struct {
+	refundService *services.RefundService
+}
+
+func NewRefundHandler(refundService *services.RefundService) *RefundHandler {
+	return &RefundHandler{
+		refundService: refundService,
+	}
+}
+
+func (h *RefundHandler) GetRefunds(c *gin.Context) {
+	expenseID, ok := h.parseExpenseID(c)
+	if !ok {
+		return
+	}
+
+	refunds, err := h.refundService.List(c.Request.Context(), expenseID)
+	if err != nil {
+		c.Error(err).SetMeta("Failed to retrieve refunds")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Refunds retrieved successfully", refunds))
+}
+
+func (h *RefundHandler) CreateRefund(c *gin.Context) {
+	expenseID, ok := h.parseExpenseID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateRefundRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	refund, err := h.refundService.Create(c.Request.Context(), expenseID, &req)
+	if err != nil {
+		c.Error(err).SetMeta("Failed to create refund")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.NewSuccessResponse("Refund created successfully", refund))
+}
+
+func (h *RefundHandler) DeleteRefund(c *gin.Context) {
+	expenseID, ok := h.parseExpenseID(c)
+	if !ok {
+		return
+	}
+
+	refundID, err := uuid.Parse(c.Param("refundId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"INVALID_UUID",
+			"Invalid refund ID format",
+			err.Error(),
+			c.Request.URL.Path,
+		))
+		return
+	}
+
+	if err := h.refundService.Delete(c.Request.Context(), expenseID, refundID); err != nil {
+		c.Error(err).SetMeta("Failed to delete refund")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Refund deleted successfully", nil))
+}
+
+func (h *RefundHandler) parseExpenseID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"INVALID_UUID",
+			"Invalid expense ID format",
+			err.Error(),
+			c.Request.URL.Path,
+		))
+		return uuid.Nil, false
+	}
+	return id, true
+}
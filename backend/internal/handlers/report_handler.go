@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/reports"
 	"kakeibo-tanuki/internal/repositories"
 
 	"github.com/gin-gonic/gin"
@@ -13,11 +16,13 @@ import (
 
 type ReportHandler struct {
 	expenseRepo repositories.ExpenseRepository
+	bookRepo    repositories.BookRepository
 }
 
-func NewReportHandler(expenseRepo repositories.ExpenseRepository) *ReportHandler {
+func NewReportHandler(expenseRepo repositories.ExpenseRepository, bookRepo repositories.BookRepository) *ReportHandler {
 	return &ReportHandler{
 		expenseRepo: expenseRepo,
+		bookRepo:    bookRepo,
 	}
 }
 
@@ -81,14 +86,19 @@ func (h *ReportHandler) GetMonthlyReport(c *gin.Context) {
 		filters.CardID = &cardID
 	}
 
-	report, err := h.expenseRepo.GetMonthlyReport(filters)
+	// Parse view parameter (optional)
+	if !parseReportView(c, filters) {
+		return
+	}
+
+	// Convert amounts to the base currency of the book
+	if !h.setReportCurrency(c, filters) {
+		return
+	}
+
+	report, err := h.expenseRepo.GetMonthlyReport(c.Request.Context(), filters)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			"INTERNAL_ERROR",
-			"Failed to generate monthly report",
-			err.Error(),
-			c.Request.URL.Path,
-		))
+		writeReportError(c, err, "Failed to generate monthly report")
 		return
 	}
 
@@ -132,16 +142,436 @@ func (h *ReportHandler) GetYearlyReport(c *gin.Context) {
 		filters.CardID = &cardID
 	}
 
-	report, err := h.expenseRepo.GetYearlyReport(filters)
+	// Parse view parameter (optional)
+	if !parseReportView(c, filters) {
+		return
+	}
+
+	// Convert amounts to the base currency of the book
+	if !h.setReportCurrency(c, filters) {
+		return
+	}
+
+	report, err := h.expenseRepo.GetYearlyReport(c.Request.Context(), filters)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
-			"INTERNAL_ERROR",
-			"Failed to generate yearly report",
+		writeReportError(c, err, "Failed to generate yearly report")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Yearly report generated successfully", report))
+}
+
+func (h *ReportHandler) GetRangeReport(c *gin.Context) {
+	filters := &models.ReportFilters{}
+
+	// Parse from and to parameters (required)
+	from, to, ok := parseReportDateRange(c, "from", "to")
+	if !ok {
+		return
+	}
+	if from == nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"MISSING_DATE_RANGE",
+			"Missing date range",
+			"Both from and to parameters are required for range report",
+			c.Request.URL.Path,
+		))
+		return
+	}
+	filters.StartDate = from
+	filters.EndDate = to
+
+	// Parse groupBy parameter (optional)
+	filters.GroupBy = c.DefaultQuery("groupBy", models.ReportGroupByMonth)
+	if !reports.ValidGroupBy(filters.GroupBy) {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"INVALID_GROUP_BY",
+			"Invalid groupBy parameter",
+			"groupBy must be one of 'day', 'week', 'month' or 'quarter'",
+			c.Request.URL.Path,
+		))
+		return
+	}
+
+	if _, err := reports.Periods(*from, *to, filters.GroupBy); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"INVALID_DATE_RANGE",
+			"Invalid date range",
 			err.Error(),
 			c.Request.URL.Path,
 		))
 		return
 	}
 
-	c.JSON(http.StatusOK, models.NewSuccessResponse("Yearly report generated successfully", report))
-}
\ No newline at end of file
+	// Parse card ID parameter (optional)
+	cardIDStr := c.Query("cardId")
+	if cardIDStr != "" {
+		cardID, err := uuid.Parse(cardIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"INVALID_CARD_ID",
+				"Invalid card ID format",
+				err.Error(),
+				c.Request.URL.Path,
+			))
+			return
+		}
+		filters.CardID = &cardID
+	}
+
+	// Parse view parameter (optional)
+	if !parseReportView(c, filters) {
+		return
+	}
+
+	// Convert amounts to the base currency of the book
+	if !h.setReportCurrency(c, filters) {
+		return
+	}
+
+	report, err := h.expenseRepo.GetRangeReport(c.Request.Context(), filters)
+	if err != nil {
+		writeReportError(c, err, "Failed to generate range report")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Range report generated successfully", report))
+}
+
+func (h *ReportHandler) GetComparisonReport(c *gin.Context) {
+	filters := &models.ReportFilters{}
+
+	// Parse the current period: from and to, or a year with an optional month
+	from, to, ok := parseReportDateRange(c, "from", "to")
+	if !ok {
+		return
+	}
+	if from == nil {
+		year := time.Now().Year()
+		if yearStr := c.Query("year"); yearStr != "" {
+			parsed, err := strconv.Atoi(yearStr)
+			if err != nil || parsed < 2000 || parsed > 2100 {
+				c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+					"INVALID_YEAR",
+					"Invalid year parameter",
+					"Year must be a valid number between 2000 and 2100",
+					c.Request.URL.Path,
+				))
+				return
+			}
+			year = parsed
+		}
+
+		start := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+		end := start.AddDate(1, 0, -1)
+		if monthStr := c.Query("month"); monthStr != "" {
+			month, err := strconv.Atoi(monthStr)
+			if err != nil || month < 1 || month > 12 {
+				c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+					"INVALID_MONTH",
+					"Invalid month parameter",
+					"Month must be a number between 1 and 12",
+					c.Request.URL.Path,
+				))
+				return
+			}
+			start = time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+			end = start.AddDate(0, 1, -1)
+		}
+		from, to = &start, &end
+	}
+	filters.StartDate = from
+	filters.EndDate = to
+
+	// Parse the period to compare with: compareFrom and compareTo, or the
+	// previous period or the same period last year
+	compareFrom, compareTo, ok := parseReportDateRange(c, "compareFrom", "compareTo")
+	if !ok {
+		return
+	}
+	if compareFrom == nil {
+		start, end, err := reports.PreviousPeriod(*from, *to, c.DefaultQuery("against", models.CompareAgainstPrevious))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"INVALID_COMPARISON",
+				"Invalid comparison",
+				err.Error(),
+				c.Request.URL.Path,
+			))
+			return
+		}
+		compareFrom, compareTo = &start, &end
+	}
+	filters.CompareStartDate = compareFrom
+	filters.CompareEndDate = compareTo
+
+	if to.Before(*from) || compareTo.Before(*compareFrom) {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"INVALID_DATE_RANGE",
+			"Invalid date range",
+			"The end date must not be before the start date",
+			c.Request.URL.Path,
+		))
+		return
+	}
+
+	// Parse card ID parameter (optional)
+	cardIDStr := c.Query("cardId")
+	if cardIDStr != "" {
+		cardID, err := uuid.Parse(cardIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"INVALID_CARD_ID",
+				"Invalid card ID format",
+				err.Error(),
+				c.Request.URL.Path,
+			))
+			return
+		}
+		filters.CardID = &cardID
+	}
+
+	// Parse view parameter (optional)
+	if !parseReportView(c, filters) {
+		return
+	}
+
+	// Convert amounts to the base currency of the book
+	if !h.setReportCurrency(c, filters) {
+		return
+	}
+
+	report, err := h.expenseRepo.GetComparisonReport(c.Request.Context(), filters)
+	if err != nil {
+		writeReportError(c, err, "Failed to generate comparison report")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Comparison report generated successfully", report))
+}
+
+func (h *ReportHandler) GetForecastReport(c *gin.Context) {
+	filters := &models.ReportFilters{}
+
+	// Parse date parameter (optional, defaults to today)
+	if dateStr := c.Query("date"); dateStr != "" {
+		asOf, err := time.Parse(reports.DateLayout, dateStr)
+		if err != nil || asOf.Year() < 2000 || asOf.Year() > 2100 {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"INVALID_DATE",
+				"Invalid date parameter",
+				"Date must be in YYYY-MM-DD format between 2000 and 2100",
+				c.Request.URL.Path,
+			))
+			return
+		}
+		filters.AsOf = &asOf
+	}
+
+	// Parse card ID parameter (optional)
+	cardIDStr := c.Query("cardId")
+	if cardIDStr != "" {
+		cardID, err := uuid.Parse(cardIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"INVALID_CARD_ID",
+				"Invalid card ID format",
+				err.Error(),
+				c.Request.URL.Path,
+			))
+			return
+		}
+		filters.CardID = &cardID
+	}
+
+	// Parse view parameter (optional)
+	if !parseReportView(c, filters) {
+		return
+	}
+
+	// Convert amounts to the base currency of the book
+	if !h.setReportCurrency(c, filters) {
+		return
+	}
+
+	report, err := h.expenseRepo.GetForecastReport(c.Request.Context(), filters)
+	if err != nil {
+		writeReportError(c, err, "Failed to generate forecast report")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Forecast report generated successfully", report))
+}
+
+func (h *ReportHandler) GetDailyReport(c *gin.Context) {
+	filters := &models.ReportFilters{}
+
+	// Parse year parameter (optional, defaults to the current year)
+	yearStr := c.Query("year")
+	if yearStr == "" {
+		filters.Year = time.Now().Year()
+	} else {
+		year, err := strconv.Atoi(yearStr)
+		if err != nil || year < 2000 || year > 2100 {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"INVALID_YEAR",
+				"Invalid year parameter",
+				"Year must be a valid number between 2000 and 2100",
+				c.Request.URL.Path,
+			))
+			return
+		}
+		filters.Year = year
+	}
+
+	// Parse month parameter (optional, the whole year without it)
+	if monthStr := c.Query("month"); monthStr != "" {
+		month, err := strconv.Atoi(monthStr)
+		if err != nil || month < 1 || month > 12 {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"INVALID_MONTH",
+				"Invalid month parameter",
+				"Month must be a number between 1 and 12",
+				c.Request.URL.Path,
+			))
+			return
+		}
+		filters.Month = &month
+	}
+
+	// Parse card ID parameter (optional)
+	cardIDStr := c.Query("cardId")
+	if cardIDStr != "" {
+		cardID, err := uuid.Parse(cardIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"INVALID_CARD_ID",
+				"Invalid card ID format",
+				err.Error(),
+				c.Request.URL.Path,
+			))
+			return
+		}
+		filters.CardID = &cardID
+	}
+
+	// Parse category ID parameter (optional)
+	categoryIDStr := c.Query("categoryId")
+	if categoryIDStr != "" {
+		categoryID, err := uuid.Parse(categoryIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"INVALID_CATEGORY_ID",
+				"Invalid category ID format",
+				err.Error(),
+				c.Request.URL.Path,
+			))
+			return
+		}
+		filters.CategoryID = &categoryID
+	}
+
+	// Parse view parameter (optional)
+	if !parseReportView(c, filters) {
+		return
+	}
+
+	// Convert amounts to the base currency of the book
+	if !h.setReportCurrency(c, filters) {
+		return
+	}
+
+	report, err := h.expenseRepo.GetDailyReport(c.Request.Context(), filters)
+	if err != nil {
+		writeReportError(c, err, "Failed to generate daily report")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Daily report generated successfully", report))
+}
+
+// parseReportDateRange reads a pair of YYYY-MM-DD parameters. Both must be
+// given or both left out, in which case from and to are nil.
+func parseReportDateRange(c *gin.Context, fromKey, toKey string) (*time.Time, *time.Time, bool) {
+	fromStr, toStr := c.Query(fromKey), c.Query(toKey)
+	if fromStr == "" && toStr == "" {
+		return nil, nil, true
+	}
+	if fromStr == "" || toStr == "" {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"MISSING_DATE_RANGE",
+			"Missing date range",
+			fmt.Sprintf("Both %s and %s parameters are required", fromKey, toKey),
+			c.Request.URL.Path,
+		))
+		return nil, nil, false
+	}
+
+	dates := make([]time.Time, 2)
+	for i, key := range []string{fromKey, toKey} {
+		date, err := time.Parse(reports.DateLayout, c.Query(key))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"INVALID_DATE",
+				fmt.Sprintf("Invalid %s parameter", key),
+				"Date must be in YYYY-MM-DD format",
+				c.Request.URL.Path,
+			))
+			return nil, nil, false
+		}
+		dates[i] = date
+	}
+	return &dates[0], &dates[1], true
+}
+
+// parseReportView reads the optional view parameter selecting between the
+// purchase-date view (default) and the installment payment view.
+func parseReportView(c *gin.Context, filters *models.ReportFilters) bool {
+	view := c.Query("view")
+	switch view {
+	case "", models.ReportViewPurchase:
+		filters.View = models.ReportViewPurchase
+	case models.ReportViewPayment:
+		filters.View = models.ReportViewPayment
+	default:
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"INVALID_VIEW",
+			"Invalid view parameter",
+			"View must be either 'purchase' or 'payment'",
+			c.Request.URL.Path,
+		))
+		return false
+	}
+	return true
+}
+
+// setReportCurrency sets the report currency to the base currency of the book
+// and who gets the remainder when shared expenses are split.
+func (h *ReportHandler) setReportCurrency(c *gin.Context, filters *models.ReportFilters) bool {
+	book, err := h.bookRepo.Get(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("Failed to retrieve book settings")
+		return false
+	}
+	filters.Currency = book.BaseCurrency
+	filters.RemainderMember = book.RemainderMember
+	return true
+}
+
+// writeReportError responds to a failed report query. Missing exchange rates
+// are reported as 422 so the client can ask the user to enter them; other
+// errors, such as a query cut off by the request timeout, are left to the
+// error middleware.
+func writeReportError(c *gin.Context, err error, message string) {
+	var missingRate *repositories.MissingExchangeRateError
+	if errors.As(err, &missingRate) {
+		c.JSON(http.StatusUnprocessableEntity, models.NewErrorResponse(
+			"EXCHANGE_RATE_NOT_FOUND",
+			"Exchange rate not found",
+			missingRate.Error(),
+			c.Request.URL.Path,
+		))
+		return
+	}
+	c.Error(err).SetMeta(message)
+}
@@ -0,0 +1,371 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+	"kakeibo-tanuki/internal/events"
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/payees"
+	"kakeibo-tanuki/internal/repositories"
+	"kakeibo-tanuki/internal/rules"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type RuleHandler struct {
+	ruleRepo     repositories.RuleRepository
+	cardRepo     repositories.CardRepository
+	categoryRepo repositories.CategoryRepository
+	payeeRepo    repositories.PayeeRepository
+	bus          *events.Bus
+}
+
+func NewRuleHandler(ruleRepo repositories.RuleRepository, cardRepo repositories.CardRepository, categoryRepo repositories.CategoryRepository, payeeRepo repositories.PayeeRepository, bus *events.Bus) *RuleHandler {
+	return &RuleHandler{
+		ruleRepo:     ruleRepo,
+		cardRepo:     cardRepo,
+		categoryRepo: categoryRepo,
+		payeeRepo:    payeeRepo,
+		bus:          bus,
+	}
+}
+
+func (h *RuleHandler) GetRules(c *gin.Context) {
+	allRules, err := h.ruleRepo.GetAll(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("Failed to retrieve rules")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Rules retrieved successfully", allRules))
+}
+
+func (h *RuleHandler) GetRule(c *gin.Context) {
+	rule, ok := h.findRule(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Rule retrieved successfully", rule))
+}
+
+// CreateRule creates a rule. New rules are enabled unless the request says
+// otherwise.
+func (h *RuleHandler) CreateRule(c *gin.Context) {
+	var req models.CreateRuleRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	rule := &models.Rule{ID: uuid.New(), Enabled: true}
+	if !h.setRule(c, rule, models.UpdateRuleRequest(req)) {
+		return
+	}
+
+	if err := h.ruleRepo.Create(c.Request.Context(), rule); err != nil {
+		c.Error(err).SetMeta("Failed to create rule")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.NewSuccessResponse("Rule created successfully", rule))
+}
+
+// UpdateRule replaces the conditions and actions of a rule. It is left
+// enabled or disabled unless the request says otherwise.
+func (h *RuleHandler) UpdateRule(c *gin.Context) {
+	rule, ok := h.findRule(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateRuleRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if !h.setRule(c, rule, req) {
+		return
+	}
+
+	if err := h.ruleRepo.Update(c.Request.Context(), rule); err != nil {
+		c.Error(err).SetMeta("Failed to update rule")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Rule updated successfully", rule))
+}
+
+func (h *RuleHandler) DeleteRule(c *gin.Context) {
+	rule, ok := h.findRule(c)
+	if !ok {
+		return
+	}
+
+	if err := h.ruleRepo.Delete(c.Request.Context(), rule.ID); err != nil {
+		c.Error(err).SetMeta("Failed to delete rule")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Rule deleted successfully", nil))
+}
+
+// DryRunRules lists what applying the rules to existing expenses would
+// change, without changing anything.
+func (h *RuleHandler) DryRunRules(c *gin.Context) {
+	h.runRules(c, true)
+}
+
+// ApplyRules applies the rules to existing expenses, overwriting their
+// category and shared flag and adding tags, and lists what changed.
+func (h *RuleHandler) ApplyRules(c *gin.Context) {
+	h.runRules(c, false)
+}
+
+func (h *RuleHandler) runRules(c *gin.Context, dryRun bool) {
+	var req models.RuleRunRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	filters := &models.RuleRunFilters{}
+	var ok bool
+	if filters.StartDate, ok = parseRuleRunDate(c, req.StartDate); !ok {
+		return
+	}
+	if filters.EndDate, ok = parseRuleRunDate(c, req.EndDate); !ok {
+		return
+	}
+	if filters.StartDate != nil && filters.EndDate != nil && filters.EndDate.Before(*filters.StartDate) {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"INVALID_DATE_RANGE",
+			"End date must not be before start date",
+			nil,
+			c.Request.URL.Path,
+		))
+		return
+	}
+
+	selected, ok := h.selectRules(c, req.RuleIDs)
+	if !ok {
+		return
+	}
+	engine, err := rules.NewEngine(selected)
+	if err != nil {
+		c.Error(err).SetMeta("Failed to prepare rules")
+		return
+	}
+
+	expenses, err := h.ruleRepo.GetExpenses(c.Request.Context(), filters)
+	if err != nil {
+		c.Error(err).SetMeta("Failed to retrieve expenses")
+		return
+	}
+
+	result := models.RuleRunResult{DryRun: dryRun, Checked: len(expenses), Changes: []models.RuleChange{}}
+	var changed []models.Expense
+	for i := range expenses {
+		change, ok := rules.Change(&expenses[i], engine.Evaluate(&expenses[i]))
+		if !ok {
+			continue
+		}
+		result.Changes = append(result.Changes, change)
+		rules.Apply(&expenses[i], change)
+		changed = append(changed, expenses[i])
+	}
+	result.Changed = len(result.Changes)
+
+	if dryRun {
+		c.JSON(http.StatusOK, models.NewSuccessResponse("Rules evaluated successfully", result))
+		return
+	}
+
+	if err := h.ruleRepo.UpdateExpenses(c.Request.Context(), changed); err != nil {
+		c.Error(err).SetMeta("Failed to apply rules")
+		return
+	}
+	for i := range changed {
+		h.bus.PublishExpense(events.ExpenseUpdated, &changed[i])
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Rules applied successfully", result))
+}
+
+func parseRuleRunDate(c *gin.Context, value string) (*time.Time, bool) {
+	if value == "" {
+		return nil, true
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"INVALID_DATE",
+			"Invalid date format",
+			"Dates must be in YYYY-MM-DD format",
+			c.Request.URL.Path,
+		))
+		return nil, false
+	}
+	return &date, true
+}
+
+// selectRules returns the rules with the given IDs, or all rules when none
+// are given. Disabled rules are returned but never match.
+func (h *RuleHandler) selectRules(c *gin.Context, ruleIDs []string) ([]models.Rule, bool) {
+	if len(ruleIDs) == 0 {
+		allRules, err := h.ruleRepo.GetAll(c.Request.Context())
+		if err != nil {
+			c.Error(err).SetMeta("Failed to retrieve rules")
+			return nil, false
+		}
+		return allRules, true
+	}
+
+	selected := make([]models.Rule, 0, len(ruleIDs))
+	for _, idStr := range ruleIDs {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"INVALID_UUID",
+				"Invalid rule ID format",
+				err.Error(),
+				c.Request.URL.Path,
+			))
+			return nil, false
+		}
+		rule, ok := h.getRule(c, id)
+		if !ok {
+			return nil, false
+		}
+		selected = append(selected, *rule)
+	}
+	return selected, true
+}
+
+// setRule checks the request and copies it into rule. Rules need at least
+// one action, and the cards, payees and categories they refer to must exist.
+func (h *RuleHandler) setRule(c *gin.Context, rule *models.Rule, req models.UpdateRuleRequest) bool {
+	if req.CategoryID == "" && len(req.Tags) == 0 && req.IsShared == nil {
+		// A rule must set a category, tags or the shared flag
+		requiredField(c, "categoryId", "required_without_all", "tags isShared")
+		return false
+	}
+
+	if req.DescriptionPattern != "" {
+		if _, err := payees.CompilePattern(req.DescriptionPattern); err != nil {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"INVALID_PATTERN",
+				"Invalid description pattern",
+				err.Error(),
+				c.Request.URL.Path,
+			))
+			return false
+		}
+	}
+
+	if req.MinAmount != nil && req.MaxAmount != nil && *req.MinAmount > *req.MaxAmount {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"INVALID_AMOUNT_RANGE",
+			"Minimum amount must not exceed maximum amount",
+			fmt.Sprintf("%s is more than %s", *req.MinAmount, *req.MaxAmount),
+			c.Request.URL.Path,
+		))
+		return false
+	}
+
+	cardID, ok := parseReference(c, "Rule", req.CardID, "card", func(id uuid.UUID) error {
+		_, err := h.cardRepo.GetByID(c.Request.Context(), id)
+		return err
+	})
+	if !ok {
+		return false
+	}
+	payeeID, ok := parseReference(c, "Rule", req.PayeeID, "payee", func(id uuid.UUID) error {
+		_, err := h.payeeRepo.GetByID(c.Request.Context(), id)
+		return err
+	})
+	if !ok {
+		return false
+	}
+	categoryID, ok := parseReference(c, "Rule", req.CategoryID, "category", func(id uuid.UUID) error {
+		_, err := h.categoryRepo.GetByID(c.Request.Context(), id)
+		return err
+	})
+	if !ok {
+		return false
+	}
+
+	rule.Name = req.Name
+	rule.Priority = req.Priority
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	rule.DescriptionPattern = req.DescriptionPattern
+	rule.MinAmount = req.MinAmount
+	rule.MaxAmount = req.MaxAmount
+	rule.CardID = cardID
+	rule.PayeeID = payeeID
+	rule.CategoryID = categoryID
+	rule.Tags = nonNil(req.Tags)
+	rule.IsShared = req.IsShared
+	return true
+}
+
+// parseReference parses the optional ID of a card, payee or category that an
+// owner such as a rule refers to and checks that it exists with get.
+func parseReference(c *gin.Context, owner string, idStr string, kind string, get func(uuid.UUID) error) (*uuid.UUID, bool) {
+	if idStr == "" {
+		return nil, true
+	}
+
+	code := map[string]string{"card": "CARD", "payee": "PAYEE", "category": "CATEGORY"}[kind]
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"INVALID_"+code+"_ID",
+			fmt.Sprintf("Invalid %s ID format", kind),
+			err.Error(),
+			c.Request.URL.Path,
+		))
+		return nil, false
+	}
+
+	if err := get(id); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				code+"_NOT_FOUND",
+				fmt.Sprintf("%s refers to a %s that does not exist", owner, kind),
+				nil,
+				c.Request.URL.Path,
+			))
+			return nil, false
+		}
+		c.Error(err).SetMeta(fmt.Sprintf("Failed to retrieve %s", kind))
+		return nil, false
+	}
+	return &id, true
+}
+
+func (h *RuleHandler) findRule(c *gin.Context) (*models.Rule, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"INVALID_UUID",
+			"Invalid rule ID format",
+			err.Error(),
+			c.Request.URL.Path,
+		))
+		return nil, false
+	}
+	return h.getRule(c, id)
+}
+
+func (h *RuleHandler) getRule(c *gin.Context, id uuid.UUID) (*models.Rule, bool) {
+	rule, err := h.ruleRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("Failed to retrieve rule")
+		return nil, false
+	}
+	return rule, true
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"kakeibo-tanuki/internal/currency"
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/repositories"
+	"kakeibo-tanuki/internal/suggestions"
+)
+
+// DefaultSuggestionLimit is the number of categories suggested unless the
+// request asks for another number.
+const DefaultSuggestionLimit = 3
+
+type SuggestionHandler struct {
+	suggester    *suggestions.Model
+	categoryRepo repositories.CategoryRepository
+	bookRepo     repositories.BookRepository
+}
+
+func NewSuggestionHandler(suggester *suggestions.Model, categoryRepo repositories.CategoryRepository, bookRepo repositories.BookRepository) *SuggestionHandler {
+	return &SuggestionHandler{
+		suggester:    suggester,
+		categoryRepo: categoryRepo,
+		bookRepo:     bookRepo,
+	}
+}
+
+// SuggestCategory ranks the categories an expense being entered probably
+// belongs to, learned from past expenses. The list is empty until expenses
+// have been recorded.
+func (h *SuggestionHandler) SuggestCategory(c *gin.Context) {
+	var req models.SuggestCategoryRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	query := suggestions.Query{Description: req.Description}
+
+	if req.CardID != "" {
+		cardID, err := uuid.Parse(req.CardID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"INVALID_CARD_ID",
+				"Invalid card ID format",
+				err.Error(),
+				c.Request.URL.Path,
+			))
+			return
+		}
+		query.CardID = cardID
+	}
+
+	// Amounts are compared in the currency they are given in, the base
+	// currency unless another one is given
+	if req.Amount > 0 {
+		code := req.Currency
+		if code == "" {
+			book, err := h.bookRepo.Get(c.Request.Context())
+			if err != nil {
+				c.Error(err).SetMeta("Failed to retrieve book settings")
+				return
+			}
+			code = book.BaseCurrency
+		}
+		code, err := currency.Normalize(code)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"INVALID_CURRENCY",
+				"Invalid currency code",
+				err.Error(),
+				c.Request.URL.Path,
+			))
+			return
+		}
+		query.Amount = req.Amount
+		query.Currency = code
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = DefaultSuggestionLimit
+	}
+
+	ranked, err := h.suggester.Suggest(query, limit)
+	if err != nil {
+		c.Error(err).SetMeta("Failed to suggest categories")
+		return
+	}
+
+	categories, err := h.categoryRepo.GetAll(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("Failed to retrieve categories")
+		return
+	}
+	byID := make(map[uuid.UUID]models.Category, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = category
+	}
+
+	result := make([]models.CategorySuggestion, 0, len(ranked))
+	for _, suggestion := range ranked {
+		category, ok := byID[suggestion.CategoryID]
+		if !ok {
+			continue
+		}
+		result = append(result, models.CategorySuggestion{
+			CategoryID:   category.ID,
+			CategoryName: category.Name,
+			Color:        category.Color,
+			Confidence:   suggestion.Probability,
+		})
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Category suggestions retrieved successfully", result))
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bindJSON decodes the JSON body of a request into req and validates it. It
+// responds with INVALID_REQUEST when the body cannot be decoded and with
+// VALIDATION_ERROR listing the fields that failed otherwise.
+func bindJSON(c *gin.Context, req interface{}) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"INVALID_REQUEST",
+			"Invalid request body",
+			err.Error(),
+			c.Request.URL.Path,
+		))
+		return false
+	}
+
+	if err := validation.Struct(req); err != nil {
+		fields := validation.Fields(err, c.GetHeader("Accept-Language"))
+		if fields == nil {
+			c.Error(err).SetMeta("Failed to validate request")
+			return false
+		}
+		writeValidationError(c, fields...)
+		return false
+	}
+	return true
+}
+
+// requiredField responds with VALIDATION_ERROR for a field that a check
+// outside of the validate tags found missing. Rule and param are those of the
+// validate tag the check stands for, such as required_without and items.
+func requiredField(c *gin.Context, field string, rule string, param string) {
+	writeValidationError(c, validation.Field(c.GetHeader("Accept-Language"), field, rule, param))
+}
+
+func writeValidationError(c *gin.Context, fields ...models.FieldError) {
+	c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+		"VALIDATION_ERROR",
+		"Validation failed",
+		fields,
+		c.Request.URL.Path,
+	))
+}
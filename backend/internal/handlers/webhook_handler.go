@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/repositories"
+	"kakeibo-tanuki/internal/webhooks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// DefaultDeliveryLimit is how many deliveries the delivery log lists unless
+// the request asks for more, up to MaxDeliveryLimit.
+const (
+	DefaultDeliveryLimit = 50
+	MaxDeliveryLimit     = 500
+)
+
+type WebhookHandler struct {
+	webhookRepo repositories.WebhookRepository
+}
+
+func NewWebhookHandler(webhookRepo repositories.WebhookRepository) *WebhookHandler {
+	return &WebhookHandler{
+		webhookRepo: webhookRepo,
+	}
+}
+
+func (h *WebhookHandler) GetWebhooks(c *gin.Context) {
+	allWebhooks, err := h.webhookRepo.GetAll(c.Request.Context())
+	if err != nil {
+		c.Error(err).SetMeta("Failed to retrieve webhooks")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Webhooks retrieved successfully", allWebhooks))
+}
+
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	webhook, ok := h.findWebhook(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Webhook retrieved successfully", webhook))
+}
+
+// CreateWebhook subscribes a URL to events. New webhooks are enabled unless
+// the request says otherwise.
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req models.CreateWebhookRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	webhook := &models.Webhook{ID: uuid.New(), Enabled: true}
+	if !setWebhook(c, webhook, models.UpdateWebhookRequest(req)) {
+		return
+	}
+
+	if err := h.webhookRepo.Create(c.Request.Context(), webhook); err != nil {
+		c.Error(err).SetMeta("Failed to create webhook")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.NewSuccessResponse("Webhook created successfully", webhook))
+}
+
+// UpdateWebhook replaces the URL and events of a webhook, and its secret
+// when one is given. Queued deliveries are sent with the new settings.
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	webhook, ok := h.findWebhook(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateWebhookRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if !setWebhook(c, webhook, req) {
+		return
+	}
+
+	if err := h.webhookRepo.Update(c.Request.Context(), webhook); err != nil {
+		c.Error(err).SetMeta("Failed to update webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Webhook updated successfully", webhook))
+}
+
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	webhook, ok := h.findWebhook(c)
+	if !ok {
+		return
+	}
+
+	if err := h.webhookRepo.Delete(c.Request.Context(), webhook.ID); err != nil {
+		c.Error(err).SetMeta("Failed to delete webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Webhook deleted successfully", nil))
+}
+
+// GetDeliveries lists the deliveries of a webhook, the newest first,
+// optionally only those with ?status=pending, succeeded or failed.
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	webhook, ok := h.findWebhook(c)
+	if !ok {
+		return
+	}
+
+	filters := &models.WebhookDeliveryFilters{WebhookID: webhook.ID, Limit: DefaultDeliveryLimit}
+	if status := c.Query("status"); status != "" {
+		if status != models.DeliveryPending && status != models.DeliverySucceeded && status != models.DeliveryFailed {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"INVALID_STATUS",
+				"Invalid delivery status",
+				"Status must be pending, succeeded or failed",
+				c.Request.URL.Path,
+			))
+			return
+		}
+		filters.Status = status
+	}
+	if limit := c.Query("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil || l < 1 || l > MaxDeliveryLimit {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"INVALID_LIMIT",
+				"Invalid limit",
+				fmt.Sprintf("Limit must be between 1 and %d", MaxDeliveryLimit),
+				c.Request.URL.Path,
+			))
+			return
+		}
+		filters.Limit = l
+	}
+
+	deliveries, err := h.webhookRepo.GetDeliveries(c.Request.Context(), filters)
+	if err != nil {
+		c.Error(err).SetMeta("Failed to retrieve deliveries")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSuccessResponse("Deliveries retrieved successfully", deliveries))
+}
+
+// setWebhook checks the event types of the request and copies it into
+// webhook.
+func setWebhook(c *gin.Context, webhook *models.Webhook, req models.UpdateWebhookRequest) bool {
+	for _, pattern := range req.Events {
+		if !webhooks.ValidPattern(pattern) {
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"INVALID_EVENT_TYPE",
+				"Invalid event type",
+				fmt.Sprintf("%q is not an event type, a kind such as \"card.*\" or \"*\"", pattern),
+				c.Request.URL.Path,
+			))
+			return false
+		}
+	}
+
+	webhook.URL = req.URL
+	if req.Secret != "" {
+		webhook.Secret = req.Secret
+	}
+	webhook.Events = req.Events
+	if req.Enabled != nil {
+		webhook.Enabled = *req.Enabled
+	}
+	return true
+}
+
+func (h *WebhookHandler) findWebhook(c *gin.Context) (*models.Webhook, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+			"INVALID_UUID",
+			"Invalid webhook ID format",
+			err.Error(),
+			c.Request.URL.Path,
+		))
+		return nil, false
+	}
+
+	webhook, err := h.webhookRepo.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.Error(err).SetMeta("Failed to retrieve webhook")
+		return nil, false
+	}
+	return webhook, true
+}
@@ -0,0 +1,306 @@
+// Package insights analyzes the spending history to point out expenses that
+// deserve a second look.
+package insights
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
+	"kakeibo-tanuki/internal/payees"
+)
+
+// HistoryDays is how far back expenses are compared with earlier ones.
+const HistoryDays = 365
+
+// MinHistory is the number of earlier expenses needed before an expense can
+// be called unusual.
+const MinHistory = 5
+
+const (
+	// OutlierRatio is how many times the median of earlier expenses of the
+	// same category an expense has to be to be an outlier
+	OutlierRatio = 2.0
+	// LargeChargeRatio is how many times the median of all earlier expenses
+	// an expense at a new merchant has to be to be flagged
+	LargeChargeRatio = 3.0
+	// SpikeRatio is how many times its average of the previous SpikeMonths
+	// months a category's monthly spending has to be to be a spike
+	SpikeRatio = 2.0
+	// SpikeMonths is the number of months a category's spending is averaged
+	// over. The category must have spending in each of them.
+	SpikeMonths = 3
+)
+
+// outlierScore is the modified z-score above which an amount is an outlier
+// (Iglewicz and Hoaglin). It keeps categories with widely varying amounts
+// from being flagged on the ratio alone.
+const outlierScore = 3.5
+
+// Charge is one expense, in the report currency.
+type Charge struct {
+	ExpenseID    uuid.UUID
+	Date         time.Time
+	Description  string
+	CardID       uuid.UUID
+	CardName     string
+	CategoryID   uuid.UUID
+	CategoryName string
+	Amount       money.Amount
+}
+
+// Spending is one amount of the report source, split by item category and
+// including refunds, in the report currency.
+type Spending struct {
+	Date         time.Time
+	CategoryID   uuid.UUID
+	CategoryName string
+	Amount       money.Amount
+}
+
+// HistoryStart returns the first day of the history needed to look for
+// anomalies from the given day on.
+func HistoryStart(from time.Time) time.Time {
+	return day(from).AddDate(0, 0, -HistoryDays)
+}
+
+// Detect returns the anomalies from through to (inclusive), given the
+// charges and spending from HistoryStart(from) through to. Every expense is
+// only compared with the expenses before it, so flagging an expense does not
+// depend on what was spent afterwards. Anomalies are sorted by date, the most
+// recent first.
+func Detect(charges []Charge, spending []Spending, from, to time.Time, currencyCode string) []models.Anomaly {
+	from, to = day(from), day(to)
+	d := detector{currencyCode: currencyCode}
+
+	sorted := make([]Charge, len(charges))
+	copy(sorted, charges)
+	for i := range sorted {
+		sorted[i].Date = day(sorted[i].Date)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].Date.Equal(sorted[j].Date) {
+			return sorted[i].Date.Before(sorted[j].Date)
+		}
+		return sorted[i].ExpenseID.String() < sorted[j].ExpenseID.String()
+	})
+
+	anomalies := []models.Anomaly{}
+	for _, charge := range sorted {
+		if charge.Date.Before(from) || charge.Date.After(to) {
+			continue
+		}
+		historyStart := charge.Date.AddDate(0, 0, -HistoryDays)
+		first := sort.Search(len(sorted), func(i int) bool { return !sorted[i].Date.Before(historyStart) })
+		last := sort.Search(len(sorted), func(i int) bool { return !sorted[i].Date.Before(charge.Date) })
+		earlier := sorted[first:last]
+
+		if anomaly, ok := d.outlier(charge, earlier); ok {
+			anomalies = append(anomalies, anomaly)
+		}
+		if anomaly, ok := d.newMerchant(charge, earlier); ok {
+			anomalies = append(anomalies, anomaly)
+		}
+	}
+	anomalies = append(anomalies, d.spikes(spending, from, to)...)
+
+	sort.SliceStable(anomalies, func(i, j int) bool {
+		a, b := anomalies[i], anomalies[j]
+		if a.Date != b.Date {
+			return a.Date > b.Date
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		if a.CategoryName != b.CategoryName {
+			return a.CategoryName < b.CategoryName
+		}
+		return a.ExpenseID != nil && b.ExpenseID != nil && a.ExpenseID.String() < b.ExpenseID.String()
+	})
+	return anomalies
+}
+
+type detector struct {
+	currencyCode string
+}
+
+// outlier flags a charge far above the median of the earlier charges of its
+// category on the same card, or of its category on any card while the card
+// has too few of them.
+func (d detector) outlier(charge Charge, earlier []Charge) (models.Anomaly, bool) {
+	var sameCard, sameCategory []float64
+	for _, e := range earlier {
+		if e.CategoryID != charge.CategoryID {
+			continue
+		}
+		sameCategory = append(sameCategory, float64(e.Amount))
+		if e.CardID == charge.CardID {
+			sameCard = append(sameCard, float64(e.Amount))
+		}
+	}
+	amounts := sameCard
+	if len(amounts) < MinHistory {
+		amounts = sameCategory
+	}
+	if len(amounts) < MinHistory {
+		return models.Anomaly{}, false
+	}
+
+	m := median(amounts)
+	x := float64(charge.Amount)
+	if m <= 0 || x < OutlierRatio*m {
+		return models.Anomaly{}, false
+	}
+	deviations := make([]float64, len(amounts))
+	for i, amount := range amounts {
+		deviations[i] = math.Abs(amount - m)
+	}
+	// When most earlier amounts are the same, any larger amount stands out
+	if mad := median(deviations); mad > 0 && 0.6745*(x-m)/mad < outlierScore {
+		return models.Anomaly{}, false
+	}
+
+	anomaly := d.chargeAnomaly(models.AnomalyTypeOutlier, charge, m)
+	anomaly.Message = fmt.Sprintf("%s %s is %.1f times the usual %s %s for %s",
+		anomaly.Amount, d.currencyCode, anomaly.Ratio, anomaly.Expected, d.currencyCode, charge.CategoryName)
+	return anomaly, true
+}
+
+// newMerchant flags a large charge with a description that no earlier
+// charge has.
+func (d detector) newMerchant(charge Charge, earlier []Charge) (models.Anomaly, bool) {
+	key := payees.Normalize(charge.Description)
+	if key == "" || len(earlier) < MinHistory {
+		return models.Anomaly{}, false
+	}
+
+	amounts := make([]float64, len(earlier))
+	for i, e := range earlier {
+		if payees.Normalize(e.Description) == key {
+			return models.Anomaly{}, false
+		}
+		amounts[i] = float64(e.Amount)
+	}
+	m := median(amounts)
+	if m <= 0 || float64(charge.Amount) < LargeChargeRatio*m {
+		return models.Anomaly{}, false
+	}
+
+	anomaly := d.chargeAnomaly(models.AnomalyTypeNewMerchant, charge, m)
+	anomaly.Message = fmt.Sprintf("First expense at %s is %s %s, %.1f times the usual %s %s",
+		charge.Description, anomaly.Amount, d.currencyCode, anomaly.Ratio, anomaly.Expected, d.currencyCode)
+	return anomaly, true
+}
+
+func (d detector) chargeAnomaly(anomalyType string, charge Charge, expected float64) models.Anomaly {
+	expenseID, cardID := charge.ExpenseID, charge.CardID
+	return models.Anomaly{
+		Type:         anomalyType,
+		ExpenseID:    &expenseID,
+		Description:  charge.Description,
+		CardID:       &cardID,
+		CardName:     charge.CardName,
+		Date:         charge.Date.Format("2006-01-02"),
+		CategoryID:   charge.CategoryID,
+		CategoryName: charge.CategoryName,
+		Amount:       charge.Amount,
+		Expected:     d.round(expected),
+		Ratio:        ratio(float64(charge.Amount), expected),
+	}
+}
+
+// spikes flags the months from through to in which a category spent at
+// least SpikeRatio times its average of the previous SpikeMonths months. The
+// month of to only counts spending up to to.
+func (d detector) spikes(spending []Spending, from, to time.Time) []models.Anomaly {
+	type categoryMonth struct {
+		categoryID uuid.UUID
+		month      int
+	}
+	sums := map[categoryMonth]money.Amount{}
+	names := map[uuid.UUID]string{}
+	var categories []uuid.UUID
+	for _, s := range spending {
+		if day(s.Date).After(to) {
+			continue
+		}
+		if _, ok := names[s.CategoryID]; !ok {
+			names[s.CategoryID] = s.CategoryName
+			categories = append(categories, s.CategoryID)
+		}
+		sums[categoryMonth{s.CategoryID, monthIndex(s.Date)}] += s.Amount
+	}
+
+	var anomalies []models.Anomaly
+	for month := monthIndex(from); month <= monthIndex(to); month++ {
+		for _, categoryID := range categories {
+			current := sums[categoryMonth{categoryID, month}]
+			if current <= 0 {
+				continue
+			}
+
+			var total money.Amount
+			regular := true
+			for m := month - SpikeMonths; m < month; m++ {
+				sum := sums[categoryMonth{categoryID, m}]
+				if sum <= 0 {
+					regular = false
+					break
+				}
+				total += sum
+			}
+			average := float64(total) / SpikeMonths
+			if !regular || float64(current) < SpikeRatio*average {
+				continue
+			}
+
+			start := time.Date(month/12, time.Month(month%12+1), 1, 0, 0, 0, 0, time.UTC)
+			anomaly := models.Anomaly{
+				Type:         models.AnomalyTypeCategorySpike,
+				Date:         start.Format("2006-01-02"),
+				CategoryID:   categoryID,
+				CategoryName: names[categoryID],
+				Amount:       current,
+				Expected:     d.round(average),
+				Ratio:        ratio(float64(current), average),
+			}
+			anomaly.Message = fmt.Sprintf("%s spending in %s is %s %s, %.1f times the monthly average of %s %s",
+				anomaly.CategoryName, start.Format("2006-01"), anomaly.Amount, d.currencyCode, anomaly.Ratio, anomaly.Expected, d.currencyCode)
+			anomalies = append(anomalies, anomaly)
+		}
+	}
+	return anomalies
+}
+
+func (d detector) round(hundredths float64) money.Amount {
+	return money.Amount(math.Round(hundredths)).Round(d.currencyCode)
+}
+
+func median(values []float64) float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+func ratio(amount, expected float64) float64 {
+	return math.Round(amount/expected*10) / 10
+}
+
+func day(t time.Time) time.Time {
+	year, month, d := t.Date()
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func monthIndex(t time.Time) int {
+	return t.Year()*12 + int(t.Month()) - 1
+}
@@ -0,0 +1,146 @@
+package installments
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
+
+	"github.com/google/uuid"
+)
+
+// MaxRevolvingPayments bounds revolving plans whose monthly payment is too
+// small to ever realistically pay off the purchase.
+const MaxRevolvingPayments = 240
+
+// BuildPlan creates an installment plan with its payment schedule for a
+// purchase of amount made on purchaseDate.
+//
+// Installment plans use equal total payments (元利均等) with interest charged
+// monthly on the remaining balance; any rounding difference is absorbed by
+// the final payment so that the principals add up to the purchase amount.
+// Revolving plans pay a fixed principal each month plus interest on the
+// remaining balance (元金定額). Amounts are rounded to the minor units of
+// currencyCode (whole yen for JPY).
+func BuildPlan(expenseID uuid.UUID, amount money.Amount, currencyCode string, purchaseDate time.Time, req *models.InstallmentPlanRequest, firstPaymentDate time.Time) (*models.InstallmentPlan, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("amount must be greater than 0")
+	}
+
+	// Due dates are computed from an anchor date so that a payment clamped
+	// to the end of a short month does not pull later payments forward
+	anchor, offset := truncateDay(firstPaymentDate), 0
+	if firstPaymentDate.IsZero() {
+		anchor, offset = truncateDay(purchaseDate), 1
+		firstPaymentDate = AddMonths(anchor, offset)
+	}
+	if firstPaymentDate.Before(truncateDay(purchaseDate)) {
+		return nil, fmt.Errorf("first payment date must not be before the purchase date")
+	}
+
+	plan := &models.InstallmentPlan{
+		ID:                 uuid.New(),
+		ExpenseID:          expenseID,
+		PaymentType:        req.PaymentType,
+		AnnualInterestRate: req.AnnualInterestRate,
+		FirstPaymentDate:   truncateDay(firstPaymentDate),
+	}
+
+	monthlyRate := req.AnnualInterestRate / 100 / 12
+
+	var payments []models.InstallmentPayment
+	switch req.PaymentType {
+	case models.PaymentTypeInstallment:
+		if req.NumberOfPayments < 2 {
+			return nil, fmt.Errorf("installment plans need at least 2 payments")
+		}
+		payments = equalPayments(amount, currencyCode, req.NumberOfPayments, monthlyRate)
+	case models.PaymentTypeRevolving:
+		if req.MonthlyPayment <= 0 {
+			return nil, fmt.Errorf("revolving plans need a monthly payment")
+		}
+		if (amount+req.MonthlyPayment-1)/req.MonthlyPayment > MaxRevolvingPayments {
+			return nil, fmt.Errorf("monthly payment is too small: more than %d payments would be needed", MaxRevolvingPayments)
+		}
+		plan.MonthlyPayment = req.MonthlyPayment
+		payments = fixedPrincipalPayments(amount, currencyCode, req.MonthlyPayment, monthlyRate)
+	default:
+		return nil, fmt.Errorf("unknown payment type: %s", req.PaymentType)
+	}
+
+	for i := range payments {
+		payments[i].ID = uuid.New()
+		payments[i].PlanID = plan.ID
+		payments[i].ExpenseID = expenseID
+		payments[i].Sequence = i + 1
+		payments[i].DueDate = AddMonths(anchor, offset+i)
+		plan.TotalInterest += payments[i].Interest
+	}
+	plan.NumberOfPayments = len(payments)
+	plan.Payments = payments
+
+	return plan, nil
+}
+
+func equalPayments(amount money.Amount, currencyCode string, n int, monthlyRate float64) []models.InstallmentPayment {
+	payments := make([]models.InstallmentPayment, n)
+
+	if monthlyRate == 0 {
+		// Without interest the odd yen goes into the first payment
+		for i, share := range amount.Split(n, currencyCode, 0) {
+			payments[i].Principal = share
+			payments[i].Amount = share
+		}
+		return payments
+	}
+
+	payment := amount.Mul(monthlyRate / (1 - math.Pow(1+monthlyRate, -float64(n)))).Round(currencyCode)
+	balance := amount
+	for i := range payments {
+		interest := balance.Mul(monthlyRate).Round(currencyCode)
+		principal := payment - interest
+		if i == n-1 || principal > balance {
+			principal = balance
+		}
+		payments[i].Principal = principal
+		payments[i].Interest = interest
+		payments[i].Amount = principal + interest
+		balance -= principal
+	}
+	return payments
+}
+
+func fixedPrincipalPayments(amount money.Amount, currencyCode string, monthlyPrincipal money.Amount, monthlyRate float64) []models.InstallmentPayment {
+	var payments []models.InstallmentPayment
+	balance := amount
+	for balance > 0 {
+		interest := balance.Mul(monthlyRate).Round(currencyCode)
+		principal := min(monthlyPrincipal, balance)
+		payments = append(payments, models.InstallmentPayment{
+			Principal: principal,
+			Interest:  interest,
+			Amount:    principal + interest,
+		})
+		balance -= principal
+	}
+	return payments
+}
+
+// AddMonths moves t by the given number of months, clamping to the last day
+// of the target month (Jan 31 + 1 month is Feb 28/29, not Mar 3).
+func AddMonths(t time.Time, months int) time.Time {
+	year, month, day := t.Date()
+	first := time.Date(year, month+time.Month(months), 1, 0, 0, 0, 0, t.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(first.Year(), first.Month(), day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
+
+func truncateDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/repositories"
+	"kakeibo-tanuki/internal/services"
+	"kakeibo-tanuki/internal/validation"
+)
+
+// ErrorMiddleware responds to an error that a handler leaves with c.Error
+// instead of writing a response. Errors of the services carry their own
+// status and code, and fields they reject are 400 VALIDATION_ERROR. Repository errors are mapped by their kind
+// and model: a missing card is 404 CARD_NOT_FOUND, a duplicate category 409
+// DUPLICATE_CATEGORY, and a reference to a record that does not exist or a
+// value the schema rejects is 422. A query cut off by the deadline of the
+// request is 504 REQUEST_TIMEOUT, and one canceled because the client went
+// away 503 REQUEST_CANCELED. Other errors are 500 INTERNAL_ERROR, with the
+// meta of the error, such as "Failed to retrieve card", as the message.
+func ErrorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		err := c.Errors.Last()
+		var fieldErr *services.FieldError
+		if errors.As(err.Err, &fieldErr) {
+			field := validation.Field(c.GetHeader("Accept-Language"), fieldErr.Field, fieldErr.Rule, fieldErr.Param)
+			c.JSON(http.StatusBadRequest, models.NewErrorResponse(
+				"VALIDATION_ERROR",
+				"Validation failed",
+				[]models.FieldError{field},
+				c.Request.URL.Path,
+			))
+			return
+		}
+
+		status, response := ErrorResponse(err.Err, c.Request.URL.Path)
+		// The context of TimeoutMiddleware is canceled by the time it
+		// returns, so the error it had while the handlers ran is used
+		ctxErr := c.Request.Context().Err()
+		if recorded, ok := c.Get(contextErrorKey); ok {
+			ctxErr, _ = recorded.(error)
+		}
+		if ctxErr != nil && status == http.StatusInternalServerError {
+			// Drivers do not always wrap the error of the context when it
+			// interrupts a query
+			status, response = ErrorResponse(errors.Join(ctxErr, err.Err), c.Request.URL.Path)
+		}
+		if message, ok := err.Meta.(string); ok && status == http.StatusInternalServerError {
+			response.Error.Message = message
+		}
+		c.JSON(status, response)
+	}
+}
+
+// ErrorResponse returns the status and response for err.
+func ErrorResponse(err error, path string) (int, *models.ErrorResponse) {
+	var serviceErr *services.Error
+	if errors.As(err, &serviceErr) {
+		return serviceErr.Status, models.NewErrorResponse(
+			serviceErr.Code,
+			serviceErr.Message,
+			serviceErr.Details,
+			path,
+		)
+	}
+
+	var dbErr *repositories.Error
+	model, name := "RECORD", "Record"
+	if errors.As(err, &dbErr) && dbErr.Model != "" {
+		model, name = modelCode(dbErr.Model)
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, models.NewErrorResponse(
+			"REQUEST_TIMEOUT",
+			"The request took too long to complete",
+			err.Error(),
+			path,
+		)
+	case errors.Is(err, context.Canceled):
+		return http.StatusServiceUnavailable, models.NewErrorResponse(
+			"REQUEST_CANCELED",
+			"The request was canceled before it completed",
+			err.Error(),
+			path,
+		)
+	case errors.Is(err, repositories.ErrNotFound):
+		return http.StatusNotFound, models.NewErrorResponse(
+			model+"_NOT_FOUND",
+			name+" not found",
+			nil,
+			path,
+		)
+	case errors.Is(err, repositories.ErrConflict):
+		return http.StatusConflict, models.NewErrorResponse(
+			"DUPLICATE_"+model,
+			name+" already exists",
+			nil,
+			path,
+		)
+	case errors.Is(err, repositories.ErrForeignKey):
+		return http.StatusUnprocessableEntity, models.NewErrorResponse(
+			"REFERENCE_NOT_FOUND",
+			name+" refers to a record that does not exist",
+			nil,
+			path,
+		)
+	case errors.Is(err, repositories.ErrCheckViolation):
+		return http.StatusUnprocessableEntity, models.NewErrorResponse(
+			"CONSTRAINT_VIOLATION",
+			name+" has a value that is not allowed",
+			nil,
+			path,
+		)
+	}
+	return http.StatusInternalServerError, models.NewErrorResponse(
+		"INTERNAL_ERROR",
+		"Internal server error",
+		err.Error(),
+		path,
+	)
+}
+
+// modelCode returns the name of a model, such as InstallmentPlan, as used in
+// error codes (INSTALLMENT_PLAN) and messages (Installment plan).
+func modelCode(model string) (string, string) {
+	var words []string
+	start := 0
+	for i, r := range model {
+		if i > 0 && unicode.IsUpper(r) {
+			words = append(words, model[start:i])
+			start = i
+		}
+	}
+	words = append(words, model[start:])
+
+	name := words[0]
+	for _, word := range words[1:] {
+		name += " " + strings.ToLower(word)
+	}
+	return strings.ToUpper(strings.Join(words, "_")), name
+}
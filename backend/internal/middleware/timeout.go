@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// contextErrorKey holds the error of the context of a request once its
+// handlers are done, before TimeoutMiddleware cancels the context.
+const contextErrorKey = "middleware:context_error"
+
+// TimeoutMiddleware gives the context of each request a deadline of timeout,
+// so that the queries of a request are canceled when it takes too long or
+// the client goes away. A query cut off by the deadline fails, and the error
+// middleware responds 504 REQUEST_TIMEOUT. Requests to paths starting with
+// one of exempt, such as the event stream that stays open, have no deadline.
+func TimeoutMiddleware(timeout time.Duration, exempt ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, prefix := range exempt {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				c.Next()
+				return
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+		c.Set(contextErrorKey, ctx.Err())
+	}
+}
@@ -0,0 +1,338 @@
+// Package migrate applies and rolls back the versioned SQL migrations of the
+// database schema. The versions applied are recorded in the
+// schema_migrations table with the checksum of their up migration, so that a
+// migration edited after it was applied is detected rather than silently
+// diverging from the databases it already ran on.
+package migrate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrChecksumMismatch is returned when an applied migration no longer
+	// matches the one it was applied from.
+	ErrChecksumMismatch = errors.New("migration changed since it was applied")
+	// ErrUnknownVersion is returned for a version no migration has.
+	ErrUnknownVersion = errors.New("unknown migration version")
+)
+
+// Migration is a version of the schema, with the SQL that brings the schema
+// to it from the previous version and back.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+	// Checksum is the SHA-256 of Up
+	Checksum string
+}
+
+// Status is a migration and whether it is applied. Missing is set for a
+// version that is applied but has no migration, for example one written by a
+// newer release.
+type Status struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+	Modified  bool
+	Missing   bool
+}
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Load reads the migrations from the NNN_name.up.sql and NNN_name.down.sql
+// files at the root of fsys, ordered by version.
+func Load(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, file := range files {
+		match := fileName.FindStringSubmatch(path.Base(file))
+		if match == nil {
+			return nil, fmt.Errorf("migration file %s is not named NNN_name.up.sql or NNN_name.down.sql", file)
+		}
+		version, err := strconv.Atoi(match[1])
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration file %s has an invalid version", file)
+		}
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", file, err)
+		}
+
+		migration := byVersion[version]
+		if migration == nil {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %03d has files named %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(data)
+			sum := sha256.Sum256(data)
+			migration.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			migration.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Checksum == "" {
+			return nil, fmt.Errorf("migration %03d_%s has no up migration", migration.Version, migration.Name)
+		}
+		if migration.Down == "" {
+			return nil, fmt.Errorf("migration %03d_%s has no down migration", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// record is a row of schema_migrations.
+type record struct {
+	Version   int       `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"not null"`
+	Checksum  string    `gorm:"not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (record) TableName() string {
+	return "schema_migrations"
+}
+
+const createTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version BIGINT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    checksum VARCHAR(64) NOT NULL,
+    applied_at TIMESTAMP NOT NULL
+)`
+
+// lockKey identifies the PostgreSQL advisory lock held while migrating
+const lockKey int64 = 0x6b616b6569626f
+
+// Migrator migrates a database. Instances sharing a PostgreSQL database
+// take turns through an advisory lock, so that each migration runs once
+// even when they start together; SQLite serializes them by itself.
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// New returns a migrator of db with the migrations in fsys.
+func New(db *gorm.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Latest returns the version of the last migration, 0 when there is none.
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Status lists the migrations with the versions applied.
+func (m *Migrator) Status() ([]Status, error) {
+	var statuses []Status
+	err := m.locked(func(conn *gorm.DB, applied map[int]record) error {
+		for _, migration := range m.migrations {
+			status := Status{Version: migration.Version, Name: migration.Name}
+			if r, ok := applied[migration.Version]; ok {
+				appliedAt := r.AppliedAt
+				status.Applied = true
+				status.AppliedAt = &appliedAt
+				status.Modified = r.Checksum != migration.Checksum
+				delete(applied, migration.Version)
+			}
+			statuses = append(statuses, status)
+		}
+		for _, r := range applied {
+			appliedAt := r.AppliedAt
+			statuses = append(statuses, Status{Version: r.Version, Name: r.Name, Applied: true, AppliedAt: &appliedAt, Missing: true})
+		}
+		return nil
+	})
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	return statuses, err
+}
+
+// Up applies the migrations not applied yet and returns them.
+func (m *Migrator) Up() ([]Migration, error) {
+	return m.To(m.Latest())
+}
+
+// Down rolls back the last steps migrations applied and returns them.
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(func(conn *gorm.DB, applied map[int]record) error {
+		if err := m.verify(applied); err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			if err := run(conn, migration, false); err != nil {
+				return err
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// To brings the schema to version, rolling back the migrations after it
+// and applying the ones up to it, and returns the migrations it ran. Version
+// 0 rolls back every migration.
+func (m *Migrator) To(version int) ([]Migration, error) {
+	if version != 0 && m.find(version) == nil {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+
+	var done []Migration
+	err := m.locked(func(conn *gorm.DB, applied map[int]record) error {
+		if err := m.verify(applied); err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok || migration.Version <= version {
+				continue
+			}
+			if err := run(conn, migration, false); err != nil {
+				return err
+			}
+			done = append(done, migration)
+		}
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok || migration.Version > version {
+				continue
+			}
+			if err := run(conn, migration, true); err != nil {
+				return err
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+func (m *Migrator) find(version int) *Migration {
+	for i := range m.migrations {
+		if m.migrations[i].Version == version {
+			return &m.migrations[i]
+		}
+	}
+	return nil
+}
+
+// verify checks that every version applied is a known migration that has
+// not changed since.
+func (m *Migrator) verify(applied map[int]record) error {
+	versions := make([]int, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Ints(versions)
+
+	for _, version := range versions {
+		r := applied[version]
+		migration := m.find(version)
+		if migration == nil {
+			return fmt.Errorf("%w: %03d_%s is applied but has no migration", ErrUnknownVersion, version, r.Name)
+		}
+		if migration.Checksum != r.Checksum {
+			return fmt.Errorf("%w: %03d_%s", ErrChecksumMismatch, version, migration.Name)
+		}
+	}
+	return nil
+}
+
+// locked runs fn on a single connection of the database, holding the
+// migration lock, with the versions applied.
+func (m *Migrator) locked(fn func(conn *gorm.DB, applied map[int]record) error) error {
+	return m.db.Connection(func(conn *gorm.DB) error {
+		if conn.Dialector.Name() == "postgres" {
+			if err := conn.Exec("SELECT pg_advisory_lock(?)", lockKey).Error; err != nil {
+				return fmt.Errorf("failed to lock migrations: %w", err)
+			}
+			defer conn.Exec("SELECT pg_advisory_unlock(?)", lockKey)
+		}
+
+		if err := conn.Exec(createTable).Error; err != nil {
+			return fmt.Errorf("failed to create schema_migrations: %w", err)
+		}
+		var records []record
+		if err := conn.Find(&records).Error; err != nil {
+			return fmt.Errorf("failed to read schema_migrations: %w", err)
+		}
+		applied := make(map[int]record, len(records))
+		for _, r := range records {
+			applied[r.Version] = r
+		}
+		return fn(conn, applied)
+	})
+}
+
+// run applies or rolls back a migration, in a transaction with its record.
+func run(conn *gorm.DB, migration Migration, up bool) error {
+	err := conn.Transaction(func(tx *gorm.DB) error {
+		if !up {
+			if err := tx.Exec(migration.Down).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&record{}, migration.Version).Error
+		}
+
+		if err := tx.Exec(migration.Up).Error; err != nil {
+			return err
+		}
+		return tx.Create(&record{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			Checksum:  migration.Checksum,
+			AppliedAt: time.Now().UTC(),
+		}).Error
+	})
+
+	direction, done := "apply", "Applied"
+	if !up {
+		direction, done = "roll back", "Rolled back"
+	}
+	if err != nil {
+		return fmt.Errorf("failed to %s migration %03d_%s: %w", direction, migration.Version, migration.Name, err)
+	}
+	log.Printf("%s migration %03d_%s", done, migration.Version, migration.Name)
+	return nil
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"kakeibo-tanuki/internal/money"
+)
+
+const (
+	AlertPeriodWeek    = "week"
+	AlertPeriodMonth   = "month"
+	AlertPeriodQuarter = "quarter"
+	AlertPeriodYear    = "year"
+)
+
+// Notification channels of alerts.
+const (
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+	ChannelInApp   = "inApp"
+)
+
+// Alert warns when the spending of a period reaches Percent percent of
+// Amount, in the base currency. It is limited to a category and a card when
+// they are set, and fires at most once per period.
+type Alert struct {
+	ID         uuid.UUID    `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Name       string       `json:"name" gorm:"not null" validate:"required,max=100"`
+	CategoryID *uuid.UUID   `json:"categoryId" gorm:"type:uuid;index"`
+	CardID     *uuid.UUID   `json:"cardId" gorm:"type:uuid;index"`
+	Period     string       `json:"period" gorm:"type:varchar(10);not null"`
+	Amount     money.Amount `json:"amount" gorm:"not null"`
+	Percent    int          `json:"percent" gorm:"not null"`
+	Channels   []string     `json:"channels" gorm:"type:text;serializer:json"`
+	Email      string       `json:"email"`
+	WebhookURL string       `json:"webhookUrl"`
+	Enabled    bool         `json:"enabled" gorm:"not null"`
+	// LastTriggered is the first day of the last period the alert fired in
+	LastTriggered string    `json:"lastTriggered"`
+	CreatedAt     time.Time `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time `json:"updatedAt" gorm:"autoUpdateTime"`
+}
+
+type CreateAlertRequest struct {
+	Name       string       `json:"name" validate:"required,max=100"`
+	CategoryID string       `json:"categoryId"`
+	CardID     string       `json:"cardId"`
+	Period     string       `json:"period" validate:"required,oneof=week month quarter year"`
+	Amount     money.Amount `json:"amount" validate:"required,gt=0"`
+	Percent    int          `json:"percent" validate:"omitempty,min=1,max=1000"`
+	Channels   []string     `json:"channels" validate:"required,min=1,dive,oneof=email webhook inApp"`
+	Email      string       `json:"email" validate:"omitempty,email"`
+	WebhookURL string       `json:"webhookUrl" validate:"omitempty,url"`
+	Enabled    *bool        `json:"enabled"`
+}
+
+type UpdateAlertRequest struct {
+	Name       string       `json:"name" validate:"required,max=100"`
+	CategoryID string       `json:"categoryId"`
+	CardID     string       `json:"cardId"`
+	Period     string       `json:"period" validate:"required,oneof=week month quarter year"`
+	Amount     money.Amount `json:"amount" validate:"required,gt=0"`
+	Percent    int          `json:"percent" validate:"omitempty,min=1,max=1000"`
+	Channels   []string     `json:"channels" validate:"required,min=1,dive,oneof=email webhook inApp"`
+	Email      string       `json:"email" validate:"omitempty,email"`
+	WebhookURL string       `json:"webhookUrl" validate:"omitempty,url"`
+	Enabled    *bool        `json:"enabled"`
+}
+
+// AlertEvent is an alert firing: the spending from From through To reached
+// Threshold.
+type AlertEvent struct {
+	AlertID   uuid.UUID    `json:"alertId"`
+	AlertName string       `json:"alertName"`
+	From      string       `json:"from"`
+	To        string       `json:"to"`
+	Currency  string       `json:"currency"`
+	Spent     money.Amount `json:"spent"`
+	Amount    money.Amount `json:"amount"`
+	Threshold money.Amount `json:"threshold"`
+	// Percent is Spent as a percentage of Amount
+	Percent float64 `json:"percent"`
+	Message string  `json:"message"`
+	// Errors lists the channels that failed to deliver the event
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// Notification is an alert shown in the app.
+type Notification struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	AlertID   *uuid.UUID `json:"alertId" gorm:"type:uuid;index"`
+	Title     string     `json:"title" gorm:"not null"`
+	Message   string     `json:"message" gorm:"not null"`
+	ReadAt    *time.Time `json:"readAt"`
+	CreatedAt time.Time  `json:"createdAt" gorm:"autoCreateTime"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Attachment struct {
+	ID           uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ExpenseID    uuid.UUID `json:"expenseId" gorm:"type:uuid;not null;index"`
+	FileName     string    `json:"fileName" gorm:"not null"`
+	ContentType  string    `json:"contentType" gorm:"not null"`
+	Size         int64     `json:"size" gorm:"not null"`
+	StorageKey   string    `json:"-" gorm:"not null"`
+	ThumbnailKey string    `json:"-"`
+	HasThumbnail bool      `json:"hasThumbnail" gorm:"not null;default:false"`
+	Expense      *Expense  `json:"-" gorm:"foreignKey:ExpenseID;constraint:OnDelete:CASCADE"`
+	CreatedAt    time.Time `json:"createdAt" gorm:"autoCreateTime"`
+}
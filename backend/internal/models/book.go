@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SharedExpenseMembers is the number of members shared expenses are split
+// between.
+const SharedExpenseMembers = 2
+
+// Book holds the settings of the household account book. Reports are
+// converted to its base currency, and when shared expenses do not split
+// evenly the remainder goes to the member at index RemainderMember.
+type Book struct {
+	ID              uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Name            string    `json:"name" gorm:"not null"`
+	BaseCurrency    string    `json:"baseCurrency" gorm:"type:varchar(3);not null;default:'JPY'"`
+	RemainderMember int       `json:"remainderMember" gorm:"not null;default:0"`
+	CreatedAt       time.Time `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time `json:"updatedAt" gorm:"autoUpdateTime"`
+}
+
+type UpdateBookRequest struct {
+	Name            string `json:"name" validate:"required,min=1,max=100"`
+	BaseCurrency    string `json:"baseCurrency" validate:"required,len=3"`
+	RemainderMember int    `json:"remainderMember" validate:"min=0,max=1"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExchangeRate is the price of one unit of Currency in BaseCurrency on Date.
+// Reports use the latest rate on or before the expense date.
+type ExchangeRate struct {
+	ID           uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Currency     string    `json:"currency" gorm:"type:varchar(3);not null;uniqueIndex:idx_exchange_rates_pair_date"`
+	BaseCurrency string    `json:"baseCurrency" gorm:"type:varchar(3);not null;uniqueIndex:idx_exchange_rates_pair_date"`
+	Date         time.Time `json:"date" gorm:"type:date;not null;uniqueIndex:idx_exchange_rates_pair_date"`
+	Rate         float64   `json:"rate" gorm:"type:decimal(18,8);not null;check:rate > 0"`
+	CreatedAt    time.Time `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `json:"updatedAt" gorm:"autoUpdateTime"`
+}
+
+type CreateExchangeRateRequest struct {
+	Currency     string  `json:"currency" validate:"required,len=3"`
+	BaseCurrency string  `json:"baseCurrency" validate:"omitempty,len=3"`
+	Date         string  `json:"date" validate:"required"`
+	Rate         float64 `json:"rate" validate:"required,gt=0"`
+}
+
+type ExchangeRateFilters struct {
+	Currency     string     `json:"currency"`
+	BaseCurrency string     `json:"baseCurrency"`
+	StartDate    *time.Time `json:"startDate"`
+	EndDate      *time.Time `json:"endDate"`
+}
+
+type ExchangeRateImportResult struct {
+	Imported int `json:"imported"`
+}
@@ -4,35 +4,74 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"kakeibo-tanuki/internal/money"
 )
 
+// Expense is one purchase. IsShared, when set, overrides whether its
+// category is shared for the shared expenses of reports.
 type Expense struct {
-	ID          uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	Amount      float64   `json:"amount" gorm:"not null;check:amount > 0" validate:"required,gt=0"`
-	Date        time.Time `json:"date" gorm:"not null" validate:"required"`
-	Description string    `json:"description"`
-	CardID      uuid.UUID `json:"cardId" gorm:"not null" validate:"required"`
-	CategoryID  uuid.UUID `json:"categoryId" gorm:"not null" validate:"required"`
-	Card        Card      `json:"card,omitempty" gorm:"foreignKey:CardID;constraint:OnDelete:CASCADE"`
-	Category    Category  `json:"category,omitempty" gorm:"foreignKey:CategoryID;constraint:OnDelete:RESTRICT"`
-	CreatedAt   time.Time `json:"createdAt" gorm:"autoCreateTime"`
-	UpdatedAt   time.Time `json:"updatedAt" gorm:"autoUpdateTime"`
+	ID          uuid.UUID     `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Amount      money.Amount  `json:"amount" gorm:"not null;check:amount > 0" validate:"required,gt=0"`
+	Currency    string        `json:"currency" gorm:"type:varchar(3);not null;default:'JPY'"`
+	Date        time.Time     `json:"date" gorm:"not null" validate:"required"`
+	Description string        `json:"description"`
+	CardID      uuid.UUID     `json:"cardId" gorm:"not null" validate:"required"`
+	CategoryID  uuid.UUID     `json:"categoryId" gorm:"not null" validate:"required"`
+	PayeeID     *uuid.UUID    `json:"payeeId" gorm:"type:uuid;index"`
+	Tags        []string      `json:"tags,omitempty" gorm:"type:text;serializer:json"`
+	IsShared    *bool         `json:"isShared"`
+	Card        Card          `json:"card,omitempty" gorm:"foreignKey:CardID;constraint:OnDelete:CASCADE"`
+	Category    Category      `json:"category,omitempty" gorm:"foreignKey:CategoryID;constraint:OnDelete:RESTRICT"`
+	Payee       *Payee        `json:"payee,omitempty" gorm:"foreignKey:PayeeID;constraint:OnDelete:SET NULL"`
+	Items       []ExpenseItem `json:"items,omitempty" gorm:"foreignKey:ExpenseID;constraint:OnDelete:CASCADE"`
+	Refunds     []Refund      `json:"refunds,omitempty" gorm:"foreignKey:ExpenseID;constraint:OnDelete:CASCADE"`
+	CreatedAt   time.Time     `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time     `json:"updatedAt" gorm:"autoUpdateTime"`
+}
+
+// ExpenseItem is one line of an expense split across several categories.
+// When an expense has items, their amounts add up to the expense amount and
+// reports aggregate by the item categories instead of Expense.CategoryID.
+type ExpenseItem struct {
+	ID         uuid.UUID    `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ExpenseID  uuid.UUID    `json:"expenseId" gorm:"type:uuid;not null;index"`
+	CategoryID uuid.UUID    `json:"categoryId" gorm:"type:uuid;not null;index"`
+	Amount     money.Amount `json:"amount" gorm:"not null;check:amount > 0"`
+	Note       string       `json:"note"`
+	Category   Category     `json:"category,omitempty" gorm:"foreignKey:CategoryID;constraint:OnDelete:RESTRICT"`
 }
 
 type CreateExpenseRequest struct {
-	Amount      float64 `json:"amount" validate:"required,gt=0"`
-	Date        string  `json:"date" validate:"required"`
-	Description string  `json:"description"`
-	CardID      string  `json:"cardId" validate:"required"`
-	CategoryID  string  `json:"categoryId" validate:"required"`
+	Amount      money.Amount         `json:"amount" validate:"required,gt=0"`
+	Currency    string               `json:"currency,omitempty" validate:"omitempty,len=3"`
+	Date        string               `json:"date" validate:"required"`
+	Description string               `json:"description"`
+	CardID      string               `json:"cardId" validate:"required"`
+	CategoryID  string               `json:"categoryId"`
+	PayeeID     string               `json:"payeeId,omitempty"`
+	Tags        []string             `json:"tags,omitempty" validate:"omitempty,dive,required,max=50"`
+	IsShared    *bool                `json:"isShared,omitempty"`
+	Items       []ExpenseItemRequest `json:"items,omitempty" validate:"omitempty,min=1,dive"`
 }
 
 type UpdateExpenseRequest struct {
-	Amount      float64 `json:"amount" validate:"required,gt=0"`
-	Date        string  `json:"date" validate:"required"`
-	Description string  `json:"description"`
-	CardID      string  `json:"cardId" validate:"required"`
-	CategoryID  string  `json:"categoryId" validate:"required"`
+	Amount      money.Amount         `json:"amount" validate:"required,gt=0"`
+	Currency    string               `json:"currency,omitempty" validate:"omitempty,len=3"`
+	Date        string               `json:"date" validate:"required"`
+	Description string               `json:"description"`
+	CardID      string               `json:"cardId" validate:"required"`
+	CategoryID  string               `json:"categoryId" validate:"required_without=Items"`
+	PayeeID     *string              `json:"payeeId,omitempty"`
+	Tags        []string             `json:"tags,omitempty" validate:"omitempty,dive,required,max=50"`
+	IsShared    *bool                `json:"isShared,omitempty"`
+	Items       []ExpenseItemRequest `json:"items,omitempty" validate:"omitempty,min=1,dive"`
+}
+
+type ExpenseItemRequest struct {
+	Amount     money.Amount `json:"amount" validate:"required,gt=0"`
+	CategoryID string       `json:"categoryId" validate:"required"`
+	Note       string       `json:"note" validate:"max=200"`
 }
 
 type ExpenseFilters struct {
@@ -40,6 +79,7 @@ type ExpenseFilters struct {
 	EndDate    *time.Time `json:"endDate"`
 	CardID     *uuid.UUID `json:"cardId"`
 	CategoryID *uuid.UUID `json:"categoryId"`
+	PayeeID    *uuid.UUID `json:"payeeId"`
 	Page       int        `json:"page"`
 	Limit      int        `json:"limit"`
-}
\ No newline at end of file
+}
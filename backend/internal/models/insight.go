@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"kakeibo-tanuki/internal/money"
+)
+
+const (
+	// AnomalyTypeOutlier is an expense much larger than the usual ones of its
+	// category on the same card
+	AnomalyTypeOutlier = "amountOutlier"
+	// AnomalyTypeNewMerchant is a large expense with a description not seen
+	// before
+	AnomalyTypeNewMerchant = "newMerchant"
+	// AnomalyTypeCategorySpike is a month in which a category's spending is
+	// far above its recent average
+	AnomalyTypeCategorySpike = "categorySpike"
+)
+
+// Anomaly is an expense, or the spending of a category in a month, that is
+// unusual compared to the history before it. Amounts are in the currency of
+// the report.
+type Anomaly struct {
+	Type string `json:"type"`
+	// ExpenseID, Description and the card are left out for category spikes
+	ExpenseID   *uuid.UUID `json:"expenseId,omitempty"`
+	Description string     `json:"description,omitempty"`
+	CardID      *uuid.UUID `json:"cardId,omitempty"`
+	CardName    string     `json:"cardName,omitempty"`
+	// Date is the expense date, or the first day of the month of a spike
+	Date         string       `json:"date"`
+	CategoryID   uuid.UUID    `json:"categoryId"`
+	CategoryName string       `json:"categoryName"`
+	Amount       money.Amount `json:"amount"`
+	// Expected is the typical amount the anomaly is measured against: the
+	// median of earlier expenses, or the average monthly spending of the
+	// category
+	Expected money.Amount `json:"expected"`
+	// Ratio is Amount divided by Expected, rounded to one decimal
+	Ratio   float64 `json:"ratio"`
+	Message string  `json:"message"`
+}
+
+type AnomalyReport struct {
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Currency  string    `json:"currency"`
+	Anomalies []Anomaly `json:"anomalies"`
+}
+
+type AnomalyFilters struct {
+	StartDate time.Time  `json:"startDate"`
+	EndDate   time.Time  `json:"endDate"`
+	CardID    *uuid.UUID `json:"cardId"`
+	// ExpenseID limits the anomalies to those of a single expense and the
+	// spikes of its categories
+	ExpenseID *uuid.UUID `json:"expenseId"`
+	Currency  string     `json:"currency"`
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"kakeibo-tanuki/internal/money"
+)
+
+const (
+	// PaymentTypeInstallment is a fixed number of equal payments (分割払い)
+	PaymentTypeInstallment = "installment"
+	// PaymentTypeRevolving is a fixed monthly principal until paid off (リボ払い)
+	PaymentTypeRevolving = "revolving"
+)
+
+const (
+	// ReportViewPurchase aggregates expenses by purchase date
+	ReportViewPurchase = "purchase"
+	// ReportViewPayment aggregates installment purchases by billing month
+	ReportViewPayment = "payment"
+)
+
+// InstallmentPlan describes how a card purchase is paid off over several
+// billing months. An expense has at most one plan.
+type InstallmentPlan struct {
+	ID                 uuid.UUID            `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ExpenseID          uuid.UUID            `json:"expenseId" gorm:"type:uuid;not null;uniqueIndex"`
+	PaymentType        string               `json:"paymentType" gorm:"not null"`
+	NumberOfPayments   int                  `json:"numberOfPayments" gorm:"not null"`
+	AnnualInterestRate float64              `json:"annualInterestRate" gorm:"not null;default:0"`
+	MonthlyPayment     money.Amount         `json:"monthlyPayment,omitempty"`
+	FirstPaymentDate   time.Time            `json:"firstPaymentDate" gorm:"type:date;not null"`
+	TotalInterest      money.Amount         `json:"totalInterest" gorm:"not null;default:0"`
+	Payments           []InstallmentPayment `json:"payments" gorm:"foreignKey:PlanID;constraint:OnDelete:CASCADE"`
+	Expense            *Expense             `json:"-" gorm:"foreignKey:ExpenseID;constraint:OnDelete:CASCADE"`
+	CreatedAt          time.Time            `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt          time.Time            `json:"updatedAt" gorm:"autoUpdateTime"`
+}
+
+// InstallmentPayment is a single charge of an installment plan in a billing month.
+type InstallmentPayment struct {
+	ID        uuid.UUID    `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	PlanID    uuid.UUID    `json:"planId" gorm:"type:uuid;not null;index"`
+	ExpenseID uuid.UUID    `json:"expenseId" gorm:"type:uuid;not null;index"`
+	Sequence  int          `json:"sequence" gorm:"not null"`
+	DueDate   time.Time    `json:"dueDate" gorm:"type:date;not null;index"`
+	Principal money.Amount `json:"principal" gorm:"not null"`
+	Interest  money.Amount `json:"interest" gorm:"not null;default:0"`
+	Amount    money.Amount `json:"amount" gorm:"not null"`
+}
+
+type InstallmentPlanRequest struct {
+	PaymentType        string       `json:"paymentType" validate:"required,oneof=installment revolving"`
+	NumberOfPayments   int          `json:"numberOfPayments" validate:"required_if=PaymentType installment,omitempty,min=2,max=120"`
+	AnnualInterestRate float64      `json:"annualInterestRate" validate:"gte=0,lte=20"`
+	MonthlyPayment     money.Amount `json:"monthlyPayment" validate:"required_if=PaymentType revolving,omitempty,gt=0"`
+	FirstPaymentDate   string       `json:"firstPaymentDate"`
+}
+
+// CardInstallmentBalance summarises the unpaid installment charges of a card
+// in one currency.
+type CardInstallmentBalance struct {
+	CardID             uuid.UUID    `json:"cardId"`
+	CardName           string       `json:"cardName"`
+	Color              string       `json:"color"`
+	Currency           string       `json:"currency"`
+	ActivePlans        int          `json:"activePlans"`
+	RemainingPayments  int          `json:"remainingPayments"`
+	RemainingPrincipal money.Amount `json:"remainingPrincipal"`
+	RemainingAmount    money.Amount `json:"remainingAmount"`
+	NextPaymentDate    *time.Time   `json:"nextPaymentDate,omitempty"`
+	NextPaymentAmount  money.Amount `json:"nextPaymentAmount"`
+}
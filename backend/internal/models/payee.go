@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"kakeibo-tanuki/internal/money"
+)
+
+// Payee is a shop or anyone else expenses are paid to. Expenses are linked
+// to a payee when their description matches its name or one of its aliases,
+// ignoring case, spacing and full-width characters, or one of its patterns,
+// which are regular expressions matched case-insensitively.
+type Payee struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Name      string    `json:"name" gorm:"not null;unique" validate:"required,max=100"`
+	Aliases   []string  `json:"aliases" gorm:"type:text;serializer:json"`
+	Patterns  []string  `json:"patterns" gorm:"type:text;serializer:json"`
+	CreatedAt time.Time `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updatedAt" gorm:"autoUpdateTime"`
+}
+
+type CreatePayeeRequest struct {
+	Name     string   `json:"name" validate:"required,max=100"`
+	Aliases  []string `json:"aliases" validate:"omitempty,dive,required,max=100"`
+	Patterns []string `json:"patterns" validate:"omitempty,dive,required,max=200"`
+}
+
+type UpdatePayeeRequest struct {
+	Name     string   `json:"name" validate:"required,max=100"`
+	Aliases  []string `json:"aliases" validate:"omitempty,dive,required,max=100"`
+	Patterns []string `json:"patterns" validate:"omitempty,dive,required,max=200"`
+}
+
+// PayeeResult is a saved payee and the number of existing expenses without
+// a payee that were linked to it.
+type PayeeResult struct {
+	Payee
+	MatchedExpenses int `json:"matchedExpenses"`
+}
+
+type PayeeExpenseSum struct {
+	PayeeID     uuid.UUID    `json:"payeeId"`
+	PayeeName   string       `json:"payeeName"`
+	TotalAmount money.Amount `json:"totalAmount"`
+	Count       int          `json:"count"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"kakeibo-tanuki/internal/money"
+)
+
+// Refund is a return or card credit against an earlier expense. Amounts are
+// stored as positive values and subtracted from the original expense's card
+// and categories in reports, on the date of the refund.
+type Refund struct {
+	ID          uuid.UUID    `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	ExpenseID   uuid.UUID    `json:"expenseId" gorm:"type:uuid;not null;index"`
+	Amount      money.Amount `json:"amount" gorm:"not null;check:amount > 0"`
+	Date        time.Time    `json:"date" gorm:"not null"`
+	Description string       `json:"description"`
+	Expense     *Expense     `json:"-" gorm:"foreignKey:ExpenseID;constraint:OnDelete:CASCADE"`
+	CreatedAt   time.Time    `json:"createdAt" gorm:"autoCreateTime"`
+}
+
+type CreateRefundRequest struct {
+	Amount      money.Amount `json:"amount" validate:"required,gt=0"`
+	Date        string       `json:"date" validate:"required"`
+	Description string       `json:"description"`
+}
@@ -1,24 +1,200 @@
 package models
 
 import (
+	"time"
+
 	"github.com/google/uuid"
+
+	"kakeibo-tanuki/internal/money"
+)
+
+const (
+	ReportGroupByDay     = "day"
+	ReportGroupByWeek    = "week"
+	ReportGroupByMonth   = "month"
+	ReportGroupByQuarter = "quarter"
+)
+
+const (
+	// CompareAgainstPrevious compares with the period just before
+	CompareAgainstPrevious = "previous"
+	// CompareAgainstLastYear compares with the same period a year earlier
+	CompareAgainstLastYear = "lastYear"
 )
 
 type MonthlyReport struct {
 	Year            int                     `json:"year"`
 	Month           int                     `json:"month"`
-	TotalAmount     float64                 `json:"totalAmount"`
+	View            string                  `json:"view"`
+	Currency        string                  `json:"currency"`
+	TotalAmount     money.Amount            `json:"totalAmount"`
 	SharedExpenses  SharedExpensesSummary   `json:"sharedExpenses"`
 	ByCategory      []CategoryExpenseSum    `json:"byCategory"`
 	ByCard          []CardExpenseSum        `json:"byCard"`
+	// ByPayee lists the payees spent most on
+	ByPayee         []PayeeExpenseSum       `json:"byPayee"`
 }
 
 type YearlyReport struct {
 	Year        int                   `json:"year"`
-	TotalAmount float64               `json:"totalAmount"`
+	View        string                `json:"view"`
+	Currency    string                `json:"currency"`
+	TotalAmount money.Amount          `json:"totalAmount"`
 	MonthlyData []MonthlyExpenseSum   `json:"monthlyData"`
 	ByCategory  []CategoryExpenseSum  `json:"byCategory"`
 	ByCard      []CardExpenseSum      `json:"byCard"`
+	// ByPayee lists the payees spent most on
+	ByPayee     []PayeeExpenseSum     `json:"byPayee"`
+}
+
+// RangeReport covers the days From through To, both inclusive, broken down
+// into periods of GroupBy.
+type RangeReport struct {
+	From           string                `json:"from"`
+	To             string                `json:"to"`
+	GroupBy        string                `json:"groupBy"`
+	View           string                `json:"view"`
+	Currency       string                `json:"currency"`
+	TotalAmount    money.Amount          `json:"totalAmount"`
+	Periods        []PeriodExpenseSum    `json:"periods"`
+	SharedExpenses SharedExpensesSummary `json:"sharedExpenses"`
+	ByCategory     []CategoryExpenseSum  `json:"byCategory"`
+	ByCard         []CardExpenseSum      `json:"byCard"`
+}
+
+// ComparisonReport compares the spending of the Current period with the
+// Previous one. Change is Current minus Previous, and ChangePercent is null
+// when nothing was spent in the previous period.
+type ComparisonReport struct {
+	View                  string               `json:"view"`
+	Currency              string               `json:"currency"`
+	Current               ReportPeriod         `json:"current"`
+	Previous              ReportPeriod         `json:"previous"`
+	Change                money.Amount         `json:"change"`
+	ChangePercent         *float64             `json:"changePercent"`
+	ByCategory            []CategoryComparison `json:"byCategory"`
+	ByCard                []CardComparison     `json:"byCard"`
+	NewCategories         []CategoryComparison `json:"newCategories"`
+	DisappearedCategories []CategoryComparison `json:"disappearedCategories"`
+}
+
+type ReportPeriod struct {
+	From        string       `json:"from"`
+	To          string       `json:"to"`
+	TotalAmount money.Amount `json:"totalAmount"`
+}
+
+type CategoryComparison struct {
+	CategoryID     uuid.UUID    `json:"categoryId"`
+	CategoryName   string       `json:"categoryName"`
+	Color          string       `json:"color"`
+	IsShared       bool         `json:"isShared"`
+	CurrentAmount  money.Amount `json:"currentAmount"`
+	PreviousAmount money.Amount `json:"previousAmount"`
+	Change         money.Amount `json:"change"`
+	ChangePercent  *float64     `json:"changePercent"`
+}
+
+type CardComparison struct {
+	CardID         uuid.UUID    `json:"cardId"`
+	CardName       string       `json:"cardName"`
+	Color          string       `json:"color"`
+	CurrentAmount  money.Amount `json:"currentAmount"`
+	PreviousAmount money.Amount `json:"previousAmount"`
+	Change         money.Amount `json:"change"`
+	ChangePercent  *float64     `json:"changePercent"`
+}
+
+// ForecastReport projects spending to the end of the month and the year of
+// AsOf, in total and per category.
+type ForecastReport struct {
+	AsOf       string             `json:"asOf"`
+	View       string             `json:"view"`
+	Currency   string             `json:"currency"`
+	Month      ForecastTotals     `json:"month"`
+	Year       ForecastTotals     `json:"year"`
+	ByCategory []CategoryForecast `json:"byCategory"`
+}
+
+// ForecastTotals splits the forecast for From through To into what has been
+// spent up to the forecast date (Actual), what is already recorded for later
+// dates such as installment payments (Scheduled), recurring charges still to
+// come (Recurring) and other spending expected from past daily patterns
+// (Projected). Forecast is their sum and Low to High its confidence range.
+type ForecastTotals struct {
+	From      string       `json:"from"`
+	To        string       `json:"to"`
+	Actual    money.Amount `json:"actual"`
+	Scheduled money.Amount `json:"scheduled"`
+	Recurring money.Amount `json:"recurring"`
+	Projected money.Amount `json:"projected"`
+	Forecast  money.Amount `json:"forecast"`
+	Low       money.Amount `json:"low"`
+	High      money.Amount `json:"high"`
+}
+
+type CategoryForecast struct {
+	CategoryID   uuid.UUID      `json:"categoryId"`
+	CategoryName string         `json:"categoryName"`
+	Color        string         `json:"color"`
+	Month        ForecastTotals `json:"month"`
+	Year         ForecastTotals `json:"year"`
+}
+
+// DailyReport has the spending of every day of a month or a year, for a
+// calendar heatmap, and its totals by day of the week and day of the month.
+type DailyReport struct {
+	Year         int                    `json:"year"`
+	Month        *int                   `json:"month,omitempty"`
+	From         string                 `json:"from"`
+	To           string                 `json:"to"`
+	View         string                 `json:"view"`
+	Currency     string                 `json:"currency"`
+	TotalAmount  money.Amount           `json:"totalAmount"`
+	// MaxAmount is the largest daily total, to scale the heatmap colors
+	MaxAmount    money.Amount           `json:"maxAmount"`
+	Days         []DailyExpenseSum      `json:"days"`
+	ByWeekday    []WeekdayExpenseSum    `json:"byWeekday"`
+	ByDayOfMonth []DayOfMonthExpenseSum `json:"byDayOfMonth"`
+}
+
+// DailyExpenseSum is the spending of one day. Days without expenses are
+// included with zero amounts.
+type DailyExpenseSum struct {
+	Date        string       `json:"date"`
+	// Weekday counts from 0 for Sunday
+	Weekday     int          `json:"weekday"`
+	TotalAmount money.Amount `json:"totalAmount"`
+	Count       int          `json:"count"`
+	// TopCategory is the category spent most on, if any
+	TopCategory *TopCategory `json:"topCategory,omitempty"`
+}
+
+type TopCategory struct {
+	CategoryID   uuid.UUID    `json:"categoryId"`
+	CategoryName string       `json:"categoryName"`
+	Color        string       `json:"color"`
+	TotalAmount  money.Amount `json:"totalAmount"`
+}
+
+// WeekdayExpenseSum adds up the days of the report falling on one day of the
+// week. AverageAmount is the total divided by the number of such Days.
+type WeekdayExpenseSum struct {
+	Weekday       int          `json:"weekday"`
+	Days          int          `json:"days"`
+	TotalAmount   money.Amount `json:"totalAmount"`
+	AverageAmount money.Amount `json:"averageAmount"`
+	Count         int          `json:"count"`
+}
+
+// DayOfMonthExpenseSum adds up the days of the report with the same day of
+// the month, such as every 25th.
+type DayOfMonthExpenseSum struct {
+	Day           int          `json:"day"`
+	Days          int          `json:"days"`
+	TotalAmount   money.Amount `json:"totalAmount"`
+	AverageAmount money.Amount `json:"averageAmount"`
+	Count         int          `json:"count"`
 }
 
 type CategoryExpenseSum struct {
@@ -26,13 +202,18 @@ type CategoryExpenseSum struct {
 	CategoryName string    `json:"categoryName"`
 	Color        string    `json:"color"`
 	IsShared     bool      `json:"isShared"`
-	TotalAmount  float64   `json:"totalAmount"`
+	TotalAmount  money.Amount `json:"totalAmount"`
+	// SharedAmount is the part of TotalAmount split between the members:
+	// the expenses of a shared category unless marked as not shared, and
+	// expenses of other categories marked as shared
+	SharedAmount money.Amount `json:"sharedAmount"`
 	Count        int       `json:"count"`
 }
 
 type SharedExpensesSummary struct {
-	TotalSharedAmount float64                 `json:"totalSharedAmount"`
-	SplitAmount       float64                 `json:"splitAmount"`
+	TotalSharedAmount money.Amount            `json:"totalSharedAmount"`
+	SplitAmount       money.Amount            `json:"splitAmount"`
+	Shares            []money.Amount          `json:"shares"`
 	Categories        []CategoryExpenseSum    `json:"categories"`
 }
 
@@ -40,19 +221,45 @@ type CardExpenseSum struct {
 	CardID      uuid.UUID `json:"cardId"`
 	CardName    string    `json:"cardName"`
 	Color       string    `json:"color"`
-	TotalAmount float64   `json:"totalAmount"`
+	TotalAmount money.Amount `json:"totalAmount"`
 	Count       int       `json:"count"`
 }
 
 type MonthlyExpenseSum struct {
 	Year        int     `json:"year"`
 	Month       int     `json:"month"`
-	TotalAmount float64 `json:"totalAmount"`
+	TotalAmount money.Amount `json:"totalAmount"`
 	Count       int     `json:"count"`
 }
 
+// PeriodExpenseSum is the spending of one period of a range report, from
+// Start through End.
+type PeriodExpenseSum struct {
+	Start       string       `json:"start"`
+	End         string       `json:"end"`
+	TotalAmount money.Amount `json:"totalAmount"`
+	Count       int          `json:"count"`
+}
+
 type ReportFilters struct {
-	Year   int        `json:"year"`
-	Month  *int       `json:"month,omitempty"`
-	CardID *uuid.UUID `json:"cardId,omitempty"`
+	Year      int        `json:"year"`
+	Month     *int       `json:"month,omitempty"`
+	StartDate *time.Time `json:"startDate,omitempty"`
+	EndDate   *time.Time `json:"endDate,omitempty"`
+	GroupBy   string     `json:"groupBy,omitempty"`
+	// CompareStartDate and CompareEndDate are the period a comparison
+	// report compares StartDate through EndDate with
+	CompareStartDate *time.Time `json:"compareStartDate,omitempty"`
+	CompareEndDate   *time.Time `json:"compareEndDate,omitempty"`
+	// AsOf is the day a forecast report is made on
+	AsOf *time.Time `json:"asOf,omitempty"`
+	CardID    *uuid.UUID `json:"cardId,omitempty"`
+	// CategoryID limits a daily report and a spending total to one
+	// category, including the items of split expenses in it
+	CategoryID *uuid.UUID `json:"categoryId,omitempty"`
+	View      string     `json:"view,omitempty"`
+	Currency  string     `json:"currency,omitempty"`
+	// RemainderMember receives the remainder when shared expenses do not
+	// split evenly between the members
+	RemainderMember int `json:"-"`
 }
\ No newline at end of file
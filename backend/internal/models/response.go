@@ -12,9 +12,21 @@ type ErrorResponse struct {
 	Path      string `json:"path"`
 }
 
+// FieldError is a field of a request that failed validation. The details of
+// VALIDATION_ERROR responses list them.
+type FieldError struct {
+	// Field is the JSON path of the field, such as items[0].amount
+	Field string `json:"field"`
+	// Rule and Param are the validate rule that failed, such as max and 100
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
 type SuccessResponse struct {
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
+	Message  string      `json:"message"`
+	Data     interface{} `json:"data,omitempty"`
+	Warnings interface{} `json:"warnings,omitempty"`
 }
 
 type PaginatedResponse struct {
@@ -52,6 +64,16 @@ func NewSuccessResponse(message string, data interface{}) *SuccessResponse {
 	}
 }
 
+// NewSuccessResponseWithWarnings adds warnings that did not stop the request,
+// such as an expense amount that looks unusual.
+func NewSuccessResponseWithWarnings(message string, data interface{}, warnings interface{}) *SuccessResponse {
+	return &SuccessResponse{
+		Message:  message,
+		Data:     data,
+		Warnings: warnings,
+	}
+}
+
 func NewPaginatedResponse(data interface{}, pagination Pagination) *PaginatedResponse {
 	return &PaginatedResponse{
 		Data:       data,
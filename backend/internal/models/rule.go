@@ -0,0 +1,96 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"kakeibo-tanuki/internal/money"
+)
+
+// Rule categorizes expenses automatically. An expense matches a rule when it
+// meets every condition the rule sets; the actions of matching rules are
+// applied in ascending order of priority.
+type Rule struct {
+	ID       uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Name     string    `json:"name" gorm:"not null" validate:"required,max=100"`
+	Priority int       `json:"priority" gorm:"not null;index"`
+	Enabled  bool      `json:"enabled" gorm:"not null"`
+	// DescriptionPattern is a regular expression matched case-insensitively
+	// against the normalized description, like payee patterns
+	DescriptionPattern string `json:"descriptionPattern"`
+	// MinAmount and MaxAmount bound the expense amount, inclusive, in the
+	// currency of the expense
+	MinAmount  *money.Amount `json:"minAmount"`
+	MaxAmount  *money.Amount `json:"maxAmount"`
+	CardID     *uuid.UUID    `json:"cardId" gorm:"type:uuid"`
+	PayeeID    *uuid.UUID    `json:"payeeId" gorm:"type:uuid"`
+	CategoryID *uuid.UUID    `json:"categoryId" gorm:"type:uuid"`
+	Tags       []string      `json:"tags" gorm:"type:text;serializer:json"`
+	IsShared   *bool         `json:"isShared"`
+	CreatedAt  time.Time     `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time     `json:"updatedAt" gorm:"autoUpdateTime"`
+}
+
+type CreateRuleRequest struct {
+	Name               string        `json:"name" validate:"required,max=100"`
+	Priority           int           `json:"priority"`
+	Enabled            *bool         `json:"enabled"`
+	DescriptionPattern string        `json:"descriptionPattern" validate:"max=200"`
+	MinAmount          *money.Amount `json:"minAmount" validate:"omitempty,gte=0"`
+	MaxAmount          *money.Amount `json:"maxAmount" validate:"omitempty,gte=0"`
+	CardID             string        `json:"cardId"`
+	PayeeID            string        `json:"payeeId"`
+	CategoryID         string        `json:"categoryId"`
+	Tags               []string      `json:"tags" validate:"omitempty,dive,required,max=50"`
+	IsShared           *bool         `json:"isShared"`
+}
+
+type UpdateRuleRequest struct {
+	Name               string        `json:"name" validate:"required,max=100"`
+	Priority           int           `json:"priority"`
+	Enabled            *bool         `json:"enabled"`
+	DescriptionPattern string        `json:"descriptionPattern" validate:"max=200"`
+	MinAmount          *money.Amount `json:"minAmount" validate:"omitempty,gte=0"`
+	MaxAmount          *money.Amount `json:"maxAmount" validate:"omitempty,gte=0"`
+	CardID             string        `json:"cardId"`
+	PayeeID            string        `json:"payeeId"`
+	CategoryID         string        `json:"categoryId"`
+	Tags               []string      `json:"tags" validate:"omitempty,dive,required,max=50"`
+	IsShared           *bool         `json:"isShared"`
+}
+
+// RuleRunRequest selects the rules and expenses of a dry run or a bulk
+// application. Empty fields select everything.
+type RuleRunRequest struct {
+	RuleIDs   []string `json:"ruleIds"`
+	StartDate string   `json:"startDate"`
+	EndDate   string   `json:"endDate"`
+}
+
+type RuleRunFilters struct {
+	StartDate *time.Time
+	EndDate   *time.Time
+}
+
+// RuleChange is what the rules change on one expense. CategoryID and
+// IsShared are only set when they change.
+type RuleChange struct {
+	ExpenseID          uuid.UUID    `json:"expenseId"`
+	Date               string       `json:"date"`
+	Description        string       `json:"description"`
+	Amount             money.Amount `json:"amount"`
+	Currency           string       `json:"currency"`
+	RuleIDs            []uuid.UUID  `json:"ruleIds"`
+	PreviousCategoryID uuid.UUID    `json:"previousCategoryId"`
+	CategoryID         *uuid.UUID   `json:"categoryId,omitempty"`
+	AddedTags          []string     `json:"addedTags,omitempty"`
+	IsShared           *bool        `json:"isShared,omitempty"`
+}
+
+type RuleRunResult struct {
+	DryRun  bool         `json:"dryRun"`
+	Checked int          `json:"checked"`
+	Changed int          `json:"changed"`
+	Changes []RuleChange `json:"changes"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"github.com/google/uuid"
+
+	"kakeibo-tanuki/internal/money"
+)
+
+// SuggestCategoryRequest describes an expense being entered. Only the
+// description is required; the amount and card refine the suggestions.
+type SuggestCategoryRequest struct {
+	Description string       `json:"description" validate:"required,max=200"`
+	Amount      money.Amount `json:"amount" validate:"gte=0"`
+	Currency    string       `json:"currency,omitempty" validate:"omitempty,len=3"`
+	CardID      string       `json:"cardId,omitempty"`
+	Limit       int          `json:"limit,omitempty" validate:"omitempty,min=1,max=10"`
+}
+
+// CategorySuggestion is a category an expense probably belongs to, with the
+// probability learned from past expenses.
+type CategorySuggestion struct {
+	CategoryID   uuid.UUID `json:"categoryId"`
+	CategoryName string    `json:"categoryName"`
+	Color        string    `json:"color"`
+	Confidence   float64   `json:"confidence"`
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Statuses of webhook deliveries.
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// Webhook receives the events it subscribes to as signed JSON posts. Events
+// are event types such as "expense.created", or "card.*" and "*" for all
+// events of a kind or all events.
+type Webhook struct {
+	ID  uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	URL string    `json:"url" gorm:"not null"`
+	// Secret signs the payloads and is never returned
+	Secret    string    `json:"-" gorm:"not null"`
+	Events    []string  `json:"events" gorm:"type:text;serializer:json"`
+	Enabled   bool      `json:"enabled" gorm:"not null"`
+	CreatedAt time.Time `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updatedAt" gorm:"autoUpdateTime"`
+}
+
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" validate:"required,url"`
+	Secret string   `json:"secret" validate:"required,min=16,max=200"`
+	Events []string `json:"events" validate:"required,min=1"`
+	// Enabled defaults to true
+	Enabled *bool `json:"enabled"`
+}
+
+// UpdateWebhookRequest keeps the secret of the webhook when none is given.
+type UpdateWebhookRequest struct {
+	URL     string   `json:"url" validate:"required,url"`
+	Secret  string   `json:"secret" validate:"omitempty,min=16,max=200"`
+	Events  []string `json:"events" validate:"required,min=1"`
+	Enabled *bool    `json:"enabled"`
+}
+
+// WebhookDelivery is an event queued for a webhook and the outcome of the
+// attempts to deliver it so far. Payload is the exact body that is posted.
+type WebhookDelivery struct {
+	ID            uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	WebhookID     uuid.UUID  `json:"webhookId" gorm:"type:uuid;not null;index"`
+	EventID       uuid.UUID  `json:"eventId" gorm:"type:uuid;not null"`
+	EventType     string     `json:"eventType" gorm:"not null"`
+	Payload       string     `json:"payload" gorm:"type:text;not null"`
+	Status        string     `json:"status" gorm:"type:varchar(10);not null;index"`
+	Attempts      int        `json:"attempts" gorm:"not null"`
+	NextAttemptAt *time.Time `json:"nextAttemptAt"`
+	LastAttemptAt *time.Time `json:"lastAttemptAt"`
+	// ResponseStatus is the HTTP status of the last attempt, 0 when the
+	// request failed before a response
+	ResponseStatus int       `json:"responseStatus"`
+	LastError      string    `json:"lastError,omitempty"`
+	CreatedAt      time.Time `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `json:"updatedAt" gorm:"autoUpdateTime"`
+}
+
+// WebhookDeliveryFilters selects the delivery log of a webhook, the newest
+// first.
+type WebhookDeliveryFilters struct {
+	WebhookID uuid.UUID
+	Status    string
+	Limit     int
+}
+
+// WebhookPayload is the body posted to webhooks. Data is the expense, card or
+// category as the API returns it.
+type WebhookPayload struct {
+	ID   uuid.UUID   `json:"id"`
+	Type string      `json:"type"`
+	Time time.Time   `json:"time"`
+	Data interface{} `json:"data"`
+}
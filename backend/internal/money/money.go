@@ -0,0 +1,189 @@
+// Package money provides an exact decimal amount type for monetary values.
+package money
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"kakeibo-tanuki/internal/currency"
+)
+
+// Scale is the number of decimal places an Amount keeps, matching the
+// DECIMAL(10,2) columns the amounts are stored in.
+const Scale = 2
+
+// hundred is one whole unit (1 yen, 1 dollar) in hundredths.
+const hundred = 100
+
+// Amount is an exact monetary amount stored as an integer number of
+// hundredths, so sums never drift the way float64 does. Amounts encode to
+// JSON as plain numbers (1500, 12.35) and are read from JSON numbers without
+// going through float64.
+//
+// An Amount carries no currency; Round and Split take the currency code to
+// apply its minor units (0 decimals for JPY, 2 for USD).
+type Amount int64
+
+// New returns an amount of whole units, e.g. New(1500) is 1,500 yen.
+func New(units int64) Amount {
+	return Amount(units * hundred)
+}
+
+// FromFloat converts f to the nearest hundredth, rounding half away from zero.
+func FromFloat(f float64) Amount {
+	return Amount(math.Round(f * hundred))
+}
+
+// Parse reads a decimal string such as "1500", "-12.5" or "1e3" exactly,
+// rounding half away from zero beyond two decimal places.
+func Parse(s string) (Amount, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok {
+		return 0, fmt.Errorf("invalid amount: %q", s)
+	}
+
+	// Scale to hundredths and round half away from zero
+	r.Mul(r, big.NewRat(hundred, 1))
+	num, den := r.Num(), r.Denom()
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Sign() != 0 && new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(den) >= 0 {
+		quo.Add(quo, big.NewInt(int64(num.Sign())))
+	}
+	if !quo.IsInt64() {
+		return 0, fmt.Errorf("amount out of range: %q", s)
+	}
+	return Amount(quo.Int64()), nil
+}
+
+// Float64 returns the amount as a float64, for display and rate math only.
+func (a Amount) Float64() float64 {
+	return float64(a) / hundred
+}
+
+// String formats the amount without trailing zeros: "1500", "12.5", "-0.05".
+func (a Amount) String() string {
+	sign := ""
+	v := int64(a)
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	whole, frac := v/hundred, v%hundred
+	if frac == 0 {
+		return sign + strconv.FormatInt(whole, 10)
+	}
+	return strings.TrimRight(fmt.Sprintf("%s%d.%02d", sign, whole, frac), "0")
+}
+
+// Mul multiplies the amount by f (an exchange or interest rate), rounding
+// half away from zero to hundredths.
+func (a Amount) Mul(f float64) Amount {
+	return Amount(math.Round(float64(a) * f))
+}
+
+// Unit returns the smallest amount of the currency: 1 for JPY, 0.01 for USD.
+func Unit(code string) Amount {
+	minorUnits := currency.MinorUnits(code)
+	if minorUnits >= Scale {
+		return 1
+	}
+	return Amount(math.Pow10(Scale - minorUnits))
+}
+
+// Round rounds the amount half away from zero to the minor units of the
+// currency.
+func (a Amount) Round(code string) Amount {
+	unit := Unit(code)
+	units, rem := a/unit, a%unit
+	if rem*2 >= unit {
+		units++
+	} else if rem*2 <= -unit {
+		units--
+	}
+	return units * unit
+}
+
+// Split divides the amount into n shares in the minor units of the currency.
+// Every share gets the same whole number of units and the remainder, which
+// is always smaller than n units, goes to the share at index remainderTo, so
+// the shares add up to the amount exactly.
+func (a Amount) Split(n int, code string, remainderTo int) []Amount {
+	if n <= 0 {
+		return nil
+	}
+	if remainderTo < 0 || remainderTo >= n {
+		remainderTo = 0
+	}
+
+	unit := Unit(code)
+	each := a / unit / Amount(n) * unit
+
+	shares := make([]Amount, n)
+	for i := range shares {
+		shares[i] = each
+	}
+	shares[remainderTo] += a - each*Amount(n)
+	return shares
+}
+
+// MarshalJSON encodes the amount as a JSON number.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON decodes a JSON number (or a numeric string) exactly.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	s = strings.Trim(s, `"`)
+	parsed, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+// Value stores the amount as an exact decimal string.
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+// Scan reads DECIMAL columns (returned as strings by PostgreSQL) as well as
+// the REAL and INTEGER values SQLite returns.
+func (a *Amount) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*a = 0
+	case int64:
+		*a = New(v)
+	case float64:
+		*a = FromFloat(v)
+	case []byte:
+		parsed, err := Parse(string(v))
+		if err != nil {
+			return err
+		}
+		*a = parsed
+	case string:
+		parsed, err := Parse(v)
+		if err != nil {
+			return err
+		}
+		*a = parsed
+	default:
+		return fmt.Errorf("cannot scan %T into money.Amount", src)
+	}
+	return nil
+}
+
+// GormDataType declares the column type used by AutoMigrate.
+func (Amount) GormDataType() string {
+	return "decimal(10,2)"
+}
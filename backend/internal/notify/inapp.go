@@ -0,0 +1,33 @@
+package notify
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"kakeibo-tanuki/internal/models"
+)
+
+// NotificationStore saves notifications shown in the app.
+type NotificationStore interface {
+	Create(ctx context.Context, notification *models.Notification) error
+}
+
+// InAppNotifier saves events as notifications listed by the app.
+type InAppNotifier struct {
+	store NotificationStore
+}
+
+func NewInAppNotifier(store NotificationStore) *InAppNotifier {
+	return &InAppNotifier{store: store}
+}
+
+func (n *InAppNotifier) Notify(alert *models.Alert, event *models.AlertEvent) error {
+	alertID := alert.ID
+	return n.store.Create(context.Background(), &models.Notification{
+		ID:      uuid.New(),
+		AlertID: &alertID,
+		Title:   Subject(event),
+		Message: event.Message,
+	})
+}
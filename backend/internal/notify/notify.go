@@ -0,0 +1,53 @@
+// Package notify delivers alert events through the channels an alert
+// chooses: email, webhooks and notifications in the app.
+package notify
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"kakeibo-tanuki/internal/models"
+)
+
+// Notifier delivers an event of an alert through one channel.
+type Notifier interface {
+	Notify(alert *models.Alert, event *models.AlertEvent) error
+}
+
+// Subject returns the title of the message announcing an event.
+func Subject(event *models.AlertEvent) string {
+	return fmt.Sprintf("[kakeibo] %s: %.0f%% of the budget spent", event.AlertName, event.Percent)
+}
+
+// SMTPConfigFromEnv reads the SMTP server from SMTP_HOST, SMTP_PORT
+// (default 587), SMTP_USERNAME, SMTP_PASSWORD and SMTP_FROM. It returns false
+// when SMTP_HOST is not set, in which case alerts cannot be sent by email.
+func SMTPConfigFromEnv() (SMTPConfig, bool, error) {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		return SMTPConfig{}, false, nil
+	}
+
+	port := 587
+	if value := os.Getenv("SMTP_PORT"); value != "" {
+		p, err := strconv.Atoi(value)
+		if err != nil {
+			return SMTPConfig{}, false, fmt.Errorf("invalid SMTP_PORT: %w", err)
+		}
+		port = p
+	}
+
+	from := os.Getenv("SMTP_FROM")
+	if from == "" {
+		return SMTPConfig{}, false, fmt.Errorf("SMTP_FROM is required with SMTP_HOST")
+	}
+
+	return SMTPConfig{
+		Host:     host,
+		Port:     port,
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     from,
+	}, true, nil
+}
@@ -0,0 +1,47 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"kakeibo-tanuki/internal/models"
+)
+
+// WebhookTimeout bounds how long a webhook may take to answer.
+const WebhookTimeout = 10 * time.Second
+
+// WebhookNotifier posts events as JSON to the webhook URL of the alert.
+type WebhookNotifier struct {
+	client *http.Client
+}
+
+func NewWebhookNotifier() *WebhookNotifier {
+	return &WebhookNotifier{client: &http.Client{Timeout: WebhookTimeout}}
+}
+
+func (n *WebhookNotifier) Notify(alert *models.Alert, event *models.AlertEvent) error {
+	if alert.WebhookURL == "" {
+		return fmt.Errorf("alert has no webhook URL")
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	resp, err := n.client.Post(alert.WebhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to call webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
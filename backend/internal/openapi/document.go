@@ -0,0 +1,309 @@
+// Package openapi describes the API as an OpenAPI 3 document. The routes are
+// listed in Endpoints next to the models they accept and return, and the
+// schemas of the models are generated from their json and validate tags, so
+// that the document follows the code it describes.
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"kakeibo-tanuki/internal/models"
+)
+
+// Version is the OpenAPI version of the document.
+const Version = "3.0.3"
+
+// Document is an OpenAPI document. ErrorCodes lists every code the API
+// responds with and what it means.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Tags       []Tag               `json:"tags,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+	ErrorCodes map[string]string   `json:"x-error-codes,omitempty"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Tag struct {
+	Name string `json:"name"`
+}
+
+// PathItem maps the lower-case HTTP methods of a path to their operations.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	Tags        []string             `json:"tags,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	OperationID string               `json:"operationId,omitempty"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Description string               `json:"description,omitempty"`
+	Required    bool                 `json:"required,omitempty"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// Build returns the document describing Endpoints.
+func Build() *Document {
+	g := newGenerator()
+	for _, envelope := range []interface{}{models.SuccessResponse{}, models.PaginatedResponse{}, models.ErrorResponse{}, models.FieldError{}} {
+		g.schema(reflect.TypeOf(envelope))
+	}
+
+	// The code of an error is one of the catalog
+	codes := make([]string, 0, len(ErrorCodes))
+	for code := range ErrorCodes {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	g.schemas["ErrorResponse"].Properties["error"].Properties["code"].Enum = codes
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:       "Kakeibo API",
+			Description: "Household account book of credit card expenses. Successful responses wrap their data in a SuccessResponse or PaginatedResponse and errors are an ErrorResponse whose code is listed in x-error-codes.",
+			Version:     "1.0.0",
+		},
+		Paths:      map[string]PathItem{},
+		ErrorCodes: ErrorCodes,
+	}
+
+	seenTags := map[string]bool{}
+	for _, endpoint := range Endpoints {
+		path := Path(endpoint.Path)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = PathItem{}
+		}
+		doc.Paths[path][strings.ToLower(endpoint.Method)] = g.operation(endpoint)
+
+		if !seenTags[endpoint.Tag] {
+			seenTags[endpoint.Tag] = true
+			doc.Tags = append(doc.Tags, Tag{Name: endpoint.Tag})
+		}
+	}
+
+	doc.Components.Schemas = g.schemas
+	return doc
+}
+
+// Path converts the parameters of a gin path such as /api/cards/:id to the
+// OpenAPI form /api/cards/{id}.
+func Path(ginPath string) string {
+	segments := strings.Split(ginPath, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func (g *generator) operation(endpoint Endpoint) *Operation {
+	op := &Operation{
+		Tags:        []string{endpoint.Tag},
+		Summary:     endpoint.Summary,
+		OperationID: endpoint.ID,
+		Responses:   map[string]*Response{},
+	}
+
+	// Path parameters are IDs
+	for _, segment := range strings.Split(endpoint.Path, "/") {
+		if strings.HasPrefix(segment, ":") {
+			op.Parameters = append(op.Parameters, Parameter{
+				Name:     segment[1:],
+				In:       "path",
+				Required: true,
+				Schema:   &Schema{Type: "string", Format: "uuid"},
+			})
+		}
+	}
+	for _, param := range endpoint.Query {
+		op.Parameters = append(op.Parameters, Parameter{
+			Name:        param.Name,
+			In:          "query",
+			Description: param.Description,
+			Required:    param.Required,
+			Schema:      param.schema(),
+		})
+	}
+
+	switch {
+	case endpoint.Body != nil:
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content: map[string]MediaType{
+				"application/json": {Schema: g.schema(reflect.TypeOf(endpoint.Body))},
+			},
+		}
+	case endpoint.Upload != "":
+		upload := &Schema{
+			Type:       "object",
+			Properties: map[string]*Schema{endpoint.Upload: {Type: "string", Format: "binary"}},
+			Required:   []string{endpoint.Upload},
+		}
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{"multipart/form-data": {Schema: upload}},
+		}
+		if endpoint.UploadType != "" {
+			op.RequestBody.Description = "The file can also be sent as the raw request body."
+			op.RequestBody.Content[endpoint.UploadType] = MediaType{Schema: &Schema{Type: "string"}}
+		}
+	}
+
+	status := endpoint.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	op.Responses[strconv.Itoa(status)] = g.success(endpoint, status)
+
+	for status, codes := range endpoint.Errors {
+		op.Responses[strconv.Itoa(status)] = failure(codes)
+	}
+	return op
+}
+
+// success describes the response of an endpoint that succeeded.
+func (g *generator) success(endpoint Endpoint, status int) *Response {
+	response := &Response{Description: http.StatusText(status)}
+
+	var data *Schema
+	if endpoint.Data != nil {
+		data = g.schema(reflect.TypeOf(endpoint.Data))
+	}
+
+	if endpoint.ContentType != "" {
+		if data == nil {
+			data = &Schema{Type: "string", Format: "binary"}
+		}
+		response.Content = map[string]MediaType{endpoint.ContentType: {Schema: data}}
+		return response
+	}
+
+	var body *Schema
+	switch {
+	case endpoint.Raw:
+		body = data
+		if body == nil {
+			body = &Schema{Type: "object"}
+		}
+	case endpoint.Paginated:
+		body = envelope("PaginatedResponse", data)
+	default:
+		body = envelope("SuccessResponse", data)
+	}
+	response.Content = map[string]MediaType{"application/json": {Schema: body}}
+	return response
+}
+
+// failure describes an ErrorResponse with one of codes.
+func failure(codes []string) *Response {
+	sorted := append([]string(nil), codes...)
+	sort.Strings(sorted)
+
+	code := &Schema{Type: "object", Properties: map[string]*Schema{
+		"code": {Type: "string", Enum: sorted},
+	}}
+	return &Response{
+		Description: strings.Join(sorted, ", "),
+		Content: map[string]MediaType{"application/json": {Schema: &Schema{AllOf: []*Schema{
+			ref("ErrorResponse"),
+			{Type: "object", Properties: map[string]*Schema{"error": code}},
+		}}}},
+	}
+}
+
+// envelope narrows the data of a response envelope to a schema.
+func envelope(name string, data *Schema) *Schema {
+	if data == nil {
+		return ref(name)
+	}
+	return &Schema{AllOf: []*Schema{
+		ref(name),
+		{Type: "object", Properties: map[string]*Schema{"data": data}, Required: []string{"data"}},
+	}}
+}
+
+// Param is a query parameter of an endpoint. Type is a JSON schema type,
+// string by default, and Format one of its formats such as date or uuid.
+type Param struct {
+	Name        string
+	Description string
+	Type        string
+	Format      string
+	Enum        []string
+	Required    bool
+}
+
+func (p Param) schema() *Schema {
+	schema := &Schema{Type: p.Type, Format: p.Format, Enum: p.Enum}
+	if schema.Type == "" {
+		schema.Type = "string"
+	}
+	return schema
+}
+
+// Endpoint is a route of the API. Body is a value of the model of the JSON
+// request body, and Upload the form field of a multipart file upload, which
+// endpoints that also accept the raw file name the content type of in
+// UploadType. Data is a value of the model of the data of the response, which
+// is wrapped in a SuccessResponse, a PaginatedResponse when Paginated is set,
+// or sent as is when Raw is set or ContentType is not JSON. Errors maps
+// statuses to the codes the endpoint responds with.
+type Endpoint struct {
+	Method      string
+	Path        string
+	ID          string
+	Tag         string
+	Summary     string
+	Query       []Param
+	Body        interface{}
+	Upload      string
+	UploadType  string
+	Status      int
+	Data        interface{}
+	Paginated   bool
+	Raw         bool
+	ContentType string
+	Errors      map[int][]string
+}
+
+func (e Endpoint) String() string {
+	return fmt.Sprintf("%s %s", e.Method, e.Path)
+}
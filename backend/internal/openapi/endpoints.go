@@ -0,0 +1,782 @@
+package openapi
+
+import (
+	"net/http"
+
+	"kakeibo-tanuki/internal/models"
+)
+
+// HealthStatus is the response of the health check.
+type HealthStatus struct {
+	Status  string `json:"status" validate:"required,oneof=ok error"`
+	Message string `json:"message" validate:"required"`
+	Error   string `json:"error,omitempty"`
+}
+
+var (
+	cardParam  = Param{Name: "cardId", Format: "uuid", Description: "Only expenses paid with this card"}
+	viewParam  = Param{Name: "view", Description: "Count expenses on the purchase date or spread over their installment payments", Enum: []string{models.ReportViewPurchase, models.ReportViewPayment}}
+	yearParam  = Param{Name: "year", Type: "integer", Description: "Defaults to the current year"}
+	monthParam = Param{Name: "month", Type: "integer", Description: "1 to 12, the whole year without it"}
+)
+
+func dateParam(name, description string) Param {
+	return Param{Name: name, Format: "date", Description: description}
+}
+
+// Errors shared by most endpoints.
+var (
+	bodyErrors   = []string{"INVALID_REQUEST", "VALIDATION_ERROR"}
+	reportErrors = map[int][]string{
+		http.StatusBadRequest:          {"INVALID_CARD_ID", "INVALID_VIEW"},
+		http.StatusUnprocessableEntity: {"EXCHANGE_RATE_NOT_FOUND"},
+		http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		http.StatusServiceUnavailable:  {"REQUEST_CANCELED"},
+		http.StatusGatewayTimeout:      {"REQUEST_TIMEOUT"},
+	}
+)
+
+// withErrors returns errors with codes added for status.
+func withErrors(errors map[int][]string, status int, codes ...string) map[int][]string {
+	merged := make(map[int][]string, len(errors)+1)
+	for s, c := range errors {
+		merged[s] = c
+	}
+	merged[status] = append(append([]string(nil), merged[status]...), codes...)
+	return merged
+}
+
+// Endpoints lists every route of the API. A route registered without an
+// endpoint here fails the tests.
+var Endpoints = []Endpoint{
+	{
+		Method: http.MethodGet, Path: "/health", ID: "getHealth", Tag: "health",
+		Summary: "Check that the API and its database are up",
+		Data:    HealthStatus{}, Raw: true,
+	},
+	{
+		Method: http.MethodGet, Path: "/api/openapi.json", ID: "getOpenAPI", Tag: "health",
+		Summary: "This document",
+		Raw:     true,
+	},
+
+	// Cards
+	{
+		Method: http.MethodGet, Path: "/api/cards", ID: "getCards", Tag: "cards",
+		Summary: "List cards",
+		Data:    []models.Card{},
+		Errors:  map[int][]string{http.StatusInternalServerError: {"INTERNAL_ERROR"}},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/cards", ID: "createCard", Tag: "cards",
+		Summary: "Create a card",
+		Body:    models.CreateCardRequest{}, Status: http.StatusCreated, Data: models.Card{},
+		Errors: map[int][]string{
+			http.StatusBadRequest:          bodyErrors,
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/cards/:id", ID: "getCard", Tag: "cards",
+		Summary: "Get a card",
+		Data:    models.Card{},
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_UUID"},
+			http.StatusNotFound:            {"CARD_NOT_FOUND"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodPut, Path: "/api/cards/:id", ID: "updateCard", Tag: "cards",
+		Summary: "Update a card",
+		Body:    models.UpdateCardRequest{}, Data: models.Card{},
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_UUID", "INVALID_REQUEST", "VALIDATION_ERROR"},
+			http.StatusNotFound:            {"CARD_NOT_FOUND"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodDelete, Path: "/api/cards/:id", ID: "deleteCard", Tag: "cards",
+		Summary: "Delete a card without expenses",
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_UUID"},
+			http.StatusNotFound:            {"CARD_NOT_FOUND"},
+			http.StatusConflict:            {"CARD_HAS_EXPENSES"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+
+	// Categories
+	{
+		Method: http.MethodGet, Path: "/api/categories", ID: "getCategories", Tag: "categories",
+		Summary: "List categories",
+		Data:    []models.Category{},
+		Errors:  map[int][]string{http.StatusInternalServerError: {"INTERNAL_ERROR"}},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/categories", ID: "createCategory", Tag: "categories",
+		Summary: "Create a category",
+		Body:    models.CreateCategoryRequest{}, Status: http.StatusCreated, Data: models.Category{},
+		Errors: map[int][]string{
+			http.StatusBadRequest:          bodyErrors,
+			http.StatusConflict:            {"DUPLICATE_CATEGORY"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/categories/:id", ID: "getCategory", Tag: "categories",
+		Summary: "Get a category",
+		Data:    models.Category{},
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_UUID"},
+			http.StatusNotFound:            {"CATEGORY_NOT_FOUND"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodPut, Path: "/api/categories/:id", ID: "updateCategory", Tag: "categories",
+		Summary: "Update a category",
+		Body:    models.UpdateCategoryRequest{}, Data: models.Category{},
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_UUID", "INVALID_REQUEST", "VALIDATION_ERROR"},
+			http.StatusNotFound:            {"CATEGORY_NOT_FOUND"},
+			http.StatusConflict:            {"DUPLICATE_CATEGORY"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodDelete, Path: "/api/categories/:id", ID: "deleteCategory", Tag: "categories",
+		Summary: "Delete a category without expenses",
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_UUID"},
+			http.StatusNotFound:            {"CATEGORY_NOT_FOUND"},
+			http.StatusConflict:            {"CATEGORY_HAS_EXPENSES"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+
+	// Payees
+	{
+		Method: http.MethodGet, Path: "/api/payees", ID: "getPayees", Tag: "payees",
+		Summary: "List payees",
+		Data:    []models.Payee{},
+		Errors:  map[int][]string{http.StatusInternalServerError: {"INTERNAL_ERROR"}},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/payees", ID: "createPayee", Tag: "payees",
+		Summary: "Create a payee and link the expenses without a payee that match it",
+		Body:    models.CreatePayeeRequest{}, Status: http.StatusCreated, Data: models.PayeeResult{},
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_REQUEST", "VALIDATION_ERROR", "INVALID_PATTERN"},
+			http.StatusConflict:            {"DUPLICATE_PAYEE"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/payees/:id", ID: "getPayee", Tag: "payees",
+		Summary: "Get a payee",
+		Data:    models.Payee{},
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_UUID"},
+			http.StatusNotFound:            {"PAYEE_NOT_FOUND"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodPut, Path: "/api/payees/:id", ID: "updatePayee", Tag: "payees",
+		Summary: "Update a payee and link the expenses without a payee that match it",
+		Body:    models.UpdatePayeeRequest{}, Data: models.PayeeResult{},
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_UUID", "INVALID_REQUEST", "VALIDATION_ERROR", "INVALID_PATTERN"},
+			http.StatusNotFound:            {"PAYEE_NOT_FOUND"},
+			http.StatusConflict:            {"DUPLICATE_PAYEE"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodDelete, Path: "/api/payees/:id", ID: "deletePayee", Tag: "payees",
+		Summary: "Delete a payee, unlinking its expenses",
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_UUID"},
+			http.StatusNotFound:            {"PAYEE_NOT_FOUND"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+
+	// Rules
+	{
+		Method: http.MethodGet, Path: "/api/rules", ID: "getRules", Tag: "rules",
+		Summary: "List rules in order of priority",
+		Data:    []models.Rule{},
+		Errors:  map[int][]string{http.StatusInternalServerError: {"INTERNAL_ERROR"}},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/rules", ID: "createRule", Tag: "rules",
+		Summary: "Create a rule",
+		Body:    models.CreateRuleRequest{}, Status: http.StatusCreated, Data: models.Rule{},
+		Errors: map[int][]string{
+			http.StatusBadRequest: {
+				"INVALID_REQUEST", "VALIDATION_ERROR", "INVALID_PATTERN", "INVALID_AMOUNT_RANGE",
+				"INVALID_CARD_ID", "INVALID_PAYEE_ID", "INVALID_CATEGORY_ID",
+				"CARD_NOT_FOUND", "PAYEE_NOT_FOUND", "CATEGORY_NOT_FOUND",
+			},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/rules/dry-run", ID: "dryRunRules", Tag: "rules",
+		Summary: "Show what applying rules would change without saving it",
+		Body:    models.RuleRunRequest{}, Data: models.RuleRunResult{},
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_REQUEST", "INVALID_UUID", "INVALID_DATE", "INVALID_DATE_RANGE"},
+			http.StatusNotFound:            {"RULE_NOT_FOUND"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/rules/apply", ID: "applyRules", Tag: "rules",
+		Summary: "Apply rules to existing expenses",
+		Body:    models.RuleRunRequest{}, Data: models.RuleRunResult{},
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_REQUEST", "INVALID_UUID", "INVALID_DATE", "INVALID_DATE_RANGE"},
+			http.StatusNotFound:            {"RULE_NOT_FOUND"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/rules/:id", ID: "getRule", Tag: "rules",
+		Summary: "Get a rule",
+		Data:    models.Rule{},
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_UUID"},
+			http.StatusNotFound:            {"RULE_NOT_FOUND"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodPut, Path: "/api/rules/:id", ID: "updateRule", Tag: "rules",
+		Summary: "Update a rule",
+		Body:    models.UpdateRuleRequest{}, Data: models.Rule{},
+		Errors: map[int][]string{
+			http.StatusBadRequest: {
+				"INVALID_UUID", "INVALID_REQUEST", "VALIDATION_ERROR", "INVALID_PATTERN", "INVALID_AMOUNT_RANGE",
+				"INVALID_CARD_ID", "INVALID_PAYEE_ID", "INVALID_CATEGORY_ID",
+				"CARD_NOT_FOUND", "PAYEE_NOT_FOUND", "CATEGORY_NOT_FOUND",
+			},
+			http.StatusNotFound:            {"RULE_NOT_FOUND"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodDelete, Path: "/api/rules/:id", ID: "deleteRule", Tag: "rules",
+		Summary: "Delete a rule",
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_UUID"},
+			http.StatusNotFound:            {"RULE_NOT_FOUND"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+
+	// Expenses
+	{
+		Method: http.MethodGet, Path: "/api/expenses", ID: "getExpenses", Tag: "expenses",
+		Summary: "List expenses, newest first",
+		Query: []Param{
+			{Name: "page", Type: "integer", Description: "Defaults to 1"},
+			{Name: "limit", Type: "integer", Description: "Expenses per page, 20 by default"},
+			dateParam("startDate", "Only expenses on or after this date"),
+			dateParam("endDate", "Only expenses on or before this date"),
+			cardParam,
+			{Name: "categoryId", Format: "uuid", Description: "Only expenses of this category"},
+			{Name: "payeeId", Format: "uuid", Description: "Only expenses paid to this payee"},
+		},
+		Data: []models.Expense{}, Paginated: true,
+		Errors: map[int][]string{http.StatusInternalServerError: {"INTERNAL_ERROR"}},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/expenses", ID: "createExpense", Tag: "expenses",
+		Summary: "Create an expense, optionally reporting unusual amounts as warnings",
+		Query:   []Param{{Name: "checkAnomalies", Type: "boolean", Description: "Report anomalies of the expense as warnings"}},
+		Body:    models.CreateExpenseRequest{}, Status: http.StatusCreated, Data: models.Expense{},
+		Errors: map[int][]string{
+			http.StatusBadRequest: {
+				"INVALID_REQUEST", "VALIDATION_ERROR", "INVALID_DATE", "FUTURE_DATE", "INVALID_CURRENCY", "INVALID_AMOUNT",
+				"INVALID_CARD_ID", "INVALID_CATEGORY_ID", "INVALID_PAYEE_ID", "ITEMS_AMOUNT_MISMATCH",
+			},
+			http.StatusUnprocessableEntity: {"CARD_NOT_FOUND", "CATEGORY_NOT_FOUND", "PAYEE_NOT_FOUND", "REFERENCE_NOT_FOUND"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/expenses/suggest-category", ID: "suggestCategory", Tag: "expenses",
+		Summary: "Suggest categories for an expense being entered",
+		Body:    models.SuggestCategoryRequest{}, Data: []models.CategorySuggestion{},
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_REQUEST", "VALIDATION_ERROR", "INVALID_CARD_ID", "INVALID_CURRENCY"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/expenses/:id", ID: "getExpense", Tag: "expenses",
+		Summary: "Get an expense",
+		Data:    models.Expense{},
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_UUID"},
+			http.StatusNotFound:            {"EXPENSE_NOT_FOUND"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodPut, Path: "/api/expenses/:id", ID: "updateExpense", Tag: "expenses",
+		Summary: "Update an expense, rescheduling its installments",
+		Body:    models.UpdateExpenseRequest{}, Data: models.Expense{},
+		Errors: map[int][]string{
+			http.StatusBadRequest: {
+				"INVALID_UUID", "INVALID_REQUEST", "VALIDATION_ERROR", "INVALID_DATE", "FUTURE_DATE", "INVALID_CURRENCY", "INVALID_AMOUNT",
+				"INVALID_CARD_ID", "INVALID_CATEGORY_ID", "INVALID_PAYEE_ID", "ITEMS_AMOUNT_MISMATCH",
+				"REFUND_EXCEEDS_AMOUNT", "INVALID_INSTALLMENT_PLAN",
+			},
+			http.StatusNotFound:            {"EXPENSE_NOT_FOUND"},
+			http.StatusUnprocessableEntity: {"CARD_NOT_FOUND", "CATEGORY_NOT_FOUND", "PAYEE_NOT_FOUND", "REFERENCE_NOT_FOUND"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodDelete, Path: "/api/expenses/:id", ID: "deleteExpense", Tag: "expenses",
+		Summary: "Delete an expense with its attachments, installments and refunds",
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_UUID"},
+			http.StatusNotFound:            {"EXPENSE_NOT_FOUND"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+
+	// Attachments
+	{
+		Method: http.MethodGet, Path: "/api/expenses/:id/attachments", ID: "getAttachments", Tag: "attachments",
+		Summary: "List the receipts attached to an expense",
+		Data:    []models.Attachment{},
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_UUID"},
+			http.StatusNotFound:            {"EXPENSE_NOT_FOUND"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/expenses/:id/attachments", ID: "uploadAttachment", Tag: "attachments",
+		Summary: "Attach a receipt image or PDF to an expense",
+		Upload:  "file", Status: http.StatusCreated, Data: models.Attachment{},
+		Errors: map[int][]string{
+			http.StatusBadRequest:            {"INVALID_UUID", "INVALID_REQUEST"},
+			http.StatusNotFound:              {"EXPENSE_NOT_FOUND"},
+			http.StatusRequestEntityTooLarge: {"FILE_TOO_LARGE"},
+			http.StatusUnsupportedMediaType:  {"UNSUPPORTED_FILE_TYPE"},
+			http.StatusInternalServerError:   {"INTERNAL_ERROR", "STORAGE_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/expenses/:id/attachments/:attachmentId", ID: "downloadAttachment", Tag: "attachments",
+		Summary:     "Download an attachment",
+		ContentType: "application/octet-stream",
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_UUID"},
+			http.StatusNotFound:            {"EXPENSE_NOT_FOUND", "ATTACHMENT_NOT_FOUND"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR", "STORAGE_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/expenses/:id/attachments/:attachmentId/thumbnail", ID: "downloadThumbnail", Tag: "attachments",
+		Summary:     "Download the JPEG thumbnail of an image attachment",
+		ContentType: "image/jpeg",
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_UUID"},
+			http.StatusNotFound:            {"EXPENSE_NOT_FOUND", "ATTACHMENT_NOT_FOUND", "THUMBNAIL_NOT_FOUND"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR", "STORAGE_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodDelete, Path: "/api/expenses/:id/attachments/:attachmentId", ID: "deleteAttachment", Tag: "attachments",
+		Summary: "Delete an attachment",
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_UUID"},
+			http.StatusNotFound:            {"EXPENSE_NOT_FOUND", "ATTACHMENT_NOT_FOUND"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+
+	// Installments
+	{
+		Method: http.MethodGet, Path: "/api/expenses/:id/installment", ID: "getInstallmentPlan", Tag: "installments",
+		Summary: "Get the installment plan of an expense",
+		Data:    models.InstallmentPlan{},
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_UUID"},
+			http.StatusNotFound:            {"EXPENSE_NOT_FOUND", "INSTALLMENT_PLAN_NOT_FOUND"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodPut, Path: "/api/expenses/:id/installment", ID: "setInstallmentPlan", Tag: "installments",
+		Summary: "Pay an expense in installments, replacing its plan",
+		Body:    models.InstallmentPlanRequest{}, Data: models.InstallmentPlan{},
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_UUID", "INVALID_REQUEST", "VALIDATION_ERROR", "INVALID_DATE", "INVALID_INSTALLMENT_PLAN"},
+			http.StatusNotFound:            {"EXPENSE_NOT_FOUND"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodDelete, Path: "/api/expenses/:id/installment", ID: "deleteInstallmentPlan", Tag: "installments",
+		Summary: "Pay an expense at once again",
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_UUID"},
+			http.StatusNotFound:            {"EXPENSE_NOT_FOUND"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/installments/balances", ID: "getInstallmentBalances", Tag: "installments",
+		Summary: "Get the installments left to pay on each card",
+		Query: []Param{
+			dateParam("asOf", "Defaults to today"),
+			cardParam,
+		},
+		Data: []models.CardInstallmentBalance{},
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_DATE", "INVALID_CARD_ID"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+
+	// Refunds
+	{
+		Method: http.MethodGet, Path: "/api/expenses/:id/refunds", ID: "getRefunds", Tag: "refunds",
+		Summary: "List the refunds of an expense",
+		Data:    []models.Refund{},
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_UUID"},
+			http.StatusNotFound:            {"EXPENSE_NOT_FOUND"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/expenses/:id/refunds", ID: "createRefund", Tag: "refunds",
+		Summary: "Refund part or all of an expense",
+		Body:    models.CreateRefundRequest{}, Status: http.StatusCreated, Data: models.Refund{},
+		Errors: map[int][]string{
+			http.StatusBadRequest: {
+				"INVALID_UUID", "INVALID_REQUEST", "VALIDATION_ERROR", "INVALID_DATE", "FUTURE_DATE",
+				"INVALID_REFUND_DATE", "INVALID_AMOUNT", "REFUND_EXCEEDS_AMOUNT",
+			},
+			http.StatusNotFound:            {"EXPENSE_NOT_FOUND"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodDelete, Path: "/api/expenses/:id/refunds/:refundId", ID: "deleteRefund", Tag: "refunds",
+		Summary: "Delete a refund",
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_UUID"},
+			http.StatusNotFound:            {"EXPENSE_NOT_FOUND", "REFUND_NOT_FOUND"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+
+	// Book
+	{
+		Method: http.MethodGet, Path: "/api/book", ID: "getBook", Tag: "book",
+		Summary: "Get the settings of the book",
+		Data:    models.Book{},
+		Errors:  map[int][]string{http.StatusInternalServerError: {"INTERNAL_ERROR"}},
+	},
+	{
+		Method: http.MethodPut, Path: "/api/book", ID: "updateBook", Tag: "book",
+		Summary: "Update the settings of the book",
+		Body:    models.UpdateBookRequest{}, Data: models.Book{},
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_REQUEST", "VALIDATION_ERROR", "INVALID_CURRENCY"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+
+	// Exchange rates
+	{
+		Method: http.MethodGet, Path: "/api/exchange-rates", ID: "getExchangeRates", Tag: "exchange-rates",
+		Summary: "List exchange rates",
+		Query: []Param{
+			{Name: "currency", Description: "Only rates of this currency"},
+			{Name: "baseCurrency", Description: "Only rates quoted in this currency"},
+			dateParam("startDate", "Only rates on or after this date"),
+			dateParam("endDate", "Only rates on or before this date"),
+		},
+		Data: []models.ExchangeRate{},
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_CURRENCY"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/exchange-rates", ID: "createExchangeRate", Tag: "exchange-rates",
+		Summary: "Save the exchange rate of a currency on a date",
+		Body:    models.CreateExchangeRateRequest{}, Status: http.StatusCreated, Data: models.ExchangeRate{},
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_REQUEST", "VALIDATION_ERROR", "INVALID_CURRENCY", "INVALID_DATE"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/exchange-rates/import", ID: "importExchangeRates", Tag: "exchange-rates",
+		Summary: "Import exchange rates from a CSV file of date, currency, rate and optional base columns",
+		Upload:  "file", UploadType: "text/csv", Data: models.ExchangeRateImportResult{},
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_REQUEST", "INVALID_CSV", "INVALID_CURRENCY"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodDelete, Path: "/api/exchange-rates/:id", ID: "deleteExchangeRate", Tag: "exchange-rates",
+		Summary: "Delete an exchange rate",
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_UUID"},
+			http.StatusNotFound:            {"EXCHANGE_RATE_NOT_FOUND"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+
+	// Reports
+	{
+		Method: http.MethodGet, Path: "/api/reports/monthly", ID: "getMonthlyReport", Tag: "reports",
+		Summary: "Spending of a month by category and card",
+		Query:   []Param{yearParam, {Name: "month", Type: "integer", Description: "1 to 12", Required: true}, cardParam, viewParam},
+		Data:    models.MonthlyReport{},
+		Errors:  withErrors(reportErrors, http.StatusBadRequest, "INVALID_YEAR", "INVALID_MONTH", "MISSING_MONTH"),
+	},
+	{
+		Method: http.MethodGet, Path: "/api/reports/yearly", ID: "getYearlyReport", Tag: "reports",
+		Summary: "Spending of a year by month, category and card",
+		Query:   []Param{yearParam, cardParam, viewParam},
+		Data:    models.YearlyReport{},
+		Errors:  withErrors(reportErrors, http.StatusBadRequest, "INVALID_YEAR"),
+	},
+	{
+		Method: http.MethodGet, Path: "/api/reports/range", ID: "getRangeReport", Tag: "reports",
+		Summary: "Spending of a date range grouped into periods",
+		Query: []Param{
+			{Name: "from", Format: "date", Required: true},
+			{Name: "to", Format: "date", Required: true},
+			{Name: "groupBy", Description: "Defaults to month", Enum: []string{models.ReportGroupByDay, models.ReportGroupByWeek, models.ReportGroupByMonth, models.ReportGroupByQuarter}},
+			cardParam,
+			viewParam,
+		},
+		Data:   models.RangeReport{},
+		Errors: withErrors(reportErrors, http.StatusBadRequest, "INVALID_DATE", "MISSING_DATE_RANGE", "INVALID_DATE_RANGE", "INVALID_GROUP_BY"),
+	},
+	{
+		Method: http.MethodGet, Path: "/api/reports/compare", ID: "getComparisonReport", Tag: "reports",
+		Summary: "Compare the spending of a period with another period",
+		Query: []Param{
+			dateParam("from", "Start of the period, instead of year and month"),
+			dateParam("to", "End of the period, instead of year and month"),
+			yearParam,
+			monthParam,
+			dateParam("compareFrom", "Start of the period to compare with"),
+			dateParam("compareTo", "End of the period to compare with"),
+			{Name: "against", Description: "Period to compare with unless compareFrom and compareTo are given, previous by default", Enum: []string{models.CompareAgainstPrevious, models.CompareAgainstLastYear}},
+			cardParam,
+			viewParam,
+		},
+		Data:   models.ComparisonReport{},
+		Errors: withErrors(reportErrors, http.StatusBadRequest, "INVALID_DATE", "MISSING_DATE_RANGE", "INVALID_DATE_RANGE", "INVALID_YEAR", "INVALID_MONTH", "INVALID_COMPARISON"),
+	},
+	{
+		Method: http.MethodGet, Path: "/api/reports/forecast", ID: "getForecastReport", Tag: "reports",
+		Summary: "Forecast the spending of the month from the spending so far",
+		Query:   []Param{dateParam("date", "Day the month is forecast from, today by default"), cardParam, viewParam},
+		Data:    models.ForecastReport{},
+		Errors:  withErrors(reportErrors, http.StatusBadRequest, "INVALID_DATE"),
+	},
+	{
+		Method: http.MethodGet, Path: "/api/reports/daily", ID: "getDailyReport", Tag: "reports",
+		Summary: "Spending by day, weekday and day of the month",
+		Query: []Param{
+			yearParam,
+			monthParam,
+			cardParam,
+			{Name: "categoryId", Format: "uuid", Description: "Only expenses of this category"},
+			viewParam,
+		},
+		Data:   models.DailyReport{},
+		Errors: withErrors(reportErrors, http.StatusBadRequest, "INVALID_YEAR", "INVALID_MONTH", "INVALID_CATEGORY_ID"),
+	},
+
+	// Alerts
+	{
+		Method: http.MethodGet, Path: "/api/alerts", ID: "getAlerts", Tag: "alerts",
+		Summary: "List budget alerts",
+		Data:    []models.Alert{},
+		Errors:  map[int][]string{http.StatusInternalServerError: {"INTERNAL_ERROR"}},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/alerts", ID: "createAlert", Tag: "alerts",
+		Summary: "Create a budget alert",
+		Body:    models.CreateAlertRequest{}, Status: http.StatusCreated, Data: models.Alert{},
+		Errors: map[int][]string{
+			http.StatusBadRequest: {
+				"INVALID_REQUEST", "VALIDATION_ERROR", "CHANNEL_UNAVAILABLE",
+				"INVALID_CARD_ID", "INVALID_CATEGORY_ID", "CARD_NOT_FOUND", "CATEGORY_NOT_FOUND",
+			},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/alerts/check", ID: "checkAlerts", Tag: "alerts",
+		Summary: "Check the alerts now and list those that fired",
+		Data:    []models.AlertEvent{},
+		Errors: map[int][]string{
+			http.StatusUnprocessableEntity: {"EXCHANGE_RATE_NOT_FOUND"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/alerts/:id", ID: "getAlert", Tag: "alerts",
+		Summary: "Get a budget alert",
+		Data:    models.Alert{},
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_UUID"},
+			http.StatusNotFound:            {"ALERT_NOT_FOUND"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodPut, Path: "/api/alerts/:id", ID: "updateAlert", Tag: "alerts",
+		Summary: "Update a budget alert",
+		Body:    models.UpdateAlertRequest{}, Data: models.Alert{},
+		Errors: map[int][]string{
+			http.StatusBadRequest: {
+				"INVALID_UUID", "INVALID_REQUEST", "VALIDATION_ERROR", "CHANNEL_UNAVAILABLE",
+				"INVALID_CARD_ID", "INVALID_CATEGORY_ID", "CARD_NOT_FOUND", "CATEGORY_NOT_FOUND",
+			},
+			http.StatusNotFound:            {"ALERT_NOT_FOUND"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodDelete, Path: "/api/alerts/:id", ID: "deleteAlert", Tag: "alerts",
+		Summary: "Delete a budget alert",
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_UUID"},
+			http.StatusNotFound:            {"ALERT_NOT_FOUND"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+
+	// Notifications
+	{
+		Method: http.MethodGet, Path: "/api/notifications", ID: "getNotifications", Tag: "notifications",
+		Summary: "List in-app notifications, newest first",
+		Query:   []Param{{Name: "unread", Type: "boolean", Description: "Only notifications not read yet"}},
+		Data:    []models.Notification{},
+		Errors:  map[int][]string{http.StatusInternalServerError: {"INTERNAL_ERROR"}},
+	},
+	{
+		Method: http.MethodPut, Path: "/api/notifications/:id/read", ID: "markNotificationRead", Tag: "notifications",
+		Summary: "Mark a notification as read",
+		Data:    models.Notification{},
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_UUID"},
+			http.StatusNotFound:            {"NOTIFICATION_NOT_FOUND"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+
+	// Webhooks
+	{
+		Method: http.MethodGet, Path: "/api/webhooks", ID: "getWebhooks", Tag: "webhooks",
+		Summary: "List webhooks",
+		Data:    []models.Webhook{},
+		Errors:  map[int][]string{http.StatusInternalServerError: {"INTERNAL_ERROR"}},
+	},
+	{
+		Method: http.MethodPost, Path: "/api/webhooks", ID: "createWebhook", Tag: "webhooks",
+		Summary: "Subscribe a webhook to events",
+		Body:    models.CreateWebhookRequest{}, Status: http.StatusCreated, Data: models.Webhook{},
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_REQUEST", "VALIDATION_ERROR", "INVALID_EVENT_TYPE"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/webhooks/:id", ID: "getWebhook", Tag: "webhooks",
+		Summary: "Get a webhook",
+		Data:    models.Webhook{},
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_UUID"},
+			http.StatusNotFound:            {"WEBHOOK_NOT_FOUND"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodPut, Path: "/api/webhooks/:id", ID: "updateWebhook", Tag: "webhooks",
+		Summary: "Update a webhook",
+		Body:    models.UpdateWebhookRequest{}, Data: models.Webhook{},
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_UUID", "INVALID_REQUEST", "VALIDATION_ERROR", "INVALID_EVENT_TYPE"},
+			http.StatusNotFound:            {"WEBHOOK_NOT_FOUND"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodDelete, Path: "/api/webhooks/:id", ID: "deleteWebhook", Tag: "webhooks",
+		Summary: "Delete a webhook and its deliveries",
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_UUID"},
+			http.StatusNotFound:            {"WEBHOOK_NOT_FOUND"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+	{
+		Method: http.MethodGet, Path: "/api/webhooks/:id/deliveries", ID: "getWebhookDeliveries", Tag: "webhooks",
+		Summary: "List the latest deliveries of a webhook",
+		Query: []Param{
+			{Name: "status", Enum: []string{models.DeliveryPending, models.DeliverySucceeded, models.DeliveryFailed}},
+			{Name: "limit", Type: "integer", Description: "50 by default"},
+		},
+		Data: []models.WebhookDelivery{},
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_UUID", "INVALID_STATUS", "INVALID_LIMIT"},
+			http.StatusNotFound:            {"WEBHOOK_NOT_FOUND"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+		},
+	},
+
+	// Live updates
+	{
+		Method: http.MethodGet, Path: "/api/events", ID: "streamEvents", Tag: "events",
+		Summary: "Stream expense, card and category changes as server-sent events",
+		Query: []Param{
+			{Name: "lastEventId", Type: "integer", Description: "Resume after this event, like the Last-Event-ID header"},
+		},
+		ContentType: "text/event-stream",
+		Errors:      map[int][]string{http.StatusBadRequest: {"INVALID_EVENT_ID"}},
+	},
+
+	// Insights
+	{
+		Method: http.MethodGet, Path: "/api/insights/anomalies", ID: "getAnomalies", Tag: "insights",
+		Summary: "Find unusual expenses",
+		Query: []Param{
+			dateParam("from", "Defaults to 30 days before to"),
+			dateParam("to", "Defaults to today"),
+			cardParam,
+		},
+		Data: models.AnomalyReport{},
+		Errors: map[int][]string{
+			http.StatusBadRequest:          {"INVALID_DATE", "MISSING_DATE_RANGE", "INVALID_DATE_RANGE", "INVALID_CARD_ID"},
+			http.StatusUnprocessableEntity: {"EXCHANGE_RATE_NOT_FOUND"},
+			http.StatusInternalServerError: {"INTERNAL_ERROR"},
+			http.StatusServiceUnavailable:  {"REQUEST_CANCELED"},
+			http.StatusGatewayTimeout:      {"REQUEST_TIMEOUT"},
+		},
+	},
+}
@@ -0,0 +1,72 @@
+package openapi
+
+// ErrorCodes is the catalog of the codes of ErrorResponse.
+var ErrorCodes = map[string]string{
+	// Requests
+	"INVALID_REQUEST":  "The request body could not be read or decoded.",
+	"VALIDATION_ERROR": "Fields of the request are missing or out of range; details lists them as FieldError, with messages in the language of Accept-Language (English or Japanese).",
+	"INVALID_UUID":     "An ID in the path is not a UUID.",
+	"INTERNAL_ERROR":   "The request could not be completed because of a server error.",
+	"STORAGE_ERROR":    "Attachment storage could not be read or written.",
+	"REQUEST_TIMEOUT":  "The request did not complete within the request timeout of the server.",
+	"REQUEST_CANCELED": "The request was canceled, for example because the client disconnected, before it completed.",
+
+	// Parameters
+	"INVALID_AMOUNT":        "An amount is smaller than the smallest unit of its currency.",
+	"INVALID_AMOUNT_RANGE":  "The minimum amount of a rule is above its maximum amount.",
+	"INVALID_CARD_ID":       "A card ID is not a UUID.",
+	"INVALID_CATEGORY_ID":   "A category ID is not a UUID.",
+	"INVALID_PAYEE_ID":      "A payee ID is not a UUID.",
+	"INVALID_CURRENCY":      "A currency is not a known ISO 4217 code.",
+	"INVALID_DATE":          "A date is not in YYYY-MM-DD form.",
+	"INVALID_DATE_RANGE":    "A date range ends before it starts.",
+	"MISSING_DATE_RANGE":    "A required date range was not given in full.",
+	"FUTURE_DATE":           "A date is in the future.",
+	"INVALID_YEAR":          "The year is not a number between 2000 and 2100.",
+	"INVALID_MONTH":         "The month is not a number between 1 and 12.",
+	"MISSING_MONTH":         "The month parameter is required.",
+	"INVALID_VIEW":          "The view is neither purchase nor payment.",
+	"INVALID_GROUP_BY":      "The grouping of a range report is not day, week, month or quarter.",
+	"INVALID_COMPARISON":    "The period to compare with cannot be determined.",
+	"INVALID_PATTERN":       "A pattern is not a valid regular expression.",
+	"INVALID_STATUS":        "The delivery status is not pending, succeeded or failed.",
+	"INVALID_LIMIT":         "The limit is not a number within the allowed range.",
+	"INVALID_EVENT_TYPE":    "An event type of a webhook is not known.",
+	"INVALID_EVENT_ID":      "The ID of the last event received is not a number.",
+	"INVALID_CSV":           "An imported CSV file has an invalid header or row.",
+	"CHANNEL_UNAVAILABLE":   "An alert channel is not configured on the server.",
+	"UNSUPPORTED_FILE_TYPE": "An uploaded file is not a JPEG, PNG, GIF, WebP or PDF.",
+	"FILE_TOO_LARGE":        "An uploaded file exceeds the size limit.",
+
+	// Expenses
+	"ITEMS_AMOUNT_MISMATCH":    "The amounts of the items do not add up to the expense amount.",
+	"REFUND_EXCEEDS_AMOUNT":    "The refunds of an expense would exceed its amount.",
+	"INVALID_REFUND_DATE":      "A refund is dated before its expense.",
+	"INVALID_INSTALLMENT_PLAN": "An installment plan does not fit its expense.",
+
+	// Database constraints
+	"REFERENCE_NOT_FOUND":  "A record refers to another record, such as a card, that does not exist.",
+	"CONSTRAINT_VIOLATION": "A value is not allowed by a constraint of the database.",
+
+	// Conflicts
+	"DUPLICATE_CATEGORY":    "A category with the same name exists.",
+	"DUPLICATE_PAYEE":       "A payee with the same name exists.",
+	"CARD_HAS_EXPENSES":     "The card cannot be deleted while it has expenses.",
+	"CATEGORY_HAS_EXPENSES": "The category cannot be deleted while it has expenses.",
+
+	// Not found
+	"RECORD_NOT_FOUND":           "A record the request needs does not exist.",
+	"CARD_NOT_FOUND":             "The card does not exist.",
+	"CATEGORY_NOT_FOUND":         "The category does not exist.",
+	"PAYEE_NOT_FOUND":            "The payee does not exist.",
+	"RULE_NOT_FOUND":             "The rule does not exist.",
+	"EXPENSE_NOT_FOUND":          "The expense does not exist.",
+	"ATTACHMENT_NOT_FOUND":       "The attachment or its file does not exist.",
+	"THUMBNAIL_NOT_FOUND":        "The attachment has no thumbnail.",
+	"INSTALLMENT_PLAN_NOT_FOUND": "The expense is not paid in installments.",
+	"REFUND_NOT_FOUND":           "The refund does not exist.",
+	"EXCHANGE_RATE_NOT_FOUND":    "The exchange rate does not exist, or a rate needed to convert amounts to the base currency is missing.",
+	"ALERT_NOT_FOUND":            "The alert does not exist.",
+	"NOTIFICATION_NOT_FOUND":     "The notification does not exist.",
+	"WEBHOOK_NOT_FOUND":          "The webhook does not exist.",
+}
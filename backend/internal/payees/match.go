@@ -0,0 +1,89 @@
+// Package payees links the free-text descriptions of expenses to payees.
+package payees
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"golang.org/x/text/unicode/norm"
+
+	"kakeibo-tanuki/internal/models"
+)
+
+// Normalize folds the differences between descriptions of the same payee
+// typed differently: case, repeated or surrounding spaces, full-width
+// letters and digits and half-width katakana (NFKC).
+func Normalize(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(norm.NFKC.String(s)), " "))
+}
+
+// CompilePattern compiles a payee pattern, which is matched
+// case-insensitively against normalized descriptions.
+func CompilePattern(pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	return re, nil
+}
+
+type payeePattern struct {
+	payeeID uuid.UUID
+	re      *regexp.Regexp
+}
+
+// Matcher finds the payee of a description.
+type Matcher struct {
+	names    map[string]uuid.UUID
+	patterns []payeePattern
+}
+
+// NewMatcher prepares the names, aliases and patterns of payees for
+// matching. When several payees match, the first by name wins, so the result
+// does not depend on the order of payees.
+func NewMatcher(payees []models.Payee) (*Matcher, error) {
+	sorted := make([]models.Payee, len(payees))
+	copy(sorted, payees)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	m := &Matcher{names: map[string]uuid.UUID{}}
+	for _, payee := range sorted {
+		for _, name := range append([]string{payee.Name}, payee.Aliases...) {
+			key := Normalize(name)
+			if _, ok := m.names[key]; !ok && key != "" {
+				m.names[key] = payee.ID
+			}
+		}
+		for _, pattern := range payee.Patterns {
+			re, err := CompilePattern(pattern)
+			if err != nil {
+				return nil, err
+			}
+			m.patterns = append(m.patterns, payeePattern{payeeID: payee.ID, re: re})
+		}
+	}
+	return m, nil
+}
+
+// Match returns the ID of the payee of description, or nil when none
+// matches. A name or alias equal to the whole description takes precedence
+// over patterns.
+func (m *Matcher) Match(description string) *uuid.UUID {
+	key := Normalize(description)
+	if key == "" {
+		return nil
+	}
+	if id, ok := m.names[key]; ok {
+		return &id
+	}
+	for _, pattern := range m.patterns {
+		if pattern.re.MatchString(key) {
+			id := pattern.payeeID
+			return &id
+		}
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package reports
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
+)
+
+// PreviousPeriod returns the period that from through to is compared
+// against. Ranges of whole calendar months move by whole months, so March is
+// compared with February (not with the 31 days before March) and with March
+// of the previous year. Other ranges move back by their own length or by a
+// year, with February 29 becoming February 28.
+func PreviousPeriod(from, to time.Time, against string) (time.Time, time.Time, error) {
+	from, to = PeriodStart(from, models.ReportGroupByDay), PeriodStart(to, models.ReportGroupByDay)
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("the end date must not be before the start date")
+	}
+
+	if months, ok := wholeMonths(from, to); ok {
+		shift := months
+		switch against {
+		case models.CompareAgainstPrevious:
+		case models.CompareAgainstLastYear:
+			shift = 12
+		default:
+			return time.Time{}, time.Time{}, fmt.Errorf("unknown comparison: %s", against)
+		}
+		start := from.AddDate(0, -shift, 0)
+		return start, start.AddDate(0, months, -1), nil
+	}
+
+	switch against {
+	case models.CompareAgainstPrevious:
+		days := int(to.Sub(from).Hours()/24) + 1
+		return from.AddDate(0, 0, -days), from.AddDate(0, 0, -1), nil
+	case models.CompareAgainstLastYear:
+		return lastYear(from), lastYear(to), nil
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("unknown comparison: %s", against)
+	}
+}
+
+// wholeMonths reports whether from through to is a run of whole calendar
+// months, and how many.
+func wholeMonths(from, to time.Time) (int, bool) {
+	if from.Day() != 1 || to.AddDate(0, 0, 1).Day() != 1 {
+		return 0, false
+	}
+	return (to.Year()-from.Year())*12 + int(to.Month()-from.Month()) + 1, true
+}
+
+// lastYear returns the same day a year earlier, clamping February 29.
+func lastYear(t time.Time) time.Time {
+	year, month, day := t.Date()
+	if month == time.February && day == 29 {
+		day = 28
+	}
+	return time.Date(year-1, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// ChangePercent returns the change from previous to current as a percentage
+// rounded to one decimal place, or nil when previous is zero.
+func ChangePercent(current, previous money.Amount) *float64 {
+	if previous == 0 {
+		return nil
+	}
+	percent := math.Round(float64(current-previous)/math.Abs(float64(previous))*1000) / 10
+	return &percent
+}
+
+// CompareCategories pairs up the category totals of two periods. It also
+// returns the categories that only have spending in the current period and
+// those that only had spending in the previous one.
+func CompareCategories(current, previous []models.CategoryExpenseSum) (comparisons, added, removed []models.CategoryComparison) {
+	index := map[uuid.UUID]int{}
+	comparisons = []models.CategoryComparison{}
+	entry := func(sum models.CategoryExpenseSum) *models.CategoryComparison {
+		i, ok := index[sum.CategoryID]
+		if !ok {
+			i = len(comparisons)
+			index[sum.CategoryID] = i
+			comparisons = append(comparisons, models.CategoryComparison{
+				CategoryID:   sum.CategoryID,
+				CategoryName: sum.CategoryName,
+				Color:        sum.Color,
+				IsShared:     sum.IsShared,
+			})
+		}
+		return &comparisons[i]
+	}
+
+	inCurrent := map[uuid.UUID]bool{}
+	for _, sum := range current {
+		entry(sum).CurrentAmount += sum.TotalAmount
+		inCurrent[sum.CategoryID] = true
+	}
+	inPrevious := map[uuid.UUID]bool{}
+	for _, sum := range previous {
+		entry(sum).PreviousAmount += sum.TotalAmount
+		inPrevious[sum.CategoryID] = true
+	}
+
+	for i := range comparisons {
+		comparisons[i].Change = comparisons[i].CurrentAmount - comparisons[i].PreviousAmount
+		comparisons[i].ChangePercent = ChangePercent(comparisons[i].CurrentAmount, comparisons[i].PreviousAmount)
+	}
+	sort.SliceStable(comparisons, func(i, j int) bool {
+		return compareOrder(comparisons[i].CurrentAmount, comparisons[i].PreviousAmount, comparisons[i].CategoryName,
+			comparisons[j].CurrentAmount, comparisons[j].PreviousAmount, comparisons[j].CategoryName)
+	})
+
+	added, removed = []models.CategoryComparison{}, []models.CategoryComparison{}
+	for _, comparison := range comparisons {
+		switch {
+		case !inPrevious[comparison.CategoryID]:
+			added = append(added, comparison)
+		case !inCurrent[comparison.CategoryID]:
+			removed = append(removed, comparison)
+		}
+	}
+	return comparisons, added, removed
+}
+
+// CompareCards pairs up the card totals of two periods.
+func CompareCards(current, previous []models.CardExpenseSum) []models.CardComparison {
+	index := map[uuid.UUID]int{}
+	comparisons := []models.CardComparison{}
+	entry := func(sum models.CardExpenseSum) *models.CardComparison {
+		i, ok := index[sum.CardID]
+		if !ok {
+			i = len(comparisons)
+			index[sum.CardID] = i
+			comparisons = append(comparisons, models.CardComparison{
+				CardID:   sum.CardID,
+				CardName: sum.CardName,
+				Color:    sum.Color,
+			})
+		}
+		return &comparisons[i]
+	}
+
+	for _, sum := range current {
+		entry(sum).CurrentAmount += sum.TotalAmount
+	}
+	for _, sum := range previous {
+		entry(sum).PreviousAmount += sum.TotalAmount
+	}
+
+	for i := range comparisons {
+		comparisons[i].Change = comparisons[i].CurrentAmount - comparisons[i].PreviousAmount
+		comparisons[i].ChangePercent = ChangePercent(comparisons[i].CurrentAmount, comparisons[i].PreviousAmount)
+	}
+	sort.SliceStable(comparisons, func(i, j int) bool {
+		return compareOrder(comparisons[i].CurrentAmount, comparisons[i].PreviousAmount, comparisons[i].CardName,
+			comparisons[j].CurrentAmount, comparisons[j].PreviousAmount, comparisons[j].CardName)
+	})
+	return comparisons
+}
+
+// compareOrder sorts comparisons by current spending, then previous
+// spending, then name, so that results do not depend on database order.
+func compareOrder(currentA, previousA money.Amount, nameA string, currentB, previousB money.Amount, nameB string) bool {
+	if currentA != currentB {
+		return currentA > currentB
+	}
+	if previousA != previousB {
+		return previousA > previousB
+	}
+	return nameA < nameB
+}
@@ -0,0 +1,132 @@
+package reports
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
+)
+
+// DailyRow is one amount of the report source split by category, in the
+// report currency.
+type DailyRow struct {
+	// ExpenseID is nil for refunds
+	ExpenseID    *uuid.UUID
+	Date         time.Time
+	CategoryID   uuid.UUID
+	CategoryName string
+	Color        string
+	Amount       money.Amount
+}
+
+// Daily adds up rows by day for every day from through to, and the days by
+// day of the week (Sunday first) and by day of the month. A day's count is
+// the number of distinct expenses on it, so a split expense counts once.
+func Daily(from, to time.Time, rows []DailyRow, currencyCode string) ([]models.DailyExpenseSum, []models.WeekdayExpenseSum, []models.DayOfMonthExpenseSum) {
+	from, to = PeriodStart(from, models.ReportGroupByDay), PeriodStart(to, models.ReportGroupByDay)
+
+	var days []models.DailyExpenseSum
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		days = append(days, models.DailyExpenseSum{
+			Date:    day.Format(DateLayout),
+			Weekday: int(day.Weekday()),
+		})
+	}
+
+	type dayCategory struct {
+		day        int
+		categoryID uuid.UUID
+	}
+	categoryTotals := map[dayCategory]*models.TopCategory{}
+	var categoryOrder []dayCategory
+	counted := map[int]map[uuid.UUID]bool{}
+
+	for _, row := range rows {
+		date := PeriodStart(row.Date, models.ReportGroupByDay)
+		if date.Before(from) || date.After(to) {
+			continue
+		}
+		i := int(date.Sub(from).Hours() / 24)
+		days[i].TotalAmount += row.Amount
+
+		// Refunds have no ID and are not counted as expenses
+		if row.ExpenseID != nil {
+			if counted[i] == nil {
+				counted[i] = map[uuid.UUID]bool{}
+			}
+			if !counted[i][*row.ExpenseID] {
+				counted[i][*row.ExpenseID] = true
+				days[i].Count++
+			}
+		}
+
+		key := dayCategory{i, row.CategoryID}
+		if categoryTotals[key] == nil {
+			categoryTotals[key] = &models.TopCategory{
+				CategoryID:   row.CategoryID,
+				CategoryName: row.CategoryName,
+				Color:        row.Color,
+			}
+			categoryOrder = append(categoryOrder, key)
+		}
+		categoryTotals[key].TotalAmount += row.Amount
+	}
+
+	// The top category of a day is the one with the largest positive
+	// total, by name on a tie
+	for _, key := range categoryOrder {
+		category := categoryTotals[key]
+		top := days[key.day].TopCategory
+		if category.TotalAmount <= 0 {
+			continue
+		}
+		if top == nil || category.TotalAmount > top.TotalAmount ||
+			category.TotalAmount == top.TotalAmount && category.CategoryName < top.CategoryName {
+			days[key.day].TopCategory = category
+		}
+	}
+
+	byWeekday := make([]models.WeekdayExpenseSum, 7)
+	for i := range byWeekday {
+		byWeekday[i].Weekday = i
+	}
+	byDayOfMonth := make([]models.DayOfMonthExpenseSum, 31)
+	for i := range byDayOfMonth {
+		byDayOfMonth[i].Day = i + 1
+	}
+	for i, day := range days {
+		weekday := &byWeekday[day.Weekday]
+		weekday.Days++
+		weekday.TotalAmount += day.TotalAmount
+		weekday.Count += day.Count
+
+		dayOfMonth := &byDayOfMonth[from.AddDate(0, 0, i).Day()-1]
+		dayOfMonth.Days++
+		dayOfMonth.TotalAmount += day.TotalAmount
+		dayOfMonth.Count += day.Count
+	}
+
+	average := func(total money.Amount, days int) money.Amount {
+		if days == 0 {
+			return 0
+		}
+		return money.Amount(math.Round(float64(total) / float64(days))).Round(currencyCode)
+	}
+	for i := range byWeekday {
+		byWeekday[i].AverageAmount = average(byWeekday[i].TotalAmount, byWeekday[i].Days)
+	}
+	// Only days of the month that the report covers are returned, so a
+	// February report stops at the 28th or 29th
+	monthDays := byDayOfMonth[:0]
+	for _, dayOfMonth := range byDayOfMonth {
+		if dayOfMonth.Days > 0 {
+			dayOfMonth.AverageAmount = average(dayOfMonth.TotalAmount, dayOfMonth.Days)
+			monthDays = append(monthDays, dayOfMonth)
+		}
+	}
+
+	return days, byWeekday, monthDays
+}
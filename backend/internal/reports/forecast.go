@@ -0,0 +1,347 @@
+package reports
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
+)
+
+// HistoryDays is the number of days, up to and including the forecast date,
+// whose spending is averaged by day of the week. 91 days is exactly 13 of
+// each weekday.
+const HistoryDays = 91
+
+// RecurringMonths is the number of months before the current one in which a
+// charge has to appear, on the same card with the same description, category
+// and amount, to be expected again.
+const RecurringMonths = 3
+
+// confidenceZ makes the forecast range cover about 80% of outcomes when
+// daily spending is roughly normally distributed.
+const confidenceZ = 1.28
+
+// ForecastRow is one amount of the report source, in the report currency.
+type ForecastRow struct {
+	// ExpenseID is nil for refunds
+	ExpenseID *uuid.UUID
+	Date      time.Time
+	// PurchaseDate differs from Date for installment payments
+	PurchaseDate time.Time
+	CategoryID   uuid.UUID
+	CategoryName string
+	Color        string
+	CardID       uuid.UUID
+	Description  string
+	Amount       money.Amount
+}
+
+// ForecastStart returns the first day of the rows needed for a forecast made
+// on asOf: the start of the year, of the daily history or of the months
+// searched for recurring charges, whichever is earliest.
+func ForecastStart(asOf time.Time) time.Time {
+	asOf = PeriodStart(asOf, models.ReportGroupByDay)
+	start := time.Date(asOf.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	if history := asOf.AddDate(0, 0, -(HistoryDays - 1)); history.Before(start) {
+		start = history
+	}
+	if months := PeriodStart(asOf, models.ReportGroupByMonth).AddDate(0, -RecurringMonths, 0); months.Before(start) {
+		start = months
+	}
+	return start
+}
+
+// ForecastEnd returns the last day of the year of asOf.
+func ForecastEnd(asOf time.Time) time.Time {
+	return time.Date(asOf.Year(), 12, 31, 0, 0, 0, 0, time.UTC)
+}
+
+// Forecast projects the spending of the month and the year of asOf from rows
+// covering ForecastStart through ForecastEnd. Spending after asOf is made up
+// of the rows already recorded for later dates, recurring charges that have
+// not been made yet this month and the average spending of each day of the
+// week over the last HistoryDays days, leaving out recurring charges and
+// installment payments. The result only depends on the rows and asOf.
+func Forecast(asOf time.Time, rows []ForecastRow, currencyCode string) (month, year models.ForecastTotals, byCategory []models.CategoryForecast) {
+	f := newForecaster(asOf, currencyCode)
+	charges, recurring := f.recurringCharges(rows)
+
+	index := map[uuid.UUID]int{}
+	var categoryRows [][]forecastRow
+	byCategory = []models.CategoryForecast{}
+	for _, row := range rows {
+		c, ok := index[row.CategoryID]
+		if !ok {
+			c = len(byCategory)
+			index[row.CategoryID] = c
+			categoryRows = append(categoryRows, nil)
+			byCategory = append(byCategory, models.CategoryForecast{
+				CategoryID:   row.CategoryID,
+				CategoryName: row.CategoryName,
+				Color:        row.Color,
+			})
+		}
+		key, ok := chargeKey(row)
+		categoryRows[c] = append(categoryRows[c], forecastRow{ForecastRow: row, recurring: ok && recurring[key]})
+	}
+
+	var allRows []forecastRow
+	for c := range byCategory {
+		var categoryCharges []recurringCharge
+		for _, charge := range charges {
+			if charge.categoryID == byCategory[c].CategoryID {
+				categoryCharges = append(categoryCharges, charge)
+			}
+		}
+		daily := f.dailyPattern(categoryRows[c])
+		byCategory[c].Month = f.totals(categoryRows[c], categoryCharges, daily, f.monthStart, f.monthEnd)
+		byCategory[c].Year = f.totals(categoryRows[c], categoryCharges, daily, f.yearStart, f.yearEnd)
+		allRows = append(allRows, categoryRows[c]...)
+	}
+
+	sort.SliceStable(byCategory, func(i, j int) bool {
+		if byCategory[i].Month.Forecast != byCategory[j].Month.Forecast {
+			return byCategory[i].Month.Forecast > byCategory[j].Month.Forecast
+		}
+		if byCategory[i].Year.Forecast != byCategory[j].Year.Forecast {
+			return byCategory[i].Year.Forecast > byCategory[j].Year.Forecast
+		}
+		return byCategory[i].CategoryName < byCategory[j].CategoryName
+	})
+
+	// The totals add up the categories, but their range comes from the
+	// daily pattern of all spending, as categories partly offset each other
+	daily := f.dailyPattern(allRows)
+	month = f.sumTotals(byCategory, func(c models.CategoryForecast) models.ForecastTotals { return c.Month }, daily, f.monthStart, f.monthEnd)
+	year = f.sumTotals(byCategory, func(c models.CategoryForecast) models.ForecastTotals { return c.Year }, daily, f.yearStart, f.yearEnd)
+	return month, year, byCategory
+}
+
+// forecastRow is a row marked when it belongs to a recurring charge.
+type forecastRow struct {
+	ForecastRow
+	recurring bool
+}
+
+// inPattern reports whether the row counts towards the daily pattern, which
+// leaves out charges forecast on their own.
+func (r forecastRow) inPattern() bool {
+	return !r.recurring && r.PurchaseDate.Equal(r.Date)
+}
+
+type forecaster struct {
+	asOf         time.Time
+	historyStart time.Time
+	monthStart   time.Time
+	monthEnd     time.Time
+	yearStart    time.Time
+	yearEnd      time.Time
+	currencyCode string
+}
+
+func newForecaster(asOf time.Time, currencyCode string) *forecaster {
+	asOf = PeriodStart(asOf, models.ReportGroupByDay)
+	monthStart := PeriodStart(asOf, models.ReportGroupByMonth)
+	yearStart := time.Date(asOf.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	return &forecaster{
+		asOf:         asOf,
+		historyStart: asOf.AddDate(0, 0, -(HistoryDays - 1)),
+		monthStart:   monthStart,
+		monthEnd:     monthStart.AddDate(0, 1, -1),
+		yearStart:    yearStart,
+		yearEnd:      ForecastEnd(asOf),
+		currencyCode: currencyCode,
+	}
+}
+
+type recurringKey struct {
+	categoryID  uuid.UUID
+	cardID      uuid.UUID
+	description string
+	amount      money.Amount
+}
+
+// recurringCharge is a charge made every month on about the same day.
+type recurringCharge struct {
+	categoryID uuid.UUID
+	day        int
+	amount     money.Amount
+	// chargedThisMonth is set once the charge has been made in the month
+	// of the forecast
+	chargedThisMonth bool
+}
+
+// recurringCharges finds the charges made in each of the RecurringMonths
+// months before the month of the forecast, and returns their keys.
+func (f *forecaster) recurringCharges(rows []ForecastRow) ([]recurringCharge, map[recurringKey]bool) {
+	current := monthIndex(f.asOf)
+	seen := map[recurringKey]map[int]int{}
+	var keys []recurringKey
+	for _, row := range rows {
+		key, ok := chargeKey(row)
+		if !ok || row.Date.After(f.asOf) {
+			continue
+		}
+		if seen[key] == nil {
+			seen[key] = map[int]int{}
+			keys = append(keys, key)
+		}
+		seen[key][monthIndex(row.Date)] = row.Date.Day()
+	}
+
+	var charges []recurringCharge
+	recurring := map[recurringKey]bool{}
+	for _, key := range keys {
+		months := seen[key]
+		found := true
+		for m := current - RecurringMonths; m < current; m++ {
+			if _, ok := months[m]; !ok {
+				found = false
+				break
+			}
+		}
+		if !found {
+			continue
+		}
+
+		_, charged := months[current]
+		recurring[key] = true
+		charges = append(charges, recurringCharge{
+			categoryID:       key.categoryID,
+			day:              months[current-1],
+			amount:           key.amount,
+			chargedThisMonth: charged,
+		})
+	}
+	return charges, recurring
+}
+
+// chargeKey identifies the expenses that repeat a recurring charge. Refunds,
+// installment payments and expenses without a description never recur.
+func chargeKey(row ForecastRow) (recurringKey, bool) {
+	if row.ExpenseID == nil || row.Description == "" || !row.PurchaseDate.Equal(row.Date) {
+		return recurringKey{}, false
+	}
+	return recurringKey{
+		categoryID:  row.CategoryID,
+		cardID:      row.CardID,
+		description: row.Description,
+		amount:      row.Amount,
+	}, true
+}
+
+func monthIndex(t time.Time) int {
+	return t.Year()*12 + int(t.Month()) - 1
+}
+
+// dailyPattern is the average spending of each day of the week, in
+// hundredths, and the variance of single days around that average.
+type dailyPattern struct {
+	mean     [7]float64
+	variance float64
+}
+
+func (f *forecaster) dailyPattern(rows []forecastRow) dailyPattern {
+	days := make([]float64, HistoryDays)
+	for _, row := range rows {
+		if !row.inPattern() || row.Date.Before(f.historyStart) || row.Date.After(f.asOf) {
+			continue
+		}
+		days[int(row.Date.Sub(f.historyStart).Hours()/24)] += float64(row.Amount)
+	}
+
+	var pattern dailyPattern
+	weeks := float64(HistoryDays / 7)
+	for i, amount := range days {
+		pattern.mean[f.historyStart.AddDate(0, 0, i).Weekday()] += amount / weeks
+	}
+	for i, amount := range days {
+		deviation := amount - pattern.mean[f.historyStart.AddDate(0, 0, i).Weekday()]
+		pattern.variance += deviation * deviation / float64(HistoryDays-7)
+	}
+	return pattern
+}
+
+// remaining returns the expected spending and its standard deviation for
+// the days after the forecast date up to end.
+func (f *forecaster) remaining(daily dailyPattern, end time.Time) (float64, float64) {
+	var expected float64
+	days := 0
+	for day := f.asOf.AddDate(0, 0, 1); !day.After(end); day = day.AddDate(0, 0, 1) {
+		expected += daily.mean[day.Weekday()]
+		days++
+	}
+	return expected, math.Sqrt(float64(days) * daily.variance)
+}
+
+func (f *forecaster) totals(rows []forecastRow, charges []recurringCharge, daily dailyPattern, from, to time.Time) models.ForecastTotals {
+	totals := models.ForecastTotals{
+		From: from.Format(DateLayout),
+		To:   to.Format(DateLayout),
+	}
+
+	for _, row := range rows {
+		switch {
+		case row.Date.Before(from) || row.Date.After(to):
+		case row.Date.After(f.asOf):
+			totals.Scheduled += row.Amount
+		default:
+			totals.Actual += row.Amount
+		}
+	}
+
+	for _, charge := range charges {
+		for month := PeriodStart(f.asOf, models.ReportGroupByMonth); !month.After(to); month = month.AddDate(0, 1, 0) {
+			if charge.chargedThisMonth && month.Equal(f.monthStart) {
+				continue
+			}
+			day := month.AddDate(0, 0, charge.day-1)
+			if last := month.AddDate(0, 1, -1); day.After(last) {
+				day = last
+			}
+			if day.After(f.asOf) {
+				totals.Recurring += charge.amount
+			}
+		}
+	}
+
+	expected, deviation := f.remaining(daily, to)
+	totals.Projected = f.round(expected)
+	f.setRange(&totals, expected, deviation)
+	return totals
+}
+
+func (f *forecaster) sumTotals(categories []models.CategoryForecast, period func(models.CategoryForecast) models.ForecastTotals, daily dailyPattern, from, to time.Time) models.ForecastTotals {
+	totals := models.ForecastTotals{
+		From: from.Format(DateLayout),
+		To:   to.Format(DateLayout),
+	}
+	for _, category := range categories {
+		p := period(category)
+		totals.Actual += p.Actual
+		totals.Scheduled += p.Scheduled
+		totals.Recurring += p.Recurring
+		totals.Projected += p.Projected
+	}
+
+	_, deviation := f.remaining(daily, to)
+	f.setRange(&totals, float64(totals.Projected), deviation)
+	return totals
+}
+
+// setRange sets the forecast and its range. Only the projected part is
+// uncertain, and it is not expected to drop below zero.
+func (f *forecaster) setRange(totals *models.ForecastTotals, expected, deviation float64) {
+	known := totals.Actual + totals.Scheduled + totals.Recurring
+	totals.Forecast = known + totals.Projected
+	totals.Low = known + f.round(math.Max(expected-confidenceZ*deviation, 0))
+	totals.High = known + f.round(math.Max(expected+confidenceZ*deviation, 0))
+}
+
+func (f *forecaster) round(hundredths float64) money.Amount {
+	return money.Amount(math.Round(hundredths)).Round(f.currencyCode)
+}
@@ -0,0 +1,95 @@
+// Package reports contains the period and comparison logic behind reports.
+package reports
+
+import (
+	"fmt"
+	"time"
+
+	"kakeibo-tanuki/internal/models"
+)
+
+// MaxPeriods bounds the number of periods a range report is split into, so
+// that a daily breakdown of several years has to use a coarser grouping.
+const MaxPeriods = 1000
+
+// DateLayout is the format of the dates in range report requests and
+// responses.
+const DateLayout = "2006-01-02"
+
+// Period is one bucket of a range report. End is the last day included.
+type Period struct {
+	Start time.Time
+	End   time.Time
+}
+
+// ValidGroupBy reports whether groupBy is a supported period length.
+func ValidGroupBy(groupBy string) bool {
+	switch groupBy {
+	case models.ReportGroupByDay, models.ReportGroupByWeek, models.ReportGroupByMonth, models.ReportGroupByQuarter:
+		return true
+	}
+	return false
+}
+
+// PeriodStart returns the first day of the period containing t. Weeks start
+// on Monday and quarters on January, April, July and October, so a fiscal
+// year starting in April is four whole quarters.
+func PeriodStart(t time.Time, groupBy string) time.Time {
+	year, month, day := t.Date()
+	switch groupBy {
+	case models.ReportGroupByWeek:
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(year, month, day-offset, 0, 0, 0, 0, time.UTC)
+	case models.ReportGroupByMonth:
+		return time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	case models.ReportGroupByQuarter:
+		return time.Date(year, month-(month-1)%3, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// nextPeriod returns the first day of the period after the one starting on
+// start.
+func nextPeriod(start time.Time, groupBy string) time.Time {
+	switch groupBy {
+	case models.ReportGroupByWeek:
+		return start.AddDate(0, 0, 7)
+	case models.ReportGroupByMonth:
+		return start.AddDate(0, 1, 0)
+	case models.ReportGroupByQuarter:
+		return start.AddDate(0, 3, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// Periods splits the days from through to (inclusive) into calendar periods.
+// The first and last periods are clipped to the range, so a trip from
+// Wednesday to Sunday grouped by week is a single period of five days.
+func Periods(from, to time.Time, groupBy string) ([]Period, error) {
+	if !ValidGroupBy(groupBy) {
+		return nil, fmt.Errorf("unknown grouping: %s", groupBy)
+	}
+	from, to = PeriodStart(from, models.ReportGroupByDay), PeriodStart(to, models.ReportGroupByDay)
+	if to.Before(from) {
+		return nil, fmt.Errorf("the end date must not be before the start date")
+	}
+
+	var periods []Period
+	for start := PeriodStart(from, groupBy); !start.After(to); start = nextPeriod(start, groupBy) {
+		if len(periods) == MaxPeriods {
+			return nil, fmt.Errorf("the range has more than %d periods; use a longer grouping", MaxPeriods)
+		}
+
+		period := Period{Start: start, End: nextPeriod(start, groupBy).AddDate(0, 0, -1)}
+		if period.Start.Before(from) {
+			period.Start = from
+		}
+		if period.End.After(to) {
+			period.End = to
+		}
+		periods = append(periods, period)
+	}
+	return periods, nil
+}
@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"context"
+	"kakeibo-tanuki/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type alertRepository struct {
+	db *gorm.DB
+}
+
+func NewAlertRepository(db *gorm.DB) AlertRepository {
+	translateErrors(db)
+	return &alertRepository{db: db}
+}
+
+func (r *alertRepository) Create(ctx context.Context, alert *models.Alert) error {
+	return r.db.WithContext(ctx).Create(alert).Error
+}
+
+func (r *alertRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Alert, error) {
+	var alert models.Alert
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&alert).Error
+	if err != nil {
+		return nil, err
+	}
+	return &alert, nil
+}
+
+func (r *alertRepository) GetAll(ctx context.Context) ([]models.Alert, error) {
+	var alerts []models.Alert
+	err := r.db.WithContext(ctx).Order("name ASC").Find(&alerts).Error
+	return alerts, err
+}
+
+func (r *alertRepository) Update(ctx context.Context, alert *models.Alert) error {
+	return r.db.WithContext(ctx).Save(alert).Error
+}
+
+func (r *alertRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&models.Alert{}, id).Error
+}
+
+// SetLastTriggered records the period an alert fired in without touching the
+// rest of the alert, which may be edited meanwhile.
+func (r *alertRepository) SetLastTriggered(ctx context.Context, id uuid.UUID, periodStart string) error {
+	return r.db.WithContext(ctx).Model(&models.Alert{}).Where("id = ?", id).Update("last_triggered", periodStart).Error
+}
@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"kakeibo-tanuki/internal/currency"
+	"kakeibo-tanuki/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type bookRepository struct {
+	db *gorm.DB
+}
+
+func NewBookRepository(db *gorm.DB) BookRepository {
+	translateErrors(db)
+	return &bookRepository{db: db}
+}
+
+// Get returns the book, creating it with the default settings on first use.
+func (r *bookRepository) Get(ctx context.Context) (*models.Book, error) {
+	var book models.Book
+	err := r.db.WithContext(ctx).Order("created_at ASC").First(&book).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		book = models.Book{
+			ID:           uuid.New(),
+			Name:         "家計簿",
+			BaseCurrency: currency.Default,
+		}
+		err = r.db.WithContext(ctx).Create(&book).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+func (r *bookRepository) Update(ctx context.Context, book *models.Book) error {
+	return r.db.WithContext(ctx).Save(book).Error
+}
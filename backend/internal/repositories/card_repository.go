@@ -1,9 +1,11 @@
 package repositories
 
 import (
+	"context"
 	"kakeibo-tanuki/internal/models"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type cardRepository struct {
@@ -11,38 +13,50 @@ type cardRepository struct {
 }
 
 func NewCardRepository(db *gorm.DB) CardRepository {
+	translateErrors(db)
 	return &cardRepository{db: db}
 }
 
-func (r *cardRepository) Create(card *models.Card) error {
-	return r.db.Create(card).Error
+func (r *cardRepository) Create(ctx context.Context, card *models.Card) error {
+	return r.db.WithContext(ctx).Create(card).Error
 }
 
-func (r *cardRepository) GetByID(id uuid.UUID) (*models.Card, error) {
+func (r *cardRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Card, error) {
 	var card models.Card
-	err := r.db.Where("id = ?", id).First(&card).Error
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&card).Error
 	if err != nil {
 		return nil, err
 	}
 	return &card, nil
 }
 
-func (r *cardRepository) GetAll() ([]models.Card, error) {
+// Lock returns the card and locks it until the end of the transaction, so
+// that no expense refers to it meanwhile.
+func (r *cardRepository) Lock(ctx context.Context, id uuid.UUID) (*models.Card, error) {
+	var card models.Card
+	err := r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&card).Error
+	if err != nil {
+		return nil, err
+	}
+	return &card, nil
+}
+
+func (r *cardRepository) GetAll(ctx context.Context) ([]models.Card, error) {
 	var cards []models.Card
-	err := r.db.Order("created_at DESC").Find(&cards).Error
+	err := r.db.WithContext(ctx).Order("created_at DESC").Find(&cards).Error
 	return cards, err
 }
 
-func (r *cardRepository) Update(card *models.Card) error {
-	return r.db.Save(card).Error
+func (r *cardRepository) Update(ctx context.Context, card *models.Card) error {
+	return r.db.WithContext(ctx).Save(card).Error
 }
 
-func (r *cardRepository) Delete(id uuid.UUID) error {
-	return r.db.Delete(&models.Card{}, id).Error
+func (r *cardRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&models.Card{}, id).Error
 }
 
-func (r *cardRepository) HasExpenses(cardID uuid.UUID) (bool, error) {
+func (r *cardRepository) HasExpenses(ctx context.Context, cardID uuid.UUID) (bool, error) {
 	var count int64
-	err := r.db.Model(&models.Expense{}).Where("card_id = ?", cardID).Count(&count).Error
+	err := r.db.WithContext(ctx).Model(&models.Expense{}).Where("card_id = ?", cardID).Count(&count).Error
 	return count > 0, err
-}
\ No newline at end of file
+}
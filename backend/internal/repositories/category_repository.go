@@ -1,9 +1,11 @@
 package repositories
 
 import (
+	"context"
 	"kakeibo-tanuki/internal/models"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type categoryRepository struct {
@@ -11,38 +13,56 @@ type categoryRepository struct {
 }
 
 func NewCategoryRepository(db *gorm.DB) CategoryRepository {
+	translateErrors(db)
 	return &categoryRepository{db: db}
 }
 
-func (r *categoryRepository) Create(category *models.Category) error {
-	return r.db.Create(category).Error
+func (r *categoryRepository) Create(ctx context.Context, category *models.Category) error {
+	return r.db.WithContext(ctx).Create(category).Error
 }
 
-func (r *categoryRepository) GetByID(id uuid.UUID) (*models.Category, error) {
+func (r *categoryRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Category, error) {
 	var category models.Category
-	err := r.db.Where("id = ?", id).First(&category).Error
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&category).Error
 	if err != nil {
 		return nil, err
 	}
 	return &category, nil
 }
 
-func (r *categoryRepository) GetAll() ([]models.Category, error) {
+// Lock returns the category and locks it until the end of the transaction, so
+// that no expense refers to it meanwhile.
+func (r *categoryRepository) Lock(ctx context.Context, id uuid.UUID) (*models.Category, error) {
+	var category models.Category
+	err := r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&category).Error
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *categoryRepository) GetAll(ctx context.Context) ([]models.Category, error) {
 	var categories []models.Category
-	err := r.db.Order("created_at DESC").Find(&categories).Error
+	err := r.db.WithContext(ctx).Order("created_at DESC").Find(&categories).Error
 	return categories, err
 }
 
-func (r *categoryRepository) Update(category *models.Category) error {
-	return r.db.Save(category).Error
+func (r *categoryRepository) Update(ctx context.Context, category *models.Category) error {
+	return r.db.WithContext(ctx).Save(category).Error
 }
 
-func (r *categoryRepository) Delete(id uuid.UUID) error {
-	return r.db.Delete(&models.Category{}, id).Error
+func (r *categoryRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&models.Category{}, id).Error
 }
 
-func (r *categoryRepository) HasExpenses(categoryID uuid.UUID) (bool, error) {
+func (r *categoryRepository) HasExpenses(ctx context.Context, categoryID uuid.UUID) (bool, error) {
 	var count int64
-	err := r.db.Model(&models.Expense{}).Where("category_id = ?", categoryID).Count(&count).Error
+	err := r.db.WithContext(ctx).Model(&models.Expense{}).Where("category_id = ?", categoryID).Count(&count).Error
+	if err != nil || count > 0 {
+		return count > 0, err
+	}
+
+	// Line items of split expenses also reference the category
+	err = r.db.WithContext(ctx).Model(&models.ExpenseItem{}).Where("category_id = ?", categoryID).Count(&count).Error
 	return count > 0, err
-}
\ No newline at end of file
+}
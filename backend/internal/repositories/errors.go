@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/gorm"
+)
+
+// Kinds of database errors that handlers act on. Errors returned by the
+// repositories match them with errors.Is, whichever database is used. A
+// statement interrupted by its context is of the kind of the error of the
+// context, context.Canceled or context.DeadlineExceeded.
+var (
+	ErrNotFound       = errors.New("record not found")
+	ErrConflict       = errors.New("record conflicts with an existing one")
+	ErrForeignKey     = errors.New("record refers to a record that does not exist")
+	ErrCheckViolation = errors.New("record has a value the schema does not accept")
+)
+
+// Error is a database error of one of the kinds above. Model is the name of
+// the model of the statement, such as "Card", and is empty for raw SQL.
+type Error struct {
+	Kind  error
+	Model string
+	Err   error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// Codes of PostgreSQL and extended result codes of SQLite for the
+// constraints of the schema
+var (
+	postgresErrors = map[string]error{
+		"23505": ErrConflict,
+		"23503": ErrForeignKey,
+		"23514": ErrCheckViolation,
+	}
+	sqliteErrors = map[sqlite3.ErrNoExtended]error{
+		sqlite3.ErrConstraintPrimaryKey: ErrConflict,
+		sqlite3.ErrConstraintUnique:     ErrConflict,
+		sqlite3.ErrConstraintForeignKey: ErrForeignKey,
+		sqlite3.ErrConstraintCheck:      ErrCheckViolation,
+	}
+)
+
+const translateCallback = "repositories:translate_error"
+
+// translateErrors registers callbacks on db that replace the errors of
+// statements with an Error when they are of a known kind, so the
+// repositories need not translate each error they return. Every repository
+// constructor calls it; the callbacks are registered once per database.
+func translateErrors(db *gorm.DB) {
+	callbacks := db.Callback()
+	processors := []interface {
+		Get(name string) func(*gorm.DB)
+		Register(name string, fn func(*gorm.DB)) error
+	}{
+		callbacks.Create(), callbacks.Query(), callbacks.Update(),
+		callbacks.Delete(), callbacks.Row(), callbacks.Raw(),
+	}
+	for _, processor := range processors {
+		if processor.Get(translateCallback) != nil {
+			continue
+		}
+		if err := processor.Register(translateCallback, translateError); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func translateError(db *gorm.DB) {
+	if db.Error == nil {
+		return
+	}
+	var translated *Error
+	if errors.As(db.Error, &translated) {
+		return
+	}
+	kind := errorKind(db.Error)
+	if ctx := db.Statement.Context; ctx != nil && ctx.Err() != nil {
+		kind = ctx.Err()
+	}
+	if kind == nil {
+		return
+	}
+	model := ""
+	if db.Statement.Schema != nil {
+		model = db.Statement.Schema.Name
+	}
+	db.Error = &Error{Kind: kind, Model: model, Err: db.Error}
+}
+
+// errorKind returns the kind of err, or nil for other errors.
+func errorKind(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return ErrConflict
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return ErrForeignKey
+	case errors.Is(err, gorm.ErrCheckConstraintViolated):
+		return ErrCheckViolation
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return postgresErrors[pgErr.Code]
+	}
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErrors[sqliteErr.ExtendedCode]
+	}
+	return nil
+}
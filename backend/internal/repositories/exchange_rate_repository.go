@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"context"
+	"kakeibo-tanuki/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type exchangeRateRepository struct {
+	db *gorm.DB
+}
+
+func NewExchangeRateRepository(db *gorm.DB) ExchangeRateRepository {
+	translateErrors(db)
+	return &exchangeRateRepository{db: db}
+}
+
+// upsert replaces the rate of an existing currency pair and date.
+var exchangeRateUpsert = clause.OnConflict{
+	Columns:   []clause.Column{{Name: "currency"}, {Name: "base_currency"}, {Name: "date"}},
+	DoUpdates: clause.AssignmentColumns([]string{"rate", "updated_at"}),
+}
+
+// Save stores the rate and reloads it, since an existing rate of the same
+// currency pair and date keeps its ID when it is replaced.
+func (r *exchangeRateRepository) Save(ctx context.Context, rate *models.ExchangeRate) error {
+	if err := r.db.WithContext(ctx).Clauses(exchangeRateUpsert).Create(rate).Error; err != nil {
+		return err
+	}
+	return r.db.WithContext(ctx).Where("currency = ? AND base_currency = ? AND date = ?", rate.Currency, rate.BaseCurrency, rate.Date).First(rate).Error
+}
+
+// SaveAll stores all rates in one transaction so a failed import leaves no
+// partial data behind.
+func (r *exchangeRateRepository) SaveAll(ctx context.Context, rates []models.ExchangeRate) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i := range rates {
+			if rates[i].ID == uuid.Nil {
+				rates[i].ID = uuid.New()
+			}
+			if err := tx.Clauses(exchangeRateUpsert).Create(&rates[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *exchangeRateRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ExchangeRate, error) {
+	var rate models.ExchangeRate
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&rate).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
+
+func (r *exchangeRateRepository) GetAll(ctx context.Context, filters *models.ExchangeRateFilters) ([]models.ExchangeRate, error) {
+	var rates []models.ExchangeRate
+
+	query := r.db.WithContext(ctx).Model(&models.ExchangeRate{})
+	if filters.Currency != "" {
+		query = query.Where("currency = ?", filters.Currency)
+	}
+	if filters.BaseCurrency != "" {
+		query = query.Where("base_currency = ?", filters.BaseCurrency)
+	}
+	if filters.StartDate != nil {
+		query = query.Where("date >= ?", filters.StartDate)
+	}
+	if filters.EndDate != nil {
+		query = query.Where("date <= ?", filters.EndDate)
+	}
+
+	err := query.Order("date DESC, currency ASC").Find(&rates).Error
+	return rates, err
+}
+
+func (r *exchangeRateRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&models.ExchangeRate{}, id).Error
+}
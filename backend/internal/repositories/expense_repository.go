@@ -1,11 +1,20 @@
 package repositories
 
 import (
+	"context"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"kakeibo-tanuki/internal/currency"
+	"kakeibo-tanuki/internal/insights"
 	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
+	"kakeibo-tanuki/internal/reports"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type expenseRepository struct {
@@ -13,23 +22,159 @@ type expenseRepository struct {
 }
 
 func NewExpenseRepository(db *gorm.DB) ExpenseRepository {
+	translateErrors(db)
 	return &expenseRepository{db: db}
 }
 
-func (r *expenseRepository) Create(expense *models.Expense) error {
+// withContext returns the repository with its queries bound to ctx, so that
+// they are canceled with the request.
+func (r *expenseRepository) withContext(ctx context.Context) *expenseRepository {
+	return &expenseRepository{db: r.db.WithContext(ctx)}
+}
+
+// reportSource returns a derived table aliased "e" with one row per amount
+// that a report should aggregate, exposing the columns id (expense ID), date,
+// card_id, category_id, payee_id, is_shared (of the expense), currency (of the
+// expense) and amount so filters can be applied uniformly.
+//
+// With splitItems, split expenses contribute each line item to the item's
+// category instead of their full amount to Expense.CategoryID. In the payment
+// view, installment purchases contribute their scheduled charges on the due
+// dates instead of the purchase amount on the purchase date; charges of split
+// expenses are allocated to the items in proportion to the item amounts.
+//
+// Refunds contribute negative amounts on the refund date to the card and
+// categories of the original expense in both views. Their id is NULL so that
+// COUNT(DISTINCT e.id) keeps counting expenses only. Refunds of split
+// expenses are allocated to the items like charges.
+//
+// Allocated shares are rounded to the minor units of the expense currency
+// and the largest item takes the remainder, so the shares of a charge or
+// refund add up to it exactly.
+//
+// Amounts are converted to baseCurrency with the latest exchange rate on or
+// before the expense date and rounded to its minor units; they are NULL when
+// no rate exists. The "?" placeholders all stand for baseCurrency.
+func reportSource(view string, splitItems bool, baseCurrency string) string {
+	hasItems := "EXISTS (SELECT 1 FROM expense_items i2 WHERE i2.expense_id = e.id)"
+	hasPlan := "EXISTS (SELECT 1 FROM installment_payments p2 WHERE p2.expense_id = e.id)"
+
+	convert := func(amount string) string {
+		return fmt.Sprintf("CASE WHEN e.currency = ? THEN %[1]s ELSE ROUND(%[1]s * (SELECT x.rate FROM exchange_rates x WHERE x.currency = e.currency AND x.base_currency = ? AND x.date <= e.date ORDER BY x.date DESC LIMIT 1), %[2]d) END",
+			amount, currency.MinorUnits(baseCurrency))
+	}
+	// Multiplying by 1.0 keeps SQLite from dividing whole amounts as integers
+	units := minorUnits("e.currency")
+	share := func(amount, item string) string {
+		return fmt.Sprintf("ROUND(%s * %s.amount * 1.0 / e.amount, %s)", amount, item, units)
+	}
+	allocate := func(amount string) string {
+		return fmt.Sprintf("CASE WHEN i.id = (SELECT i3.id FROM expense_items i3 WHERE i3.expense_id = e.id ORDER BY i3.amount DESC, i3.id LIMIT 1) THEN %s - COALESCE((SELECT SUM(%s) FROM expense_items i3 WHERE i3.expense_id = e.id AND i3.id <> i.id), 0) ELSE %s END",
+			amount, share(amount, "i3"), share(amount, "i"))
+	}
+	row := func(id, date, categoryID, amount, from string) string {
+		return fmt.Sprintf("SELECT %s AS id, %s AS date, e.card_id, %s AS category_id, e.payee_id, e.is_shared, e.currency, %s AS amount FROM %s",
+			id, date, categoryID, convert(amount), from)
+	}
+
+	expenses := "expenses e"
+	items := "expense_items i JOIN expenses e ON e.id = i.expense_id"
+	payments := "installment_payments p JOIN expenses e ON e.id = p.expense_id"
+	refunds := "refunds r JOIN expenses e ON e.id = r.expense_id"
+
+	var parts []string
+	if view == models.ReportViewPayment {
+		if splitItems {
+			parts = append(parts,
+				row("e.id", "e.date", "e.category_id", "e.amount", expenses+" WHERE NOT "+hasItems+" AND NOT "+hasPlan),
+				row("e.id", "e.date", "i.category_id", "i.amount", items+" WHERE NOT "+hasPlan),
+				row("e.id", "p.due_date", "e.category_id", "p.amount", payments+" WHERE NOT "+hasItems),
+				row("e.id", "p.due_date", "i.category_id", allocate("p.amount"), payments+" JOIN expense_items i ON i.expense_id = e.id"),
+			)
+		} else {
+			parts = append(parts,
+				row("e.id", "e.date", "e.category_id", "e.amount", expenses+" WHERE NOT "+hasPlan),
+				row("e.id", "p.due_date", "e.category_id", "p.amount", payments),
+			)
+		}
+	} else if splitItems {
+		parts = append(parts,
+			row("e.id", "e.date", "e.category_id", "e.amount", expenses+" WHERE NOT "+hasItems),
+			row("e.id", "e.date", "i.category_id", "i.amount", items),
+		)
+	} else {
+		parts = append(parts, row("e.id", "e.date", "e.category_id", "e.amount", expenses))
+	}
+
+	if splitItems {
+		parts = append(parts,
+			row("NULL", "r.date", "e.category_id", "(-r.amount)", refunds+" WHERE NOT "+hasItems),
+			row("NULL", "r.date", "i.category_id", "(-("+allocate("r.amount")+"))", refunds+" JOIN expense_items i ON i.expense_id = e.id"),
+		)
+	} else {
+		parts = append(parts, row("NULL", "r.date", "e.category_id", "(-r.amount)", refunds))
+	}
+
+	return "(" + strings.Join(parts, " UNION ALL ") + ") e"
+}
+
+// minorUnits returns an SQL expression for the number of decimals of the
+// currency in column.
+func minorUnits(column string) string {
+	expr := "CASE"
+	for n := 0; n < currency.MaxMinorUnits; n++ {
+		if codes := currency.WithMinorUnits(n); len(codes) > 0 {
+			expr += fmt.Sprintf(" WHEN %s IN ('%s') THEN %d", column, strings.Join(codes, "', '"), n)
+		}
+	}
+	return expr + fmt.Sprintf(" ELSE %d END", currency.MaxMinorUnits)
+}
+
+// reportTable starts a query on reportSource with the base currency bound.
+func (r *expenseRepository) reportTable(view string, splitItems bool, baseCurrency string) *gorm.DB {
+	source := reportSource(view, splitItems, baseCurrency)
+	args := make([]interface{}, strings.Count(source, "?"))
+	for i := range args {
+		args[i] = baseCurrency
+	}
+	return r.db.Table(source, args...)
+}
+
+// checkExchangeRates returns a *MissingExchangeRateError when an amount in
+// scope cannot be converted to the base currency.
+func (r *expenseRepository) checkExchangeRates(scope func(*gorm.DB) *gorm.DB, view, baseCurrency string) error {
+	var missing []string
+	err := scope(r.reportTable(view, false, baseCurrency)).
+		Where("e.amount IS NULL").
+		Distinct("e.currency").
+		Order("e.currency").
+		Pluck("e.currency", &missing).Error
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return &MissingExchangeRateError{BaseCurrency: baseCurrency, Currencies: missing}
+	}
+	return nil
+}
+
+func (r *expenseRepository) Create(ctx context.Context, expense *models.Expense) error {
+	r = r.withContext(ctx)
 	return r.db.Create(expense).Error
 }
 
-func (r *expenseRepository) GetByID(id uuid.UUID) (*models.Expense, error) {
+func (r *expenseRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Expense, error) {
+	r = r.withContext(ctx)
 	var expense models.Expense
-	err := r.db.Preload("Card").Preload("Category").Where("id = ?", id).First(&expense).Error
+	err := r.db.Preload("Card").Preload("Category").Preload("Payee").Preload("Items").Preload("Items.Category").Preload("Refunds").Where("id = ?", id).First(&expense).Error
 	if err != nil {
 		return nil, err
 	}
 	return &expense, nil
 }
 
-func (r *expenseRepository) GetByIDWithoutPreload(id uuid.UUID) (*models.Expense, error) {
+func (r *expenseRepository) GetByIDWithoutPreload(ctx context.Context, id uuid.UUID) (*models.Expense, error) {
+	r = r.withContext(ctx)
 	var expense models.Expense
 	err := r.db.Where("id = ?", id).First(&expense).Error
 	if err != nil {
@@ -38,11 +183,24 @@ func (r *expenseRepository) GetByIDWithoutPreload(id uuid.UUID) (*models.Expense
 	return &expense, nil
 }
 
-func (r *expenseRepository) GetAll(filters *models.ExpenseFilters) ([]models.Expense, int, error) {
+// Lock returns the expense without its relations and locks it until the end
+// of the transaction, so that its amount and refunds do not change meanwhile.
+func (r *expenseRepository) Lock(ctx context.Context, id uuid.UUID) (*models.Expense, error) {
+	r = r.withContext(ctx)
+	var expense models.Expense
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&expense).Error
+	if err != nil {
+		return nil, err
+	}
+	return &expense, nil
+}
+
+func (r *expenseRepository) GetAll(ctx context.Context, filters *models.ExpenseFilters) ([]models.Expense, int, error) {
+	r = r.withContext(ctx)
 	var expenses []models.Expense
 	var totalCount int64
 
-	query := r.db.Model(&models.Expense{}).Preload("Card").Preload("Category")
+	query := r.db.Model(&models.Expense{}).Preload("Card").Preload("Category").Preload("Payee").Preload("Items").Preload("Items.Category").Preload("Refunds")
 
 	// Apply filters
 	if filters.StartDate != nil {
@@ -55,7 +213,11 @@ func (r *expenseRepository) GetAll(filters *models.ExpenseFilters) ([]models.Exp
 		query = query.Where("card_id = ?", filters.CardID)
 	}
 	if filters.CategoryID != nil {
-		query = query.Where("category_id = ?", filters.CategoryID)
+		// Split expenses match when any of their items is in the category
+		query = query.Where("category_id = ? OR EXISTS (SELECT 1 FROM expense_items i WHERE i.expense_id = expenses.id AND i.category_id = ?)", filters.CategoryID, filters.CategoryID)
+	}
+	if filters.PayeeID != nil {
+		query = query.Where("payee_id = ?", filters.PayeeID)
 	}
 
 	// Count total records
@@ -77,17 +239,56 @@ func (r *expenseRepository) GetAll(filters *models.ExpenseFilters) ([]models.Exp
 	return expenses, int(totalCount), err
 }
 
-func (r *expenseRepository) Update(expense *models.Expense) error {
-	return r.db.Save(expense).Error
+// GetHistory returns every expense, without related data, oldest first.
+func (r *expenseRepository) GetHistory(ctx context.Context) ([]models.Expense, error) {
+	r = r.withContext(ctx)
+	var expenses []models.Expense
+	err := r.db.Order("date ASC, id ASC").Find(&expenses).Error
+	return expenses, err
 }
 
-func (r *expenseRepository) Delete(id uuid.UUID) error {
-	return r.db.Delete(&models.Expense{}, id).Error
+// Update saves the expense and replaces its line items with expense.Items.
+func (r *expenseRepository) Update(ctx context.Context, expense *models.Expense) error {
+	r = r.withContext(ctx)
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Items", "Refunds").Save(expense).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("expense_id = ?", expense.ID).Delete(&models.ExpenseItem{}).Error; err != nil {
+			return err
+		}
+		if len(expense.Items) == 0 {
+			return nil
+		}
+		for i := range expense.Items {
+			expense.Items[i].ExpenseID = expense.ID
+		}
+		return tx.Create(&expense.Items).Error
+	})
 }
 
-func (r *expenseRepository) GetMonthlyReport(filters *models.ReportFilters) (*models.MonthlyReport, error) {
+func (r *expenseRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r = r.withContext(ctx)
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expense_id = ?", id).Delete(&models.ExpenseItem{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("expense_id = ?", id).Delete(&models.Refund{}).Error; err != nil {
+			return err
+		}
+		if err := deleteInstallmentPlan(tx, id); err != nil {
+			return err
+		}
+		return tx.Delete(&models.Expense{}, id).Error
+	})
+}
+
+func (r *expenseRepository) GetMonthlyReport(ctx context.Context, filters *models.ReportFilters) (*models.MonthlyReport, error) {
+	r = r.withContext(ctx)
 	var report models.MonthlyReport
 	report.Year = filters.Year
+	report.View = reportView(filters)
+	report.Currency = reportCurrency(filters)
 	
 	// Month is now required
 	if filters.Month == nil {
@@ -95,152 +296,626 @@ func (r *expenseRepository) GetMonthlyReport(filters *models.ReportFilters) (*mo
 	}
 	report.Month = *filters.Month
 
-	// Base query for the month
-	baseQuery := r.db.Where("EXTRACT(YEAR FROM date) = ?", filters.Year)
-	
-	if filters.Month != nil {
-		baseQuery = baseQuery.Where("EXTRACT(MONTH FROM date) = ?", *filters.Month)
+	start := time.Date(filters.Year, time.Month(*filters.Month), 1, 0, 0, 0, 0, time.UTC)
+	scope := reportScope(start, start.AddDate(0, 1, 0), filters.CardID)
+
+	if err := r.checkExchangeRates(scope, report.View, report.Currency); err != nil {
+		return nil, err
 	}
 
-	if filters.CardID != nil {
-		baseQuery = baseQuery.Where("card_id = ?", filters.CardID)
+	breakdown, err := r.reportBreakdown(scope, report.View, report.Currency, filters.CardID == nil)
+	if err != nil {
+		return nil, err
 	}
+	report.TotalAmount = breakdown.total
+	report.ByCategory = breakdown.byCategory
+	report.SharedExpenses = sharedExpensesSummary(breakdown.byCategory, report.Currency, filters.RemainderMember)
+	report.ByCard = breakdown.byCard
 
-	// Get total amount
-	var totalAmount float64
-	err := baseQuery.Model(&models.Expense{}).Select("COALESCE(SUM(amount), 0)").Scan(&totalAmount).Error
+	report.ByPayee, err = r.topPayees(scope, report.View, report.Currency)
 	if err != nil {
 		return nil, err
 	}
-	report.TotalAmount = totalAmount
 
-	// Get expenses by category using separate query
-	var categoryExpenses []models.CategoryExpenseSum
-	categoryQuery := r.db.Table("expenses e").
-		Select("c.id as category_id, c.name as category_name, c.color, c.is_shared, COALESCE(SUM(e.amount), 0) as total_amount, COUNT(e.id) as count").
-		Joins("JOIN categories c ON e.category_id = c.id").
-		Where("EXTRACT(YEAR FROM e.date) = ?", filters.Year)
-	
-	if filters.Month != nil {
-		categoryQuery = categoryQuery.Where("EXTRACT(MONTH FROM e.date) = ?", *filters.Month)
+	return &report, nil
+}
+
+func (r *expenseRepository) GetYearlyReport(ctx context.Context, filters *models.ReportFilters) (*models.YearlyReport, error) {
+	r = r.withContext(ctx)
+	var report models.YearlyReport
+	report.Year = filters.Year
+	report.View = reportView(filters)
+	report.Currency = reportCurrency(filters)
+
+	start := time.Date(filters.Year, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(1, 0, 0)
+	scope := reportScope(start, end, filters.CardID)
+
+	if err := r.checkExchangeRates(scope, report.View, report.Currency); err != nil {
+		return nil, err
 	}
-	if filters.CardID != nil {
-		categoryQuery = categoryQuery.Where("e.card_id = ?", filters.CardID)
+
+	breakdown, err := r.reportBreakdown(scope, report.View, report.Currency, filters.CardID == nil)
+	if err != nil {
+		return nil, err
 	}
-	
-	err = categoryQuery.Group("c.id, c.name, c.color, c.is_shared").Scan(&categoryExpenses).Error
+	report.TotalAmount = breakdown.total
+	report.ByCategory = breakdown.byCategory
+	report.ByCard = breakdown.byCard
+
+	report.ByPayee, err = r.topPayees(scope, report.View, report.Currency)
 	if err != nil {
 		return nil, err
 	}
-	report.ByCategory = categoryExpenses
 
-	// Calculate shared expenses summary
-	var sharedCategories []models.CategoryExpenseSum
-	var totalSharedAmount float64
-	
-	for _, category := range categoryExpenses {
-		if category.IsShared {
-			sharedCategories = append(sharedCategories, category)
-			totalSharedAmount += category.TotalAmount
+	// Get monthly breakdown, leaving out months without any expenses
+	months, err := reports.Periods(start, end.AddDate(0, 0, -1), models.ReportGroupByMonth)
+	if err != nil {
+		return nil, err
+	}
+	monthlyTotals, err := r.periodTotals(scope, report.View, report.Currency, months)
+	if err != nil {
+		return nil, err
+	}
+	report.MonthlyData = []models.MonthlyExpenseSum{}
+	for i, month := range monthlyTotals {
+		if month.Count == 0 && month.TotalAmount == 0 {
+			continue
 		}
+		report.MonthlyData = append(report.MonthlyData, models.MonthlyExpenseSum{
+			Year:        months[i].Start.Year(),
+			Month:       int(months[i].Start.Month()),
+			TotalAmount: month.TotalAmount,
+			Count:       month.Count,
+		})
 	}
-	
-	report.SharedExpenses = models.SharedExpensesSummary{
-		TotalSharedAmount: totalSharedAmount,
-		SplitAmount:       totalSharedAmount / 2.0,
-		Categories:        sharedCategories,
+
+	return &report, nil
+}
+
+func (r *expenseRepository) GetRangeReport(ctx context.Context, filters *models.ReportFilters) (*models.RangeReport, error) {
+	r = r.withContext(ctx)
+	if filters.StartDate == nil || filters.EndDate == nil {
+		return nil, fmt.Errorf("start and end dates are required for range report")
 	}
 
-	// Get expenses by card (if not filtered by card)
-	if filters.CardID == nil {
-		var cardExpenses []models.CardExpenseSum
-		cardQuery := r.db.Table("expenses e").
-			Select("cd.id as card_id, cd.name as card_name, cd.color, COALESCE(SUM(e.amount), 0) as total_amount, COUNT(e.id) as count").
-			Joins("JOIN cards cd ON e.card_id = cd.id").
-			Where("EXTRACT(YEAR FROM e.date) = ?", filters.Year)
-		
-		if filters.Month != nil {
-			cardQuery = cardQuery.Where("EXTRACT(MONTH FROM e.date) = ?", *filters.Month)
+	var report models.RangeReport
+	report.GroupBy = filters.GroupBy
+	if report.GroupBy == "" {
+		report.GroupBy = models.ReportGroupByMonth
+	}
+	report.View = reportView(filters)
+	report.Currency = reportCurrency(filters)
+
+	periods, err := reports.Periods(*filters.StartDate, *filters.EndDate, report.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+	first, last := periods[0].Start, periods[len(periods)-1].End
+	report.From = first.Format(reports.DateLayout)
+	report.To = last.Format(reports.DateLayout)
+	scope := reportScope(first, last.AddDate(0, 0, 1), filters.CardID)
+
+	if err := r.checkExchangeRates(scope, report.View, report.Currency); err != nil {
+		return nil, err
+	}
+
+	breakdown, err := r.reportBreakdown(scope, report.View, report.Currency, filters.CardID == nil)
+	if err != nil {
+		return nil, err
+	}
+	report.TotalAmount = breakdown.total
+	report.ByCategory = breakdown.byCategory
+	report.SharedExpenses = sharedExpensesSummary(breakdown.byCategory, report.Currency, filters.RemainderMember)
+	report.ByCard = breakdown.byCard
+
+	report.Periods, err = r.periodTotals(scope, report.View, report.Currency, periods)
+	if err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+func (r *expenseRepository) GetComparisonReport(ctx context.Context, filters *models.ReportFilters) (*models.ComparisonReport, error) {
+	r = r.withContext(ctx)
+	if filters.StartDate == nil || filters.EndDate == nil || filters.CompareStartDate == nil || filters.CompareEndDate == nil {
+		return nil, fmt.Errorf("both periods are required for comparison report")
+	}
+
+	var report models.ComparisonReport
+	report.View = reportView(filters)
+	report.Currency = reportCurrency(filters)
+
+	// Both periods are aggregated the same way as a monthly report
+	summarize := func(start, end time.Time, period *models.ReportPeriod) (*reportSums, error) {
+		from := reports.PeriodStart(start, models.ReportGroupByDay)
+		to := reports.PeriodStart(end, models.ReportGroupByDay)
+		scope := reportScope(from, to.AddDate(0, 0, 1), filters.CardID)
+
+		if err := r.checkExchangeRates(scope, report.View, report.Currency); err != nil {
+			return nil, err
 		}
-		
-		err = cardQuery.Group("cd.id, cd.name, cd.color").Scan(&cardExpenses).Error
+
+		breakdown, err := r.reportBreakdown(scope, report.View, report.Currency, filters.CardID == nil)
 		if err != nil {
 			return nil, err
 		}
-		report.ByCard = cardExpenses
+		*period = models.ReportPeriod{
+			From:        from.Format(reports.DateLayout),
+			To:          to.Format(reports.DateLayout),
+			TotalAmount: breakdown.total,
+		}
+		return breakdown, nil
 	}
 
+	current, err := summarize(*filters.StartDate, *filters.EndDate, &report.Current)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := summarize(*filters.CompareStartDate, *filters.CompareEndDate, &report.Previous)
+	if err != nil {
+		return nil, err
+	}
+
+	report.Change = report.Current.TotalAmount - report.Previous.TotalAmount
+	report.ChangePercent = reports.ChangePercent(report.Current.TotalAmount, report.Previous.TotalAmount)
+	report.ByCategory, report.NewCategories, report.DisappearedCategories = reports.CompareCategories(current.byCategory, previous.byCategory)
+	report.ByCard = reports.CompareCards(current.byCard, previous.byCard)
+
 	return &report, nil
 }
 
-func (r *expenseRepository) GetYearlyReport(filters *models.ReportFilters) (*models.YearlyReport, error) {
-	var report models.YearlyReport
-	report.Year = filters.Year
+func (r *expenseRepository) GetForecastReport(ctx context.Context, filters *models.ReportFilters) (*models.ForecastReport, error) {
+	r = r.withContext(ctx)
+	asOf := time.Now()
+	if filters.AsOf != nil {
+		asOf = *filters.AsOf
+	}
+	asOf = reports.PeriodStart(asOf, models.ReportGroupByDay)
 
-	// Base query for the year
-	baseQuery := r.db.Where("EXTRACT(YEAR FROM date) = ?", filters.Year)
-	
-	if filters.CardID != nil {
-		baseQuery = baseQuery.Where("card_id = ?", filters.CardID)
+	var report models.ForecastReport
+	report.AsOf = asOf.Format(reports.DateLayout)
+	report.View = reportView(filters)
+	report.Currency = reportCurrency(filters)
+
+	scope := reportScope(reports.ForecastStart(asOf), reports.ForecastEnd(asOf).AddDate(0, 0, 1), filters.CardID)
+
+	if err := r.checkExchangeRates(scope, report.View, report.Currency); err != nil {
+		return nil, err
 	}
 
-	// Get total amount for the year
-	var totalAmount float64
-	err := baseQuery.Model(&models.Expense{}).Select("COALESCE(SUM(amount), 0)").Scan(&totalAmount).Error
+	// Rows are split by item category and joined back to their expense for
+	// the purchase date and description used to find recurring charges
+	var rows []struct {
+		ID           *uuid.UUID
+		Date         reportDate
+		PurchaseDate reportDate
+		CategoryID   uuid.UUID
+		CategoryName string
+		Color        string
+		CardID       uuid.UUID
+		Description  string
+		Amount       money.Amount
+	}
+	err := scope(r.reportTable(report.View, true, report.Currency)).
+		Select("e.id, e.date, COALESCE(x.date, e.date) as purchase_date, e.category_id, c.name as category_name, c.color, e.card_id, COALESCE(x.description, '') as description, e.amount").
+		Joins("JOIN categories c ON e.category_id = c.id").
+		Joins("LEFT JOIN expenses x ON x.id = e.id").
+		Order("e.date").
+		Scan(&rows).Error
 	if err != nil {
 		return nil, err
 	}
-	report.TotalAmount = totalAmount
 
-	// Get monthly breakdown using separate query
-	var monthlyData []models.MonthlyExpenseSum
-	monthlyQuery := r.db.Table("expenses e").
-		Select("EXTRACT(YEAR FROM e.date) as year, EXTRACT(MONTH FROM e.date) as month, COALESCE(SUM(e.amount), 0) as total_amount, COUNT(e.id) as count").
-		Where("EXTRACT(YEAR FROM e.date) = ?", filters.Year)
-	
-	if filters.CardID != nil {
-		monthlyQuery = monthlyQuery.Where("e.card_id = ?", filters.CardID)
+	forecastRows := make([]reports.ForecastRow, len(rows))
+	for i, row := range rows {
+		forecastRows[i] = reports.ForecastRow{
+			ExpenseID:    row.ID,
+			Date:         reports.PeriodStart(time.Time(row.Date), models.ReportGroupByDay),
+			PurchaseDate: reports.PeriodStart(time.Time(row.PurchaseDate), models.ReportGroupByDay),
+			CategoryID:   row.CategoryID,
+			CategoryName: row.CategoryName,
+			Color:        row.Color,
+			CardID:       row.CardID,
+			Description:  row.Description,
+			Amount:       row.Amount,
+		}
 	}
-	
-	err = monthlyQuery.Group("EXTRACT(YEAR FROM e.date), EXTRACT(MONTH FROM e.date)").
-		Order("month").
-		Scan(&monthlyData).Error
+
+	report.Month, report.Year, report.ByCategory = reports.Forecast(asOf, forecastRows, report.Currency)
+
+	return &report, nil
+}
+
+func (r *expenseRepository) GetDailyReport(ctx context.Context, filters *models.ReportFilters) (*models.DailyReport, error) {
+	r = r.withContext(ctx)
+	var report models.DailyReport
+	report.Year = filters.Year
+	report.Month = filters.Month
+	report.View = reportView(filters)
+	report.Currency = reportCurrency(filters)
+
+	from := time.Date(filters.Year, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(1, 0, -1)
+	if filters.Month != nil {
+		from = time.Date(filters.Year, time.Month(*filters.Month), 1, 0, 0, 0, 0, time.UTC)
+		to = from.AddDate(0, 1, -1)
+	}
+	report.From = from.Format(reports.DateLayout)
+	report.To = to.Format(reports.DateLayout)
+	scope := reportScope(from, to.AddDate(0, 0, 1), filters.CardID)
+
+	if err := r.checkExchangeRates(scope, report.View, report.Currency); err != nil {
+		return nil, err
+	}
+
+	// Rows are split by item category for the top category of each day and
+	// the category filter
+	query := scope(r.reportTable(report.View, true, report.Currency)).
+		Select("e.id, e.date, e.category_id, c.name as category_name, c.color, e.amount").
+		Joins("JOIN categories c ON e.category_id = c.id")
+	if filters.CategoryID != nil {
+		query = query.Where("e.category_id = ?", filters.CategoryID)
+	}
+	var rows []struct {
+		ID           *uuid.UUID
+		Date         reportDate
+		CategoryID   uuid.UUID
+		CategoryName string
+		Color        string
+		Amount       money.Amount
+	}
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	dailyRows := make([]reports.DailyRow, len(rows))
+	for i, row := range rows {
+		dailyRows[i] = reports.DailyRow{
+			ExpenseID:    row.ID,
+			Date:         time.Time(row.Date),
+			CategoryID:   row.CategoryID,
+			CategoryName: row.CategoryName,
+			Color:        row.Color,
+			Amount:       row.Amount,
+		}
+	}
+
+	report.Days, report.ByWeekday, report.ByDayOfMonth = reports.Daily(from, to, dailyRows, report.Currency)
+	for _, day := range report.Days {
+		report.TotalAmount += day.TotalAmount
+		if day.TotalAmount > report.MaxAmount {
+			report.MaxAmount = day.TotalAmount
+		}
+	}
+
+	return &report, nil
+}
+
+func (r *expenseRepository) GetAnomalies(ctx context.Context, filters *models.AnomalyFilters) (*models.AnomalyReport, error) {
+	r = r.withContext(ctx)
+	from := reports.PeriodStart(filters.StartDate, models.ReportGroupByDay)
+	to := reports.PeriodStart(filters.EndDate, models.ReportGroupByDay)
+
+	var report models.AnomalyReport
+	report.From = from.Format(reports.DateLayout)
+	report.To = to.Format(reports.DateLayout)
+	report.Currency = filters.Currency
+	if report.Currency == "" {
+		report.Currency = currency.Default
+	}
+
+	scope := reportScope(insights.HistoryStart(from), to.AddDate(0, 0, 1), filters.CardID)
+
+	if err := r.checkExchangeRates(scope, models.ReportViewPurchase, report.Currency); err != nil {
+		return nil, err
+	}
+
+	// Expenses are compared whole, on their purchase date
+	var chargeRows []struct {
+		ID           uuid.UUID
+		Date         reportDate
+		Description  string
+		CardID       uuid.UUID
+		CardName     string
+		CategoryID   uuid.UUID
+		CategoryName string
+		Amount       money.Amount
+	}
+	err := scope(r.reportTable(models.ReportViewPurchase, false, report.Currency)).
+		Select("e.id, e.date, x.description, e.card_id, k.name as card_name, e.category_id, c.name as category_name, e.amount").
+		Joins("JOIN expenses x ON x.id = e.id").
+		Joins("JOIN cards k ON e.card_id = k.id").
+		Joins("JOIN categories c ON e.category_id = c.id").
+		Order("e.date").
+		Scan(&chargeRows).Error
 	if err != nil {
 		return nil, err
 	}
-	report.MonthlyData = monthlyData
 
-	// Get expenses by category using separate query
-	var categoryExpenses []models.CategoryExpenseSum
-	categoryQuery := r.db.Table("expenses e").
-		Select("c.id as category_id, c.name as category_name, c.color, c.is_shared, COALESCE(SUM(e.amount), 0) as total_amount, COUNT(e.id) as count").
+	// Monthly spending is split by item category and net of refunds
+	var spendingRows []struct {
+		Date         reportDate
+		CategoryID   uuid.UUID
+		CategoryName string
+		Amount       money.Amount
+	}
+	err = scope(r.reportTable(models.ReportViewPurchase, true, report.Currency)).
+		Select("e.date, e.category_id, c.name as category_name, e.amount").
 		Joins("JOIN categories c ON e.category_id = c.id").
-		Where("EXTRACT(YEAR FROM e.date) = ?", filters.Year)
-	
-	if filters.CardID != nil {
-		categoryQuery = categoryQuery.Where("e.card_id = ?", filters.CardID)
+		Order("e.date").
+		Scan(&spendingRows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	charges := make([]insights.Charge, len(chargeRows))
+	for i, row := range chargeRows {
+		charges[i] = insights.Charge{
+			ExpenseID:    row.ID,
+			Date:         time.Time(row.Date),
+			Description:  row.Description,
+			CardID:       row.CardID,
+			CardName:     row.CardName,
+			CategoryID:   row.CategoryID,
+			CategoryName: row.CategoryName,
+			Amount:       row.Amount,
+		}
+	}
+	spending := make([]insights.Spending, len(spendingRows))
+	for i, row := range spendingRows {
+		spending[i] = insights.Spending{
+			Date:         time.Time(row.Date),
+			CategoryID:   row.CategoryID,
+			CategoryName: row.CategoryName,
+			Amount:       row.Amount,
+		}
 	}
+
+	report.Anomalies = insights.Detect(charges, spending, from, to, report.Currency)
+
+	if filters.ExpenseID != nil {
+		expense, err := r.GetByID(ctx, *filters.ExpenseID)
+		if err != nil {
+			return nil, err
+		}
+		categories := map[uuid.UUID]bool{expense.CategoryID: true}
+		for _, item := range expense.Items {
+			categories[item.CategoryID] = true
+		}
+
+		anomalies := []models.Anomaly{}
+		for _, anomaly := range report.Anomalies {
+			if anomaly.ExpenseID != nil && *anomaly.ExpenseID == expense.ID ||
+				anomaly.Type == models.AnomalyTypeCategorySpike && categories[anomaly.CategoryID] {
+				anomalies = append(anomalies, anomaly)
+			}
+		}
+		report.Anomalies = anomalies
+	}
+
+	return &report, nil
+}
+
+// GetSpendingTotal returns the spending from StartDate through EndDate in
+// the base currency, limited to CardID and CategoryID when they are set.
+func (r *expenseRepository) GetSpendingTotal(ctx context.Context, filters *models.ReportFilters) (money.Amount, error) {
+	r = r.withContext(ctx)
+	view := reportView(filters)
+	baseCurrency := reportCurrency(filters)
+	scope := reportScope(*filters.StartDate, filters.EndDate.AddDate(0, 0, 1), filters.CardID)
+
+	if err := r.checkExchangeRates(scope, view, baseCurrency); err != nil {
+		return 0, err
+	}
+
+	// Split expenses count toward a category with their items in it only
+	query := scope(r.reportTable(view, filters.CategoryID != nil, baseCurrency))
+	if filters.CategoryID != nil {
+		query = query.Where("e.category_id = ?", filters.CategoryID)
+	}
+	var total money.Amount
+	err := query.Select("COALESCE(SUM(e.amount), 0)").Scan(&total).Error
+	return total, err
+}
+
+// reportScope restricts a query on the report source to the days from start
+// up to but not including end, and to one card if cardID is set. Dates are
+// compared as plain ranges so that the date indexes can be used.
+func reportScope(start, end time.Time, cardID *uuid.UUID) func(*gorm.DB) *gorm.DB {
+	return func(query *gorm.DB) *gorm.DB {
+		query = query.Where("e.date >= ? AND e.date < ?", start.Format(reports.DateLayout), end.Format(reports.DateLayout))
+		if cardID != nil {
+			query = query.Where("e.card_id = ?", cardID)
+		}
+		return query
+	}
+}
+
+// reportSums holds the totals shared by all report types.
+type reportSums struct {
+	total      money.Amount
+	byCategory []models.CategoryExpenseSum
+	byCard     []models.CardExpenseSum
+}
+
+// reportBreakdown sums the report source in scope, by category and, unless
+// byCard is false, by card.
+func (r *expenseRepository) reportBreakdown(scope func(*gorm.DB) *gorm.DB, view, baseCurrency string, byCard bool) (*reportSums, error) {
+	var breakdown reportSums
+
+	// Get total amount
+	err := scope(r.reportTable(view, false, baseCurrency)).Select("COALESCE(SUM(e.amount), 0)").Scan(&breakdown.total).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Get expenses by category using separate query
+	categoryQuery := scope(r.reportTable(view, true, baseCurrency)).
+		Select("c.id as category_id, c.name as category_name, c.color, c.is_shared, COALESCE(SUM(e.amount), 0) as total_amount, COALESCE(SUM(CASE WHEN COALESCE(e.is_shared, c.is_shared) THEN e.amount ELSE 0 END), 0) as shared_amount, COUNT(DISTINCT e.id) as count").
+		Joins("JOIN categories c ON e.category_id = c.id")
 	
-	err = categoryQuery.Group("c.id, c.name, c.color, c.is_shared").Scan(&categoryExpenses).Error
+	err = categoryQuery.Group("c.id, c.name, c.color, c.is_shared").Scan(&breakdown.byCategory).Error
 	if err != nil {
 		return nil, err
 	}
-	report.ByCategory = categoryExpenses
 
-	// Get expenses by card (if not filtered by card)
-	if filters.CardID == nil {
-		var cardExpenses []models.CardExpenseSum
-		cardQuery := r.db.Table("expenses e").
-			Select("cd.id as card_id, cd.name as card_name, cd.color, COALESCE(SUM(e.amount), 0) as total_amount, COUNT(e.id) as count").
-			Joins("JOIN cards cd ON e.card_id = cd.id").
-			Where("EXTRACT(YEAR FROM e.date) = ?", filters.Year)
+	// Get expenses by card
+	if byCard {
+		cardQuery := scope(r.reportTable(view, false, baseCurrency)).
+			Select("cd.id as card_id, cd.name as card_name, cd.color, COALESCE(SUM(e.amount), 0) as total_amount, COUNT(DISTINCT e.id) as count").
+			Joins("JOIN cards cd ON e.card_id = cd.id")
 		
-		err = cardQuery.Group("cd.id, cd.name, cd.color").Scan(&cardExpenses).Error
+		err = cardQuery.Group("cd.id, cd.name, cd.color").Scan(&breakdown.byCard).Error
 		if err != nil {
 			return nil, err
 		}
-		report.ByCard = cardExpenses
 	}
 
-	return &report, nil
-}
\ No newline at end of file
+	return &breakdown, nil
+}
+
+// topPayeeCount is the number of payees listed in reports.
+const topPayeeCount = 10
+
+// topPayees returns the payees spent most on in scope, net of refunds.
+// Expenses without a payee are left out.
+func (r *expenseRepository) topPayees(scope func(*gorm.DB) *gorm.DB, view, baseCurrency string) ([]models.PayeeExpenseSum, error) {
+	byPayee := []models.PayeeExpenseSum{}
+	err := scope(r.reportTable(view, false, baseCurrency)).
+		Select("p.id as payee_id, p.name as payee_name, COALESCE(SUM(e.amount), 0) as total_amount, COUNT(DISTINCT e.id) as count").
+		Joins("JOIN payees p ON e.payee_id = p.id").
+		Group("p.id, p.name").
+		Order("total_amount DESC, p.name").
+		Limit(topPayeeCount).
+		Scan(&byPayee).Error
+	return byPayee, err
+}
+
+// sharedExpensesSummary totals the shared spending of the categories and
+// splits the total between the members in whole units of the report
+// currency. The odd yen goes to remainderMember and SplitAmount is everyone
+// else's share. Categories are listed when they have shared spending, which
+// expenses marked as shared can give a category that is not.
+func sharedExpensesSummary(categories []models.CategoryExpenseSum, baseCurrency string, remainderMember int) models.SharedExpensesSummary {
+	var sharedCategories []models.CategoryExpenseSum
+	var totalSharedAmount money.Amount
+	
+	for _, category := range categories {
+		if category.IsShared || category.SharedAmount != 0 {
+			sharedCategories = append(sharedCategories, category)
+			totalSharedAmount += category.SharedAmount
+		}
+	}
+	
+	shares := totalSharedAmount.Split(models.SharedExpenseMembers, baseCurrency, remainderMember)
+	
+	return models.SharedExpensesSummary{
+		TotalSharedAmount: totalSharedAmount,
+		SplitAmount:       shares[(remainderMember+1)%len(shares)],
+		Shares:            shares,
+		Categories:        sharedCategories,
+	}
+}
+
+// periodTotals sums the report source in scope into periods, which must be
+// sorted and cover the scope. Rows are bucketed here rather than grouped in
+// SQL so that the same code works for every grouping and database.
+func (r *expenseRepository) periodTotals(scope func(*gorm.DB) *gorm.DB, view, baseCurrency string, periods []reports.Period) ([]models.PeriodExpenseSum, error) {
+	var rows []struct {
+		ID     *uuid.UUID
+		Date   reportDate
+		Amount money.Amount
+	}
+	if err := scope(r.reportTable(view, false, baseCurrency)).Select("e.id, e.date, e.amount").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	totals := make([]models.PeriodExpenseSum, len(periods))
+	counted := make([]map[uuid.UUID]bool, len(periods))
+	for i, period := range periods {
+		totals[i] = models.PeriodExpenseSum{
+			Start: period.Start.Format(reports.DateLayout),
+			End:   period.End.Format(reports.DateLayout),
+		}
+		counted[i] = map[uuid.UUID]bool{}
+	}
+
+	for _, row := range rows {
+		date := reports.PeriodStart(time.Time(row.Date), models.ReportGroupByDay)
+		i := sort.Search(len(periods), func(i int) bool { return periods[i].Start.After(date) }) - 1
+		if i < 0 || date.After(periods[i].End) {
+			continue
+		}
+
+		totals[i].TotalAmount += row.Amount
+		// Refunds have no ID and are not counted as expenses
+		if row.ID != nil && !counted[i][*row.ID] {
+			counted[i][*row.ID] = true
+			totals[i].Count++
+		}
+	}
+
+	return totals, nil
+}
+
+// reportDate scans dates from the report source. PostgreSQL returns them as
+// time.Time, while SQLite returns the text it stored once the column has
+// passed through a UNION.
+type reportDate time.Time
+
+var reportDateLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+	reports.DateLayout,
+}
+
+func (d *reportDate) Scan(src interface{}) error {
+	var text string
+	switch v := src.(type) {
+	case time.Time:
+		*d = reportDate(v)
+		return nil
+	case []byte:
+		text = string(v)
+	case string:
+		text = v
+	default:
+		return fmt.Errorf("cannot scan %T into a report date", src)
+	}
+
+	for _, layout := range reportDateLayouts {
+		if t, err := time.Parse(layout, text); err == nil {
+			*d = reportDate(t)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid report date: %q", text)
+}
+
+func reportView(filters *models.ReportFilters) string {
+	if filters.View == "" {
+		return models.ReportViewPurchase
+	}
+	return filters.View
+}
+
+func reportCurrency(filters *models.ReportFilters) string {
+	if filters.Currency == "" {
+		return currency.Default
+	}
+	return filters.Currency
+}
+
+// MissingExchangeRateError reports currencies that have expenses in a report
+// but no exchange rate to the base currency on or before the expense date.
+type MissingExchangeRateError struct {
+	BaseCurrency string
+	Currencies   []string
+}
+
+func (e *MissingExchangeRateError) Error() string {
+	return fmt.Sprintf("no exchange rate to %s for %s", e.BaseCurrency, strings.Join(e.Currencies, ", "))
+}
@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type installmentRepository struct {
+	db *gorm.DB
+}
+
+func NewInstallmentRepository(db *gorm.DB) InstallmentRepository {
+	translateErrors(db)
+	return &installmentRepository{db: db}
+}
+
+func (r *installmentRepository) GetByExpenseID(ctx context.Context, expenseID uuid.UUID) (*models.InstallmentPlan, error) {
+	var plan models.InstallmentPlan
+	err := r.db.WithContext(ctx).Preload("Payments", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sequence ASC")
+	}).Where("expense_id = ?", expenseID).First(&plan).Error
+	if err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// Save stores the plan and its schedule, replacing any previous plan of the expense.
+func (r *installmentRepository) Save(ctx context.Context, plan *models.InstallmentPlan) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := deleteInstallmentPlan(tx, plan.ExpenseID); err != nil {
+			return err
+		}
+		return tx.Create(plan).Error
+	})
+}
+
+func (r *installmentRepository) DeleteByExpenseID(ctx context.Context, expenseID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return deleteInstallmentPlan(tx, expenseID)
+	})
+}
+
+func deleteInstallmentPlan(tx *gorm.DB, expenseID uuid.UUID) error {
+	if err := tx.Where("expense_id = ?", expenseID).Delete(&models.InstallmentPayment{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("expense_id = ?", expenseID).Delete(&models.InstallmentPlan{}).Error
+}
+
+// GetCardBalances sums the installment charges due after asOf for every card
+// and currency its expenses are in, as amounts in different currencies do not
+// add up. Charges due on asOf itself are considered paid.
+func (r *installmentRepository) GetCardBalances(ctx context.Context, asOf time.Time, cardID *uuid.UUID) ([]models.CardInstallmentBalance, error) {
+	var rows []struct {
+		CardID    uuid.UUID
+		CardName  string
+		Color     string
+		Currency  string
+		PlanID    uuid.UUID
+		DueDate   time.Time
+		Principal money.Amount
+		Amount    money.Amount
+	}
+
+	query := r.db.WithContext(ctx).Table("installment_payments p").
+		Select("cd.id as card_id, cd.name as card_name, cd.color, e.currency, p.plan_id, p.due_date, p.principal, p.amount").
+		Joins("JOIN expenses e ON e.id = p.expense_id").
+		Joins("JOIN cards cd ON cd.id = e.card_id").
+		Where("p.due_date > ?", asOf)
+	if cardID != nil {
+		query = query.Where("e.card_id = ?", cardID)
+	}
+
+	if err := query.Order("cd.name, cd.id, e.currency, p.due_date").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	type key struct {
+		cardID   uuid.UUID
+		currency string
+	}
+	balances := []models.CardInstallmentBalance{}
+	index := map[key]int{}
+	plans := map[key]map[uuid.UUID]bool{}
+	for _, row := range rows {
+		k := key{row.CardID, row.Currency}
+		i, ok := index[k]
+		if !ok {
+			i = len(balances)
+			index[k] = i
+			plans[k] = map[uuid.UUID]bool{}
+			balances = append(balances, models.CardInstallmentBalance{
+				CardID:   row.CardID,
+				CardName: row.CardName,
+				Color:    row.Color,
+				Currency: row.Currency,
+			})
+		}
+
+		balance := &balances[i]
+		plans[k][row.PlanID] = true
+		balance.ActivePlans = len(plans[k])
+		balance.RemainingPayments++
+		balance.RemainingPrincipal += row.Principal
+		balance.RemainingAmount += row.Amount
+
+		// Rows are ordered by due date, so the first date seen is the next one
+		dueDate := row.DueDate
+		if balance.NextPaymentDate == nil {
+			balance.NextPaymentDate = &dueDate
+		}
+		if balance.NextPaymentDate.Equal(dueDate) {
+			balance.NextPaymentAmount += row.Amount
+		}
+	}
+
+	return balances, nil
+}
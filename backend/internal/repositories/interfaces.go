@@ -1,41 +1,147 @@
 package repositories
 
 import (
+	"context"
+	"time"
 	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
 	"github.com/google/uuid"
 )
 
 type CardRepository interface {
-	Create(card *models.Card) error
-	GetByID(id uuid.UUID) (*models.Card, error)
-	GetAll() ([]models.Card, error)
-	Update(card *models.Card) error
-	Delete(id uuid.UUID) error
-	HasExpenses(cardID uuid.UUID) (bool, error)
+	Create(ctx context.Context, card *models.Card) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Card, error)
+	Lock(ctx context.Context, id uuid.UUID) (*models.Card, error)
+	GetAll(ctx context.Context) ([]models.Card, error)
+	Update(ctx context.Context, card *models.Card) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	HasExpenses(ctx context.Context, cardID uuid.UUID) (bool, error)
 }
 
 type CategoryRepository interface {
-	Create(category *models.Category) error
-	GetByID(id uuid.UUID) (*models.Category, error)
-	GetAll() ([]models.Category, error)
-	Update(category *models.Category) error
-	Delete(id uuid.UUID) error
-	HasExpenses(categoryID uuid.UUID) (bool, error)
+	Create(ctx context.Context, category *models.Category) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Category, error)
+	Lock(ctx context.Context, id uuid.UUID) (*models.Category, error)
+	GetAll(ctx context.Context) ([]models.Category, error)
+	Update(ctx context.Context, category *models.Category) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	HasExpenses(ctx context.Context, categoryID uuid.UUID) (bool, error)
+}
+
+type PayeeRepository interface {
+	Create(ctx context.Context, payee *models.Payee) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Payee, error)
+	GetAll(ctx context.Context) ([]models.Payee, error)
+	Update(ctx context.Context, payee *models.Payee) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	AssignExpenses(ctx context.Context, payee *models.Payee) (int, error)
+}
+
+type RuleRepository interface {
+	Create(ctx context.Context, rule *models.Rule) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Rule, error)
+	GetAll(ctx context.Context) ([]models.Rule, error)
+	Update(ctx context.Context, rule *models.Rule) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	GetExpenses(ctx context.Context, filters *models.RuleRunFilters) ([]models.Expense, error)
+	UpdateExpenses(ctx context.Context, expenses []models.Expense) error
 }
 
 type ExpenseRepository interface {
-	Create(expense *models.Expense) error
-	GetByID(id uuid.UUID) (*models.Expense, error)
-	GetByIDWithoutPreload(id uuid.UUID) (*models.Expense, error)
-	GetAll(filters *models.ExpenseFilters) ([]models.Expense, int, error)
-	Update(expense *models.Expense) error
-	Delete(id uuid.UUID) error
-	GetMonthlyReport(filters *models.ReportFilters) (*models.MonthlyReport, error)
-	GetYearlyReport(filters *models.ReportFilters) (*models.YearlyReport, error)
+	Create(ctx context.Context, expense *models.Expense) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Expense, error)
+	GetByIDWithoutPreload(ctx context.Context, id uuid.UUID) (*models.Expense, error)
+	Lock(ctx context.Context, id uuid.UUID) (*models.Expense, error)
+	GetAll(ctx context.Context, filters *models.ExpenseFilters) ([]models.Expense, int, error)
+	GetHistory(ctx context.Context) ([]models.Expense, error)
+	Update(ctx context.Context, expense *models.Expense) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	GetMonthlyReport(ctx context.Context, filters *models.ReportFilters) (*models.MonthlyReport, error)
+	GetYearlyReport(ctx context.Context, filters *models.ReportFilters) (*models.YearlyReport, error)
+	GetRangeReport(ctx context.Context, filters *models.ReportFilters) (*models.RangeReport, error)
+	GetComparisonReport(ctx context.Context, filters *models.ReportFilters) (*models.ComparisonReport, error)
+	GetForecastReport(ctx context.Context, filters *models.ReportFilters) (*models.ForecastReport, error)
+	GetDailyReport(ctx context.Context, filters *models.ReportFilters) (*models.DailyReport, error)
+	GetAnomalies(ctx context.Context, filters *models.AnomalyFilters) (*models.AnomalyReport, error)
+	GetSpendingTotal(ctx context.Context, filters *models.ReportFilters) (money.Amount, error)
+}
+
+type AttachmentRepository interface {
+	Create(ctx context.Context, attachment *models.Attachment) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Attachment, error)
+	GetByExpenseID(ctx context.Context, expenseID uuid.UUID) ([]models.Attachment, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	DeleteByExpenseID(ctx context.Context, expenseID uuid.UUID) error
+}
+
+type InstallmentRepository interface {
+	GetByExpenseID(ctx context.Context, expenseID uuid.UUID) (*models.InstallmentPlan, error)
+	Save(ctx context.Context, plan *models.InstallmentPlan) error
+	DeleteByExpenseID(ctx context.Context, expenseID uuid.UUID) error
+	GetCardBalances(ctx context.Context, asOf time.Time, cardID *uuid.UUID) ([]models.CardInstallmentBalance, error)
+}
+
+type RefundRepository interface {
+	Create(ctx context.Context, refund *models.Refund) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Refund, error)
+	GetByExpenseID(ctx context.Context, expenseID uuid.UUID) ([]models.Refund, error)
+	GetTotalByExpenseID(ctx context.Context, expenseID uuid.UUID) (money.Amount, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type BookRepository interface {
+	Get(ctx context.Context) (*models.Book, error)
+	Update(ctx context.Context, book *models.Book) error
+}
+
+type AlertRepository interface {
+	Create(ctx context.Context, alert *models.Alert) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Alert, error)
+	GetAll(ctx context.Context) ([]models.Alert, error)
+	Update(ctx context.Context, alert *models.Alert) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	SetLastTriggered(ctx context.Context, id uuid.UUID, periodStart string) error
+}
+
+type NotificationRepository interface {
+	Create(ctx context.Context, notification *models.Notification) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Notification, error)
+	GetAll(ctx context.Context, unreadOnly bool) ([]models.Notification, error)
+	MarkRead(ctx context.Context, id uuid.UUID, readAt time.Time) error
+}
+
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook *models.Webhook) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Webhook, error)
+	GetAll(ctx context.Context) ([]models.Webhook, error)
+	Update(ctx context.Context, webhook *models.Webhook) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	CreateDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) error
+	GetDueDeliveries(ctx context.Context, now time.Time, limit int) ([]models.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	GetDeliveries(ctx context.Context, filters *models.WebhookDeliveryFilters) ([]models.WebhookDelivery, error)
+}
+
+type ExchangeRateRepository interface {
+	Save(ctx context.Context, rate *models.ExchangeRate) error
+	SaveAll(ctx context.Context, rates []models.ExchangeRate) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.ExchangeRate, error)
+	GetAll(ctx context.Context, filters *models.ExchangeRateFilters) ([]models.ExchangeRate, error)
+	Delete(ctx context.Context, id uuid.UUID) error
 }
 
 type Repository struct {
-	Card     CardRepository
-	Category CategoryRepository
-	Expense  ExpenseRepository
+	Card         CardRepository
+	Category     CategoryRepository
+	Payee        PayeeRepository
+	Rule         RuleRepository
+	Expense      ExpenseRepository
+	Attachment   AttachmentRepository
+	Installment  InstallmentRepository
+	Refund       RefundRepository
+	Book         BookRepository
+	ExchangeRate ExchangeRateRepository
+	Alert        AlertRepository
+	Notification NotificationRepository
+	Webhook      WebhookRepository
 }
\ No newline at end of file
@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"context"
+	"time"
+	"kakeibo-tanuki/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	translateErrors(db)
+	return &notificationRepository{db: db}
+}
+
+func (r *notificationRepository) Create(ctx context.Context, notification *models.Notification) error {
+	return r.db.WithContext(ctx).Create(notification).Error
+}
+
+func (r *notificationRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Notification, error) {
+	var notification models.Notification
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&notification).Error
+	if err != nil {
+		return nil, err
+	}
+	return &notification, nil
+}
+
+// GetAll returns the notifications, the newest first.
+func (r *notificationRepository) GetAll(ctx context.Context, unreadOnly bool) ([]models.Notification, error) {
+	var notifications []models.Notification
+	query := r.db.WithContext(ctx).Order("created_at DESC")
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	err := query.Find(&notifications).Error
+	return notifications, err
+}
+
+func (r *notificationRepository) MarkRead(ctx context.Context, id uuid.UUID, readAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.Notification{}).Where("id = ?", id).Update("read_at", readAt).Error
+}
@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"context"
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/payees"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type payeeRepository struct {
+	db *gorm.DB
+}
+
+func NewPayeeRepository(db *gorm.DB) PayeeRepository {
+	translateErrors(db)
+	return &payeeRepository{db: db}
+}
+
+func (r *payeeRepository) Create(ctx context.Context, payee *models.Payee) error {
+	return r.db.WithContext(ctx).Create(payee).Error
+}
+
+func (r *payeeRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Payee, error) {
+	var payee models.Payee
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&payee).Error
+	if err != nil {
+		return nil, err
+	}
+	return &payee, nil
+}
+
+func (r *payeeRepository) GetAll(ctx context.Context) ([]models.Payee, error) {
+	var payees []models.Payee
+	err := r.db.WithContext(ctx).Order("name ASC").Find(&payees).Error
+	return payees, err
+}
+
+func (r *payeeRepository) Update(ctx context.Context, payee *models.Payee) error {
+	return r.db.WithContext(ctx).Save(payee).Error
+}
+
+// Delete removes the payee and unlinks its expenses, which are kept.
+func (r *payeeRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Expense{}).Where("payee_id = ?", id).UpdateColumn("payee_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Payee{}, id).Error
+	})
+}
+
+// AssignExpenses links the expenses without a payee whose description
+// matches payee to it, and returns how many were linked. Expenses already
+// linked to another payee are left alone.
+func (r *payeeRepository) AssignExpenses(ctx context.Context, payee *models.Payee) (int, error) {
+	matcher, err := payees.NewMatcher([]models.Payee{*payee})
+	if err != nil {
+		return 0, err
+	}
+
+	var unassigned []struct {
+		ID          uuid.UUID
+		Description string
+	}
+	err = r.db.WithContext(ctx).Model(&models.Expense{}).
+		Select("id, description").
+		Where("payee_id IS NULL AND description IS NOT NULL AND description <> ''").
+		Scan(&unassigned).Error
+	if err != nil {
+		return 0, err
+	}
+
+	var ids []uuid.UUID
+	for _, expense := range unassigned {
+		if matcher.Match(expense.Description) != nil {
+			ids = append(ids, expense.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	err = r.db.WithContext(ctx).Model(&models.Expense{}).Where("id IN ?", ids).UpdateColumn("payee_id", payee.ID).Error
+	return len(ids), err
+}
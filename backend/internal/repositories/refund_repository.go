@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"context"
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type refundRepository struct {
+	db *gorm.DB
+}
+
+func NewRefundRepository(db *gorm.DB) RefundRepository {
+	translateErrors(db)
+	return &refundRepository{db: db}
+}
+
+func (r *refundRepository) Create(ctx context.Context, refund *models.Refund) error {
+	return r.db.WithContext(ctx).Create(refund).Error
+}
+
+func (r *refundRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Refund, error) {
+	var refund models.Refund
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&refund).Error
+	if err != nil {
+		return nil, err
+	}
+	return &refund, nil
+}
+
+func (r *refundRepository) GetByExpenseID(ctx context.Context, expenseID uuid.UUID) ([]models.Refund, error) {
+	var refunds []models.Refund
+	err := r.db.WithContext(ctx).Where("expense_id = ?", expenseID).Order("date ASC, created_at ASC").Find(&refunds).Error
+	return refunds, err
+}
+
+// GetTotalByExpenseID returns the amount refunded so far against an expense.
+func (r *refundRepository) GetTotalByExpenseID(ctx context.Context, expenseID uuid.UUID) (money.Amount, error) {
+	var total money.Amount
+	err := r.db.WithContext(ctx).Model(&models.Refund{}).Select("COALESCE(SUM(amount), 0)").Where("expense_id = ?", expenseID).Scan(&total).Error
+	return total, err
+}
+
+func (r *refundRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&models.Refund{}, id).Error
+}
@@ -6,8 +6,18 @@ import (
 
 func NewRepository(db *gorm.DB) *Repository {
 	return &Repository{
-		Card:     NewCardRepository(db),
-		Category: NewCategoryRepository(db),
-		Expense:  NewExpenseRepository(db),
+		Card:         NewCardRepository(db),
+		Category:     NewCategoryRepository(db),
+		Payee:        NewPayeeRepository(db),
+		Rule:         NewRuleRepository(db),
+		Expense:      NewExpenseRepository(db),
+		Attachment:   NewAttachmentRepository(db),
+		Installment:  NewInstallmentRepository(db),
+		Refund:       NewRefundRepository(db),
+		Book:         NewBookRepository(db),
+		ExchangeRate: NewExchangeRateRepository(db),
+		Alert:        NewAlertRepository(db),
+		Notification: NewNotificationRepository(db),
+		Webhook:      NewWebhookRepository(db),
 	}
 }
\ No newline at end of file
@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"context"
+	"kakeibo-tanuki/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ruleRepository struct {
+	db *gorm.DB
+}
+
+func NewRuleRepository(db *gorm.DB) RuleRepository {
+	translateErrors(db)
+	return &ruleRepository{db: db}
+}
+
+func (r *ruleRepository) Create(ctx context.Context, rule *models.Rule) error {
+	return r.db.WithContext(ctx).Create(rule).Error
+}
+
+func (r *ruleRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Rule, error) {
+	var rule models.Rule
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&rule).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// GetAll returns the rules in the order they are applied.
+func (r *ruleRepository) GetAll(ctx context.Context) ([]models.Rule, error) {
+	var rules []models.Rule
+	err := r.db.WithContext(ctx).Order("priority ASC, name ASC").Find(&rules).Error
+	return rules, err
+}
+
+func (r *ruleRepository) Update(ctx context.Context, rule *models.Rule) error {
+	return r.db.WithContext(ctx).Save(rule).Error
+}
+
+func (r *ruleRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&models.Rule{}, id).Error
+}
+
+// GetExpenses returns the expenses rules are run against, with their items
+// so that split expenses can be told apart.
+func (r *ruleRepository) GetExpenses(ctx context.Context, filters *models.RuleRunFilters) ([]models.Expense, error) {
+	var expenses []models.Expense
+	query := r.db.WithContext(ctx).Preload("Items")
+	if filters.StartDate != nil {
+		query = query.Where("date >= ?", filters.StartDate)
+	}
+	if filters.EndDate != nil {
+		query = query.Where("date < ?", filters.EndDate.AddDate(0, 0, 1))
+	}
+	err := query.Order("date ASC, id ASC").Find(&expenses).Error
+	return expenses, err
+}
+
+// UpdateExpenses saves the category, tags and shared flag of the expenses in
+// one transaction, so a failed bulk update changes nothing.
+func (r *ruleRepository) UpdateExpenses(ctx context.Context, expenses []models.Expense) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i := range expenses {
+			err := tx.Model(&expenses[i]).Select("CategoryID", "Tags", "IsShared").Updates(&expenses[i]).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,162 @@
+// Package rules evaluates the user-defined rules that categorize, tag and
+// share expenses automatically.
+package rules
+
+import (
+	"regexp"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/payees"
+)
+
+// Actions are what the rules matching an expense set on it. CategoryID and
+// IsShared come from the first matching rule that sets them; Tags are those
+// of all matching rules, without duplicates.
+type Actions struct {
+	RuleIDs    []uuid.UUID
+	CategoryID *uuid.UUID
+	Tags       []string
+	IsShared   *bool
+}
+
+type compiledRule struct {
+	rule    models.Rule
+	pattern *regexp.Regexp
+}
+
+// Engine evaluates a set of rules.
+type Engine struct {
+	rules []compiledRule
+}
+
+// NewEngine prepares the enabled rules for evaluation in ascending order of
+// priority, then by name, so the result does not depend on the order of
+// rules.
+func NewEngine(rules []models.Rule) (*Engine, error) {
+	var enabled []models.Rule
+	for _, rule := range rules {
+		if rule.Enabled {
+			enabled = append(enabled, rule)
+		}
+	}
+	sort.SliceStable(enabled, func(i, j int) bool {
+		if enabled[i].Priority != enabled[j].Priority {
+			return enabled[i].Priority < enabled[j].Priority
+		}
+		return enabled[i].Name < enabled[j].Name
+	})
+
+	e := &Engine{}
+	for _, rule := range enabled {
+		compiled := compiledRule{rule: rule}
+		if rule.DescriptionPattern != "" {
+			re, err := payees.CompilePattern(rule.DescriptionPattern)
+			if err != nil {
+				return nil, err
+			}
+			compiled.pattern = re
+		}
+		e.rules = append(e.rules, compiled)
+	}
+	return e, nil
+}
+
+// Evaluate returns the actions of the rules that expense matches.
+func (e *Engine) Evaluate(expense *models.Expense) Actions {
+	var actions Actions
+	seen := map[string]bool{}
+	for _, r := range e.rules {
+		if !r.matches(expense) {
+			continue
+		}
+		actions.RuleIDs = append(actions.RuleIDs, r.rule.ID)
+		if actions.CategoryID == nil && r.rule.CategoryID != nil {
+			categoryID := *r.rule.CategoryID
+			actions.CategoryID = &categoryID
+		}
+		if actions.IsShared == nil && r.rule.IsShared != nil {
+			isShared := *r.rule.IsShared
+			actions.IsShared = &isShared
+		}
+		for _, tag := range r.rule.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				actions.Tags = append(actions.Tags, tag)
+			}
+		}
+	}
+	return actions
+}
+
+func (r compiledRule) matches(expense *models.Expense) bool {
+	rule := r.rule
+	if r.pattern != nil && !r.pattern.MatchString(payees.Normalize(expense.Description)) {
+		return false
+	}
+	if rule.MinAmount != nil && expense.Amount < *rule.MinAmount {
+		return false
+	}
+	if rule.MaxAmount != nil && expense.Amount > *rule.MaxAmount {
+		return false
+	}
+	if rule.CardID != nil && expense.CardID != *rule.CardID {
+		return false
+	}
+	if rule.PayeeID != nil && (expense.PayeeID == nil || *expense.PayeeID != *rule.PayeeID) {
+		return false
+	}
+	return true
+}
+
+// Change returns what applying actions to expense would change, and false
+// when it would change nothing. The category of split expenses follows their
+// items and is left alone.
+func Change(expense *models.Expense, actions Actions) (models.RuleChange, bool) {
+	change := models.RuleChange{
+		ExpenseID:          expense.ID,
+		Date:               expense.Date.Format("2006-01-02"),
+		Description:        expense.Description,
+		Amount:             expense.Amount,
+		Currency:           expense.Currency,
+		RuleIDs:            actions.RuleIDs,
+		PreviousCategoryID: expense.CategoryID,
+	}
+	if actions.CategoryID != nil && *actions.CategoryID != expense.CategoryID && len(expense.Items) == 0 {
+		change.CategoryID = actions.CategoryID
+	}
+	for _, tag := range actions.Tags {
+		if !hasTag(expense.Tags, tag) {
+			change.AddedTags = append(change.AddedTags, tag)
+		}
+	}
+	if actions.IsShared != nil && (expense.IsShared == nil || *expense.IsShared != *actions.IsShared) {
+		change.IsShared = actions.IsShared
+	}
+
+	changed := change.CategoryID != nil || len(change.AddedTags) > 0 || change.IsShared != nil
+	return change, changed
+}
+
+// Apply makes change to expense.
+func Apply(expense *models.Expense, change models.RuleChange) {
+	if change.CategoryID != nil {
+		expense.CategoryID = *change.CategoryID
+	}
+	expense.Tags = append(expense.Tags, change.AddedTags...)
+	if change.IsShared != nil {
+		isShared := *change.IsShared
+		expense.IsShared = &isShared
+	}
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
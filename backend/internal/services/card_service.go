@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/repositories"
+)
+
+// CardService manages cards.
+type CardService struct {
+	uow UnitOfWork
+}
+
+func NewCardService(uow UnitOfWork) *CardService {
+	return &CardService{uow: uow}
+}
+
+func (s *CardService) List(ctx context.Context) ([]models.Card, error) {
+	return s.uow.Repository().Card.GetAll(ctx)
+}
+
+func (s *CardService) Get(ctx context.Context, id uuid.UUID) (*models.Card, error) {
+	return s.uow.Repository().Card.GetByID(ctx, id)
+}
+
+func (s *CardService) Create(ctx context.Context, req *models.CreateCardRequest) (*models.Card, error) {
+	card := &models.Card{
+		ID:    uuid.New(),
+		Name:  req.Name,
+		Color: req.Color,
+	}
+	if err := s.uow.Repository().Card.Create(ctx, card); err != nil {
+		return nil, err
+	}
+	return card, nil
+}
+
+func (s *CardService) Update(ctx context.Context, id uuid.UUID, req *models.UpdateCardRequest) (*models.Card, error) {
+	var card *models.Card
+	err := s.uow.Do(ctx, func(repo *repositories.Repository) error {
+		var err error
+		card, err = repo.Card.Lock(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		card.Name = req.Name
+		card.Color = req.Color
+		return repo.Card.Update(ctx, card)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return card, nil
+}
+
+// Delete deletes a card that has no expenses and returns it. The card is
+// locked while its expenses are counted, so none can be added before it is
+// deleted.
+func (s *CardService) Delete(ctx context.Context, id uuid.UUID) (*models.Card, error) {
+	var card *models.Card
+	err := s.uow.Do(ctx, func(repo *repositories.Repository) error {
+		var err error
+		card, err = repo.Card.Lock(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		hasExpenses, err := repo.Card.HasExpenses(ctx, id)
+		if err != nil {
+			return err
+		}
+		if hasExpenses {
+			return &Error{
+				Status:  http.StatusConflict,
+				Code:    "CARD_HAS_EXPENSES",
+				Message: "Cannot delete card with associated expenses",
+				Details: "This card has expenses associated with it. Please delete the expenses first or reassign them to another card.",
+			}
+		}
+
+		return repo.Card.Delete(ctx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return card, nil
+}
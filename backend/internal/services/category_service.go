@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/repositories"
+)
+
+// CategoryService manages categories, whose names are unique.
+type CategoryService struct {
+	uow UnitOfWork
+}
+
+func NewCategoryService(uow UnitOfWork) *CategoryService {
+	return &CategoryService{uow: uow}
+}
+
+func (s *CategoryService) List(ctx context.Context) ([]models.Category, error) {
+	return s.uow.Repository().Category.GetAll(ctx)
+}
+
+func (s *CategoryService) Get(ctx context.Context, id uuid.UUID) (*models.Category, error) {
+	return s.uow.Repository().Category.GetByID(ctx, id)
+}
+
+func (s *CategoryService) Create(ctx context.Context, req *models.CreateCategoryRequest) (*models.Category, error) {
+	category := &models.Category{
+		ID:       uuid.New(),
+		Name:     req.Name,
+		Color:    req.Color,
+		IsShared: req.IsShared,
+	}
+	if err := s.uow.Repository().Category.Create(ctx, category); err != nil {
+		return nil, duplicateCategory(err)
+	}
+	return category, nil
+}
+
+func (s *CategoryService) Update(ctx context.Context, id uuid.UUID, req *models.UpdateCategoryRequest) (*models.Category, error) {
+	var category *models.Category
+	err := s.uow.Do(ctx, func(repo *repositories.Repository) error {
+		var err error
+		category, err = repo.Category.Lock(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		category.Name = req.Name
+		category.Color = req.Color
+		category.IsShared = req.IsShared
+		return duplicateCategory(repo.Category.Update(ctx, category))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// Delete deletes a category that no expense or line item refers to and
+// returns it. The category is locked while they are counted.
+func (s *CategoryService) Delete(ctx context.Context, id uuid.UUID) (*models.Category, error) {
+	var category *models.Category
+	err := s.uow.Do(ctx, func(repo *repositories.Repository) error {
+		var err error
+		category, err = repo.Category.Lock(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		hasExpenses, err := repo.Category.HasExpenses(ctx, id)
+		if err != nil {
+			return err
+		}
+		if hasExpenses {
+			return &Error{
+				Status:  http.StatusConflict,
+				Code:    "CATEGORY_HAS_EXPENSES",
+				Message: "Cannot delete category with associated expenses",
+				Details: "This category has expenses associated with it. Please delete the expenses first or reassign them to another category.",
+			}
+		}
+
+		return repo.Category.Delete(ctx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// duplicateCategory returns a 409 error when err is a duplicate name, and
+// err itself otherwise.
+func duplicateCategory(err error) error {
+	if !errors.Is(err, repositories.ErrConflict) {
+		return err
+	}
+	return &Error{
+		Status:  http.StatusConflict,
+		Code:    "DUPLICATE_CATEGORY",
+		Message: "Category with this name already exists",
+		Details: "A category with this name already exists. Please choose a different name.",
+	}
+}
@@ -0,0 +1,33 @@
+// Package services holds the business rules that requests go through
+// between the handlers and the repositories.
+package services
+
+import "fmt"
+
+// Error is a request that a service rejects, with the status and code of
+// the error response. Handlers leave it with c.Error for the error
+// middleware to respond with.
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	Details interface{}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// FieldError is a field of a request that a service found invalid for a
+// reason a validate tag stands for, such as categoryId being required
+// without items. Handlers leave it with c.Error for the error middleware to
+// respond with VALIDATION_ERROR in the language of the request.
+type FieldError struct {
+	Field string
+	Rule  string
+	Param string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s failed on the %s rule", e.Field, e.Rule)
+}
@@ -0,0 +1,542 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"kakeibo-tanuki/internal/currency"
+	"kakeibo-tanuki/internal/installments"
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
+	"kakeibo-tanuki/internal/payees"
+	"kakeibo-tanuki/internal/repositories"
+	"kakeibo-tanuki/internal/rules"
+)
+
+// ExpenseService validates expenses and saves them together with the
+// records that depend on them, each operation in a single transaction.
+type ExpenseService struct {
+	uow UnitOfWork
+}
+
+func NewExpenseService(uow UnitOfWork) *ExpenseService {
+	return &ExpenseService{uow: uow}
+}
+
+// ParseDate parses the date of an expense or a refund, given as YYYY-MM-DD
+// or in RFC 3339 form. Neither can be dated in the future.
+func ParseDate(value string) (time.Time, error) {
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		date, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			return time.Time{}, &Error{
+				Status:  http.StatusBadRequest,
+				Code:    "INVALID_DATE",
+				Message: "Invalid date format",
+				Details: "Date must be in YYYY-MM-DD or RFC3339 format",
+			}
+		}
+	}
+
+	if date.After(time.Now()) {
+		return time.Time{}, &Error{
+			Status:  http.StatusBadRequest,
+			Code:    "FUTURE_DATE",
+			Message: "Date cannot be in the future",
+			Details: "Please select a current or past date",
+		}
+	}
+	return date, nil
+}
+
+// List returns a page of the expenses that match filters and the number of
+// them on all pages.
+func (s *ExpenseService) List(ctx context.Context, filters *models.ExpenseFilters) ([]models.Expense, int, error) {
+	return s.uow.Repository().Expense.GetAll(ctx, filters)
+}
+
+// Get returns an expense with its card, categories and payee.
+func (s *ExpenseService) Get(ctx context.Context, id uuid.UUID) (*models.Expense, error) {
+	return s.uow.Repository().Expense.GetByID(ctx, id)
+}
+
+// Create saves a new expense with its line items and returns it with its
+// card, categories and payee. The currency defaults to the base currency of
+// the book, the payee is matched from the description unless given, and the
+// rules choose the category when neither it nor items are given.
+func (s *ExpenseService) Create(ctx context.Context, req *models.CreateExpenseRequest) (*models.Expense, error) {
+	date, err := ParseDate(req.Date)
+	if err != nil {
+		return nil, err
+	}
+	cardID, err := parseID(req.CardID, "INVALID_CARD_ID", "Invalid card ID format")
+	if err != nil {
+		return nil, err
+	}
+
+	var created *models.Expense
+	err = s.uow.Do(ctx, func(repo *repositories.Repository) error {
+		// Expenses are recorded in the base currency unless another one is given
+		code := req.Currency
+		if code == "" {
+			book, err := repo.Book.Get(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to retrieve book settings: %w", err)
+			}
+			code = book.BaseCurrency
+		}
+
+		code, amount, err := parseAmount(code, req.Amount)
+		if err != nil {
+			return err
+		}
+		items, err := parseItems(req.Items, amount, code)
+		if err != nil {
+			return err
+		}
+		payeeID, err := parsePayee(ctx, repo, req.PayeeID, req.Description)
+		if err != nil {
+			return err
+		}
+
+		expense := &models.Expense{
+			ID:          uuid.New(),
+			Amount:      amount,
+			Currency:    code,
+			Date:        date,
+			Description: req.Description,
+			CardID:      cardID,
+			PayeeID:     payeeID,
+			Tags:        req.Tags,
+			IsShared:    req.IsShared,
+			Items:       items,
+		}
+
+		// Without a category the rules choose one
+		if req.CategoryID == "" && len(items) == 0 {
+			if err := applyRules(ctx, repo, expense); err != nil {
+				return err
+			}
+		} else {
+			expense.CategoryID, err = parseCategory(req.CategoryID, items)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := checkReferences(ctx, repo, expense); err != nil {
+			return err
+		}
+		if err := repo.Expense.Create(ctx, expense); err != nil {
+			return err
+		}
+
+		created, err = repo.Expense.GetByID(ctx, expense.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// Update saves the changes to an expense, which cannot bring its amount
+// below what has been refunded, and reschedules its installments for the
+// new amount and date. Without a payeeId the payee is kept, or matched
+// again if the description changed, and an empty payeeId clears it. It
+// returns the expense with its card, categories and payee.
+func (s *ExpenseService) Update(ctx context.Context, id uuid.UUID, req *models.UpdateExpenseRequest) (*models.Expense, error) {
+	date, err := ParseDate(req.Date)
+	if err != nil {
+		return nil, err
+	}
+	cardID, err := parseID(req.CardID, "INVALID_CARD_ID", "Invalid card ID format")
+	if err != nil {
+		return nil, err
+	}
+
+	var updated *models.Expense
+	err = s.uow.Do(ctx, func(repo *repositories.Repository) error {
+		// Refunds are added up with the expense locked, see RefundService
+		expense, err := repo.Expense.Lock(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		// Keep the recorded currency unless a new one is given
+		code := req.Currency
+		if code == "" {
+			code = expense.Currency
+		}
+
+		code, amount, err := parseAmount(code, req.Amount)
+		if err != nil {
+			return err
+		}
+		items, err := parseItems(req.Items, amount, code)
+		if err != nil {
+			return err
+		}
+		categoryID, err := parseCategory(req.CategoryID, items)
+		if err != nil {
+			return err
+		}
+
+		payeeID := expense.PayeeID
+		switch {
+		case req.PayeeID == nil:
+			// Kept unless the description it was matched with changed
+			if req.Description != expense.Description {
+				payeeID, err = parsePayee(ctx, repo, "", req.Description)
+			}
+		case *req.PayeeID == "":
+			// Cleared rather than matched again, so that a wrong match can
+			// be undone
+			payeeID = nil
+		default:
+			payeeID, err = parsePayee(ctx, repo, *req.PayeeID, req.Description)
+		}
+		if err != nil {
+			return err
+		}
+
+		expense.Amount = amount
+		expense.Currency = code
+		expense.Date = date
+		expense.Description = req.Description
+		expense.CardID = cardID
+		expense.CategoryID = categoryID
+		expense.PayeeID = payeeID
+		expense.Tags = req.Tags
+		expense.IsShared = req.IsShared
+		expense.Items = items
+
+		if err := checkReferences(ctx, repo, expense); err != nil {
+			return err
+		}
+
+		refunded, err := repo.Refund.GetTotalByExpenseID(ctx, expense.ID)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve refunds: %w", err)
+		}
+		if refunded > expense.Amount {
+			return &Error{
+				Status:  http.StatusBadRequest,
+				Code:    "REFUND_EXCEEDS_AMOUNT",
+				Message: "Expense amount cannot be less than the refunded amount",
+				Details: fmt.Sprintf("%s has already been refunded", refunded),
+			}
+		}
+
+		if err := repo.Expense.Update(ctx, expense); err != nil {
+			return err
+		}
+		if err := rescheduleInstallments(ctx, repo, expense); err != nil {
+			return err
+		}
+
+		updated, err = repo.Expense.GetByID(ctx, expense.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// Delete deletes an expense with its line items, refunds, installments and
+// attachments. It returns the expense and its attachments, whose files are
+// left for the caller to remove once the rows are gone.
+func (s *ExpenseService) Delete(ctx context.Context, id uuid.UUID) (*models.Expense, []models.Attachment, error) {
+	var expense *models.Expense
+	var attachments []models.Attachment
+	err := s.uow.Do(ctx, func(repo *repositories.Repository) error {
+		var err error
+		expense, err = repo.Expense.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		attachments, err = repo.Attachment.GetByExpenseID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve expense attachments: %w", err)
+		}
+		if err := repo.Attachment.DeleteByExpenseID(ctx, id); err != nil {
+			return err
+		}
+		return repo.Expense.Delete(ctx, id)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return expense, attachments, nil
+}
+
+// Anomalies returns the anomalies found for an expense. They are only
+// advisory, so none are returned when they cannot be detected, for example
+// for lack of an exchange rate.
+func (s *ExpenseService) Anomalies(ctx context.Context, expense *models.Expense) []models.Anomaly {
+	repo := s.uow.Repository()
+	book, err := repo.Book.Get(ctx)
+	if err != nil {
+		return nil
+	}
+
+	report, err := repo.Expense.GetAnomalies(ctx, &models.AnomalyFilters{
+		StartDate: expense.Date,
+		EndDate:   expense.Date,
+		ExpenseID: &expense.ID,
+		Currency:  book.BaseCurrency,
+	})
+	if err != nil {
+		return nil
+	}
+	return report.Anomalies
+}
+
+// parseID parses the ID of a record that a request refers to, rejecting it
+// with code when it is malformed.
+func parseID(value, code, message string) (uuid.UUID, error) {
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, &Error{
+			Status:  http.StatusBadRequest,
+			Code:    code,
+			Message: message,
+			Details: err.Error(),
+		}
+	}
+	return id, nil
+}
+
+// parseAmount validates the currency code and rounds the amount to the minor
+// units of the currency.
+func parseAmount(code string, amount money.Amount) (string, money.Amount, error) {
+	code, err := currency.Normalize(code)
+	if err != nil {
+		return "", 0, &Error{
+			Status:  http.StatusBadRequest,
+			Code:    "INVALID_CURRENCY",
+			Message: "Invalid currency code",
+			Details: err.Error(),
+		}
+	}
+
+	rounded := amount.Round(code)
+	if rounded <= 0 {
+		return "", 0, &Error{
+			Status:  http.StatusBadRequest,
+			Code:    "INVALID_AMOUNT",
+			Message: "Amount is too small",
+			Details: fmt.Sprintf("%s amounts are rounded to %d decimal places", code, currency.MinorUnits(code)),
+		}
+	}
+
+	return code, rounded, nil
+}
+
+// parseItems converts line item requests into models and verifies that the
+// item amounts, rounded to the minor units of the currency, add up to the
+// expense amount.
+func parseItems(reqItems []models.ExpenseItemRequest, amount money.Amount, code string) ([]models.ExpenseItem, error) {
+	if len(reqItems) == 0 {
+		return nil, nil
+	}
+
+	items := make([]models.ExpenseItem, 0, len(reqItems))
+	var total money.Amount
+	for i, reqItem := range reqItems {
+		categoryID, err := uuid.Parse(reqItem.CategoryID)
+		if err != nil {
+			return nil, &Error{
+				Status:  http.StatusBadRequest,
+				Code:    "INVALID_CATEGORY_ID",
+				Message: "Invalid category ID format",
+				Details: fmt.Sprintf("items[%d]: %s", i, err.Error()),
+			}
+		}
+
+		itemAmount := reqItem.Amount.Round(code)
+		if itemAmount <= 0 {
+			return nil, &Error{
+				Status:  http.StatusBadRequest,
+				Code:    "INVALID_AMOUNT",
+				Message: "Line item amount is too small",
+				Details: fmt.Sprintf("items[%d]: %s amounts are rounded to %d decimal places", i, code, currency.MinorUnits(code)),
+			}
+		}
+
+		items = append(items, models.ExpenseItem{
+			ID:         uuid.New(),
+			CategoryID: categoryID,
+			Amount:     itemAmount,
+			Note:       reqItem.Note,
+		})
+		total += itemAmount
+	}
+
+	// Compare in minor units so float rounding does not reject exact splits
+	if total != amount {
+		return nil, &Error{
+			Status:  http.StatusBadRequest,
+			Code:    "ITEMS_AMOUNT_MISMATCH",
+			Message: "Line item amounts do not match the expense amount",
+			Details: fmt.Sprintf("Line items add up to %s but the expense amount is %s", total, amount),
+		}
+	}
+
+	return items, nil
+}
+
+// parseCategory resolves the expense's own category. Split expenses may omit
+// it, in which case the category of the largest item is used.
+func parseCategory(value string, items []models.ExpenseItem) (uuid.UUID, error) {
+	if value == "" && len(items) > 0 {
+		primary := items[0]
+		for _, item := range items[1:] {
+			if item.Amount > primary.Amount {
+				primary = item
+			}
+		}
+		return primary.CategoryID, nil
+	}
+
+	return parseID(value, "INVALID_CATEGORY_ID", "Invalid category ID format")
+}
+
+// parsePayee returns the payee given by ID, or else the payee whose name,
+// aliases or patterns match the description, if any.
+func parsePayee(ctx context.Context, repo *repositories.Repository, value string, description string) (*uuid.UUID, error) {
+	if value != "" {
+		payeeID, err := parseID(value, "INVALID_PAYEE_ID", "Invalid payee ID format")
+		if err != nil {
+			return nil, err
+		}
+		return &payeeID, nil
+	}
+
+	allPayees, err := repo.Payee.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve payees: %w", err)
+	}
+	matcher, err := payees.NewMatcher(allPayees)
+	if err != nil {
+		return nil, fmt.Errorf("failed to match payee: %w", err)
+	}
+	return matcher.Match(description), nil
+}
+
+// applyRules sets the category of a new expense, and the tags and shared
+// flag the request leaves out, from the rules it matches. The category is
+// required when no rule sets it.
+func applyRules(ctx context.Context, repo *repositories.Repository, expense *models.Expense) error {
+	allRules, err := repo.Rule.GetAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve rules: %w", err)
+	}
+	engine, err := rules.NewEngine(allRules)
+	if err != nil {
+		return fmt.Errorf("failed to prepare rules: %w", err)
+	}
+
+	actions := engine.Evaluate(expense)
+	if actions.CategoryID == nil {
+		// Neither given nor set by a rule
+		return &FieldError{Field: "categoryId", Rule: "required_without", Param: "items"}
+	}
+	if expense.IsShared != nil {
+		actions.IsShared = nil
+	}
+	change, _ := rules.Change(expense, actions)
+	rules.Apply(expense, change)
+	return nil
+}
+
+// checkReferences verifies that the card, the payee and the categories of an
+// expense and its items exist. There is a single book, so every card, payee
+// and category that exists belongs to the book of the expense.
+func checkReferences(ctx context.Context, repo *repositories.Repository, expense *models.Expense) error {
+	if _, err := repo.Card.GetByID(ctx, expense.CardID); err != nil {
+		return notFound(err, "CARD_NOT_FOUND", "Card not found", "cardId", expense.CardID)
+	}
+
+	if _, err := repo.Category.GetByID(ctx, expense.CategoryID); err != nil {
+		return notFound(err, "CATEGORY_NOT_FOUND", "Category not found", "categoryId", expense.CategoryID)
+	}
+
+	if expense.PayeeID != nil {
+		if _, err := repo.Payee.GetByID(ctx, *expense.PayeeID); err != nil {
+			return notFound(err, "PAYEE_NOT_FOUND", "Payee not found", "payeeId", *expense.PayeeID)
+		}
+	}
+
+	checked := map[uuid.UUID]bool{expense.CategoryID: true}
+	for i, item := range expense.Items {
+		if checked[item.CategoryID] {
+			continue
+		}
+		checked[item.CategoryID] = true
+
+		if _, err := repo.Category.GetByID(ctx, item.CategoryID); err != nil {
+			field := fmt.Sprintf("items[%d].categoryId", i)
+			return notFound(err, "CATEGORY_NOT_FOUND", "Category not found", field, item.CategoryID)
+		}
+	}
+	return nil
+}
+
+// rescheduleInstallments rebuilds the payment schedule of an installment
+// purchase after its amount or date changed, keeping the plan parameters.
+func rescheduleInstallments(ctx context.Context, repo *repositories.Repository, expense *models.Expense) error {
+	plan, err := repo.Installment.GetByExpenseID(ctx, expense.ID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to retrieve installment plan: %w", err)
+	}
+
+	req := &models.InstallmentPlanRequest{
+		PaymentType:        plan.PaymentType,
+		NumberOfPayments:   plan.NumberOfPayments,
+		AnnualInterestRate: plan.AnnualInterestRate,
+		MonthlyPayment:     plan.MonthlyPayment,
+	}
+
+	// Keep an explicit first payment date unless the purchase moved past it
+	firstPaymentDate := plan.FirstPaymentDate
+	if firstPaymentDate.Before(expense.Date) {
+		firstPaymentDate = time.Time{}
+	}
+
+	newPlan, err := installments.BuildPlan(expense.ID, expense.Amount, expense.Currency, expense.Date, req, firstPaymentDate)
+	if err != nil {
+		return &Error{
+			Status:  http.StatusBadRequest,
+			Code:    "INVALID_INSTALLMENT_PLAN",
+			Message: "The installment plan does not fit the updated expense",
+			Details: err.Error(),
+		}
+	}
+	return repo.Installment.Save(ctx, newPlan)
+}
+
+// notFound returns a 422 error with code when err is a missing record, and
+// err itself otherwise.
+func notFound(err error, code, message, field string, id uuid.UUID) error {
+	if !errors.Is(err, repositories.ErrNotFound) {
+		return err
+	}
+	return &Error{
+		Status:  http.StatusUnprocessableEntity,
+		Code:    code,
+		Message: message,
+		Details: fmt.Sprintf("%s %s does not exist", field, id),
+	}
+}
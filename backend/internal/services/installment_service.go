@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"kakeibo-tanuki/internal/installments"
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/repositories"
+)
+
+// InstallmentService manages the installment plans of expenses paid over
+// several months.
+type InstallmentService struct {
+	uow UnitOfWork
+}
+
+func NewInstallmentService(uow UnitOfWork) *InstallmentService {
+	return &InstallmentService{uow: uow}
+}
+
+// Get returns the installment plan of an expense.
+func (s *InstallmentService) Get(ctx context.Context, expenseID uuid.UUID) (*models.InstallmentPlan, error) {
+	repo := s.uow.Repository()
+	if _, err := repo.Expense.GetByIDWithoutPreload(ctx, expenseID); err != nil {
+		return nil, err
+	}
+
+	plan, err := repo.Installment.GetByExpenseID(ctx, expenseID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, &Error{
+			Status:  http.StatusNotFound,
+			Code:    "INSTALLMENT_PLAN_NOT_FOUND",
+			Message: "Installment plan not found",
+			Details: "This expense is paid in a single payment",
+		}
+	}
+	return plan, err
+}
+
+// Set replaces the installment plan of an expense with one built from req.
+// The expense is locked so that the plan is built for its current amount
+// and date.
+func (s *InstallmentService) Set(ctx context.Context, expenseID uuid.UUID, req *models.InstallmentPlanRequest) (*models.InstallmentPlan, error) {
+	var firstPaymentDate time.Time
+	if req.FirstPaymentDate != "" {
+		date, err := time.Parse("2006-01-02", req.FirstPaymentDate)
+		if err != nil {
+			return nil, &Error{
+				Status:  http.StatusBadRequest,
+				Code:    "INVALID_DATE",
+				Message: "Invalid first payment date format",
+				Details: "Date must be in YYYY-MM-DD format",
+			}
+		}
+		firstPaymentDate = date
+	}
+
+	var plan *models.InstallmentPlan
+	err := s.uow.Do(ctx, func(repo *repositories.Repository) error {
+		expense, err := repo.Expense.Lock(ctx, expenseID)
+		if err != nil {
+			return err
+		}
+
+		plan, err = installments.BuildPlan(expense.ID, expense.Amount, expense.Currency, expense.Date, req, firstPaymentDate)
+		if err != nil {
+			return &Error{
+				Status:  http.StatusBadRequest,
+				Code:    "INVALID_INSTALLMENT_PLAN",
+				Message: "Invalid installment plan",
+				Details: err.Error(),
+			}
+		}
+		return repo.Installment.Save(ctx, plan)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// Delete deletes the installment plan of an expense, if it has one.
+func (s *InstallmentService) Delete(ctx context.Context, expenseID uuid.UUID) error {
+	return s.uow.Do(ctx, func(repo *repositories.Repository) error {
+		if _, err := repo.Expense.Lock(ctx, expenseID); err != nil {
+			return err
+		}
+		return repo.Installment.DeleteByExpenseID(ctx, expenseID)
+	})
+}
+
+// Balances returns the installment charges due after asOf for every card,
+// or only for cardID when it is given.
+func (s *InstallmentService) Balances(ctx context.Context, asOf time.Time, cardID *uuid.UUID) ([]models.CardInstallmentBalance, error) {
+	return s.uow.Repository().Installment.GetCardBalances(ctx, asOf, cardID)
+}
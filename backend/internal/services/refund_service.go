@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"kakeibo-tanuki/internal/currency"
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/repositories"
+)
+
+// RefundService manages the refunds of expenses, which together cannot
+// exceed the amount of their expense.
+type RefundService struct {
+	uow UnitOfWork
+}
+
+func NewRefundService(uow UnitOfWork) *RefundService {
+	return &RefundService{uow: uow}
+}
+
+// List returns the refunds of an expense.
+func (s *RefundService) List(ctx context.Context, expenseID uuid.UUID) ([]models.Refund, error) {
+	repo := s.uow.Repository()
+	if _, err := repo.Expense.GetByIDWithoutPreload(ctx, expenseID); err != nil {
+		return nil, err
+	}
+	return repo.Refund.GetByExpenseID(ctx, expenseID)
+}
+
+// Create refunds part of an expense in its currency. The expense is locked
+// while its refunds are added up, so that concurrent refunds cannot together
+// exceed its amount.
+func (s *RefundService) Create(ctx context.Context, expenseID uuid.UUID, req *models.CreateRefundRequest) (*models.Refund, error) {
+	date, err := ParseDate(req.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	var refund *models.Refund
+	err = s.uow.Do(ctx, func(repo *repositories.Repository) error {
+		expense, err := repo.Expense.Lock(ctx, expenseID)
+		if err != nil {
+			return err
+		}
+
+		if truncateDate(date).Before(truncateDate(expense.Date)) {
+			return &Error{
+				Status:  http.StatusBadRequest,
+				Code:    "INVALID_REFUND_DATE",
+				Message: "Refund date cannot be before the expense date",
+				Details: fmt.Sprintf("The expense was made on %s", expense.Date.Format("2006-01-02")),
+			}
+		}
+
+		amount := req.Amount.Round(expense.Currency)
+		if amount <= 0 {
+			return &Error{
+				Status:  http.StatusBadRequest,
+				Code:    "INVALID_AMOUNT",
+				Message: "Amount is too small",
+				Details: fmt.Sprintf("%s amounts are rounded to %d decimal places", expense.Currency, currency.MinorUnits(expense.Currency)),
+			}
+		}
+
+		refunded, err := repo.Refund.GetTotalByExpenseID(ctx, expense.ID)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve refunds: %w", err)
+		}
+		if refunded+amount > expense.Amount {
+			return &Error{
+				Status:  http.StatusBadRequest,
+				Code:    "REFUND_EXCEEDS_AMOUNT",
+				Message: "Refunds cannot exceed the original expense amount",
+				Details: fmt.Sprintf("At most %s can still be refunded", max(expense.Amount-refunded, 0)),
+			}
+		}
+
+		refund = &models.Refund{
+			ID:          uuid.New(),
+			ExpenseID:   expense.ID,
+			Amount:      amount,
+			Date:        date,
+			Description: req.Description,
+		}
+		return repo.Refund.Create(ctx, refund)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return refund, nil
+}
+
+// Delete deletes a refund of an expense.
+func (s *RefundService) Delete(ctx context.Context, expenseID, refundID uuid.UUID) error {
+	return s.uow.Do(ctx, func(repo *repositories.Repository) error {
+		if _, err := repo.Expense.Lock(ctx, expenseID); err != nil {
+			return err
+		}
+
+		refund, err := repo.Refund.GetByID(ctx, refundID)
+		if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+			return err
+		}
+		if err != nil || refund.ExpenseID != expenseID {
+			return &Error{
+				Status:  http.StatusNotFound,
+				Code:    "REFUND_NOT_FOUND",
+				Message: "Refund not found",
+			}
+		}
+		return repo.Refund.Delete(ctx, refund.ID)
+	})
+}
+
+func truncateDate(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"kakeibo-tanuki/internal/repositories"
+)
+
+// UnitOfWork gives services the repositories, and runs the steps of an
+// operation that must see and leave the database consistent in a single
+// transaction.
+type UnitOfWork interface {
+	// Repository returns repositories outside of any transaction, for reads
+	// that stand alone.
+	Repository() *repositories.Repository
+
+	// Do runs fn with repositories bound to a transaction, which is committed
+	// when fn returns nil and rolled back otherwise, including when ctx is
+	// done before it commits.
+	Do(ctx context.Context, fn func(repo *repositories.Repository) error) error
+}
+
+type gormUnitOfWork struct {
+	db   *gorm.DB
+	repo *repositories.Repository
+}
+
+func NewUnitOfWork(db *gorm.DB) UnitOfWork {
+	return &gormUnitOfWork{
+		db:   db,
+		repo: repositories.NewRepository(db),
+	}
+}
+
+func (u *gormUnitOfWork) Repository() *repositories.Repository {
+	return u.repo
+}
+
+func (u *gormUnitOfWork) Do(ctx context.Context, fn func(repo *repositories.Repository) error) error {
+	return u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(repositories.NewRepository(tx))
+	})
+}
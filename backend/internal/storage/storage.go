@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ErrObjectNotFound is returned when the requested key does not exist in the store.
+var ErrObjectNotFound = errors.New("object not found")
+
+// Storage stores binary objects (receipt images, thumbnails) under opaque keys.
+type Storage interface {
+	Put(key string, body io.Reader, size int64, contentType string) error
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+// NewStorageFromEnv builds the storage backend selected by STORAGE_DRIVER.
+// "local" (default) writes under STORAGE_LOCAL_PATH, "s3" talks to any
+// S3-compatible endpoint configured through the S3_* variables.
+func NewStorageFromEnv() (Storage, error) {
+	driver := os.Getenv("STORAGE_DRIVER")
+	if driver == "" {
+		driver = "local"
+	}
+
+	switch driver {
+	case "local":
+		basePath := os.Getenv("STORAGE_LOCAL_PATH")
+		if basePath == "" {
+			basePath = "./data/attachments"
+		}
+		return NewLocalStorage(basePath)
+	case "s3":
+		region := os.Getenv("S3_REGION")
+		if region == "" {
+			region = "us-east-1"
+		}
+		return NewS3Storage(S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			Region:          region,
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage driver: %s", driver)
+	}
+}
@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+// ThumbnailMaxSize is the length in pixels of the longest thumbnail edge.
+const ThumbnailMaxSize = 256
+
+// ThumbnailMaxPixels is the largest number of pixels of an image that
+// thumbnails are generated for. Decoding holds every pixel in memory, so a
+// small file declaring a huge image could otherwise exhaust it.
+const ThumbnailMaxPixels = 40_000_000
+
+// ErrImageTooLarge is returned for images with more than ThumbnailMaxPixels
+// pixels.
+var ErrImageTooLarge = errors.New("image is too large for a thumbnail")
+
+// SupportsThumbnail reports whether a thumbnail can be generated for the content type.
+func SupportsThumbnail(contentType string) bool {
+	return contentType == "image/jpeg" || contentType == "image/png"
+}
+
+// GenerateThumbnail decodes a JPEG or PNG image and returns a JPEG thumbnail
+// whose longest edge is at most maxSize pixels. Images already smaller than
+// maxSize are re-encoded without scaling. The size the image declares is
+// checked before it is decoded, and ErrImageTooLarge is returned above
+// ThumbnailMaxPixels.
+func GenerateThumbnail(r io.Reader, contentType string, maxSize int) ([]byte, error) {
+	var decode func(io.Reader) (image.Image, error)
+	var decodeConfig func(io.Reader) (image.Config, error)
+	switch contentType {
+	case "image/jpeg":
+		decode, decodeConfig = jpeg.Decode, jpeg.DecodeConfig
+	case "image/png":
+		decode, decodeConfig = png.Decode, png.DecodeConfig
+	default:
+		return nil, fmt.Errorf("thumbnails are not supported for %s", contentType)
+	}
+
+	// The header read for the size is read again by the decoder
+	var header bytes.Buffer
+	config, err := decodeConfig(io.TeeReader(r, &header))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	if int64(config.Width)*int64(config.Height) > ThumbnailMaxPixels {
+		return nil, fmt.Errorf("%w: %dx%d pixels", ErrImageTooLarge, config.Width, config.Height)
+	}
+	src, err := decode(io.MultiReader(&header, r))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width == 0 || height == 0 {
+		return nil, fmt.Errorf("image has no pixels")
+	}
+
+	dstWidth, dstHeight := width, height
+	if width > maxSize || height > maxSize {
+		if width >= height {
+			dstWidth = maxSize
+			dstHeight = max(1, height*maxSize/width)
+		} else {
+			dstHeight = maxSize
+			dstWidth = max(1, width*maxSize/height)
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	scaleBox(dst, src)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 80}); err != nil {
+		return nil, fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// scaleBox downsamples src into dst by averaging every source pixel that
+// falls into each destination pixel. It is slower than nearest-neighbour but
+// keeps receipt text legible.
+func scaleBox(dst *image.RGBA, src image.Image) {
+	sb := src.Bounds()
+	db := dst.Bounds()
+	sw, sh := sb.Dx(), sb.Dy()
+	dw, dh := db.Dx(), db.Dy()
+
+	for dy := 0; dy < dh; dy++ {
+		y0 := sb.Min.Y + dy*sh/dh
+		y1 := max(y0+1, sb.Min.Y+(dy+1)*sh/dh)
+		for dx := 0; dx < dw; dx++ {
+			x0 := sb.Min.X + dx*sw/dw
+			x1 := max(x0+1, sb.Min.X+(dx+1)*sw/dw)
+
+			var r, g, b, a, n uint64
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					pr, pg, pb, pa := src.At(x, y).RGBA()
+					r += uint64(pr)
+					g += uint64(pg)
+					b += uint64(pb)
+					a += uint64(pa)
+					n++
+				}
+			}
+
+			i := dst.PixOffset(dx, dy)
+			dst.Pix[i+0] = uint8(r / n >> 8)
+			dst.Pix[i+1] = uint8(g / n >> 8)
+			dst.Pix[i+2] = uint8(b / n >> 8)
+			dst.Pix[i+3] = uint8(a / n >> 8)
+		}
+	}
+}
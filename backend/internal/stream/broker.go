@@ -0,0 +1,137 @@
+// Package stream broadcasts expense, card and category events to the clients
+// of the household, so that their screens stay up to date without reloading.
+// Recent events are kept in memory so that clients which lost the connection
+// can resume where they left off.
+package stream
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"kakeibo-tanuki/internal/events"
+)
+
+// ResetEvent tells a client that events it missed are no longer buffered,
+// so it should reload instead of resuming.
+const ResetEvent = "reset"
+
+// clientBuffer is how many messages may wait for a client before it is
+// disconnected as too slow; it resumes from the buffer when it reconnects.
+const clientBuffer = 64
+
+// Message is an event as it is sent to clients. IDs increase by one from
+// message to message.
+type Message struct {
+	ID    uint64
+	Event string
+	Data  []byte
+}
+
+// payload is the data of a message.
+type payload struct {
+	Type string      `json:"type"`
+	Time time.Time   `json:"time"`
+	Data interface{} `json:"data"`
+}
+
+// Broker fans events out to the connected clients and keeps the last of
+// them for clients that resume. Since the backend serves one household, all
+// clients receive all events. It is safe for concurrent use.
+type Broker struct {
+	mu      sync.Mutex
+	size    int
+	lastID  uint64
+	buffer  []Message
+	clients map[chan Message]struct{}
+}
+
+// NewBroker returns a broker that keeps the last size messages.
+func NewBroker(size int) *Broker {
+	return &Broker{
+		size:    size,
+		buffer:  make([]Message, 0, size),
+		clients: map[chan Message]struct{}{},
+	}
+}
+
+// HandleEvent broadcasts event. It is subscribed to the event bus and never
+// waits for clients: a client that cannot keep up is disconnected.
+func (b *Broker) HandleEvent(event events.Event) {
+	data, err := json.Marshal(payload{Type: event.Type, Time: event.Time, Data: event.Subject()})
+	if err != nil {
+		log.Printf("Failed to encode %s event: %v", event.Type, err)
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	message := Message{ID: b.lastID, Event: event.Type, Data: data}
+	if len(b.buffer) == b.size {
+		copy(b.buffer, b.buffer[1:])
+		b.buffer = b.buffer[:b.size-1]
+	}
+	b.buffer = append(b.buffer, message)
+
+	for client := range b.clients {
+		select {
+		case client <- message:
+		default:
+			delete(b.clients, client)
+			close(client)
+		}
+	}
+}
+
+// Subscribe connects a client. With resume, the messages after lastID are
+// replayed first; when some of them are no longer buffered, or lastID is not
+// known, the replay is a single reset message instead. The returned channel
+// is closed when the client is disconnected for being too slow; cancel
+// disconnects it and must be called when the client goes away.
+func (b *Broker) Subscribe(lastID uint64, resume bool) (replay []Message, messages <-chan Message, cancel func()) {
+	client := make(chan Message, clientBuffer)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if resume {
+		replay = b.replay(lastID)
+	}
+	b.clients[client] = struct{}{}
+
+	cancel = func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.clients[client]; ok {
+			delete(b.clients, client)
+			close(client)
+		}
+	}
+	return replay, client, cancel
+}
+
+// Clients returns the number of connected clients.
+func (b *Broker) Clients() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.clients)
+}
+
+// replay returns the buffered messages after lastID.
+func (b *Broker) replay(lastID uint64) []Message {
+	if lastID == b.lastID {
+		return nil
+	}
+	// A lastID from before a restart or older than the buffer cannot be
+	// resumed from
+	oldest := b.lastID - uint64(len(b.buffer)) + 1
+	if lastID > b.lastID || lastID+1 < oldest {
+		return []Message{{ID: b.lastID, Event: ResetEvent, Data: []byte("{}")}}
+	}
+	messages := make([]Message, b.lastID-lastID)
+	copy(messages, b.buffer[len(b.buffer)-len(messages):])
+	return messages
+}
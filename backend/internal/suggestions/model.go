@@ -0,0 +1,272 @@
+// Package suggestions learns from past expenses which category a new expense
+// probably belongs to. It is a multinomial naive Bayes classifier over the
+// character n-grams of descriptions, which works for Japanese text without a
+// dictionary, plus the card and the order of magnitude of the amount.
+package suggestions
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"kakeibo-tanuki/internal/events"
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
+	"kakeibo-tanuki/internal/payees"
+)
+
+// smoothing is the additive (Laplace) smoothing of feature counts.
+const smoothing = 1.0
+
+// Query describes an expense to suggest categories for. Zero fields are
+// left out of the prediction.
+type Query struct {
+	Description string
+	Amount      money.Amount
+	Currency    string
+	CardID      uuid.UUID
+}
+
+// Suggestion is a category and the probability the model gives it.
+type Suggestion struct {
+	CategoryID  uuid.UUID
+	Probability float64
+}
+
+type example struct {
+	categoryID uuid.UUID
+	features   map[string]int
+}
+
+// Model is trained on all expenses the first time it is used and then kept
+// up to date with Learn and Forget as expenses change. It is safe for
+// concurrent use.
+type Model struct {
+	mu     sync.RWMutex
+	load   func(ctx context.Context) ([]models.Expense, error)
+	loaded bool
+
+	examples map[uuid.UUID]example
+	// categories counts the examples of each category
+	categories map[uuid.UUID]int
+	// counts and totals count the features of each category
+	counts map[uuid.UUID]map[string]int
+	totals map[uuid.UUID]int
+	// vocabulary counts the categories each feature occurs in
+	vocabulary map[string]int
+}
+
+// NewModel returns a model trained with the expenses load returns.
+func NewModel(load func(ctx context.Context) ([]models.Expense, error)) *Model {
+	m := &Model{load: load}
+	m.reset()
+	return m
+}
+
+func (m *Model) reset() {
+	m.examples = map[uuid.UUID]example{}
+	m.categories = map[uuid.UUID]int{}
+	m.counts = map[uuid.UUID]map[string]int{}
+	m.totals = map[uuid.UUID]int{}
+	m.vocabulary = map[string]int{}
+}
+
+// ensureLoaded trains the model on first use. The lock is held while loading
+// so that expenses learned meanwhile are not lost.
+func (m *Model) ensureLoaded() error {
+	m.mu.RLock()
+	loaded := m.loaded
+	m.mu.RUnlock()
+	if loaded {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.loaded {
+		return nil
+	}
+	// The model is shared by all requests, so loading it is not canceled
+	// with the request that happens to trigger it
+	expenses, err := m.load(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to load expenses: %w", err)
+	}
+	m.reset()
+	for i := range expenses {
+		m.add(&expenses[i])
+	}
+	m.loaded = true
+	return nil
+}
+
+// Learn adds a new expense to the model or replaces what it learned from an
+// updated one. An expense left without a category is only forgotten.
+func (m *Model) Learn(expense *models.Expense) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// Until the model is loaded, the expense is learned when it is
+	if !m.loaded {
+		return
+	}
+	m.remove(expense.ID)
+	m.add(expense)
+}
+
+// Forget removes a deleted expense from the model.
+func (m *Model) Forget(id uuid.UUID) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.loaded {
+		return
+	}
+	m.remove(id)
+}
+
+// HandleEvent keeps the model up to date with the expense events it is
+// subscribed to.
+func (m *Model) HandleEvent(event events.Event) {
+	if event.Expense == nil {
+		return
+	}
+	if event.Type == events.ExpenseDeleted {
+		m.Forget(event.Expense.ID)
+		return
+	}
+	m.Learn(event.Expense)
+}
+
+// add learns the category of an expense. Expenses without one are skipped,
+// as uuid.Nil is not a category that can be suggested.
+func (m *Model) add(expense *models.Expense) {
+	if expense.CategoryID == uuid.Nil {
+		return
+	}
+	ex := example{
+		categoryID: expense.CategoryID,
+		features:   features(Query{Description: expense.Description, Amount: expense.Amount, Currency: expense.Currency, CardID: expense.CardID}),
+	}
+	m.examples[expense.ID] = ex
+	m.categories[ex.categoryID]++
+
+	counts := m.counts[ex.categoryID]
+	if counts == nil {
+		counts = map[string]int{}
+		m.counts[ex.categoryID] = counts
+	}
+	for feature, n := range ex.features {
+		if counts[feature] == 0 {
+			m.vocabulary[feature]++
+		}
+		counts[feature] += n
+		m.totals[ex.categoryID] += n
+	}
+}
+
+func (m *Model) remove(id uuid.UUID) {
+	ex, ok := m.examples[id]
+	if !ok {
+		return
+	}
+	delete(m.examples, id)
+
+	if m.categories[ex.categoryID]--; m.categories[ex.categoryID] == 0 {
+		delete(m.categories, ex.categoryID)
+	}
+	counts := m.counts[ex.categoryID]
+	for feature, n := range ex.features {
+		m.totals[ex.categoryID] -= n
+		if counts[feature] -= n; counts[feature] == 0 {
+			delete(counts, feature)
+			if m.vocabulary[feature]--; m.vocabulary[feature] == 0 {
+				delete(m.vocabulary, feature)
+			}
+		}
+	}
+	if len(counts) == 0 {
+		delete(m.counts, ex.categoryID)
+		delete(m.totals, ex.categoryID)
+	}
+}
+
+// Suggest returns up to limit categories for query, the most probable
+// first. It returns none before any expense has been recorded.
+func (m *Model) Suggest(query Query, limit int) ([]Suggestion, error) {
+	if err := m.ensureLoaded(); err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	suggestions := []Suggestion{}
+	if len(m.examples) == 0 {
+		return suggestions, nil
+	}
+
+	// Features never seen in any category say nothing about the category
+	queryFeatures := features(query)
+	vocabularySize := float64(len(m.vocabulary))
+	scores := make(map[uuid.UUID]float64, len(m.categories))
+	best := math.Inf(-1)
+	for categoryID, n := range m.categories {
+		score := math.Log(float64(n) / float64(len(m.examples)))
+		denominator := math.Log(float64(m.totals[categoryID]) + smoothing*vocabularySize)
+		for feature, count := range queryFeatures {
+			if m.vocabulary[feature] == 0 {
+				continue
+			}
+			score += float64(count) * (math.Log(float64(m.counts[categoryID][feature])+smoothing) - denominator)
+		}
+		scores[categoryID] = score
+		best = math.Max(best, score)
+	}
+
+	// Normalize the log scores into probabilities without overflowing
+	var sum float64
+	for categoryID, score := range scores {
+		p := math.Exp(score - best)
+		scores[categoryID] = p
+		sum += p
+	}
+	for categoryID, p := range scores {
+		suggestions = append(suggestions, Suggestion{CategoryID: categoryID, Probability: p / sum})
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Probability != suggestions[j].Probability {
+			return suggestions[i].Probability > suggestions[j].Probability
+		}
+		return suggestions[i].CategoryID.String() < suggestions[j].CategoryID.String()
+	})
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions, nil
+}
+
+// features returns the features of an expense and how often each occurs:
+// the character bigrams and trigrams of the normalized description with a
+// space on either side, so that the start and end of a word count, the card,
+// and the order of magnitude of the amount in its currency.
+func features(query Query) map[string]int {
+	result := map[string]int{}
+	if description := payees.Normalize(query.Description); description != "" {
+		chars := []rune(" " + description + " ")
+		for n := 2; n <= 3; n++ {
+			for i := 0; i+n <= len(chars); i++ {
+				result["d:"+string(chars[i:i+n])]++
+			}
+		}
+	}
+	if query.CardID != uuid.Nil {
+		result["c:"+query.CardID.String()]++
+	}
+	if query.Amount > 0 {
+		magnitude := int(math.Floor(math.Log10(query.Amount.Float64())))
+		result[fmt.Sprintf("a:%s:%d", query.Currency, magnitude)]++
+	}
+	return result
+}
@@ -0,0 +1,195 @@
+// Package validation validates requests against the validate tags of their
+// models and describes each field that failed, with a message in English or
+// Japanese depending on the Accept-Language header of the request.
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/ja"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	ja_translations "github.com/go-playground/validator/v10/translations/ja"
+
+	"kakeibo-tanuki/internal/models"
+)
+
+// Translations are registered on a validator and a translator once, so all
+// requests share them.
+var (
+	translators = ut.New(en.New(), en.New(), ja.New())
+	validate    = newValidator()
+)
+
+// Rules that the default translations leave out or describe without their
+// params. Their params are field names, which are given as JSON names. The
+// placeholders must appear in order.
+var extraRules = map[string]map[string]string{
+	"en": {
+		"required_without":     "{0} is required when {1} is not given",
+		"required_without_all": "{0} is required when none of {1} are given",
+	},
+	"ja": {
+		"required_without":     "{0}は{1}が指定されていない場合に必須です",
+		"required_without_all": "{0}は{1}のいずれも指定されていない場合に必須です",
+	},
+}
+
+func newValidator() *validator.Validate {
+	validate := validator.New()
+
+	// Fields are named as clients send them
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+
+	register := map[string]func(*validator.Validate, ut.Translator) error{
+		"en": en_translations.RegisterDefaultTranslations,
+		"ja": ja_translations.RegisterDefaultTranslations,
+	}
+	for locale, registerDefaults := range register {
+		trans, _ := translators.GetTranslator(locale)
+		if err := registerDefaults(validate, trans); err != nil {
+			panic(err)
+		}
+		for rule, text := range extraRules[locale] {
+			err := validate.RegisterTranslation(rule, trans, func(trans ut.Translator) error {
+				return trans.Add(rule, text, true)
+			}, func(trans ut.Translator, fe validator.FieldError) string {
+				message, _ := trans.T(fe.Tag(), fe.Field(), jsonNames(fe.Param()))
+				return message
+			})
+			if err != nil {
+				panic(err)
+			}
+		}
+	}
+	return validate
+}
+
+// Struct validates a request model.
+func Struct(s interface{}) error {
+	return validate.Struct(s)
+}
+
+// Fields describes the fields of err, an error returned by Struct, in the
+// language of acceptLanguage. It returns nil for other errors.
+func Fields(err error, acceptLanguage string) []models.FieldError {
+	var invalid validator.ValidationErrors
+	if !errors.As(err, &invalid) {
+		return nil
+	}
+
+	trans := Translator(acceptLanguage)
+	fields := make([]models.FieldError, 0, len(invalid))
+	for _, fe := range invalid {
+		param := fe.Param()
+		if _, ok := extraRules["en"][fe.Tag()]; ok {
+			param = jsonNames(param)
+		}
+		fields = append(fields, models.FieldError{
+			Field:   fieldPath(fe.Namespace()),
+			Rule:    fe.Tag(),
+			Param:   param,
+			Message: fe.Translate(trans),
+		})
+	}
+	return fields
+}
+
+// Field describes a field that failed a check made outside of the validate
+// tags, such as a field required by another one. Param is a list of JSON
+// field names for rules such as required_without.
+func Field(acceptLanguage, field, rule, param string) models.FieldError {
+	trans := Translator(acceptLanguage)
+	message, err := trans.T(rule, field, param)
+	if err != nil {
+		message = field + ": " + rule
+	}
+	return models.FieldError{Field: field, Rule: rule, Param: param, Message: message}
+}
+
+// Translator returns the translator of the preferred language of an
+// Accept-Language header that the messages are available in, English by
+// default.
+func Translator(acceptLanguage string) ut.Translator {
+	trans, _ := translators.FindTranslator(Languages(acceptLanguage)...)
+	return trans
+}
+
+// Languages returns the primary language tags of an Accept-Language header,
+// such as "ja" for "ja-JP", in order of preference.
+func Languages(acceptLanguage string) []string {
+	type language struct {
+		tag     string
+		quality float64
+	}
+	var languages []language
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag, _, _ = strings.Cut(tag, "-")
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		if quality > 0 {
+			languages = append(languages, language{strings.ToLower(tag), quality})
+		}
+	}
+	sort.SliceStable(languages, func(i, j int) bool {
+		return languages[i].quality > languages[j].quality
+	})
+
+	tags := make([]string, 0, len(languages))
+	seen := map[string]bool{}
+	for _, l := range languages {
+		if !seen[l.tag] {
+			seen[l.tag] = true
+			tags = append(tags, l.tag)
+		}
+	}
+	return tags
+}
+
+// fieldPath drops the name of the request model from the namespace of a
+// field, leaving a path such as items[0].amount.
+func fieldPath(namespace string) string {
+	_, path, found := strings.Cut(namespace, ".")
+	if !found {
+		return namespace
+	}
+	return path
+}
+
+// jsonNames converts the Go field names of a rule param, such as "Items" or
+// "Tags IsShared", to the JSON names of the models.
+func jsonNames(param string) string {
+	names := strings.Fields(param)
+	for i, name := range names {
+		if name != "" && name[0] >= 'A' && name[0] <= 'Z' {
+			names[i] = strings.ToLower(name[:1]) + name[1:]
+		}
+	}
+	return strings.Join(names, " ")
+}
@@ -0,0 +1,243 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"kakeibo-tanuki/internal/events"
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/repositories"
+)
+
+const (
+	// MaxAttempts is how often a delivery is attempted before it fails.
+	MaxAttempts = 8
+	// BaseDelay is the wait before the first retry; it doubles after every
+	// failed attempt up to MaxDelay.
+	BaseDelay = 30 * time.Second
+	MaxDelay  = 6 * time.Hour
+	// Timeout bounds how long a webhook may take to answer.
+	Timeout = 10 * time.Second
+	// batchSize is how many due deliveries are attempted at a time.
+	batchSize = 50
+)
+
+// Backoff returns the wait after the given number of failed attempts.
+func Backoff(attempts int) time.Duration {
+	delay := BaseDelay
+	for i := 1; i < attempts && delay < MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > MaxDelay {
+		return MaxDelay
+	}
+	return delay
+}
+
+// Dispatcher queues events for the webhooks subscribed to them and delivers
+// the queue. It is safe for concurrent use.
+type Dispatcher struct {
+	repo   repositories.WebhookRepository
+	client *http.Client
+	// wake starts a delivery run of the worker when events were queued
+	wake chan struct{}
+	// mu keeps deliveries from being attempted by two runs at once
+	mu sync.Mutex
+}
+
+func NewDispatcher(repo repositories.WebhookRepository) *Dispatcher {
+	return &Dispatcher{
+		repo:   repo,
+		client: &http.Client{Timeout: Timeout},
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// HandleEvent queues event for every enabled webhook subscribed to it. It is
+// subscribed to the event bus, so events are queued once the change they
+// describe has been saved.
+func (d *Dispatcher) HandleEvent(event events.Event) {
+	ctx := context.Background()
+	webhooks, err := d.repo.GetAll(ctx)
+	if err != nil {
+		log.Printf("Failed to load webhooks: %v", err)
+		return
+	}
+
+	// Every webhook receives the event with the same ID, so receivers can
+	// tell retries and copies apart
+	eventID := uuid.New()
+	payload, err := json.Marshal(models.WebhookPayload{
+		ID:   eventID,
+		Type: event.Type,
+		Time: event.Time,
+		Data: event.Subject(),
+	})
+	if err != nil {
+		log.Printf("Failed to encode %s event: %v", event.Type, err)
+		return
+	}
+
+	var deliveries []models.WebhookDelivery
+	for _, webhook := range webhooks {
+		if !webhook.Enabled || !Matches(webhook.Events, event.Type) {
+			continue
+		}
+		nextAttemptAt := event.Time
+		deliveries = append(deliveries, models.WebhookDelivery{
+			ID:            uuid.New(),
+			WebhookID:     webhook.ID,
+			EventID:       eventID,
+			EventType:     event.Type,
+			Payload:       string(payload),
+			Status:        models.DeliveryPending,
+			NextAttemptAt: &nextAttemptAt,
+		})
+	}
+	if len(deliveries) == 0 {
+		return
+	}
+	if err := d.repo.CreateDeliveries(ctx, deliveries); err != nil {
+		log.Printf("Failed to queue %s event: %v", event.Type, err)
+		return
+	}
+
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// ProcessDue attempts the deliveries that are due at now and returns how
+// many it attempted.
+func (d *Dispatcher) ProcessDue(now time.Time) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	ctx := context.Background()
+	deliveries, err := d.repo.GetDueDeliveries(ctx, now, batchSize)
+	if err != nil {
+		return 0, err
+	}
+	webhooks := map[uuid.UUID]*models.Webhook{}
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		webhook, ok := webhooks[delivery.WebhookID]
+		if !ok {
+			webhook, err = d.repo.GetByID(ctx, delivery.WebhookID)
+			if err != nil {
+				return i, err
+			}
+			webhooks[delivery.WebhookID] = webhook
+		}
+
+		d.attempt(webhook, delivery, now)
+		if err := d.repo.UpdateDelivery(ctx, delivery); err != nil {
+			return i + 1, err
+		}
+	}
+	return len(deliveries), nil
+}
+
+// Start delivers the queue whenever events are queued and every interval,
+// for retries, until stop is called.
+func (d *Dispatcher) Start(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+			case <-d.wake:
+			case <-done:
+				return
+			}
+			// A full batch may leave more deliveries due
+			for {
+				n, err := d.ProcessDue(time.Now().UTC())
+				if err != nil {
+					log.Printf("Failed to deliver webhooks: %v", err)
+				}
+				if err != nil || n < batchSize {
+					break
+				}
+			}
+		}
+	}()
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
+
+// attempt posts a delivery and records the outcome in it: succeeded, retried
+// after the backoff, or failed after MaxAttempts or when the webhook was
+// disabled.
+func (d *Dispatcher) attempt(webhook *models.Webhook, delivery *models.WebhookDelivery, now time.Time) {
+	attemptedAt := now
+	delivery.LastAttemptAt = &attemptedAt
+	delivery.ResponseStatus = 0
+
+	if !webhook.Enabled {
+		delivery.Status = models.DeliveryFailed
+		delivery.NextAttemptAt = nil
+		delivery.LastError = "webhook is disabled"
+		return
+	}
+
+	delivery.Attempts++
+	status, err := d.post(webhook, delivery, now)
+	delivery.ResponseStatus = status
+	if err == nil {
+		delivery.Status = models.DeliverySucceeded
+		delivery.NextAttemptAt = nil
+		delivery.LastError = ""
+		return
+	}
+
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= MaxAttempts {
+		delivery.Status = models.DeliveryFailed
+		delivery.NextAttemptAt = nil
+		return
+	}
+	next := now.Add(Backoff(delivery.Attempts))
+	delivery.NextAttemptAt = &next
+}
+
+func (d *Dispatcher) post(webhook *models.Webhook, delivery *models.WebhookDelivery, now time.Time) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := now.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "kakeibo-tanuki-webhooks")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, delivery.ID.String())
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(webhook.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,74 @@
+// Package webhooks delivers expense, card and category events to the
+// webhooks subscribed to them. Events are queued in the database when they
+// are published and posted by a worker that retries failed deliveries with
+// exponential backoff.
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"kakeibo-tanuki/internal/events"
+)
+
+// Headers of webhook requests.
+const (
+	HeaderEvent     = "X-Kakeibo-Event"
+	HeaderDelivery  = "X-Kakeibo-Delivery"
+	HeaderTimestamp = "X-Kakeibo-Timestamp"
+	HeaderSignature = "X-Kakeibo-Signature"
+)
+
+// Sign returns the signature of a payload sent at timestamp (Unix seconds):
+// "sha256=" and the hex HMAC-SHA256 of the timestamp, a dot and the body,
+// keyed with the secret of the webhook. Signing the timestamp lets receivers
+// reject replayed requests.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the signature of a payload sent at
+// timestamp, comparing in constant time.
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// ValidPattern reports whether a webhook can subscribe to pattern: an event
+// type, a kind followed by ".*", or "*".
+func ValidPattern(pattern string) bool {
+	if pattern == "*" {
+		return true
+	}
+	for _, eventType := range events.Types {
+		if pattern == eventType || pattern == kind(eventType)+".*" {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches reports whether a webhook subscribed to patterns receives events
+// of eventType.
+func Matches(patterns []string, eventType string) bool {
+	for _, pattern := range patterns {
+		if pattern == "*" || pattern == eventType || pattern == kind(eventType)+".*" {
+			return true
+		}
+	}
+	return false
+}
+
+// kind returns the part of an event type before the dot, such as "card".
+func kind(eventType string) string {
+	if i := strings.Index(eventType, "."); i >= 0 {
+		return eventType[:i]
+	}
+	return eventType
+}
@@ -0,0 +1,29 @@
+// Package migrations embeds the SQL migrations of the database schema, so
+// that the binary can apply them wherever it runs. Each version has an up
+// migration, NNN_name.up.sql, and a down migration, NNN_name.down.sql, that
+// undoes it.
+//
+// The migrations of each database are in a directory named after its
+// dialect. SQLite is supported from version 13 on, so its migrations start
+// with the schema PostgreSQL has at that version; later versions are
+// written for both.
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+)
+
+//go:embed postgres/*.sql sqlite/*.sql
+var FS embed.FS
+
+// For returns the migrations of the dialect, "postgres" or "sqlite".
+func For(dialect string) (fs.FS, error) {
+	switch dialect {
+	case "postgres", "sqlite":
+		return fs.Sub(FS, dialect)
+	default:
+		return nil, fmt.Errorf("no migrations for database dialect: %s", dialect)
+	}
+}
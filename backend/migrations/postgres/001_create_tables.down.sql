@@ -0,0 +1,5 @@
+-- Drop the tables of the kakeibo application, with the default categories
+
+DROP TABLE IF EXISTS expenses;
+DROP TABLE IF EXISTS categories;
+DROP TABLE IF EXISTS cards;
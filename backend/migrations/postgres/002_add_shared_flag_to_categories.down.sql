@@ -0,0 +1,4 @@
+-- Remove the shared flag of categories
+
+DROP INDEX IF EXISTS idx_categories_is_shared;
+ALTER TABLE categories DROP COLUMN IF EXISTS is_shared;
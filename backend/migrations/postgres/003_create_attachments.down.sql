@@ -0,0 +1,3 @@
+-- Drop receipt attachments; their files are left in storage
+
+DROP TABLE IF EXISTS attachments;
@@ -0,0 +1,3 @@
+-- Drop the line items of expenses
+
+DROP TABLE IF EXISTS expense_items;
@@ -0,0 +1,12 @@
+-- Line items for expenses split across multiple categories
+
+CREATE TABLE IF NOT EXISTS expense_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    expense_id UUID NOT NULL REFERENCES expenses(id) ON DELETE CASCADE,
+    category_id UUID NOT NULL REFERENCES categories(id) ON DELETE RESTRICT,
+    amount DECIMAL(10,2) NOT NULL CHECK (amount > 0),
+    note TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_expense_items_expense_id ON expense_items(expense_id);
+CREATE INDEX IF NOT EXISTS idx_expense_items_category_id ON expense_items(category_id);
@@ -0,0 +1,4 @@
+-- Drop installment and revolving payment plans
+
+DROP TABLE IF EXISTS installment_payments;
+DROP TABLE IF EXISTS installment_plans;
@@ -0,0 +1,30 @@
+-- Installment (分割払い) and revolving (リボ払い) payment plans for card purchases
+
+CREATE TABLE IF NOT EXISTS installment_plans (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    expense_id UUID NOT NULL UNIQUE REFERENCES expenses(id) ON DELETE CASCADE,
+    payment_type VARCHAR(20) NOT NULL CHECK (payment_type IN ('installment', 'revolving')),
+    number_of_payments INTEGER NOT NULL CHECK (number_of_payments > 0),
+    annual_interest_rate DECIMAL(5,2) NOT NULL DEFAULT 0,
+    monthly_payment DECIMAL(10,2),
+    first_payment_date DATE NOT NULL,
+    total_interest DECIMAL(10,2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- One row per billing month of a plan
+CREATE TABLE IF NOT EXISTS installment_payments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    plan_id UUID NOT NULL REFERENCES installment_plans(id) ON DELETE CASCADE,
+    expense_id UUID NOT NULL REFERENCES expenses(id) ON DELETE CASCADE,
+    sequence INTEGER NOT NULL,
+    due_date DATE NOT NULL,
+    principal DECIMAL(10,2) NOT NULL,
+    interest DECIMAL(10,2) NOT NULL DEFAULT 0,
+    amount DECIMAL(10,2) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_installment_payments_plan_id ON installment_payments(plan_id);
+CREATE INDEX IF NOT EXISTS idx_installment_payments_expense_id ON installment_payments(expense_id);
+CREATE INDEX IF NOT EXISTS idx_installment_payments_due_date ON installment_payments(due_date);
@@ -0,0 +1,3 @@
+-- Drop refunds
+
+DROP TABLE IF EXISTS refunds;
@@ -0,0 +1,5 @@
+-- Remove currencies; amounts in other currencies are left as if they were yen
+
+DROP TABLE IF EXISTS exchange_rates;
+ALTER TABLE expenses DROP COLUMN IF EXISTS currency;
+DROP TABLE IF EXISTS books;
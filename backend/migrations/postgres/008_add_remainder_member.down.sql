@@ -0,0 +1,3 @@
+-- Remove the member the remainder of shared expenses goes to
+
+ALTER TABLE books DROP COLUMN IF EXISTS remainder_member;
@@ -0,0 +1,3 @@
+-- Shared expenses are split in whole units of the base currency; the
+-- remainder goes to the member at this index
+ALTER TABLE books ADD COLUMN IF NOT EXISTS remainder_member INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+-- Drop payees and the payees of expenses
+
+DROP INDEX IF EXISTS idx_expenses_payee_id;
+ALTER TABLE expenses DROP COLUMN IF EXISTS payee_id;
+DROP TABLE IF EXISTS payees;
@@ -0,0 +1,15 @@
+-- Payees with the aliases and patterns their expense descriptions are
+-- matched with
+
+CREATE TABLE IF NOT EXISTS payees (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL UNIQUE,
+    aliases TEXT,
+    patterns TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE expenses ADD COLUMN IF NOT EXISTS payee_id UUID REFERENCES payees(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_expenses_payee_id ON expenses(payee_id);
@@ -0,0 +1,5 @@
+-- Drop rules, and the tags and shared flag of expenses
+
+ALTER TABLE expenses DROP COLUMN IF EXISTS is_shared;
+ALTER TABLE expenses DROP COLUMN IF EXISTS tags;
+DROP TABLE IF EXISTS rules;
@@ -0,0 +1,26 @@
+-- Rules that categorize, tag and share expenses automatically, and the tags
+-- and shared flag they set on expenses
+
+CREATE TABLE IF NOT EXISTS rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    priority INTEGER NOT NULL DEFAULT 0,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    description_pattern TEXT,
+    min_amount DECIMAL(10,2),
+    max_amount DECIMAL(10,2),
+    -- A rule limited to a card or payee goes away with it rather than
+    -- matching every expense
+    card_id UUID REFERENCES cards(id) ON DELETE CASCADE,
+    payee_id UUID REFERENCES payees(id) ON DELETE CASCADE,
+    category_id UUID REFERENCES categories(id) ON DELETE SET NULL,
+    tags TEXT,
+    is_shared BOOLEAN,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_rules_priority ON rules(priority);
+
+ALTER TABLE expenses ADD COLUMN IF NOT EXISTS tags TEXT;
+ALTER TABLE expenses ADD COLUMN IF NOT EXISTS is_shared BOOLEAN;
@@ -0,0 +1,4 @@
+-- Drop budget alerts and their notifications
+
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS alerts;
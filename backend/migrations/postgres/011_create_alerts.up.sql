@@ -0,0 +1,35 @@
+-- Budget alerts and the notifications they leave in the app
+
+CREATE TABLE IF NOT EXISTS alerts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    -- An alert limited to a card or category goes away with it rather than
+    -- watching all spending
+    category_id UUID REFERENCES categories(id) ON DELETE CASCADE,
+    card_id UUID REFERENCES cards(id) ON DELETE CASCADE,
+    period VARCHAR(10) NOT NULL CHECK (period IN ('week', 'month', 'quarter', 'year')),
+    amount DECIMAL(10,2) NOT NULL,
+    percent INTEGER NOT NULL DEFAULT 100,
+    channels TEXT,
+    email VARCHAR(255),
+    webhook_url TEXT,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    last_triggered VARCHAR(10),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_alerts_category_id ON alerts(category_id);
+CREATE INDEX IF NOT EXISTS idx_alerts_card_id ON alerts(card_id);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    alert_id UUID REFERENCES alerts(id) ON DELETE SET NULL,
+    title TEXT NOT NULL,
+    message TEXT NOT NULL,
+    read_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_alert_id ON notifications(alert_id);
+CREATE INDEX IF NOT EXISTS idx_notifications_created_at ON notifications(created_at);
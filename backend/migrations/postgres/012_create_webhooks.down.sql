@@ -0,0 +1,4 @@
+-- Drop webhooks and their deliveries
+
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
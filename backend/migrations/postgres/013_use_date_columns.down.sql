@@ -0,0 +1,4 @@
+-- Dates stay days: the times of day the timestamps had are gone, and the
+-- application reads DATE columns as well
+
+SELECT 1;
@@ -0,0 +1,6 @@
+-- Databases created by GORM AutoMigrate before migrations were run by the
+-- application store the dates of expenses and refunds as timestamps; store
+-- them as days, as the other migrations do
+
+ALTER TABLE expenses ALTER COLUMN date TYPE DATE USING date::date;
+ALTER TABLE refunds ALTER COLUMN date TYPE DATE USING date::date;
@@ -0,0 +1,18 @@
+-- Drop the tables of the kakeibo application on SQLite
+
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS alerts;
+DROP TABLE IF EXISTS rules;
+DROP TABLE IF EXISTS exchange_rates;
+DROP TABLE IF EXISTS books;
+DROP TABLE IF EXISTS refunds;
+DROP TABLE IF EXISTS installment_payments;
+DROP TABLE IF EXISTS installment_plans;
+DROP TABLE IF EXISTS expense_items;
+DROP TABLE IF EXISTS attachments;
+DROP TABLE IF EXISTS expenses;
+DROP TABLE IF EXISTS payees;
+DROP TABLE IF EXISTS categories;
+DROP TABLE IF EXISTS cards;
@@ -0,0 +1,234 @@
+-- Create the tables of the kakeibo application on SQLite. SQLite is
+-- supported from version 13 on, so its schema starts as the one the
+-- PostgreSQL migrations up to 013 build, with IDs stored as text
+
+CREATE TABLE IF NOT EXISTS cards (
+    id TEXT PRIMARY KEY NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    color VARCHAR(7) NOT NULL DEFAULT '#3B82F6',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS categories (
+    id TEXT PRIMARY KEY NOT NULL,
+    name VARCHAR(50) NOT NULL UNIQUE,
+    color VARCHAR(7) NOT NULL DEFAULT '#10B981',
+    is_shared BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_categories_is_shared ON categories(is_shared);
+
+CREATE TABLE IF NOT EXISTS payees (
+    id TEXT PRIMARY KEY NOT NULL,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    aliases TEXT,
+    patterns TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS expenses (
+    id TEXT PRIMARY KEY NOT NULL,
+    amount DECIMAL(10,2) NOT NULL CHECK (amount > 0),
+    currency VARCHAR(3) NOT NULL DEFAULT 'JPY',
+    date DATE NOT NULL,
+    description TEXT,
+    card_id TEXT NOT NULL REFERENCES cards(id) ON DELETE CASCADE,
+    category_id TEXT NOT NULL REFERENCES categories(id) ON DELETE RESTRICT,
+    payee_id TEXT REFERENCES payees(id) ON DELETE SET NULL,
+    tags TEXT,
+    is_shared BOOLEAN,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_expenses_date ON expenses(date DESC);
+CREATE INDEX IF NOT EXISTS idx_expenses_card_id ON expenses(card_id);
+CREATE INDEX IF NOT EXISTS idx_expenses_category_id ON expenses(category_id);
+CREATE INDEX IF NOT EXISTS idx_expenses_date_card ON expenses(date DESC, card_id);
+CREATE INDEX IF NOT EXISTS idx_expenses_date_amount ON expenses(date, amount);
+CREATE INDEX IF NOT EXISTS idx_expenses_payee_id ON expenses(payee_id);
+
+CREATE TABLE IF NOT EXISTS attachments (
+    id TEXT PRIMARY KEY NOT NULL,
+    expense_id TEXT NOT NULL REFERENCES expenses(id) ON DELETE CASCADE,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL,
+    storage_key TEXT NOT NULL,
+    thumbnail_key TEXT,
+    has_thumbnail BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_attachments_expense_id ON attachments(expense_id);
+
+CREATE TABLE IF NOT EXISTS expense_items (
+    id TEXT PRIMARY KEY NOT NULL,
+    expense_id TEXT NOT NULL REFERENCES expenses(id) ON DELETE CASCADE,
+    category_id TEXT NOT NULL REFERENCES categories(id) ON DELETE RESTRICT,
+    amount DECIMAL(10,2) NOT NULL CHECK (amount > 0),
+    note TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_expense_items_expense_id ON expense_items(expense_id);
+CREATE INDEX IF NOT EXISTS idx_expense_items_category_id ON expense_items(category_id);
+
+CREATE TABLE IF NOT EXISTS installment_plans (
+    id TEXT PRIMARY KEY NOT NULL,
+    expense_id TEXT NOT NULL UNIQUE REFERENCES expenses(id) ON DELETE CASCADE,
+    payment_type VARCHAR(20) NOT NULL CHECK (payment_type IN ('installment', 'revolving')),
+    number_of_payments INTEGER NOT NULL CHECK (number_of_payments > 0),
+    annual_interest_rate DECIMAL(5,2) NOT NULL DEFAULT 0,
+    monthly_payment DECIMAL(10,2),
+    first_payment_date DATE NOT NULL,
+    total_interest DECIMAL(10,2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS installment_payments (
+    id TEXT PRIMARY KEY NOT NULL,
+    plan_id TEXT NOT NULL REFERENCES installment_plans(id) ON DELETE CASCADE,
+    expense_id TEXT NOT NULL REFERENCES expenses(id) ON DELETE CASCADE,
+    sequence INTEGER NOT NULL,
+    due_date DATE NOT NULL,
+    principal DECIMAL(10,2) NOT NULL,
+    interest DECIMAL(10,2) NOT NULL DEFAULT 0,
+    amount DECIMAL(10,2) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_installment_payments_plan_id ON installment_payments(plan_id);
+CREATE INDEX IF NOT EXISTS idx_installment_payments_expense_id ON installment_payments(expense_id);
+CREATE INDEX IF NOT EXISTS idx_installment_payments_due_date ON installment_payments(due_date);
+
+CREATE TABLE IF NOT EXISTS refunds (
+    id TEXT PRIMARY KEY NOT NULL,
+    expense_id TEXT NOT NULL REFERENCES expenses(id) ON DELETE CASCADE,
+    amount DECIMAL(10,2) NOT NULL CHECK (amount > 0),
+    date DATE NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_refunds_expense_id ON refunds(expense_id);
+CREATE INDEX IF NOT EXISTS idx_refunds_date ON refunds(date);
+
+CREATE TABLE IF NOT EXISTS books (
+    id TEXT PRIMARY KEY NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    base_currency VARCHAR(3) NOT NULL DEFAULT 'JPY',
+    remainder_member INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id TEXT PRIMARY KEY NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    base_currency VARCHAR(3) NOT NULL,
+    date DATE NOT NULL,
+    rate DECIMAL(18,8) NOT NULL CHECK (rate > 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT idx_exchange_rates_pair_date UNIQUE (currency, base_currency, date)
+);
+
+CREATE TABLE IF NOT EXISTS rules (
+    id TEXT PRIMARY KEY NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    priority INTEGER NOT NULL DEFAULT 0,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    description_pattern TEXT,
+    min_amount DECIMAL(10,2),
+    max_amount DECIMAL(10,2),
+    card_id TEXT REFERENCES cards(id) ON DELETE CASCADE,
+    payee_id TEXT REFERENCES payees(id) ON DELETE CASCADE,
+    category_id TEXT REFERENCES categories(id) ON DELETE SET NULL,
+    tags TEXT,
+    is_shared BOOLEAN,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_rules_priority ON rules(priority);
+
+CREATE TABLE IF NOT EXISTS alerts (
+    id TEXT PRIMARY KEY NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    category_id TEXT REFERENCES categories(id) ON DELETE CASCADE,
+    card_id TEXT REFERENCES cards(id) ON DELETE CASCADE,
+    period VARCHAR(10) NOT NULL CHECK (period IN ('week', 'month', 'quarter', 'year')),
+    amount DECIMAL(10,2) NOT NULL,
+    percent INTEGER NOT NULL DEFAULT 100,
+    channels TEXT,
+    email VARCHAR(255),
+    webhook_url TEXT,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    last_triggered VARCHAR(10),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_alerts_category_id ON alerts(category_id);
+CREATE INDEX IF NOT EXISTS idx_alerts_card_id ON alerts(card_id);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id TEXT PRIMARY KEY NOT NULL,
+    alert_id TEXT REFERENCES alerts(id) ON DELETE SET NULL,
+    title TEXT NOT NULL,
+    message TEXT NOT NULL,
+    read_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_alert_id ON notifications(alert_id);
+CREATE INDEX IF NOT EXISTS idx_notifications_created_at ON notifications(created_at);
+
+CREATE TABLE IF NOT EXISTS webhooks (
+    id TEXT PRIMARY KEY NOT NULL,
+    url TEXT NOT NULL,
+    secret VARCHAR(200) NOT NULL,
+    events TEXT,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id TEXT PRIMARY KEY NOT NULL,
+    webhook_id TEXT NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    event_id TEXT NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(10) NOT NULL CHECK (status IN ('pending', 'succeeded', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP,
+    last_attempt_at TIMESTAMP,
+    response_status INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook_id ON webhook_deliveries(webhook_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(status, next_attempt_at);
+
+-- Insert default categories, with random version 4 UUIDs as IDs
+INSERT INTO categories (id, name, color)
+SELECT lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' ||
+       substr(lower(hex(randomblob(2))), 2) || '-' ||
+       substr('89ab', 1 + (abs(random()) % 4), 1) || substr(lower(hex(randomblob(2))), 2) || '-' ||
+       lower(hex(randomblob(6))),
+       column1, column2
+FROM (VALUES
+    ('食費', '#EF4444'),
+    ('交通費', '#3B82F6'),
+    ('娯楽費', '#8B5CF6'),
+    ('光熱費', '#F59E0B'),
+    ('その他', '#6B7280'))
+WHERE true
+ON CONFLICT (name) DO NOTHING;
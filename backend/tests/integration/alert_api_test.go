@@ -0,0 +1,211 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
+)
+
+func TestAlertAPI(t *testing.T) {
+	server := SetupTestServer(t)
+	defer server.CleanupTestServer()
+
+	card := server.CreateTestCard(t, "テストカード", "#3B82F6")
+	food := server.CreateTestCategory(t, "食費", "#10B981", false)
+	hobby := server.CreateTestCategory(t, "趣味", "#8B5CF6", false)
+
+	var mu sync.Mutex
+	var delivered []models.AlertEvent
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event models.AlertEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err == nil {
+			mu.Lock()
+			delivered = append(delivered, event)
+			mu.Unlock()
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer webhook.Close()
+	webhookEvents := func() []models.AlertEvent {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]models.AlertEvent(nil), delivered...)
+	}
+
+	today := time.Now().UTC().Format("2006-01-02")
+	createExpense := func(t *testing.T, amount int64, categoryID string) {
+		w := server.MakeRequest("POST", "/api/expenses", models.CreateExpenseRequest{
+			Amount:      money.New(amount),
+			Date:        today,
+			Description: "スーパー",
+			CardID:      card.ID.String(),
+			CategoryID:  categoryID,
+		})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	}
+	notifications := func(t *testing.T, query string) []models.Notification {
+		w := server.MakeRequest("GET", "/api/notifications"+query, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var list []models.Notification
+		decodeData(t, w.Body.Bytes(), &list)
+		return list
+	}
+
+	w := server.MakeRequest("POST", "/api/alerts", models.CreateAlertRequest{
+		Name:       "食費の予算",
+		CategoryID: food.ID.String(),
+		Period:     models.AlertPeriodMonth,
+		Amount:     money.New(10000),
+		Percent:    80,
+		Channels:   []string{models.ChannelInApp, models.ChannelWebhook},
+		WebhookURL: webhook.URL,
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var alert models.Alert
+	decodeData(t, w.Body.Bytes(), &alert)
+	assert.True(t, alert.Enabled)
+	assert.Equal(t, 80, alert.Percent)
+
+	t.Run("fires once when the threshold is reached", func(t *testing.T) {
+		createExpense(t, 5000, food.ID.String())
+		// Spending in other categories does not count
+		createExpense(t, 20000, hobby.ID.String())
+		assert.Empty(t, notifications(t, ""))
+
+		createExpense(t, 3500, food.ID.String())
+		list := notifications(t, "")
+		require.Len(t, list, 1)
+		assert.Equal(t, alert.ID, *list[0].AlertID)
+		assert.Contains(t, list[0].Message, "8500 JPY")
+		assert.Nil(t, list[0].ReadAt)
+
+		require.Eventually(t, func() bool { return len(webhookEvents()) == 1 }, 5*time.Second, 10*time.Millisecond)
+		event := webhookEvents()[0]
+		assert.Equal(t, alert.ID, event.AlertID)
+		assert.Equal(t, money.New(8500), event.Spent)
+		assert.Equal(t, money.New(8000), event.Threshold)
+		assert.InDelta(t, 85, event.Percent, 1e-9)
+
+		createExpense(t, 1000, food.ID.String())
+		assert.Len(t, notifications(t, ""), 1)
+
+		w := server.MakeRequest("POST", "/api/alerts/check", nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var fired []models.AlertEvent
+		decodeData(t, w.Body.Bytes(), &fired)
+		assert.Empty(t, fired)
+	})
+
+	t.Run("check fires updated alerts again", func(t *testing.T) {
+		w := server.MakeRequest("PUT", "/api/alerts/"+alert.ID.String(), models.UpdateAlertRequest{
+			Name:       "食費の予算",
+			CategoryID: food.ID.String(),
+			Period:     models.AlertPeriodMonth,
+			Amount:     money.New(9000),
+			Channels:   []string{models.ChannelInApp},
+		})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var updated models.Alert
+		decodeData(t, w.Body.Bytes(), &updated)
+		assert.Equal(t, 100, updated.Percent)
+		assert.Empty(t, updated.LastTriggered)
+
+		w = server.MakeRequest("POST", "/api/alerts/check", nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var fired []models.AlertEvent
+		decodeData(t, w.Body.Bytes(), &fired)
+		require.Len(t, fired, 1)
+		assert.Equal(t, money.New(9500), fired[0].Spent)
+		assert.Empty(t, fired[0].Errors)
+		assert.Len(t, notifications(t, ""), 2)
+		assert.Len(t, webhookEvents(), 1)
+	})
+
+	t.Run("notifications are marked read", func(t *testing.T) {
+		list := notifications(t, "?unread=true")
+		require.Len(t, list, 2)
+
+		w := server.MakeRequest("PUT", "/api/notifications/"+list[0].ID.String()+"/read", nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var read models.Notification
+		decodeData(t, w.Body.Bytes(), &read)
+		require.NotNil(t, read.ReadAt)
+
+		assert.Len(t, notifications(t, "?unread=true"), 1)
+		assert.Len(t, notifications(t, ""), 2)
+
+		w = server.MakeRequest("PUT", "/api/notifications/"+alert.ID.String()+"/read", nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("disabled alerts do not fire", func(t *testing.T) {
+		disabled := false
+		w := server.MakeRequest("POST", "/api/alerts", models.CreateAlertRequest{
+			Name:     "カードの予算",
+			CardID:   card.ID.String(),
+			Period:   models.AlertPeriodWeek,
+			Amount:   money.New(1000),
+			Channels: []string{models.ChannelInApp},
+			Enabled:  &disabled,
+		})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		w = server.MakeRequest("POST", "/api/alerts/check", nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var fired []models.AlertEvent
+		decodeData(t, w.Body.Bytes(), &fired)
+		assert.Empty(t, fired)
+	})
+
+	valid := models.CreateAlertRequest{Name: "予算", Period: models.AlertPeriodMonth, Amount: money.New(10000), Channels: []string{models.ChannelInApp}}
+	with := func(change func(req *models.CreateAlertRequest)) models.CreateAlertRequest {
+		req := valid
+		change(&req)
+		return req
+	}
+	invalid := []struct {
+		name         string
+		body         models.CreateAlertRequest
+		expectedCode string
+	}{
+		{name: "Invalid period", body: with(func(req *models.CreateAlertRequest) { req.Period = "day" }), expectedCode: "VALIDATION_ERROR"},
+		{name: "Zero amount", body: with(func(req *models.CreateAlertRequest) { req.Amount = 0 }), expectedCode: "VALIDATION_ERROR"},
+		{name: "No channels", body: with(func(req *models.CreateAlertRequest) { req.Channels = nil }), expectedCode: "VALIDATION_ERROR"},
+		{name: "Unknown channel", body: with(func(req *models.CreateAlertRequest) { req.Channels = []string{"sms"} }), expectedCode: "VALIDATION_ERROR"},
+		{name: "Webhook without URL", body: with(func(req *models.CreateAlertRequest) { req.Channels = []string{models.ChannelWebhook} }), expectedCode: "VALIDATION_ERROR"},
+		{name: "Email not configured", body: with(func(req *models.CreateAlertRequest) {
+			req.Channels = []string{models.ChannelEmail}
+			req.Email = "tanuki@example.com"
+		}), expectedCode: "CHANNEL_UNAVAILABLE"},
+		{name: "Invalid category ID", body: with(func(req *models.CreateAlertRequest) { req.CategoryID = "invalid" }), expectedCode: "INVALID_CATEGORY_ID"},
+		{name: "Category not found", body: with(func(req *models.CreateAlertRequest) { req.CategoryID = alert.ID.String() }), expectedCode: "CATEGORY_NOT_FOUND"},
+	}
+
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			w := server.MakeRequest("POST", "/api/alerts", tt.body)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var response models.ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedCode, response.Error.Code)
+		})
+	}
+
+	t.Run("deleted", func(t *testing.T) {
+		w := server.MakeRequest("DELETE", "/api/alerts/"+alert.ID.String(), nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		w = server.MakeRequest("GET", "/api/alerts/"+alert.ID.String(), nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -2,23 +2,44 @@ package integration
 
 import (
 	"bytes"
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 
+	"kakeibo-tanuki/internal/alerts"
+	"kakeibo-tanuki/internal/events"
 	"kakeibo-tanuki/internal/handlers"
 	"kakeibo-tanuki/internal/middleware"
+	"kakeibo-tanuki/internal/migrate"
 	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
+	"kakeibo-tanuki/internal/notify"
 	"kakeibo-tanuki/internal/repositories"
+	"kakeibo-tanuki/internal/services"
+	"kakeibo-tanuki/internal/storage"
+	"kakeibo-tanuki/internal/stream"
+	"kakeibo-tanuki/internal/suggestions"
+	"kakeibo-tanuki/internal/webhooks"
+	"kakeibo-tanuki/migrations"
 )
 
 // TestServer represents a test server setup for API integration tests
@@ -26,44 +47,172 @@ type TestServer struct {
 	Router     *gin.Engine
 	DB         *gorm.DB
 	Repository *repositories.Repository
+	Storage    storage.Storage
+	// Webhooks delivers queued webhook events when a test calls ProcessDue
+	Webhooks *webhooks.Dispatcher
+	Events   *stream.Broker
 }
 
-// SetupTestServer creates a new test server with in-memory database
+// openTestDB opens a database of the test's own and applies the migrations
+// of the app to it. Tests start without the default categories.
+//
+// DB_DRIVER selects the database as it does for the app, but defaults to
+// "sqlite", which opens a file in a temporary directory. "postgres" creates
+// a schema, dropped after the test, in the database at TEST_DATABASE_DSN.
+func openTestDB(t *testing.T) (*gorm.DB, error) {
+	driver := os.Getenv("DB_DRIVER")
+	if driver == "" {
+		driver = "sqlite"
+	}
+
+	var dialector gorm.Dialector
+	switch driver {
+	case "sqlite":
+		dsn := filepath.Join(t.TempDir(), "kakeibo.db") + "?_foreign_keys=on&_journal_mode=WAL&_busy_timeout=5000&_txlock=immediate"
+		dialector = sqlite.Open(dsn)
+	case "postgres":
+		conn, err := openTestSchema(t)
+		if err != nil {
+			return nil, err
+		}
+		dialector = postgres.New(postgres.Config{Conn: conn})
+	default:
+		return nil, fmt.Errorf("unknown database driver: %s", driver)
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fsys, err := migrations.For(driver)
+	if err != nil {
+		return nil, err
+	}
+	migrator, err := migrate.New(db, fsys)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := migrator.Up(); err != nil {
+		return nil, err
+	}
+	return db, db.Exec("DELETE FROM categories").Error
+}
+
+// openTestSchema creates an empty schema in the PostgreSQL database at
+// TEST_DATABASE_DSN and connects to it, so that tests do not see each
+// other's tables.
+func openTestSchema(t *testing.T) (*sql.DB, error) {
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		return nil, errors.New("TEST_DATABASE_DSN is required to test with PostgreSQL")
+	}
+
+	config, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+	admin := stdlib.OpenDB(*config)
+
+	schema := "test_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		admin.Close()
+		return nil, err
+	}
+	t.Cleanup(func() {
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		admin.Close()
+	})
+
+	// Parsed again, as copies of a config share their runtime parameters
+	config, err = pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+	config.RuntimeParams["search_path"] = schema
+	conn := stdlib.OpenDB(*config)
+	t.Cleanup(func() { conn.Close() })
+	return conn, nil
+}
+
+// SetupTestServer creates a new test server with a temporary database
 func SetupTestServer(t *testing.T) *TestServer {
 	// Set Gin to test mode
 	gin.SetMode(gin.TestMode)
 
-	// Setup test database
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
-		Logger:                                   logger.Default.LogMode(logger.Silent),
-		DisableForeignKeyConstraintWhenMigrating: true,
-	})
+	// Setup a test database of its own, with the schema the app migrates
+	// databases to
+	db, err := openTestDB(t)
 	require.NoError(t, err)
 
-	// Create tables with simplified schema for testing
-	err = db.Exec("CREATE TABLE IF NOT EXISTS cards (id TEXT PRIMARY KEY, name TEXT NOT NULL, color TEXT NOT NULL, created_at DATETIME, updated_at DATETIME)").Error
-	require.NoError(t, err)
+	// Initialize repositories, and the unit of work that services run
+	// transactions with
+	uow := services.NewUnitOfWork(db)
+	repo := uow.Repository()
 
-	err = db.Exec("CREATE TABLE IF NOT EXISTS categories (id TEXT PRIMARY KEY, name TEXT NOT NULL UNIQUE, color TEXT NOT NULL, is_shared BOOLEAN, created_at DATETIME, updated_at DATETIME)").Error
+	// Store attachments in a per-test temporary directory
+	store, err := storage.NewLocalStorage(t.TempDir())
 	require.NoError(t, err)
 
-	err = db.Exec("CREATE TABLE IF NOT EXISTS expenses (id TEXT PRIMARY KEY, amount REAL NOT NULL, date DATETIME, description TEXT, card_id TEXT, category_id TEXT, created_at DATETIME, updated_at DATETIME)").Error
-	require.NoError(t, err)
+	// Category suggestions are learned from the expenses on first use
+	suggester := suggestions.NewModel(repo.Expense.GetHistory)
+
+	// Alerts are delivered in the app and by webhook; email is not configured
+	evaluator := alerts.NewEvaluator(repo.Alert, repo.Expense, repo.Book, map[string]notify.Notifier{
+		models.ChannelInApp:   notify.NewInAppNotifier(repo.Notification),
+		models.ChannelWebhook: notify.NewWebhookNotifier(),
+	})
+
+	// Changes of expenses are published to the parts that keep up with them
+	bus := events.NewBus()
+	bus.Subscribe(suggester.HandleEvent)
+	bus.Subscribe(evaluator.HandleEvent)
+
+	// Webhook events are queued but only delivered when a test asks
+	dispatcher := webhooks.NewDispatcher(repo.Webhook)
+	bus.Subscribe(dispatcher.HandleEvent)
+
+	// Recent events are kept for clients of the event stream that resume
+	broker := stream.NewBroker(100)
+	bus.Subscribe(broker.HandleEvent)
 
-	// Initialize repositories
-	repo := repositories.NewRepository(db)
+	// Initialize services
+	cardService := services.NewCardService(uow)
+	categoryService := services.NewCategoryService(uow)
+	expenseService := services.NewExpenseService(uow)
+	refundService := services.NewRefundService(uow)
+	installmentService := services.NewInstallmentService(uow)
 
 	// Initialize handlers
-	cardHandler := handlers.NewCardHandler(repo.Card)
-	categoryHandler := handlers.NewCategoryHandler(repo.Category)
-	expenseHandler := handlers.NewExpenseHandler(repo.Expense)
-	reportHandler := handlers.NewReportHandler(repo.Expense)
+	cardHandler := handlers.NewCardHandler(cardService, bus)
+	categoryHandler := handlers.NewCategoryHandler(categoryService, bus)
+	payeeHandler := handlers.NewPayeeHandler(repo.Payee)
+	ruleHandler := handlers.NewRuleHandler(repo.Rule, repo.Card, repo.Category, repo.Payee, bus)
+	expenseHandler := handlers.NewExpenseHandler(expenseService, bus, store)
+	attachmentHandler := handlers.NewAttachmentHandler(repo.Attachment, repo.Expense, store)
+	installmentHandler := handlers.NewInstallmentHandler(installmentService)
+	refundHandler := handlers.NewRefundHandler(refundService)
+	bookHandler := handlers.NewBookHandler(repo.Book)
+	exchangeRateHandler := handlers.NewExchangeRateHandler(repo.ExchangeRate, repo.Book)
+	reportHandler := handlers.NewReportHandler(repo.Expense, repo.Book)
+	insightHandler := handlers.NewInsightHandler(repo.Expense, repo.Book)
+	suggestionHandler := handlers.NewSuggestionHandler(suggester, repo.Category, repo.Book)
+	alertHandler := handlers.NewAlertHandler(repo.Alert, repo.Card, repo.Category, evaluator)
+	notificationHandler := handlers.NewNotificationHandler(repo.Notification)
+	webhookHandler := handlers.NewWebhookHandler(repo.Webhook)
+	// Pinged often so that tests need not wait for heartbeats
+	eventHandler := handlers.NewEventHandler(broker, 50*time.Millisecond)
+	openAPIHandler := handlers.NewOpenAPIHandler()
 
 	// Initialize Gin router
 	router := gin.New()
 
 	// Add middleware (but skip logging for tests)
 	router.Use(middleware.CORSMiddleware())
+	router.Use(middleware.ErrorMiddleware())
+	router.Use(middleware.TimeoutMiddleware(30*time.Second, "/api/events"))
 
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
@@ -76,6 +225,9 @@ func SetupTestServer(t *testing.T) *TestServer {
 	// API routes group
 	api := router.Group("/api")
 	{
+		// API description
+		api.GET("/openapi.json", openAPIHandler.GetOpenAPI)
+
 		// Card routes
 		cards := api.Group("/cards")
 		{
@@ -96,14 +248,70 @@ func SetupTestServer(t *testing.T) *TestServer {
 			categories.DELETE("/:id", categoryHandler.DeleteCategory)
 		}
 
+		// Payee routes
+		payees := api.Group("/payees")
+		{
+			payees.GET("", payeeHandler.GetPayees)
+			payees.POST("", payeeHandler.CreatePayee)
+			payees.GET("/:id", payeeHandler.GetPayee)
+			payees.PUT("/:id", payeeHandler.UpdatePayee)
+			payees.DELETE("/:id", payeeHandler.DeletePayee)
+		}
+
+		// Rule routes
+		rules := api.Group("/rules")
+		{
+			rules.GET("", ruleHandler.GetRules)
+			rules.POST("", ruleHandler.CreateRule)
+			rules.POST("/dry-run", ruleHandler.DryRunRules)
+			rules.POST("/apply", ruleHandler.ApplyRules)
+			rules.GET("/:id", ruleHandler.GetRule)
+			rules.PUT("/:id", ruleHandler.UpdateRule)
+			rules.DELETE("/:id", ruleHandler.DeleteRule)
+		}
+
 		// Expense routes
 		expenses := api.Group("/expenses")
 		{
 			expenses.GET("", expenseHandler.GetExpenses)
 			expenses.POST("", expenseHandler.CreateExpense)
+			expenses.POST("/suggest-category", suggestionHandler.SuggestCategory)
 			expenses.GET("/:id", expenseHandler.GetExpense)
 			expenses.PUT("/:id", expenseHandler.UpdateExpense)
 			expenses.DELETE("/:id", expenseHandler.DeleteExpense)
+
+			// Receipt attachments
+			expenses.GET("/:id/attachments", attachmentHandler.GetAttachments)
+			expenses.POST("/:id/attachments", attachmentHandler.UploadAttachment)
+			expenses.GET("/:id/attachments/:attachmentId", attachmentHandler.DownloadAttachment)
+			expenses.GET("/:id/attachments/:attachmentId/thumbnail", attachmentHandler.DownloadThumbnail)
+			expenses.DELETE("/:id/attachments/:attachmentId", attachmentHandler.DeleteAttachment)
+
+			// Installment payments
+			expenses.GET("/:id/installment", installmentHandler.GetInstallmentPlan)
+			expenses.PUT("/:id/installment", installmentHandler.SetInstallmentPlan)
+			expenses.DELETE("/:id/installment", installmentHandler.DeleteInstallmentPlan)
+
+			// Refunds
+			expenses.GET("/:id/refunds", refundHandler.GetRefunds)
+			expenses.POST("/:id/refunds", refundHandler.CreateRefund)
+			expenses.DELETE("/:id/refunds/:refundId", refundHandler.DeleteRefund)
+		}
+
+		// Installment routes
+		api.GET("/installments/balances", installmentHandler.GetBalances)
+
+		// Book settings
+		api.GET("/book", bookHandler.GetBook)
+		api.PUT("/book", bookHandler.UpdateBook)
+
+		// Exchange rate routes
+		exchangeRates := api.Group("/exchange-rates")
+		{
+			exchangeRates.GET("", exchangeRateHandler.GetExchangeRates)
+			exchangeRates.POST("", exchangeRateHandler.CreateExchangeRate)
+			exchangeRates.POST("/import", exchangeRateHandler.ImportExchangeRates)
+			exchangeRates.DELETE("/:id", exchangeRateHandler.DeleteExchangeRate)
 		}
 
 		// Report routes
@@ -111,6 +319,48 @@ func SetupTestServer(t *testing.T) *TestServer {
 		{
 			reports.GET("/monthly", reportHandler.GetMonthlyReport)
 			reports.GET("/yearly", reportHandler.GetYearlyReport)
+			reports.GET("/range", reportHandler.GetRangeReport)
+			reports.GET("/compare", reportHandler.GetComparisonReport)
+			reports.GET("/forecast", reportHandler.GetForecastReport)
+			reports.GET("/daily", reportHandler.GetDailyReport)
+		}
+
+		// Alert routes
+		alertRoutes := api.Group("/alerts")
+		{
+			alertRoutes.GET("", alertHandler.GetAlerts)
+			alertRoutes.POST("", alertHandler.CreateAlert)
+			alertRoutes.POST("/check", alertHandler.CheckAlerts)
+			alertRoutes.GET("/:id", alertHandler.GetAlert)
+			alertRoutes.PUT("/:id", alertHandler.UpdateAlert)
+			alertRoutes.DELETE("/:id", alertHandler.DeleteAlert)
+		}
+
+		// Notification routes
+		notifications := api.Group("/notifications")
+		{
+			notifications.GET("", notificationHandler.GetNotifications)
+			notifications.PUT("/:id/read", notificationHandler.MarkNotificationRead)
+		}
+
+		// Webhook routes
+		webhookRoutes := api.Group("/webhooks")
+		{
+			webhookRoutes.GET("", webhookHandler.GetWebhooks)
+			webhookRoutes.POST("", webhookHandler.CreateWebhook)
+			webhookRoutes.GET("/:id", webhookHandler.GetWebhook)
+			webhookRoutes.PUT("/:id", webhookHandler.UpdateWebhook)
+			webhookRoutes.DELETE("/:id", webhookHandler.DeleteWebhook)
+			webhookRoutes.GET("/:id/deliveries", webhookHandler.GetDeliveries)
+		}
+
+		// Live updates
+		api.GET("/events", eventHandler.StreamEvents)
+
+		// Insight routes
+		insights := api.Group("/insights")
+		{
+			insights.GET("/anomalies", insightHandler.GetAnomalies)
 		}
 	}
 
@@ -118,13 +368,29 @@ func SetupTestServer(t *testing.T) *TestServer {
 		Router:     router,
 		DB:         db,
 		Repository: repo,
+		Storage:    store,
+		Webhooks:   dispatcher,
+		Events:     broker,
 	}
 }
 
 // CleanupTestServer performs cleanup after tests
 func (ts *TestServer) CleanupTestServer() {
 	// Clear all tables
+	ts.DB.Exec("DELETE FROM webhook_deliveries")
+	ts.DB.Exec("DELETE FROM webhooks")
+	ts.DB.Exec("DELETE FROM notifications")
+	ts.DB.Exec("DELETE FROM alerts")
+	ts.DB.Exec("DELETE FROM attachments")
+	ts.DB.Exec("DELETE FROM expense_items")
+	ts.DB.Exec("DELETE FROM installment_payments")
+	ts.DB.Exec("DELETE FROM installment_plans")
+	ts.DB.Exec("DELETE FROM refunds")
+	ts.DB.Exec("DELETE FROM exchange_rates")
+	ts.DB.Exec("DELETE FROM books")
 	ts.DB.Exec("DELETE FROM expenses")
+	ts.DB.Exec("DELETE FROM rules")
+	ts.DB.Exec("DELETE FROM payees")
 	ts.DB.Exec("DELETE FROM categories")
 	ts.DB.Exec("DELETE FROM cards")
 }
@@ -137,7 +403,7 @@ func (ts *TestServer) CreateTestCard(t *testing.T, name, color string) *models.C
 		Color: color,
 	}
 	
-	err := ts.Repository.Card.Create(card)
+	err := ts.Repository.Card.Create(context.Background(), card)
 	require.NoError(t, err)
 	
 	return card
@@ -152,7 +418,7 @@ func (ts *TestServer) CreateTestCategory(t *testing.T, name, color string, isSha
 		IsShared: isShared,
 	}
 	
-	err := ts.Repository.Category.Create(category)
+	err := ts.Repository.Category.Create(context.Background(), category)
 	require.NoError(t, err)
 	
 	return category
@@ -162,14 +428,14 @@ func (ts *TestServer) CreateTestCategory(t *testing.T, name, color string, isSha
 func (ts *TestServer) CreateTestExpense(t *testing.T, amount float64, description string, cardID, categoryID uuid.UUID) *models.Expense {
 	expense := &models.Expense{
 		ID:          uuid.New(),
-		Amount:      amount,
+		Amount:      money.FromFloat(amount),
 		Date:        time.Now().Add(-24 * time.Hour), // Set a past date
 		Description: description,
 		CardID:      cardID,
 		CategoryID:  categoryID,
 	}
 	
-	err := ts.Repository.Expense.Create(expense)
+	err := ts.Repository.Expense.Create(context.Background(), expense)
 	require.NoError(t, err)
 	
 	return expense
@@ -0,0 +1,194 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/storage"
+)
+
+// uploadAttachment sends a multipart request with the given file contents
+func (ts *TestServer) uploadAttachment(t *testing.T, expenseID uuid.UUID, fileName string, content []byte) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", fileName)
+	require.NoError(t, err)
+	_, err = part.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req, err := http.NewRequest("POST", fmt.Sprintf("/api/expenses/%s/attachments", expenseID), &body)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	w := httptest.NewRecorder()
+	ts.Router.ServeHTTP(w, req)
+	return w
+}
+
+func testPNG(t *testing.T, width, height int) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))))
+	return buf.Bytes()
+}
+
+func decodeAttachment(t *testing.T, w *httptest.ResponseRecorder) models.Attachment {
+	var response models.SuccessResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	dataBytes, err := json.Marshal(response.Data)
+	require.NoError(t, err)
+	var attachment models.Attachment
+	require.NoError(t, json.Unmarshal(dataBytes, &attachment))
+	return attachment
+}
+
+func TestAttachmentAPI_UploadAndDownload(t *testing.T) {
+	server := SetupTestServer(t)
+	defer server.CleanupTestServer()
+
+	card := server.CreateTestCard(t, "テストカード", "#3B82F6")
+	category := server.CreateTestCategory(t, "食費", "#10B981", false)
+	expense := server.CreateTestExpense(t, 3200.0, "スーパー", card.ID, category.ID)
+
+	content := testPNG(t, 800, 600)
+
+	w := server.uploadAttachment(t, expense.ID, "receipt.png", content)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	attachment := decodeAttachment(t, w)
+	assert.Equal(t, expense.ID, attachment.ExpenseID)
+	assert.Equal(t, "receipt.png", attachment.FileName)
+	assert.Equal(t, "image/png", attachment.ContentType)
+	assert.Equal(t, int64(len(content)), attachment.Size)
+	assert.True(t, attachment.HasThumbnail)
+
+	t.Run("list attachments", func(t *testing.T) {
+		w := server.MakeRequest("GET", fmt.Sprintf("/api/expenses/%s/attachments", expense.ID), nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response models.SuccessResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Len(t, response.Data, 1)
+	})
+
+	t.Run("download original", func(t *testing.T) {
+		w := server.MakeRequest("GET", fmt.Sprintf("/api/expenses/%s/attachments/%s", expense.ID, attachment.ID), nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "receipt.png")
+		assert.Equal(t, content, w.Body.Bytes())
+	})
+
+	t.Run("download thumbnail", func(t *testing.T) {
+		w := server.MakeRequest("GET", fmt.Sprintf("/api/expenses/%s/attachments/%s/thumbnail", expense.ID, attachment.ID), nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "image/jpeg", w.Header().Get("Content-Type"))
+
+		thumbnail, _, err := image.Decode(bytes.NewReader(w.Body.Bytes()))
+		require.NoError(t, err)
+		assert.Equal(t, storage.ThumbnailMaxSize, thumbnail.Bounds().Dx())
+	})
+
+	t.Run("attachment of another expense", func(t *testing.T) {
+		other := server.CreateTestExpense(t, 500.0, "別の支出", card.ID, category.ID)
+
+		w := server.MakeRequest("GET", fmt.Sprintf("/api/expenses/%s/attachments/%s", other.ID, attachment.ID), nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("delete attachment", func(t *testing.T) {
+		stored, err := server.Repository.Attachment.GetByID(context.Background(), attachment.ID)
+		require.NoError(t, err)
+
+		w := server.MakeRequest("DELETE", fmt.Sprintf("/api/expenses/%s/attachments/%s", expense.ID, attachment.ID), nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		_, err = server.Storage.Get(stored.StorageKey)
+		assert.ErrorIs(t, err, storage.ErrObjectNotFound)
+
+		w = server.MakeRequest("GET", fmt.Sprintf("/api/expenses/%s/attachments/%s", expense.ID, attachment.ID), nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestAttachmentAPI_UploadValidation(t *testing.T) {
+	server := SetupTestServer(t)
+	defer server.CleanupTestServer()
+
+	card := server.CreateTestCard(t, "テストカード", "#3B82F6")
+	category := server.CreateTestCategory(t, "食費", "#10B981", false)
+	expense := server.CreateTestExpense(t, 1000.0, "テスト支出", card.ID, category.ID)
+
+	t.Run("unsupported file type", func(t *testing.T) {
+		w := server.uploadAttachment(t, expense.ID, "notes.txt", []byte("just some text"))
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+
+		var response models.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "UNSUPPORTED_FILE_TYPE", response.Error.Code)
+	})
+
+	t.Run("pdf without thumbnail", func(t *testing.T) {
+		w := server.uploadAttachment(t, expense.ID, "receipt.pdf", []byte("%PDF-1.4\n%âãÏÓ\n"))
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		attachment := decodeAttachment(t, w)
+		assert.Equal(t, "application/pdf", attachment.ContentType)
+		assert.False(t, attachment.HasThumbnail)
+
+		w = server.MakeRequest("GET", fmt.Sprintf("/api/expenses/%s/attachments/%s/thumbnail", expense.ID, attachment.ID), nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("missing file field", func(t *testing.T) {
+		w := server.MakeRequest("POST", fmt.Sprintf("/api/expenses/%s/attachments", expense.ID), nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("non-existent expense", func(t *testing.T) {
+		w := server.uploadAttachment(t, uuid.New(), "receipt.png", testPNG(t, 10, 10))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestAttachmentAPI_CascadeOnExpenseDelete(t *testing.T) {
+	server := SetupTestServer(t)
+	defer server.CleanupTestServer()
+
+	card := server.CreateTestCard(t, "テストカード", "#3B82F6")
+	category := server.CreateTestCategory(t, "食費", "#10B981", false)
+	expense := server.CreateTestExpense(t, 4500.0, "家電", card.ID, category.ID)
+
+	w := server.uploadAttachment(t, expense.ID, "warranty.png", testPNG(t, 300, 300))
+	require.Equal(t, http.StatusCreated, w.Code)
+	attachment := decodeAttachment(t, w)
+
+	stored, err := server.Repository.Attachment.GetByID(context.Background(), attachment.ID)
+	require.NoError(t, err)
+
+	w = server.MakeRequest("DELETE", fmt.Sprintf("/api/expenses/%s", expense.ID), nil)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	attachments, err := server.Repository.Attachment.GetByExpenseID(context.Background(), expense.ID)
+	require.NoError(t, err)
+	assert.Empty(t, attachments)
+
+	_, err = server.Storage.Get(stored.StorageKey)
+	assert.ErrorIs(t, err, storage.ErrObjectNotFound)
+	_, err = server.Storage.Get(stored.ThumbnailKey)
+	assert.ErrorIs(t, err, storage.ErrObjectNotFound)
+}
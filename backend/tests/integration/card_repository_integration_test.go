@@ -1,52 +1,24 @@
 package integration
 
 import (
+	"context"
 	"testing"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"gorm.io/driver/sqlite"
-	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 
 	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
 	"kakeibo-tanuki/internal/repositories"
 )
 
-func setupTestDB() (*gorm.DB, error) {
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Silent),
-		DisableForeignKeyConstraintWhenMigrating: true,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	// Auto-migrate tables with simplified schema for testing
-	err = db.Exec("CREATE TABLE IF NOT EXISTS cards (id TEXT PRIMARY KEY, name TEXT NOT NULL, color TEXT NOT NULL, created_at DATETIME, updated_at DATETIME)").Error
-	if err != nil {
-		return nil, err
-	}
-
-	err = db.Exec("CREATE TABLE IF NOT EXISTS categories (id TEXT PRIMARY KEY, name TEXT NOT NULL UNIQUE, color TEXT NOT NULL, is_shared BOOLEAN, created_at DATETIME, updated_at DATETIME)").Error
-	if err != nil {
-		return nil, err
-	}
-
-	err = db.Exec("CREATE TABLE IF NOT EXISTS expenses (id TEXT PRIMARY KEY, amount REAL NOT NULL, date DATETIME, description TEXT, card_id TEXT, category_id TEXT, created_at DATETIME, updated_at DATETIME)").Error
-	if err != nil {
-		return nil, err
-	}
-
-	return db, nil
-}
-
 func TestCardRepositoryIntegration_CRUD(t *testing.T) {
-	db, err := setupTestDB()
+	db, err := openTestDB(t)
 	require.NoError(t, err)
 
 	repo := repositories.NewCardRepository(db)
+	ctx := context.Background()
 
 	// Test Create
 	card := &models.Card{
@@ -55,18 +27,18 @@ func TestCardRepositoryIntegration_CRUD(t *testing.T) {
 		Color: "#3B82F6",
 	}
 
-	err = repo.Create(card)
+	err = repo.Create(ctx, card)
 	assert.NoError(t, err)
 	assert.NotEqual(t, uuid.Nil, card.ID)
 
 	// Test GetByID
-	retrievedCard, err := repo.GetByID(card.ID)
+	retrievedCard, err := repo.GetByID(ctx, card.ID)
 	assert.NoError(t, err)
 	assert.Equal(t, card.Name, retrievedCard.Name)
 	assert.Equal(t, card.Color, retrievedCard.Color)
 
 	// Test GetAll
-	cards, err := repo.GetAll()
+	cards, err := repo.GetAll(ctx)
 	assert.NoError(t, err)
 	assert.Len(t, cards, 1)
 	assert.Equal(t, card.Name, cards[0].Name)
@@ -74,16 +46,16 @@ func TestCardRepositoryIntegration_CRUD(t *testing.T) {
 	// Test Update
 	card.Name = "更新されたカード"
 	card.Color = "#EF4444"
-	err = repo.Update(card)
+	err = repo.Update(ctx, card)
 	assert.NoError(t, err)
 
-	updatedCard, err := repo.GetByID(card.ID)
+	updatedCard, err := repo.GetByID(ctx, card.ID)
 	assert.NoError(t, err)
 	assert.Equal(t, "更新されたカード", updatedCard.Name)
 	assert.Equal(t, "#EF4444", updatedCard.Color)
 
 	// Test HasExpenses (should be false initially)
-	hasExpenses, err := repo.HasExpenses(card.ID)
+	hasExpenses, err := repo.HasExpenses(ctx, card.ID)
 	assert.NoError(t, err)
 	assert.False(t, hasExpenses)
 
@@ -95,57 +67,59 @@ func TestCardRepositoryIntegration_CRUD(t *testing.T) {
 		Color:    "#10B981",
 		IsShared: false,
 	}
-	err = categoryRepo.Create(category)
+	err = categoryRepo.Create(ctx, category)
 	require.NoError(t, err)
 
 	expenseRepo := repositories.NewExpenseRepository(db)
 	expense := &models.Expense{
 		ID:          uuid.New(),
-		Amount:      1000.0,
+		Amount:      money.New(1000),
 		Description: "テスト支出",
 		CardID:      card.ID,
 		CategoryID:  category.ID,
 	}
-	err = expenseRepo.Create(expense)
+	err = expenseRepo.Create(ctx, expense)
 	require.NoError(t, err)
 
 	// Test HasExpenses (should be true now)
-	hasExpenses, err = repo.HasExpenses(card.ID)
+	hasExpenses, err = repo.HasExpenses(ctx, card.ID)
 	assert.NoError(t, err)
 	assert.True(t, hasExpenses)
 
 	// Delete expense first to allow card deletion
-	err = expenseRepo.Delete(expense.ID)
+	err = expenseRepo.Delete(ctx, expense.ID)
 	assert.NoError(t, err)
 
 	// Test Delete
-	err = repo.Delete(card.ID)
+	err = repo.Delete(ctx, card.ID)
 	assert.NoError(t, err)
 
 	// Verify deletion
-	_, err = repo.GetByID(card.ID)
+	_, err = repo.GetByID(ctx, card.ID)
 	assert.Error(t, err)
-	assert.Equal(t, gorm.ErrRecordNotFound, err)
+	assert.ErrorIs(t, err, repositories.ErrNotFound)
 }
 
 func TestCardRepositoryIntegration_GetByID_NotFound(t *testing.T) {
-	db, err := setupTestDB()
+	db, err := openTestDB(t)
 	require.NoError(t, err)
 
 	repo := repositories.NewCardRepository(db)
+	ctx := context.Background()
 
 	nonExistentID := uuid.New()
-	card, err := repo.GetByID(nonExistentID)
+	card, err := repo.GetByID(ctx, nonExistentID)
 	assert.Error(t, err)
-	assert.Equal(t, gorm.ErrRecordNotFound, err)
+	assert.ErrorIs(t, err, repositories.ErrNotFound)
 	assert.Nil(t, card)
 }
 
 func TestCardRepositoryIntegration_MultipleCards(t *testing.T) {
-	db, err := setupTestDB()
+	db, err := openTestDB(t)
 	require.NoError(t, err)
 
 	repo := repositories.NewCardRepository(db)
+	ctx := context.Background()
 
 	// Create multiple cards
 	cards := []*models.Card{
@@ -155,12 +129,12 @@ func TestCardRepositoryIntegration_MultipleCards(t *testing.T) {
 	}
 
 	for _, card := range cards {
-		err = repo.Create(card)
+		err = repo.Create(ctx, card)
 		assert.NoError(t, err)
 	}
 
 	// Test GetAll returns all cards in correct order (newest first)
-	allCards, err := repo.GetAll()
+	allCards, err := repo.GetAll(ctx)
 	assert.NoError(t, err)
 	assert.Len(t, allCards, 3)
 
@@ -1,22 +1,24 @@
 package integration
 
 import (
+	"context"
 	"testing"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"gorm.io/gorm"
 
 	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
 	"kakeibo-tanuki/internal/repositories"
 )
 
 func TestCategoryRepositoryIntegration_CRUD(t *testing.T) {
-	db, err := setupTestDB()
+	db, err := openTestDB(t)
 	require.NoError(t, err)
 
 	repo := repositories.NewCategoryRepository(db)
+	ctx := context.Background()
 
 	// Test Create
 	category := &models.Category{
@@ -26,19 +28,19 @@ func TestCategoryRepositoryIntegration_CRUD(t *testing.T) {
 		IsShared: false,
 	}
 
-	err = repo.Create(category)
+	err = repo.Create(ctx, category)
 	assert.NoError(t, err)
 	assert.NotEqual(t, uuid.Nil, category.ID)
 
 	// Test GetByID
-	retrievedCategory, err := repo.GetByID(category.ID)
+	retrievedCategory, err := repo.GetByID(ctx, category.ID)
 	assert.NoError(t, err)
 	assert.Equal(t, category.Name, retrievedCategory.Name)
 	assert.Equal(t, category.Color, retrievedCategory.Color)
 	assert.Equal(t, category.IsShared, retrievedCategory.IsShared)
 
 	// Test GetAll
-	categories, err := repo.GetAll()
+	categories, err := repo.GetAll(ctx)
 	assert.NoError(t, err)
 	assert.Len(t, categories, 1)
 	assert.Equal(t, category.Name, categories[0].Name)
@@ -47,17 +49,17 @@ func TestCategoryRepositoryIntegration_CRUD(t *testing.T) {
 	category.Name = "更新された食費"
 	category.Color = "#EF4444"
 	category.IsShared = true
-	err = repo.Update(category)
+	err = repo.Update(ctx, category)
 	assert.NoError(t, err)
 
-	updatedCategory, err := repo.GetByID(category.ID)
+	updatedCategory, err := repo.GetByID(ctx, category.ID)
 	assert.NoError(t, err)
 	assert.Equal(t, "更新された食費", updatedCategory.Name)
 	assert.Equal(t, "#EF4444", updatedCategory.Color)
 	assert.True(t, updatedCategory.IsShared)
 
 	// Test HasExpenses (should be false initially)
-	hasExpenses, err := repo.HasExpenses(category.ID)
+	hasExpenses, err := repo.HasExpenses(ctx, category.ID)
 	assert.NoError(t, err)
 	assert.False(t, hasExpenses)
 
@@ -68,44 +70,45 @@ func TestCategoryRepositoryIntegration_CRUD(t *testing.T) {
 		Name:  "テストカード",
 		Color: "#3B82F6",
 	}
-	err = cardRepo.Create(card)
+	err = cardRepo.Create(ctx, card)
 	require.NoError(t, err)
 
 	expenseRepo := repositories.NewExpenseRepository(db)
 	expense := &models.Expense{
 		ID:          uuid.New(),
-		Amount:      1500.0,
+		Amount:      money.New(1500),
 		Description: "テスト支出",
 		CardID:      card.ID,
 		CategoryID:  category.ID,
 	}
-	err = expenseRepo.Create(expense)
+	err = expenseRepo.Create(ctx, expense)
 	require.NoError(t, err)
 
 	// Test HasExpenses (should be true now)
-	hasExpenses, err = repo.HasExpenses(category.ID)
+	hasExpenses, err = repo.HasExpenses(ctx, category.ID)
 	assert.NoError(t, err)
 	assert.True(t, hasExpenses)
 
 	// Delete expense first to allow category deletion
-	err = expenseRepo.Delete(expense.ID)
+	err = expenseRepo.Delete(ctx, expense.ID)
 	assert.NoError(t, err)
 
 	// Test Delete
-	err = repo.Delete(category.ID)
+	err = repo.Delete(ctx, category.ID)
 	assert.NoError(t, err)
 
 	// Verify deletion
-	_, err = repo.GetByID(category.ID)
+	_, err = repo.GetByID(ctx, category.ID)
 	assert.Error(t, err)
-	assert.Equal(t, gorm.ErrRecordNotFound, err)
+	assert.ErrorIs(t, err, repositories.ErrNotFound)
 }
 
 func TestCategoryRepositoryIntegration_SharedCategories(t *testing.T) {
-	db, err := setupTestDB()
+	db, err := openTestDB(t)
 	require.NoError(t, err)
 
 	repo := repositories.NewCategoryRepository(db)
+	ctx := context.Background()
 
 	// Create multiple categories with different shared flags
 	categories := []*models.Category{
@@ -116,12 +119,12 @@ func TestCategoryRepositoryIntegration_SharedCategories(t *testing.T) {
 	}
 
 	for _, category := range categories {
-		err = repo.Create(category)
+		err = repo.Create(ctx, category)
 		assert.NoError(t, err)
 	}
 
 	// Test GetAll returns all categories
-	allCategories, err := repo.GetAll()
+	allCategories, err := repo.GetAll(ctx)
 	assert.NoError(t, err)
 	assert.Len(t, allCategories, 4)
 
@@ -136,10 +139,11 @@ func TestCategoryRepositoryIntegration_SharedCategories(t *testing.T) {
 }
 
 func TestCategoryRepositoryIntegration_UniqueNameConstraint(t *testing.T) {
-	db, err := setupTestDB()
+	db, err := openTestDB(t)
 	require.NoError(t, err)
 
 	repo := repositories.NewCategoryRepository(db)
+	ctx := context.Background()
 
 	// Create first category
 	category1 := &models.Category{
@@ -148,7 +152,7 @@ func TestCategoryRepositoryIntegration_UniqueNameConstraint(t *testing.T) {
 		Color:    "#10B981",
 		IsShared: false,
 	}
-	err = repo.Create(category1)
+	err = repo.Create(ctx, category1)
 	assert.NoError(t, err)
 
 	// Try to create another category with the same name
@@ -158,19 +162,20 @@ func TestCategoryRepositoryIntegration_UniqueNameConstraint(t *testing.T) {
 		Color:    "#EF4444",
 		IsShared: true,
 	}
-	err = repo.Create(category2)
+	err = repo.Create(ctx, category2)
 	assert.Error(t, err) // Should fail due to unique constraint
 }
 
 func TestCategoryRepositoryIntegration_GetByID_NotFound(t *testing.T) {
-	db, err := setupTestDB()
+	db, err := openTestDB(t)
 	require.NoError(t, err)
 
 	repo := repositories.NewCategoryRepository(db)
+	ctx := context.Background()
 
 	nonExistentID := uuid.New()
-	category, err := repo.GetByID(nonExistentID)
+	category, err := repo.GetByID(ctx, nonExistentID)
 	assert.Error(t, err)
-	assert.Equal(t, gorm.ErrRecordNotFound, err)
+	assert.ErrorIs(t, err, repositories.ErrNotFound)
 	assert.Nil(t, category)
 }
\ No newline at end of file
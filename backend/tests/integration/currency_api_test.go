@@ -0,0 +1,301 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
+)
+
+func decodeData(t *testing.T, body []byte, target interface{}) {
+	var response models.SuccessResponse
+	require.NoError(t, json.Unmarshal(body, &response))
+	dataBytes, err := json.Marshal(response.Data)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(dataBytes, target))
+}
+
+func TestBookAPI(t *testing.T) {
+	server := SetupTestServer(t)
+	defer server.CleanupTestServer()
+
+	t.Run("default book", func(t *testing.T) {
+		w := server.MakeRequest("GET", "/api/book", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var book models.Book
+		decodeData(t, w.Body.Bytes(), &book)
+		assert.Equal(t, "JPY", book.BaseCurrency)
+		assert.Equal(t, 0, book.RemainderMember)
+	})
+
+	t.Run("update base currency", func(t *testing.T) {
+		w := server.MakeRequest("PUT", "/api/book", models.UpdateBookRequest{Name: "旅行用", BaseCurrency: "usd"})
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var book models.Book
+		decodeData(t, w.Body.Bytes(), &book)
+		assert.Equal(t, "USD", book.BaseCurrency)
+		assert.Equal(t, "旅行用", book.Name)
+	})
+
+	t.Run("remainder goes to the second member", func(t *testing.T) {
+		w := server.MakeRequest("PUT", "/api/book", models.UpdateBookRequest{Name: "家計簿", BaseCurrency: "JPY", RemainderMember: 1})
+		require.Equal(t, http.StatusOK, w.Code)
+
+		book, err := server.Repository.Book.Get(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, book.RemainderMember)
+	})
+
+	t.Run("remainder member out of range", func(t *testing.T) {
+		w := server.MakeRequest("PUT", "/api/book", models.UpdateBookRequest{Name: "家計簿", BaseCurrency: "JPY", RemainderMember: models.SharedExpenseMembers})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("invalid currency", func(t *testing.T) {
+		w := server.MakeRequest("PUT", "/api/book", models.UpdateBookRequest{Name: "旅行用", BaseCurrency: "ZZZ"})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var response models.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "INVALID_CURRENCY", response.Error.Code)
+	})
+}
+
+func TestExpenseAPI_Currencies(t *testing.T) {
+	server := SetupTestServer(t)
+	defer server.CleanupTestServer()
+
+	card := server.CreateTestCard(t, "テストカード", "#3B82F6")
+	food := server.CreateTestCategory(t, "食費", "#10B981", false)
+	travel := server.CreateTestCategory(t, "旅行", "#F59E0B", false)
+	date := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+
+	createExpense := func(t *testing.T, req models.CreateExpenseRequest) *httptest.ResponseRecorder {
+		req.Date = date
+		req.CardID = card.ID.String()
+		return server.MakeRequest("POST", "/api/expenses", req)
+	}
+
+	t.Run("defaults to the base currency and rounds to yen", func(t *testing.T) {
+		w := createExpense(t, models.CreateExpenseRequest{Amount: money.FromFloat(1500.6), CategoryID: food.ID.String()})
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var expense models.Expense
+		decodeData(t, w.Body.Bytes(), &expense)
+		assert.Equal(t, "JPY", expense.Currency)
+		assert.Equal(t, money.New(1501), expense.Amount)
+	})
+
+	t.Run("keeps the original amount in a foreign currency", func(t *testing.T) {
+		w := createExpense(t, models.CreateExpenseRequest{Amount: money.FromFloat(12.35), Currency: "usd", CategoryID: travel.ID.String()})
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var expense models.Expense
+		decodeData(t, w.Body.Bytes(), &expense)
+		assert.Equal(t, "USD", expense.Currency)
+		assert.Equal(t, money.FromFloat(12.35), expense.Amount)
+
+		w = server.MakeRequest("GET", fmt.Sprintf("/api/expenses?categoryId=%s", travel.ID), nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		var listed []models.Expense
+		decodeData(t, w.Body.Bytes(), &listed)
+		require.Len(t, listed, 1)
+		assert.Equal(t, "USD", listed[0].Currency)
+		assert.Equal(t, money.FromFloat(12.35), listed[0].Amount)
+	})
+
+	t.Run("split items are rounded to the currency", func(t *testing.T) {
+		w := createExpense(t, models.CreateExpenseRequest{
+			Amount:   money.New(2000),
+			Currency: "JPY",
+			Items: []models.ExpenseItemRequest{
+				{Amount: money.FromFloat(1200.4), CategoryID: food.ID.String()},
+				{Amount: money.FromFloat(799.6), CategoryID: travel.ID.String()},
+			},
+		})
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var expense models.Expense
+		decodeData(t, w.Body.Bytes(), &expense)
+		require.Len(t, expense.Items, 2)
+		assert.Equal(t, money.New(2000), expense.Items[0].Amount+expense.Items[1].Amount)
+		for _, item := range expense.Items {
+			assert.Equal(t, item.Amount.Round("JPY"), item.Amount)
+		}
+	})
+
+	t.Run("unknown currency", func(t *testing.T) {
+		w := createExpense(t, models.CreateExpenseRequest{Amount: money.New(10), Currency: "ZZZ", CategoryID: food.ID.String()})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var response models.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "INVALID_CURRENCY", response.Error.Code)
+	})
+
+	t.Run("amount below the minor unit", func(t *testing.T) {
+		w := createExpense(t, models.CreateExpenseRequest{Amount: money.FromFloat(0.4), Currency: "JPY", CategoryID: food.ID.String()})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var response models.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "INVALID_AMOUNT", response.Error.Code)
+	})
+
+	t.Run("update keeps the recorded currency", func(t *testing.T) {
+		expense := server.CreateTestExpense(t, 100.0, "ホテル", card.ID, travel.ID)
+		expense.Currency = "USD"
+		require.NoError(t, server.Repository.Expense.Update(context.Background(), expense))
+
+		// Sent as a raw JSON number so that it is rounded when decoded
+		w := server.MakeRequest("PUT", fmt.Sprintf("/api/expenses/%s", expense.ID), map[string]interface{}{
+			"amount":     120.555,
+			"date":       date,
+			"cardId":     card.ID.String(),
+			"categoryId": travel.ID.String(),
+		})
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var updated models.Expense
+		decodeData(t, w.Body.Bytes(), &updated)
+		assert.Equal(t, "USD", updated.Currency)
+		assert.Equal(t, money.FromFloat(120.56), updated.Amount)
+	})
+}
+
+func TestExchangeRateAPI(t *testing.T) {
+	server := SetupTestServer(t)
+	defer server.CleanupTestServer()
+
+	t.Run("manual entry", func(t *testing.T) {
+		w := server.MakeRequest("POST", "/api/exchange-rates", models.CreateExchangeRateRequest{
+			Currency: "usd",
+			Date:     "2025-03-01",
+			Rate:     149.5,
+		})
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var rate models.ExchangeRate
+		decodeData(t, w.Body.Bytes(), &rate)
+		assert.Equal(t, "USD", rate.Currency)
+		assert.Equal(t, "JPY", rate.BaseCurrency)
+		assert.Equal(t, 149.5, rate.Rate)
+	})
+
+	t.Run("entering the same date replaces the rate", func(t *testing.T) {
+		w := server.MakeRequest("POST", "/api/exchange-rates", models.CreateExchangeRateRequest{
+			Currency: "USD",
+			Date:     "2025-03-01",
+			Rate:     150.25,
+		})
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		rates, err := server.Repository.ExchangeRate.GetAll(context.Background(), &models.ExchangeRateFilters{Currency: "USD"})
+		require.NoError(t, err)
+		require.Len(t, rates, 1)
+		assert.Equal(t, 150.25, rates[0].Rate)
+
+		var rate models.ExchangeRate
+		decodeData(t, w.Body.Bytes(), &rate)
+		assert.Equal(t, rates[0].ID, rate.ID)
+	})
+
+	t.Run("invalid manual entries", func(t *testing.T) {
+		for _, req := range []models.CreateExchangeRateRequest{
+			{Currency: "JPY", Date: "2025-03-01", Rate: 1},
+			{Currency: "ZZZ", Date: "2025-03-01", Rate: 1},
+			{Currency: "USD", Date: "2025/03/01", Rate: 150},
+			{Currency: "USD", Date: "2025-03-01", Rate: -1},
+		} {
+			w := server.MakeRequest("POST", "/api/exchange-rates", req)
+			assert.Equal(t, http.StatusBadRequest, w.Code, "%+v", req)
+		}
+	})
+
+	t.Run("CSV import from the request body", func(t *testing.T) {
+		csv := "date,currency,rate\n2025-03-02,USD,151\n2025-03-02,EUR,163.5\n"
+		req, _ := http.NewRequest("POST", "/api/exchange-rates/import", strings.NewReader(csv))
+		req.Header.Set("Content-Type", "text/csv")
+		w := httptest.NewRecorder()
+		server.Router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var result models.ExchangeRateImportResult
+		decodeData(t, w.Body.Bytes(), &result)
+		assert.Equal(t, 2, result.Imported)
+	})
+
+	t.Run("CSV import as a file upload", func(t *testing.T) {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, err := writer.CreateFormFile("file", "rates.csv")
+		require.NoError(t, err)
+		_, err = part.Write([]byte("date,currency,base,rate\n2025-03-03,EUR,USD,1.08\n"))
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		req, _ := http.NewRequest("POST", "/api/exchange-rates/import", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		w := httptest.NewRecorder()
+		server.Router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		rates, err := server.Repository.ExchangeRate.GetAll(context.Background(), &models.ExchangeRateFilters{BaseCurrency: "USD"})
+		require.NoError(t, err)
+		require.Len(t, rates, 1)
+		assert.Equal(t, "EUR", rates[0].Currency)
+	})
+
+	t.Run("invalid CSV imports nothing", func(t *testing.T) {
+		csv := "date,currency,rate\n2025-03-04,USD,152\n2025-03-04,USD,abc\n"
+		req, _ := http.NewRequest("POST", "/api/exchange-rates/import", strings.NewReader(csv))
+		req.Header.Set("Content-Type", "text/csv")
+		w := httptest.NewRecorder()
+		server.Router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var response models.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "INVALID_CSV", response.Error.Code)
+
+		rates, err := server.Repository.ExchangeRate.GetAll(context.Background(), &models.ExchangeRateFilters{Currency: "USD", BaseCurrency: "JPY"})
+		require.NoError(t, err)
+		assert.Len(t, rates, 2)
+	})
+
+	t.Run("list with filters", func(t *testing.T) {
+		w := server.MakeRequest("GET", "/api/exchange-rates?currency=usd&startDate=2025-03-02", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var rates []models.ExchangeRate
+		decodeData(t, w.Body.Bytes(), &rates)
+		require.Len(t, rates, 1)
+		assert.Equal(t, 151.0, rates[0].Rate)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		rates, err := server.Repository.ExchangeRate.GetAll(context.Background(), &models.ExchangeRateFilters{Currency: "EUR", BaseCurrency: "USD"})
+		require.NoError(t, err)
+		require.Len(t, rates, 1)
+
+		w := server.MakeRequest("DELETE", fmt.Sprintf("/api/exchange-rates/%s", rates[0].ID), nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = server.MakeRequest("DELETE", fmt.Sprintf("/api/exchange-rates/%s", rates[0].ID), nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
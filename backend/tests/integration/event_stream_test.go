@@ -0,0 +1,164 @@
+package integration
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
+)
+
+// sseEvent is an event read from the stream; comments such as pings have
+// only Comment set.
+type sseEvent struct {
+	ID      string
+	Event   string
+	Data    string
+	Comment string
+}
+
+// openEventStream connects to the event stream and sends what it reads to
+// the returned channel until the stream ends.
+func openEventStream(t *testing.T, ctx context.Context, url string, lastEventID string) (*http.Response, <-chan sseEvent) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url+"/api/events", nil)
+	require.NoError(t, err)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	received := make(chan sseEvent, 100)
+	go func() {
+		defer close(received)
+		defer resp.Body.Close()
+		scanner := bufio.NewScanner(resp.Body)
+		var event sseEvent
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case line == "":
+				if event != (sseEvent{}) {
+					received <- event
+				}
+				event = sseEvent{}
+			case strings.HasPrefix(line, ":"):
+				event.Comment = strings.TrimSpace(line[1:])
+			case strings.HasPrefix(line, "id:"):
+				event.ID = strings.TrimSpace(line[3:])
+			case strings.HasPrefix(line, "event:"):
+				event.Event = strings.TrimSpace(line[6:])
+			case strings.HasPrefix(line, "data:"):
+				event.Data = strings.TrimSpace(line[5:])
+			}
+		}
+	}()
+	return resp, received
+}
+
+// nextEvent returns the next event that is not a ping.
+func nextEvent(t *testing.T, received <-chan sseEvent) sseEvent {
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event, ok := <-received:
+			require.True(t, ok, "stream ended")
+			if event.Comment == "" && event.Event != "" {
+				return event
+			}
+		case <-timeout:
+			t.Fatal("no event received")
+		}
+	}
+}
+
+func TestEventStream(t *testing.T) {
+	server := SetupTestServer(t)
+	defer server.CleanupTestServer()
+
+	httpServer := httptest.NewServer(server.Router)
+	defer httpServer.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	resp, received := openEventStream(t, ctx, httpServer.URL, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "no-cache", resp.Header.Get("Cache-Control"))
+	require.Eventually(t, func() bool { return server.Events.Clients() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	var firstID string
+	t.Run("changes are broadcast", func(t *testing.T) {
+		w := server.MakeRequest("POST", "/api/cards", models.CreateCardRequest{Name: "楽天カード", Color: "#BF0000"})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var card models.Card
+		decodeData(t, w.Body.Bytes(), &card)
+
+		event := nextEvent(t, received)
+		assert.Equal(t, "card.created", event.Event)
+		firstID = event.ID
+		var payload struct {
+			Type string      `json:"type"`
+			Data models.Card `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(event.Data), &payload))
+		assert.Equal(t, "card.created", payload.Type)
+		assert.Equal(t, card.ID, payload.Data.ID)
+
+		category := server.CreateTestCategory(t, "食費", "#10B981", false)
+		w = server.MakeRequest("POST", "/api/expenses", models.CreateExpenseRequest{
+			Amount:      money.New(1200),
+			Date:        "2025-03-01",
+			Description: "スーパー",
+			CardID:      card.ID.String(),
+			CategoryID:  category.ID.String(),
+		})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		assert.Equal(t, "expense.created", nextEvent(t, received).Event)
+
+		w = server.MakeRequest("PUT", "/api/categories/"+category.ID.String(), models.UpdateCategoryRequest{Name: "食料品", Color: "#10B981"})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, "category.updated", nextEvent(t, received).Event)
+	})
+
+	t.Run("heartbeats keep the connection open", func(t *testing.T) {
+		timeout := time.After(5 * time.Second)
+		for {
+			select {
+			case event := <-received:
+				if event.Comment == "ping" {
+					return
+				}
+			case <-timeout:
+				t.Fatal("no heartbeat received")
+			}
+		}
+	})
+
+	t.Run("clients are removed when they disconnect", func(t *testing.T) {
+		cancel()
+		require.Eventually(t, func() bool { return server.Events.Clients() == 0 }, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("resumed after the last event ID", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		_, resumed := openEventStream(t, ctx, httpServer.URL, firstID)
+
+		assert.Equal(t, "expense.created", nextEvent(t, resumed).Event)
+		assert.Equal(t, "category.updated", nextEvent(t, resumed).Event)
+	})
+
+	t.Run("Invalid last event ID", func(t *testing.T) {
+		w := server.MakeRequest("GET", "/api/events?lastEventId=abc", nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -1,6 +1,7 @@
 package integration
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -12,6 +13,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
 )
 
 func TestExpenseAPI_GetExpenses(t *testing.T) {
@@ -85,7 +87,7 @@ func TestExpenseAPI_CreateExpense(t *testing.T) {
 	t.Run("valid expense creation", func(t *testing.T) {
 		pastDate := time.Now().Add(-24 * time.Hour)
 		requestBody := models.CreateExpenseRequest{
-			Amount:      2500.0,
+			Amount:      money.New(2500),
 			Date:        pastDate.Format(time.RFC3339),
 			Description: "ディナー",
 			CardID:      card.ID.String(),
@@ -121,7 +123,7 @@ func TestExpenseAPI_CreateExpense(t *testing.T) {
 	t.Run("invalid expense creation - negative amount", func(t *testing.T) {
 		pastDate := time.Now().Add(-24 * time.Hour)
 		requestBody := models.CreateExpenseRequest{
-			Amount:      -100.0,
+			Amount:      money.New(-100),
 			Date:        pastDate.Format(time.RFC3339),
 			Description: "無効な支出",
 			CardID:      card.ID.String(),
@@ -143,7 +145,7 @@ func TestExpenseAPI_CreateExpense(t *testing.T) {
 	t.Run("invalid expense creation - future date", func(t *testing.T) {
 		futureDate := time.Now().Add(24 * time.Hour)
 		requestBody := models.CreateExpenseRequest{
-			Amount:      1000.0,
+			Amount:      money.New(1000),
 			Date:        futureDate.Format(time.RFC3339),
 			Description: "未来の支出",
 			CardID:      card.ID.String(),
@@ -165,7 +167,7 @@ func TestExpenseAPI_CreateExpense(t *testing.T) {
 	t.Run("invalid expense creation - invalid card ID format", func(t *testing.T) {
 		pastDate := time.Now().Add(-24 * time.Hour)
 		requestBody := models.CreateExpenseRequest{
-			Amount:      1000.0,
+			Amount:      money.New(1000),
 			Date:        pastDate.Format(time.RFC3339),
 			Description: "テスト支出",
 			CardID:      "invalid-uuid",
@@ -184,10 +186,77 @@ func TestExpenseAPI_CreateExpense(t *testing.T) {
 		assert.Contains(t, response.Error.Message, "Invalid card ID format")
 	})
 
+	t.Run("invalid expense creation - unknown card", func(t *testing.T) {
+		pastDate := time.Now().Add(-24 * time.Hour)
+		requestBody := models.CreateExpenseRequest{
+			Amount:      money.New(1000),
+			Date:        pastDate.Format(time.RFC3339),
+			Description: "テスト支出",
+			CardID:      uuid.New().String(),
+			CategoryID:  category.ID.String(),
+		}
+
+		w := server.MakeRequest("POST", "/api/expenses", requestBody)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+
+		var response models.ErrorResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+
+		assert.Equal(t, "CARD_NOT_FOUND", response.Error.Code)
+	})
+
+	t.Run("invalid expense creation - unknown category", func(t *testing.T) {
+		pastDate := time.Now().Add(-24 * time.Hour)
+		requestBody := models.CreateExpenseRequest{
+			Amount:      money.New(1000),
+			Date:        pastDate.Format(time.RFC3339),
+			Description: "テスト支出",
+			CardID:      card.ID.String(),
+			CategoryID:  uuid.New().String(),
+		}
+
+		w := server.MakeRequest("POST", "/api/expenses", requestBody)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+
+		var response models.ErrorResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+
+		assert.Equal(t, "CATEGORY_NOT_FOUND", response.Error.Code)
+	})
+
+	t.Run("invalid expense creation - unknown item category", func(t *testing.T) {
+		pastDate := time.Now().Add(-24 * time.Hour)
+		requestBody := models.CreateExpenseRequest{
+			Amount:      money.New(1000),
+			Date:        pastDate.Format(time.RFC3339),
+			Description: "テスト支出",
+			CardID:      card.ID.String(),
+			Items: []models.ExpenseItemRequest{
+				{CategoryID: category.ID.String(), Amount: money.New(600)},
+				{CategoryID: uuid.New().String(), Amount: money.New(400)},
+			},
+		}
+
+		w := server.MakeRequest("POST", "/api/expenses", requestBody)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+
+		var response models.ErrorResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+
+		assert.Equal(t, "CATEGORY_NOT_FOUND", response.Error.Code)
+		assert.Contains(t, response.Error.Details, "items[1].categoryId")
+	})
+
 	t.Run("invalid expense creation - invalid category ID format", func(t *testing.T) {
 		pastDate := time.Now().Add(-24 * time.Hour)
 		requestBody := models.CreateExpenseRequest{
-			Amount:      1000.0,
+			Amount:      money.New(1000),
 			Date:        pastDate.Format(time.RFC3339),
 			Description: "テスト支出",
 			CardID:      card.ID.String(),
@@ -293,7 +362,7 @@ func TestExpenseAPI_UpdateExpense(t *testing.T) {
 
 		pastDate := time.Now().Add(-12 * time.Hour)
 		updateRequest := models.UpdateExpenseRequest{
-			Amount:      1500.0,
+			Amount:      money.New(1500),
 			Date:        pastDate.Format(time.RFC3339),
 			Description: "更新された支出",
 			CardID:      card2.ID.String(),
@@ -336,7 +405,7 @@ func TestExpenseAPI_UpdateExpense(t *testing.T) {
 
 		pastDate := time.Now().Add(-24 * time.Hour)
 		updateRequest := models.UpdateExpenseRequest{
-			Amount:      1500.0,
+			Amount:      money.New(1500),
 			Date:        pastDate.Format(time.RFC3339),
 			Description: "更新された支出",
 			CardID:      card1.ID.String(),
@@ -365,7 +434,7 @@ func TestExpenseAPI_UpdateExpense(t *testing.T) {
 
 		pastDate := time.Now().Add(-24 * time.Hour)
 		updateRequest := models.UpdateExpenseRequest{
-			Amount:      -100.0, // Invalid negative amount
+			Amount:      money.New(-100), // Invalid negative amount
 			Date:        pastDate.Format(time.RFC3339),
 			Description: "更新された支出",
 			CardID:      card1.ID.String(),
@@ -394,7 +463,7 @@ func TestExpenseAPI_UpdateExpense(t *testing.T) {
 
 		pastDate := time.Now().Add(-24 * time.Hour)
 		updateRequest := models.UpdateExpenseRequest{
-			Amount:      1500.0,
+			Amount:      money.New(1500),
 			Date:        pastDate.Format(time.RFC3339),
 			Description: "更新された支出",
 			CardID:      "invalid-uuid",
@@ -411,6 +480,39 @@ func TestExpenseAPI_UpdateExpense(t *testing.T) {
 
 		assert.Equal(t, "INVALID_CARD_ID", response.Error.Code)
 	})
+
+	t.Run("invalid update data - unknown card", func(t *testing.T) {
+		server := SetupTestServer(t)
+		defer server.CleanupTestServer()
+		card1 := server.CreateTestCard(t, "カード1", "#3B82F6")
+		category1 := server.CreateTestCategory(t, "食費", "#10B981", false)
+
+		expense := server.CreateTestExpense(t, 1000.0, "テスト支出", card1.ID, category1.ID)
+
+		pastDate := time.Now().Add(-24 * time.Hour)
+		updateRequest := models.UpdateExpenseRequest{
+			Amount:      money.New(1500),
+			Date:        pastDate.Format(time.RFC3339),
+			Description: "更新された支出",
+			CardID:      uuid.New().String(),
+			CategoryID:  category1.ID.String(),
+		}
+
+		w := server.MakeRequest("PUT", fmt.Sprintf("/api/expenses/%s", expense.ID), updateRequest)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+
+		var response models.ErrorResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		require.NoError(t, err)
+
+		assert.Equal(t, "CARD_NOT_FOUND", response.Error.Code)
+
+		// The expense is left as it was
+		unchanged, err := server.Repository.Expense.GetByIDWithoutPreload(context.Background(), expense.ID)
+		require.NoError(t, err)
+		assert.Equal(t, card1.ID, unchanged.CardID)
+	})
 }
 
 func TestExpenseAPI_DeleteExpense(t *testing.T) {
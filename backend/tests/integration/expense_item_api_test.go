@@ -0,0 +1,196 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
+)
+
+func TestExpenseAPI_SplitExpense(t *testing.T) {
+	server := SetupTestServer(t)
+	defer server.CleanupTestServer()
+
+	card := server.CreateTestCard(t, "テストカード", "#3B82F6")
+	food := server.CreateTestCategory(t, "食費", "#10B981", false)
+	household := server.CreateTestCategory(t, "日用品", "#F59E0B", false)
+	pastDate := time.Now().Add(-24 * time.Hour).Format(time.RFC3339)
+
+	var created models.Expense
+
+	t.Run("create split expense", func(t *testing.T) {
+		requestBody := models.CreateExpenseRequest{
+			Amount:      money.New(3500),
+			Date:        pastDate,
+			Description: "スーパー",
+			CardID:      card.ID.String(),
+			Items: []models.ExpenseItemRequest{
+				{Amount: money.New(1200), CategoryID: household.ID.String(), Note: "洗剤"},
+				{Amount: money.New(2300), CategoryID: food.ID.String()},
+			},
+		}
+
+		w := server.MakeRequest("POST", "/api/expenses", requestBody)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var response models.SuccessResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		dataBytes, err := json.Marshal(response.Data)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(dataBytes, &created))
+
+		// The largest item becomes the expense's primary category
+		assert.Equal(t, food.ID, created.CategoryID)
+		require.Len(t, created.Items, 2)
+
+		var total money.Amount
+		for _, item := range created.Items {
+			total += item.Amount
+			assert.Equal(t, created.ID, item.ExpenseID)
+			assert.Equal(t, item.CategoryID, item.Category.ID)
+		}
+		assert.Equal(t, money.New(3500), total)
+	})
+
+	t.Run("filter by item category", func(t *testing.T) {
+		w := server.MakeRequest("GET", fmt.Sprintf("/api/expenses?categoryId=%s", household.ID), nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response models.PaginatedResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 1, response.Pagination.TotalItems)
+	})
+
+	t.Run("category used by items cannot be deleted", func(t *testing.T) {
+		w := server.MakeRequest("DELETE", fmt.Sprintf("/api/categories/%s", household.ID), nil)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("update back to single category", func(t *testing.T) {
+		updateRequest := models.UpdateExpenseRequest{
+			Amount:      money.New(3500),
+			Date:        pastDate,
+			Description: "スーパー",
+			CardID:      card.ID.String(),
+			CategoryID:  food.ID.String(),
+		}
+
+		w := server.MakeRequest("PUT", fmt.Sprintf("/api/expenses/%s", created.ID), updateRequest)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		expense, err := server.Repository.Expense.GetByID(context.Background(), created.ID)
+		require.NoError(t, err)
+		assert.Empty(t, expense.Items)
+		assert.Equal(t, food.ID, expense.CategoryID)
+	})
+
+	t.Run("delete split expense removes items", func(t *testing.T) {
+		expense := server.CreateTestExpense(t, 1000.0, "ドラッグストア", card.ID, food.ID)
+		updateRequest := models.UpdateExpenseRequest{
+			Amount:      money.New(1000),
+			Date:        pastDate,
+			Description: "ドラッグストア",
+			CardID:      card.ID.String(),
+			Items: []models.ExpenseItemRequest{
+				{Amount: money.New(400), CategoryID: food.ID.String()},
+				{Amount: money.New(600), CategoryID: household.ID.String()},
+			},
+		}
+
+		w := server.MakeRequest("PUT", fmt.Sprintf("/api/expenses/%s", expense.ID), updateRequest)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = server.MakeRequest("DELETE", fmt.Sprintf("/api/expenses/%s", expense.ID), nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var count int64
+		require.NoError(t, server.DB.Model(&models.ExpenseItem{}).Where("expense_id = ?", expense.ID).Count(&count).Error)
+		assert.Zero(t, count)
+	})
+}
+
+func TestExpenseAPI_SplitExpenseValidation(t *testing.T) {
+	server := SetupTestServer(t)
+	defer server.CleanupTestServer()
+
+	card := server.CreateTestCard(t, "テストカード", "#3B82F6")
+	food := server.CreateTestCategory(t, "食費", "#10B981", false)
+	household := server.CreateTestCategory(t, "日用品", "#F59E0B", false)
+	pastDate := time.Now().Add(-24 * time.Hour).Format(time.RFC3339)
+
+	t.Run("item amounts do not add up", func(t *testing.T) {
+		requestBody := models.CreateExpenseRequest{
+			Amount: money.New(3000),
+			Date:   pastDate,
+			CardID: card.ID.String(),
+			Items: []models.ExpenseItemRequest{
+				{Amount: money.New(1000), CategoryID: food.ID.String()},
+				{Amount: money.New(1500), CategoryID: household.ID.String()},
+			},
+		}
+
+		w := server.MakeRequest("POST", "/api/expenses", requestBody)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var response models.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "ITEMS_AMOUNT_MISMATCH", response.Error.Code)
+	})
+
+	t.Run("decimal amounts add up exactly", func(t *testing.T) {
+		requestBody := models.CreateExpenseRequest{
+			Amount:   money.FromFloat(0.3),
+			Currency: "USD",
+			Date:     pastDate,
+			CardID:   card.ID.String(),
+			Items: []models.ExpenseItemRequest{
+				{Amount: money.FromFloat(0.1), CategoryID: food.ID.String()},
+				{Amount: money.FromFloat(0.2), CategoryID: household.ID.String()},
+			},
+		}
+
+		w := server.MakeRequest("POST", "/api/expenses", requestBody)
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("invalid item category ID", func(t *testing.T) {
+		requestBody := models.CreateExpenseRequest{
+			Amount: money.New(1000),
+			Date:   pastDate,
+			CardID: card.ID.String(),
+			Items: []models.ExpenseItemRequest{
+				{Amount: money.New(1000), CategoryID: "invalid-uuid"},
+			},
+		}
+
+		w := server.MakeRequest("POST", "/api/expenses", requestBody)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var response models.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "INVALID_CATEGORY_ID", response.Error.Code)
+	})
+
+	t.Run("neither category nor items", func(t *testing.T) {
+		requestBody := models.CreateExpenseRequest{
+			Amount: money.New(1000),
+			Date:   pastDate,
+			CardID: card.ID.String(),
+		}
+
+		w := server.MakeRequest("POST", "/api/expenses", requestBody)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var response models.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "VALIDATION_ERROR", response.Error.Code)
+	})
+}
@@ -0,0 +1,123 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
+)
+
+func TestInsightAPI_Anomalies(t *testing.T) {
+	server := SetupTestServer(t)
+	defer server.CleanupTestServer()
+
+	card := server.CreateTestCard(t, "テストカード", "#3B82F6")
+	food := server.CreateTestCategory(t, "食費", "#10B981", true)
+
+	for day := 1; day <= 6; day++ {
+		createDatedExpense(t, server, time.March, day, 1000, card.ID, food.ID)
+	}
+
+	createExpense := func(t *testing.T, amount int64, date string) models.SuccessResponse {
+		w := server.MakeRequest("POST", "/api/expenses?checkAnomalies=true", models.CreateExpenseRequest{
+			Amount:     money.New(amount),
+			Date:       date,
+			CardID:     card.ID.String(),
+			CategoryID: food.ID.String(),
+		})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		var response models.SuccessResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	t.Run("usual expense has no warnings", func(t *testing.T) {
+		response := createExpense(t, 1200, "2025-04-01")
+		assert.Nil(t, response.Warnings)
+	})
+
+	var typo models.Expense
+	t.Run("unusual expense is created with a warning", func(t *testing.T) {
+		response := createExpense(t, 10000, "2025-04-02")
+
+		dataBytes, err := json.Marshal(response.Data)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(dataBytes, &typo))
+
+		warningBytes, err := json.Marshal(response.Warnings)
+		require.NoError(t, err)
+		var warnings []models.Anomaly
+		require.NoError(t, json.Unmarshal(warningBytes, &warnings))
+
+		require.Len(t, warnings, 1)
+		assert.Equal(t, models.AnomalyTypeOutlier, warnings[0].Type)
+		assert.Equal(t, typo.ID, *warnings[0].ExpenseID)
+		assert.Equal(t, money.New(1000), warnings[0].Expected)
+	})
+
+	t.Run("list anomalies", func(t *testing.T) {
+		w := server.MakeRequest("GET", "/api/insights/anomalies?from=2025-03-01&to=2025-04-30", nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var report models.AnomalyReport
+		decodeData(t, w.Body.Bytes(), &report)
+
+		assert.Equal(t, "JPY", report.Currency)
+		require.Len(t, report.Anomalies, 1)
+		assert.Equal(t, typo.ID, *report.Anomalies[0].ExpenseID)
+		assert.Equal(t, "テストカード", report.Anomalies[0].CardName)
+		assert.Equal(t, "食費", report.Anomalies[0].CategoryName)
+	})
+
+	t.Run("no anomalies in range", func(t *testing.T) {
+		w := server.MakeRequest("GET", "/api/insights/anomalies?from=2025-03-01&to=2025-03-31", nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var report models.AnomalyReport
+		decodeData(t, w.Body.Bytes(), &report)
+		assert.NotNil(t, report.Anomalies)
+		assert.Empty(t, report.Anomalies)
+	})
+
+	t.Run("unusual expense is not checked unless asked", func(t *testing.T) {
+		w := server.MakeRequest("POST", "/api/expenses", models.CreateExpenseRequest{
+			Amount:     money.New(10000),
+			Date:       "2025-05-01",
+			CardID:     card.ID.String(),
+			CategoryID: food.ID.String(),
+		})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		var response models.SuccessResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Nil(t, response.Warnings)
+	})
+
+	invalid := []struct {
+		name         string
+		query        string
+		expectedCode string
+	}{
+		{name: "Missing to", query: "from=2025-04-01", expectedCode: "MISSING_DATE_RANGE"},
+		{name: "Range ends before it starts", query: "from=2025-04-30&to=2025-04-01", expectedCode: "INVALID_DATE_RANGE"},
+		{name: "Invalid card ID", query: "cardId=invalid", expectedCode: "INVALID_CARD_ID"},
+	}
+
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			w := server.MakeRequest("GET", "/api/insights/anomalies?"+tt.query, nil)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var response models.ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedCode, response.Error.Code)
+		})
+	}
+}
@@ -0,0 +1,358 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
+)
+
+func decodeInstallmentPlan(t *testing.T, body []byte) models.InstallmentPlan {
+	var response models.SuccessResponse
+	require.NoError(t, json.Unmarshal(body, &response))
+
+	dataBytes, err := json.Marshal(response.Data)
+	require.NoError(t, err)
+	var plan models.InstallmentPlan
+	require.NoError(t, json.Unmarshal(dataBytes, &plan))
+	return plan
+}
+
+func TestInstallmentAPI_PlanLifecycle(t *testing.T) {
+	server := SetupTestServer(t)
+	defer server.CleanupTestServer()
+
+	card := server.CreateTestCard(t, "テストカード", "#3B82F6")
+	category := server.CreateTestCategory(t, "家電", "#10B981", false)
+	expense := server.CreateTestExpense(t, 120000.0, "冷蔵庫", card.ID, category.ID)
+	planURL := fmt.Sprintf("/api/expenses/%s/installment", expense.ID)
+
+	t.Run("no plan yet", func(t *testing.T) {
+		w := server.MakeRequest("GET", planURL, nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		var response models.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "INSTALLMENT_PLAN_NOT_FOUND", response.Error.Code)
+	})
+
+	t.Run("set installment plan", func(t *testing.T) {
+		w := server.MakeRequest("PUT", planURL, models.InstallmentPlanRequest{
+			PaymentType:      models.PaymentTypeInstallment,
+			NumberOfPayments: 6,
+		})
+		require.Equal(t, http.StatusOK, w.Code)
+
+		plan := decodeInstallmentPlan(t, w.Body.Bytes())
+		assert.Equal(t, 6, plan.NumberOfPayments)
+		require.Len(t, plan.Payments, 6)
+		assert.Equal(t, money.New(20000), plan.Payments[0].Amount)
+	})
+
+	t.Run("get installment plan", func(t *testing.T) {
+		w := server.MakeRequest("GET", planURL, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		plan := decodeInstallmentPlan(t, w.Body.Bytes())
+		require.Len(t, plan.Payments, 6)
+		for i, payment := range plan.Payments {
+			assert.Equal(t, i+1, payment.Sequence)
+		}
+	})
+
+	t.Run("replace with revolving plan", func(t *testing.T) {
+		w := server.MakeRequest("PUT", planURL, models.InstallmentPlanRequest{
+			PaymentType:        models.PaymentTypeRevolving,
+			MonthlyPayment:     money.New(50000),
+			AnnualInterestRate: 15,
+		})
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var count int64
+		require.NoError(t, server.DB.Model(&models.InstallmentPayment{}).Where("expense_id = ?", expense.ID).Count(&count).Error)
+		assert.Equal(t, int64(3), count)
+	})
+
+	t.Run("updating the expense reschedules the plan", func(t *testing.T) {
+		w := server.MakeRequest("PUT", fmt.Sprintf("/api/expenses/%s", expense.ID), models.UpdateExpenseRequest{
+			Amount:     money.New(200000),
+			Date:       expense.Date.Format(time.RFC3339),
+			CardID:     card.ID.String(),
+			CategoryID: category.ID.String(),
+		})
+		require.Equal(t, http.StatusOK, w.Code)
+
+		plan, err := server.Repository.Installment.GetByExpenseID(context.Background(), expense.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.PaymentTypeRevolving, plan.PaymentType)
+		assert.Len(t, plan.Payments, 4)
+
+		var principal money.Amount
+		for _, payment := range plan.Payments {
+			principal += payment.Principal
+		}
+		assert.Equal(t, money.New(200000), principal)
+	})
+
+	t.Run("an update the plan does not fit is rolled back", func(t *testing.T) {
+		w := server.MakeRequest("PUT", fmt.Sprintf("/api/expenses/%s", expense.ID), models.UpdateExpenseRequest{
+			Amount:     money.New(20000000),
+			Date:       expense.Date.Format(time.RFC3339),
+			CardID:     card.ID.String(),
+			CategoryID: category.ID.String(),
+		})
+		require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+		var response models.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "INVALID_INSTALLMENT_PLAN", response.Error.Code)
+
+		unchanged, err := server.Repository.Expense.GetByIDWithoutPreload(context.Background(), expense.ID)
+		require.NoError(t, err)
+		assert.Equal(t, money.New(200000), unchanged.Amount)
+	})
+
+	t.Run("invalid plan", func(t *testing.T) {
+		w := server.MakeRequest("PUT", planURL, models.InstallmentPlanRequest{
+			PaymentType: "bonus",
+		})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var response models.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "VALIDATION_ERROR", response.Error.Code)
+	})
+
+	t.Run("delete plan", func(t *testing.T) {
+		w := server.MakeRequest("DELETE", planURL, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = server.MakeRequest("GET", planURL, nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("non-existent expense", func(t *testing.T) {
+		w := server.MakeRequest("GET", fmt.Sprintf("/api/expenses/%s/installment", uuid.New()), nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestInstallmentAPI_Balances(t *testing.T) {
+	server := SetupTestServer(t)
+	defer server.CleanupTestServer()
+
+	card1 := server.CreateTestCard(t, "カード1", "#3B82F6")
+	card2 := server.CreateTestCard(t, "カード2", "#EF4444")
+	category := server.CreateTestCategory(t, "家電", "#10B981", false)
+	purchaseDate := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	for _, amount := range []float64{60000, 30000} {
+		expense := server.CreateTestExpense(t, amount, "分割購入", card1.ID, category.ID)
+		expense.Date = purchaseDate
+		require.NoError(t, server.Repository.Expense.Update(context.Background(), expense))
+
+		w := server.MakeRequest("PUT", fmt.Sprintf("/api/expenses/%s/installment", expense.ID), models.InstallmentPlanRequest{
+			PaymentType:      models.PaymentTypeInstallment,
+			NumberOfPayments: 3,
+		})
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+
+	// A lump-sum expense on the second card has no installment balance
+	server.CreateTestExpense(t, 5000, "一括", card2.ID, category.ID)
+
+	getBalances := func(t *testing.T, query string) []models.CardInstallmentBalance {
+		w := server.MakeRequest("GET", "/api/installments/balances"+query, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response models.SuccessResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		dataBytes, err := json.Marshal(response.Data)
+		require.NoError(t, err)
+		var balances []models.CardInstallmentBalance
+		require.NoError(t, json.Unmarshal(dataBytes, &balances))
+		return balances
+	}
+
+	t.Run("before the first payment", func(t *testing.T) {
+		balances := getBalances(t, "?asOf=2025-01-31")
+		require.Len(t, balances, 1)
+		assert.Equal(t, card1.ID, balances[0].CardID)
+		assert.Equal(t, "JPY", balances[0].Currency)
+		assert.Equal(t, 2, balances[0].ActivePlans)
+		assert.Equal(t, 6, balances[0].RemainingPayments)
+		assert.Equal(t, money.New(90000), balances[0].RemainingAmount)
+		assert.Equal(t, money.New(30000), balances[0].NextPaymentAmount)
+		require.NotNil(t, balances[0].NextPaymentDate)
+		assert.Equal(t, "2025-02-15", balances[0].NextPaymentDate.Format("2006-01-02"))
+	})
+
+	t.Run("after two payments", func(t *testing.T) {
+		balances := getBalances(t, fmt.Sprintf("?asOf=2025-03-15&cardId=%s", card1.ID))
+		require.Len(t, balances, 1)
+		assert.Equal(t, 2, balances[0].RemainingPayments)
+		assert.Equal(t, money.New(30000), balances[0].RemainingPrincipal)
+	})
+
+	t.Run("fully paid", func(t *testing.T) {
+		balances := getBalances(t, "?asOf=2025-12-31")
+		assert.Empty(t, balances)
+	})
+
+	t.Run("invalid asOf", func(t *testing.T) {
+		w := server.MakeRequest("GET", "/api/installments/balances?asOf=yesterday", nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestInstallmentAPI_BalancesInTwoCurrencies(t *testing.T) {
+	server := SetupTestServer(t)
+	defer server.CleanupTestServer()
+
+	card := server.CreateTestCard(t, "カード", "#3B82F6")
+	category := server.CreateTestCategory(t, "旅行", "#10B981", false)
+
+	for _, purchase := range []struct {
+		amount   float64
+		currency string
+	}{{90000, "JPY"}, {300, "USD"}} {
+		w := server.MakeRequest("POST", "/api/expenses", models.CreateExpenseRequest{
+			Amount:     money.FromFloat(purchase.amount),
+			Currency:   purchase.currency,
+			Date:       "2025-01-15",
+			CardID:     card.ID.String(),
+			CategoryID: category.ID.String(),
+		})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var expense models.Expense
+		decodeData(t, w.Body.Bytes(), &expense)
+
+		w = server.MakeRequest("PUT", fmt.Sprintf("/api/expenses/%s/installment", expense.ID), models.InstallmentPlanRequest{
+			PaymentType:      models.PaymentTypeInstallment,
+			NumberOfPayments: 3,
+		})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	}
+
+	w := server.MakeRequest("GET", "/api/installments/balances?asOf=2025-01-31", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var balances []models.CardInstallmentBalance
+	decodeData(t, w.Body.Bytes(), &balances)
+
+	// Amounts in different currencies are not added up
+	require.Len(t, balances, 2)
+	byCurrency := map[string]models.CardInstallmentBalance{}
+	for _, balance := range balances {
+		assert.Equal(t, card.ID, balance.CardID)
+		byCurrency[balance.Currency] = balance
+	}
+	assert.Equal(t, 1, byCurrency["JPY"].ActivePlans)
+	assert.Equal(t, money.New(90000), byCurrency["JPY"].RemainingAmount)
+	assert.Equal(t, money.New(30000), byCurrency["JPY"].NextPaymentAmount)
+	assert.Equal(t, 1, byCurrency["USD"].ActivePlans)
+	assert.Equal(t, money.New(300), byCurrency["USD"].RemainingAmount)
+	assert.Equal(t, money.New(100), byCurrency["USD"].NextPaymentAmount)
+}
+
+func TestInstallmentAPI_CascadeOnExpenseDelete(t *testing.T) {
+	server := SetupTestServer(t)
+	defer server.CleanupTestServer()
+
+	card := server.CreateTestCard(t, "テストカード", "#3B82F6")
+	category := server.CreateTestCategory(t, "家電", "#10B981", false)
+	expense := server.CreateTestExpense(t, 90000.0, "テレビ", card.ID, category.ID)
+
+	w := server.MakeRequest("PUT", fmt.Sprintf("/api/expenses/%s/installment", expense.ID), models.InstallmentPlanRequest{
+		PaymentType:      models.PaymentTypeInstallment,
+		NumberOfPayments: 3,
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = server.MakeRequest("DELETE", fmt.Sprintf("/api/expenses/%s", expense.ID), nil)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var count int64
+	require.NoError(t, server.DB.Model(&models.InstallmentPayment{}).Where("expense_id = ?", expense.ID).Count(&count).Error)
+	assert.Zero(t, count)
+	require.NoError(t, server.DB.Model(&models.InstallmentPlan{}).Where("expense_id = ?", expense.ID).Count(&count).Error)
+	assert.Zero(t, count)
+}
+
+func TestReportAPI_InvalidView(t *testing.T) {
+	server := SetupTestServer(t)
+	defer server.CleanupTestServer()
+
+	for _, url := range []string{
+		"/api/reports/monthly?year=2025&month=1&view=billing",
+		"/api/reports/yearly?year=2025&view=billing",
+	} {
+		w := server.MakeRequest("GET", url, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var response models.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "INVALID_VIEW", response.Error.Code)
+	}
+}
+
+func TestReportAPI_PaymentViewSplitExpense(t *testing.T) {
+	server := SetupTestServer(t)
+	defer server.CleanupTestServer()
+
+	card := server.CreateTestCard(t, "テストカード", "#3B82F6")
+	food := server.CreateTestCategory(t, "食費", "#10B981", false)
+	household := server.CreateTestCategory(t, "日用品", "#F59E0B", false)
+	travel := server.CreateTestCategory(t, "旅行", "#8B5CF6", false)
+
+	// Each charge is split three ways into halves and quarters, which do
+	// not come out in whole yen
+	expense := createSplitExpense(t, server, card.ID, []int64{500, 250, 250}, []uuid.UUID{food.ID, household.ID, travel.ID})
+	w := server.MakeRequest("PUT", fmt.Sprintf("/api/expenses/%s/installment", expense.ID), models.InstallmentPlanRequest{
+		PaymentType:      models.PaymentTypeInstallment,
+		NumberOfPayments: 3,
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	plan := decodeInstallmentPlan(t, w.Body.Bytes())
+
+	getReport := func(t *testing.T, from, to string) models.RangeReport {
+		w := server.MakeRequest("GET", fmt.Sprintf("/api/reports/range?from=%s&to=%s&view=payment", from, to), nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var report models.RangeReport
+		decodeData(t, w.Body.Bytes(), &report)
+		return report
+	}
+
+	t.Run("each charge is allocated in whole yen", func(t *testing.T) {
+		require.Len(t, plan.Payments, 3)
+		for _, payment := range plan.Payments {
+			date := payment.DueDate.Format("2006-01-02")
+			report := getReport(t, date, date)
+			assert.Equal(t, payment.Amount, report.TotalAmount)
+
+			var allocated money.Amount
+			for _, category := range report.ByCategory {
+				assert.Zero(t, category.TotalAmount%money.Unit("JPY"), "%s on %s", category.CategoryName, date)
+				allocated += category.TotalAmount
+			}
+			assert.Equal(t, payment.Amount, allocated, date)
+		}
+	})
+
+	t.Run("the charges add up to the items", func(t *testing.T) {
+		report := getReport(t, "2025-01-01", "2025-12-31")
+		assert.Equal(t, map[uuid.UUID]money.Amount{
+			food.ID:      money.New(500),
+			household.ID: money.New(250),
+			travel.ID:    money.New(250),
+		}, categoryTotals(report))
+	})
+}
@@ -0,0 +1,196 @@
+package integration
+
+import (
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"kakeibo-tanuki/internal/database"
+	"kakeibo-tanuki/internal/migrate"
+)
+
+func testMigrations() fstest.MapFS {
+	return fstest.MapFS{
+		"001_create_cards.up.sql":      {Data: []byte("CREATE TABLE cards (id TEXT PRIMARY KEY, name TEXT NOT NULL);")},
+		"001_create_cards.down.sql":    {Data: []byte("DROP TABLE cards;")},
+		"002_add_card_color.up.sql":    {Data: []byte("ALTER TABLE cards ADD COLUMN color TEXT;")},
+		"002_add_card_color.down.sql":  {Data: []byte("ALTER TABLE cards DROP COLUMN color;")},
+		"003_create_expenses.up.sql":   {Data: []byte("CREATE TABLE expenses (id TEXT PRIMARY KEY, card_id TEXT REFERENCES cards(id));\nCREATE INDEX idx_expenses_card_id ON expenses(card_id);")},
+		"003_create_expenses.down.sql": {Data: []byte("DROP TABLE expenses;")},
+	}
+}
+
+func openMigrationDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "kakeibo.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		sqlDB, _ := db.DB()
+		sqlDB.Close()
+	})
+	return db
+}
+
+func versions(migrations []migrate.Migration) []int {
+	result := []int{}
+	for _, migration := range migrations {
+		result = append(result, migration.Version)
+	}
+	return result
+}
+
+func TestMigrator(t *testing.T) {
+	db := openMigrationDB(t)
+	migrator, err := migrate.New(db, testMigrations())
+	require.NoError(t, err)
+
+	t.Run("status before migrating", func(t *testing.T) {
+		statuses, err := migrator.Status()
+		require.NoError(t, err)
+		require.Len(t, statuses, 3)
+		for _, status := range statuses {
+			assert.False(t, status.Applied)
+		}
+	})
+
+	t.Run("up applies every migration once", func(t *testing.T) {
+		applied, err := migrator.Up()
+		require.NoError(t, err)
+		assert.Equal(t, []int{1, 2, 3}, versions(applied))
+		assert.True(t, db.Migrator().HasColumn("cards", "color"))
+		assert.True(t, db.Migrator().HasTable("expenses"))
+
+		applied, err = migrator.Up()
+		require.NoError(t, err)
+		assert.Empty(t, applied)
+
+		statuses, err := migrator.Status()
+		require.NoError(t, err)
+		for _, status := range statuses {
+			assert.True(t, status.Applied)
+			assert.NotNil(t, status.AppliedAt)
+			assert.False(t, status.Modified)
+		}
+	})
+
+	t.Run("down rolls back the last migrations", func(t *testing.T) {
+		rolledBack, err := migrator.Down(2)
+		require.NoError(t, err)
+		assert.Equal(t, []int{3, 2}, versions(rolledBack))
+		assert.False(t, db.Migrator().HasTable("expenses"))
+		assert.False(t, db.Migrator().HasColumn("cards", "color"))
+	})
+
+	t.Run("to reaches a version in either direction", func(t *testing.T) {
+		done, err := migrator.To(3)
+		require.NoError(t, err)
+		assert.Equal(t, []int{2, 3}, versions(done))
+
+		done, err = migrator.To(1)
+		require.NoError(t, err)
+		assert.Equal(t, []int{3, 2}, versions(done))
+
+		done, err = migrator.To(0)
+		require.NoError(t, err)
+		assert.Equal(t, []int{1}, versions(done))
+		assert.False(t, db.Migrator().HasTable("cards"))
+
+		_, err = migrator.To(7)
+		assert.ErrorIs(t, err, migrate.ErrUnknownVersion)
+	})
+
+	t.Run("failed migration is rolled back", func(t *testing.T) {
+		fsys := testMigrations()
+		fsys["004_broken.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE payees (id TEXT PRIMARY KEY);\nINSERT INTO missing VALUES (1);")}
+		fsys["004_broken.down.sql"] = &fstest.MapFile{Data: []byte("DROP TABLE payees;")}
+		broken, err := migrate.New(db, fsys)
+		require.NoError(t, err)
+
+		applied, err := broken.Up()
+		require.Error(t, err)
+		assert.Equal(t, []int{1, 2, 3}, versions(applied))
+		assert.False(t, db.Migrator().HasTable("payees"))
+
+		statuses, err := broken.Status()
+		require.NoError(t, err)
+		assert.False(t, statuses[3].Applied)
+	})
+}
+
+func TestMigrator_Checksums(t *testing.T) {
+	db := openMigrationDB(t)
+	migrator, err := migrate.New(db, testMigrations())
+	require.NoError(t, err)
+	_, err = migrator.Up()
+	require.NoError(t, err)
+
+	t.Run("edited migration", func(t *testing.T) {
+		fsys := testMigrations()
+		fsys["002_add_card_color.up.sql"] = &fstest.MapFile{Data: []byte("ALTER TABLE cards ADD COLUMN colour TEXT;")}
+		edited, err := migrate.New(db, fsys)
+		require.NoError(t, err)
+
+		statuses, err := edited.Status()
+		require.NoError(t, err)
+		assert.True(t, statuses[1].Modified)
+
+		_, err = edited.Up()
+		assert.ErrorIs(t, err, migrate.ErrChecksumMismatch)
+		_, err = edited.Down(1)
+		assert.ErrorIs(t, err, migrate.ErrChecksumMismatch)
+	})
+
+	t.Run("applied migration that is gone", func(t *testing.T) {
+		fsys := testMigrations()
+		delete(fsys, "003_create_expenses.up.sql")
+		delete(fsys, "003_create_expenses.down.sql")
+		older, err := migrate.New(db, fsys)
+		require.NoError(t, err)
+
+		statuses, err := older.Status()
+		require.NoError(t, err)
+		require.Len(t, statuses, 3)
+		assert.True(t, statuses[2].Missing)
+
+		_, err = older.Up()
+		assert.ErrorIs(t, err, migrate.ErrUnknownVersion)
+	})
+}
+
+func TestNewDatabase_SQLite(t *testing.T) {
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DB_PATH", filepath.Join(t.TempDir(), "data", "kakeibo.db"))
+
+	db, err := database.NewDatabase()
+	require.NoError(t, err)
+	defer db.Close()
+	db.DB = db.DB.Session(&gorm.Session{Logger: logger.Default.LogMode(logger.Silent)})
+
+	migrator, err := db.Migrator()
+	require.NoError(t, err)
+	statuses, err := migrator.Status()
+	require.NoError(t, err)
+	require.NotEmpty(t, statuses)
+	for _, status := range statuses {
+		assert.True(t, status.Applied)
+	}
+
+	var categories int64
+	require.NoError(t, db.DB.Table("categories").Count(&categories).Error)
+	assert.Equal(t, int64(5), categories, "default categories")
+
+	_, err = migrator.To(0)
+	require.NoError(t, err)
+	assert.False(t, db.DB.Migrator().HasTable("expenses"))
+
+	t.Setenv("DB_DRIVER", "mysql")
+	_, err = database.Open()
+	assert.EqualError(t, err, "unknown database driver: mysql")
+}
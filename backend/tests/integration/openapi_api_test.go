@@ -0,0 +1,45 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kakeibo-tanuki/internal/openapi"
+)
+
+func TestOpenAPIDocument(t *testing.T) {
+	server := SetupTestServer(t)
+	defer server.CleanupTestServer()
+
+	w := server.MakeRequest("GET", "/api/openapi.json", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+
+	var doc struct {
+		OpenAPI    string                                `json:"openapi"`
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+		ErrorCodes map[string]string `json:"x-error-codes"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	assert.Equal(t, openapi.Version, doc.OpenAPI)
+	assert.Contains(t, doc.Components.Schemas, "ErrorResponse")
+	assert.Contains(t, doc.ErrorCodes, "CARD_NOT_FOUND")
+
+	// Every route the server answers is documented
+	routes := server.Router.Routes()
+	require.NotEmpty(t, routes)
+	for _, route := range routes {
+		operations, ok := doc.Paths[openapi.Path(route.Path)]
+		if assert.True(t, ok, "%s %s is not documented", route.Method, route.Path) {
+			assert.Contains(t, operations, strings.ToLower(route.Method), "%s %s is not documented", route.Method, route.Path)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
+)
+
+func TestPayeeAPI(t *testing.T) {
+	server := SetupTestServer(t)
+	defer server.CleanupTestServer()
+
+	card := server.CreateTestCard(t, "テストカード", "#3B82F6")
+	category := server.CreateTestCategory(t, "日用品", "#10B981", false)
+
+	// Recorded before the payee exists
+	existing := server.CreateTestExpense(t, 3000, "AMAZON.CO.JP", card.ID, category.ID)
+	other := server.CreateTestExpense(t, 500, "コンビニ", card.ID, category.ID)
+
+	var amazon models.PayeeResult
+	t.Run("create payee links matching expenses", func(t *testing.T) {
+		w := server.MakeRequest("POST", "/api/payees", models.CreatePayeeRequest{
+			Name:     "Amazon",
+			Aliases:  []string{"アマゾン"},
+			Patterns: []string{`^amazon\.co\.jp`},
+		})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		decodeData(t, w.Body.Bytes(), &amazon)
+
+		assert.Equal(t, "Amazon", amazon.Name)
+		assert.Equal(t, []string{"アマゾン"}, amazon.Aliases)
+		assert.Equal(t, 1, amazon.MatchedExpenses)
+
+		expense, err := server.Repository.Expense.GetByID(context.Background(), existing.ID)
+		require.NoError(t, err)
+		require.NotNil(t, expense.PayeeID)
+		assert.Equal(t, amazon.ID, *expense.PayeeID)
+
+		expense, err = server.Repository.Expense.GetByID(context.Background(), other.ID)
+		require.NoError(t, err)
+		assert.Nil(t, expense.PayeeID)
+	})
+
+	createExpense := func(t *testing.T, description, payeeID string) models.Expense {
+		w := server.MakeRequest("POST", "/api/expenses", models.CreateExpenseRequest{
+			Amount:      money.New(1200),
+			Date:        time.Now().Add(-24 * time.Hour).Format(time.RFC3339),
+			Description: description,
+			CardID:      card.ID.String(),
+			CategoryID:  category.ID.String(),
+			PayeeID:     payeeID,
+		})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		var expense models.Expense
+		decodeData(t, w.Body.Bytes(), &expense)
+		return expense
+	}
+
+	t.Run("new expenses are matched by description", func(t *testing.T) {
+		expense := createExpense(t, "ｱﾏｿﾞﾝ", "")
+		require.NotNil(t, expense.PayeeID)
+		assert.Equal(t, amazon.ID, *expense.PayeeID)
+		require.NotNil(t, expense.Payee)
+		assert.Equal(t, "Amazon", expense.Payee.Name)
+
+		expense = createExpense(t, "本屋", "")
+		assert.Nil(t, expense.PayeeID)
+	})
+
+	t.Run("payee given explicitly", func(t *testing.T) {
+		expense := createExpense(t, "ギフト", amazon.ID.String())
+		require.NotNil(t, expense.PayeeID)
+		assert.Equal(t, amazon.ID, *expense.PayeeID)
+
+		w := server.MakeRequest("GET", "/api/expenses?payeeId="+amazon.ID.String(), nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		var response models.PaginatedResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 3, response.Pagination.TotalItems)
+	})
+
+	t.Run("top payees in reports", func(t *testing.T) {
+		now := time.Now().Add(-24 * time.Hour)
+		w := server.MakeRequest("GET", "/api/reports/yearly?year="+now.Format("2006"), nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var report models.YearlyReport
+		decodeData(t, w.Body.Bytes(), &report)
+		require.Len(t, report.ByPayee, 1)
+		assert.Equal(t, amazon.ID, report.ByPayee[0].PayeeID)
+		assert.Equal(t, money.New(5400), report.ByPayee[0].TotalAmount)
+		assert.Equal(t, 3, report.ByPayee[0].Count)
+	})
+
+	t.Run("update payee", func(t *testing.T) {
+		w := server.MakeRequest("PUT", "/api/payees/"+amazon.ID.String(), models.UpdatePayeeRequest{
+			Name:     "Amazon",
+			Patterns: []string{`^amazon`, `本屋`},
+		})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var updated models.PayeeResult
+		decodeData(t, w.Body.Bytes(), &updated)
+		assert.Empty(t, updated.Aliases)
+		assert.Equal(t, 1, updated.MatchedExpenses)
+	})
+
+	t.Run("payee is kept or cleared on update", func(t *testing.T) {
+		update := func(t *testing.T, payeeID *string) models.Expense {
+			w := server.MakeRequest("PUT", "/api/expenses/"+existing.ID.String(), models.UpdateExpenseRequest{
+				Amount:      existing.Amount,
+				Date:        existing.Date.Format("2006-01-02"),
+				Description: existing.Description,
+				CardID:      card.ID.String(),
+				CategoryID:  category.ID.String(),
+				PayeeID:     payeeID,
+			})
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			var expense models.Expense
+			decodeData(t, w.Body.Bytes(), &expense)
+			return expense
+		}
+
+		expense := update(t, nil)
+		require.NotNil(t, expense.PayeeID, "left out")
+		assert.Equal(t, amazon.ID, *expense.PayeeID)
+
+		empty := ""
+		expense = update(t, &empty)
+		assert.Nil(t, expense.PayeeID, "cleared rather than matched with the description again")
+	})
+
+	errorCases := []struct {
+		name         string
+		method       string
+		path         string
+		body         interface{}
+		expectedCode string
+		expectedHTTP int
+	}{
+		{name: "Invalid pattern", method: "POST", path: "/api/payees", body: models.CreatePayeeRequest{Name: "Broken", Patterns: []string{"amazon("}}, expectedCode: "INVALID_PATTERN", expectedHTTP: http.StatusBadRequest},
+		{name: "Duplicate name", method: "POST", path: "/api/payees", body: models.CreatePayeeRequest{Name: "Amazon"}, expectedCode: "DUPLICATE_PAYEE", expectedHTTP: http.StatusConflict},
+		{name: "Missing name", method: "POST", path: "/api/payees", body: models.CreatePayeeRequest{}, expectedCode: "VALIDATION_ERROR", expectedHTTP: http.StatusBadRequest},
+		{name: "Unknown payee", method: "GET", path: "/api/payees/" + card.ID.String(), expectedCode: "PAYEE_NOT_FOUND", expectedHTTP: http.StatusNotFound},
+		{name: "Expense with unknown payee", method: "POST", path: "/api/expenses", body: models.CreateExpenseRequest{Amount: money.New(100), Date: "2025-01-01", CardID: card.ID.String(), CategoryID: category.ID.String(), PayeeID: card.ID.String()}, expectedCode: "PAYEE_NOT_FOUND", expectedHTTP: http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range errorCases {
+		t.Run(tt.name, func(t *testing.T) {
+			w := server.MakeRequest(tt.method, tt.path, tt.body)
+			assert.Equal(t, tt.expectedHTTP, w.Code)
+
+			var response models.ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedCode, response.Error.Code)
+		})
+	}
+
+	t.Run("delete payee keeps its expenses", func(t *testing.T) {
+		w := server.MakeRequest("DELETE", "/api/payees/"+amazon.ID.String(), nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		expense, err := server.Repository.Expense.GetByID(context.Background(), existing.ID)
+		require.NoError(t, err)
+		assert.Nil(t, expense.PayeeID)
+
+		w = server.MakeRequest("GET", "/api/payees", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		var list []models.Payee
+		decodeData(t, w.Body.Bytes(), &list)
+		assert.Empty(t, list)
+	})
+}
@@ -0,0 +1,290 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
+)
+
+func TestRefundAPI_Lifecycle(t *testing.T) {
+	server := SetupTestServer(t)
+	defer server.CleanupTestServer()
+
+	card := server.CreateTestCard(t, "テストカード", "#3B82F6")
+	category := server.CreateTestCategory(t, "衣服", "#10B981", false)
+	expense := server.CreateTestExpense(t, 10000.0, "コート", card.ID, category.ID)
+	refundsURL := fmt.Sprintf("/api/expenses/%s/refunds", expense.ID)
+	today := time.Now().Format("2006-01-02")
+
+	var partialRefund models.Refund
+
+	t.Run("partial refund", func(t *testing.T) {
+		w := server.MakeRequest("POST", refundsURL, models.CreateRefundRequest{
+			Amount:      money.New(3000),
+			Date:        today,
+			Description: "サイズ違いを返品",
+		})
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var response models.SuccessResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		dataBytes, err := json.Marshal(response.Data)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(dataBytes, &partialRefund))
+		assert.Equal(t, expense.ID, partialRefund.ExpenseID)
+		assert.Equal(t, money.New(3000), partialRefund.Amount)
+	})
+
+	t.Run("refund exceeding the remaining amount", func(t *testing.T) {
+		w := server.MakeRequest("POST", refundsURL, models.CreateRefundRequest{
+			Amount: money.New(7001),
+			Date:   today,
+		})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var response models.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "REFUND_EXCEEDS_AMOUNT", response.Error.Code)
+	})
+
+	t.Run("refund of the remaining amount", func(t *testing.T) {
+		w := server.MakeRequest("POST", refundsURL, models.CreateRefundRequest{
+			Amount: money.New(7000),
+			Date:   today,
+		})
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		total, err := server.Repository.Refund.GetTotalByExpenseID(context.Background(), expense.ID)
+		require.NoError(t, err)
+		assert.Equal(t, money.New(10000), total)
+	})
+
+	t.Run("list refunds", func(t *testing.T) {
+		w := server.MakeRequest("GET", refundsURL, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response models.SuccessResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		refunds, ok := response.Data.([]interface{})
+		require.True(t, ok)
+		assert.Len(t, refunds, 2)
+	})
+
+	t.Run("expense includes its refunds", func(t *testing.T) {
+		w := server.MakeRequest("GET", fmt.Sprintf("/api/expenses/%s", expense.ID), nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response models.SuccessResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		dataBytes, err := json.Marshal(response.Data)
+		require.NoError(t, err)
+		var fetched models.Expense
+		require.NoError(t, json.Unmarshal(dataBytes, &fetched))
+		assert.Len(t, fetched.Refunds, 2)
+	})
+
+	t.Run("expense amount cannot drop below the refunded amount", func(t *testing.T) {
+		w := server.MakeRequest("PUT", fmt.Sprintf("/api/expenses/%s", expense.ID), models.UpdateExpenseRequest{
+			Amount:     money.New(5000),
+			Date:       expense.Date.Format(time.RFC3339),
+			CardID:     card.ID.String(),
+			CategoryID: category.ID.String(),
+		})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var response models.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "REFUND_EXCEEDS_AMOUNT", response.Error.Code)
+	})
+
+	t.Run("delete refund", func(t *testing.T) {
+		w := server.MakeRequest("DELETE", fmt.Sprintf("%s/%s", refundsURL, partialRefund.ID), nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		total, err := server.Repository.Refund.GetTotalByExpenseID(context.Background(), expense.ID)
+		require.NoError(t, err)
+		assert.Equal(t, money.New(7000), total)
+	})
+
+	t.Run("delete non-existent refund", func(t *testing.T) {
+		w := server.MakeRequest("DELETE", fmt.Sprintf("%s/%s", refundsURL, uuid.New()), nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		var response models.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "REFUND_NOT_FOUND", response.Error.Code)
+	})
+}
+
+func TestRefundAPI_Validation(t *testing.T) {
+	server := SetupTestServer(t)
+	defer server.CleanupTestServer()
+
+	card := server.CreateTestCard(t, "テストカード", "#3B82F6")
+	category := server.CreateTestCategory(t, "食費", "#10B981", false)
+	expense := server.CreateTestExpense(t, 2000.0, "ランチ", card.ID, category.ID)
+	other := server.CreateTestExpense(t, 500.0, "コーヒー", card.ID, category.ID)
+	refundsURL := fmt.Sprintf("/api/expenses/%s/refunds", expense.ID)
+
+	tests := []struct {
+		name         string
+		url          string
+		request      models.CreateRefundRequest
+		expectedCode string
+		expectedHTTP int
+	}{
+		{
+			name:         "Zero amount",
+			url:          refundsURL,
+			request:      models.CreateRefundRequest{Amount: 0, Date: time.Now().Format("2006-01-02")},
+			expectedCode: "VALIDATION_ERROR",
+			expectedHTTP: http.StatusBadRequest,
+		},
+		{
+			name:         "Negative amount",
+			url:          refundsURL,
+			request:      models.CreateRefundRequest{Amount: money.New(-100), Date: time.Now().Format("2006-01-02")},
+			expectedCode: "VALIDATION_ERROR",
+			expectedHTTP: http.StatusBadRequest,
+		},
+		{
+			name:         "Invalid date",
+			url:          refundsURL,
+			request:      models.CreateRefundRequest{Amount: money.New(100), Date: "2025/01/01"},
+			expectedCode: "INVALID_DATE",
+			expectedHTTP: http.StatusBadRequest,
+		},
+		{
+			name:         "Future date",
+			url:          refundsURL,
+			request:      models.CreateRefundRequest{Amount: money.New(100), Date: time.Now().AddDate(0, 0, 2).Format("2006-01-02")},
+			expectedCode: "FUTURE_DATE",
+			expectedHTTP: http.StatusBadRequest,
+		},
+		{
+			name:         "Before the expense date",
+			url:          refundsURL,
+			request:      models.CreateRefundRequest{Amount: money.New(100), Date: expense.Date.AddDate(0, 0, -3).Format("2006-01-02")},
+			expectedCode: "INVALID_REFUND_DATE",
+			expectedHTTP: http.StatusBadRequest,
+		},
+		{
+			name:         "Non-existent expense",
+			url:          fmt.Sprintf("/api/expenses/%s/refunds", uuid.New()),
+			request:      models.CreateRefundRequest{Amount: money.New(100), Date: time.Now().Format("2006-01-02")},
+			expectedCode: "EXPENSE_NOT_FOUND",
+			expectedHTTP: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := server.MakeRequest("POST", tt.url, tt.request)
+			assert.Equal(t, tt.expectedHTTP, w.Code)
+
+			var response models.ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedCode, response.Error.Code)
+		})
+	}
+
+	t.Run("refund of another expense cannot be deleted", func(t *testing.T) {
+		refund := &models.Refund{ID: uuid.New(), ExpenseID: other.ID, Amount: money.New(100), Date: time.Now()}
+		require.NoError(t, server.Repository.Refund.Create(context.Background(), refund))
+
+		w := server.MakeRequest("DELETE", fmt.Sprintf("%s/%s", refundsURL, refund.ID), nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("deleting the expense removes its refunds", func(t *testing.T) {
+		w := server.MakeRequest("DELETE", fmt.Sprintf("/api/expenses/%s", other.ID), nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		refunds, err := server.Repository.Refund.GetByExpenseID(context.Background(), other.ID)
+		require.NoError(t, err)
+		assert.Empty(t, refunds)
+	})
+}
+
+func TestRefundAPI_ConcurrentRefunds(t *testing.T) {
+	server := SetupTestServer(t)
+	defer server.CleanupTestServer()
+
+	card := server.CreateTestCard(t, "テストカード", "#3B82F6")
+	category := server.CreateTestCategory(t, "衣服", "#10B981", false)
+	expense := server.CreateTestExpense(t, 10000.0, "コート", card.ID, category.ID)
+	refundsURL := fmt.Sprintf("/api/expenses/%s/refunds", expense.ID)
+
+	// Only three of the refunds fit in the expense amount
+	codes := make([]int, 8)
+	var wg sync.WaitGroup
+	for i := range codes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := server.MakeRequest("POST", refundsURL, models.CreateRefundRequest{
+				Amount: money.New(3000),
+				Date:   time.Now().Format("2006-01-02"),
+			})
+			codes[i] = w.Code
+		}()
+	}
+	wg.Wait()
+
+	created := 0
+	for _, code := range codes {
+		if code == http.StatusCreated {
+			created++
+		} else {
+			assert.Equal(t, http.StatusBadRequest, code)
+		}
+	}
+	assert.Equal(t, 3, created)
+
+	refunded, err := server.Repository.Refund.GetTotalByExpenseID(context.Background(), expense.ID)
+	require.NoError(t, err)
+	assert.Equal(t, money.New(9000), refunded)
+}
+
+func TestReportAPI_RefundOfSplitExpense(t *testing.T) {
+	server := SetupTestServer(t)
+	defer server.CleanupTestServer()
+
+	card := server.CreateTestCard(t, "テストカード", "#3B82F6")
+	food := server.CreateTestCategory(t, "食費", "#10B981", false)
+	household := server.CreateTestCategory(t, "日用品", "#F59E0B", false)
+	travel := server.CreateTestCategory(t, "旅行", "#8B5CF6", false)
+
+	// A third of the expense is refunded, which does not divide into whole
+	// yen between the items
+	expense := createSplitExpense(t, server, card.ID, []int64{500, 250, 250}, []uuid.UUID{food.ID, household.ID, travel.ID})
+	require.NoError(t, server.Repository.Refund.Create(context.Background(), &models.Refund{
+		ID:        uuid.New(),
+		ExpenseID: expense.ID,
+		Amount:    money.New(333),
+		Date:      time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+	}))
+
+	w := server.MakeRequest("GET", "/api/reports/range?from=2025-02-01&to=2025-02-28", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var report models.RangeReport
+	decodeData(t, w.Body.Bytes(), &report)
+
+	assert.Equal(t, money.New(-333), report.TotalAmount)
+	assert.Equal(t, map[uuid.UUID]money.Amount{
+		food.ID:      money.New(-167),
+		household.ID: money.New(-83),
+		travel.ID:    money.New(-83),
+	}, categoryTotals(report))
+}
@@ -0,0 +1,460 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kakeibo-tanuki/internal/handlers"
+	"kakeibo-tanuki/internal/middleware"
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
+)
+
+// createDatedExpense stores an expense on the given day of 2025.
+func createDatedExpense(t *testing.T, server *TestServer, month time.Month, day int, amount int64, cardID, categoryID uuid.UUID) *models.Expense {
+	expense := &models.Expense{
+		ID:         uuid.New(),
+		Amount:     money.New(amount),
+		Currency:   "JPY",
+		Date:       time.Date(2025, month, day, 0, 0, 0, 0, time.UTC),
+		CardID:     cardID,
+		CategoryID: categoryID,
+	}
+	require.NoError(t, server.Repository.Expense.Create(context.Background(), expense))
+	return expense
+}
+
+// createSplitExpense records an expense on 2025-01-15 split between items
+// of the given amounts and categories, in that order.
+func createSplitExpense(t *testing.T, server *TestServer, cardID uuid.UUID, amounts []int64, categoryIDs []uuid.UUID) *models.Expense {
+	request := models.CreateExpenseRequest{
+		Date:   "2025-01-15",
+		CardID: cardID.String(),
+	}
+	for i, amount := range amounts {
+		request.Amount += money.New(amount)
+		request.Items = append(request.Items, models.ExpenseItemRequest{
+			Amount:     money.New(amount),
+			CategoryID: categoryIDs[i].String(),
+		})
+	}
+
+	w := server.MakeRequest("POST", "/api/expenses", request)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var expense models.Expense
+	decodeData(t, w.Body.Bytes(), &expense)
+	return &expense
+}
+
+// categoryTotals returns the total of each category of report.
+func categoryTotals(report models.RangeReport) map[uuid.UUID]money.Amount {
+	totals := map[uuid.UUID]money.Amount{}
+	for _, category := range report.ByCategory {
+		totals[category.CategoryID] = category.TotalAmount
+	}
+	return totals
+}
+
+func TestReportAPI_Range(t *testing.T) {
+	server := SetupTestServer(t)
+	defer server.CleanupTestServer()
+
+	cardA := server.CreateTestCard(t, "カードA", "#3B82F6")
+	cardB := server.CreateTestCard(t, "カードB", "#EF4444")
+	food := server.CreateTestCategory(t, "食費", "#10B981", true)
+	travel := server.CreateTestCategory(t, "旅行", "#F59E0B", false)
+
+	createDatedExpense(t, server, time.March, 31, 777, cardA.ID, food.ID)
+	createDatedExpense(t, server, time.April, 1, 1000, cardA.ID, food.ID)
+	createDatedExpense(t, server, time.April, 15, 2001, cardB.ID, food.ID)
+	trip := createDatedExpense(t, server, time.May, 10, 5000, cardA.ID, travel.ID)
+	createDatedExpense(t, server, time.June, 30, 500, cardA.ID, food.ID)
+	createDatedExpense(t, server, time.July, 1, 999, cardA.ID, food.ID)
+
+	require.NoError(t, server.Repository.Refund.Create(context.Background(), &models.Refund{
+		ID:        uuid.New(),
+		ExpenseID: trip.ID,
+		Amount:    money.New(1000),
+		Date:      time.Date(2025, 5, 12, 0, 0, 0, 0, time.UTC),
+	}))
+
+	getReport := func(t *testing.T, query string) models.RangeReport {
+		w := server.MakeRequest("GET", "/api/reports/range?"+query, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var report models.RangeReport
+		decodeData(t, w.Body.Bytes(), &report)
+		return report
+	}
+
+	t.Run("grouped by month", func(t *testing.T) {
+		report := getReport(t, "from=2025-04-01&to=2025-06-30&groupBy=month")
+
+		assert.Equal(t, "2025-04-01", report.From)
+		assert.Equal(t, "2025-06-30", report.To)
+		assert.Equal(t, models.ReportGroupByMonth, report.GroupBy)
+		assert.Equal(t, "JPY", report.Currency)
+		assert.Equal(t, money.New(7501), report.TotalAmount)
+
+		require.Len(t, report.Periods, 3)
+		assert.Equal(t, models.PeriodExpenseSum{Start: "2025-04-01", End: "2025-04-30", TotalAmount: money.New(3001), Count: 2}, report.Periods[0])
+		assert.Equal(t, models.PeriodExpenseSum{Start: "2025-05-01", End: "2025-05-31", TotalAmount: money.New(4000), Count: 1}, report.Periods[1])
+		assert.Equal(t, models.PeriodExpenseSum{Start: "2025-06-01", End: "2025-06-30", TotalAmount: money.New(500), Count: 1}, report.Periods[2])
+
+		assert.Len(t, report.ByCategory, 2)
+		assert.Len(t, report.ByCard, 2)
+
+		// The odd yen of the shared food expenses goes to the first member
+		assert.Equal(t, money.New(3501), report.SharedExpenses.TotalSharedAmount)
+		assert.Equal(t, money.New(1750), report.SharedExpenses.SplitAmount)
+		assert.Equal(t, []money.Amount{money.New(1751), money.New(1750)}, report.SharedExpenses.Shares)
+	})
+
+	t.Run("grouped by quarter", func(t *testing.T) {
+		report := getReport(t, "from=2025-04-01&to=2025-06-30&groupBy=quarter")
+
+		require.Len(t, report.Periods, 1)
+		assert.Equal(t, money.New(7501), report.Periods[0].TotalAmount)
+		assert.Equal(t, 4, report.Periods[0].Count)
+	})
+
+	t.Run("weeks are clipped to the range", func(t *testing.T) {
+		report := getReport(t, "from=2025-04-02&to=2025-04-15&groupBy=week")
+
+		require.Len(t, report.Periods, 3)
+		assert.Equal(t, "2025-04-02", report.Periods[0].Start)
+		assert.Equal(t, "2025-04-06", report.Periods[0].End)
+		assert.Equal(t, "2025-04-14", report.Periods[2].Start)
+		assert.Equal(t, "2025-04-15", report.Periods[2].End)
+		assert.Zero(t, report.Periods[0].TotalAmount)
+		assert.Equal(t, money.New(2001), report.Periods[2].TotalAmount)
+		assert.Equal(t, money.New(2001), report.TotalAmount)
+	})
+
+	t.Run("defaults to monthly periods", func(t *testing.T) {
+		report := getReport(t, "from=2025-03-15&to=2025-04-10")
+
+		assert.Equal(t, models.ReportGroupByMonth, report.GroupBy)
+		require.Len(t, report.Periods, 2)
+		assert.Equal(t, "2025-03-15", report.Periods[0].Start)
+		assert.Equal(t, money.New(777), report.Periods[0].TotalAmount)
+	})
+
+	t.Run("filtered by card", func(t *testing.T) {
+		report := getReport(t, fmt.Sprintf("from=2025-04-01&to=2025-06-30&cardId=%s", cardB.ID))
+
+		assert.Equal(t, money.New(2001), report.TotalAmount)
+		assert.Empty(t, report.ByCard)
+	})
+
+	t.Run("monthly report uses the same totals", func(t *testing.T) {
+		w := server.MakeRequest("GET", "/api/reports/monthly?year=2025&month=4", nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var report models.MonthlyReport
+		decodeData(t, w.Body.Bytes(), &report)
+		assert.Equal(t, money.New(3001), report.TotalAmount)
+		assert.Equal(t, money.New(3001), report.SharedExpenses.TotalSharedAmount)
+	})
+
+	t.Run("yearly report lists months with expenses", func(t *testing.T) {
+		w := server.MakeRequest("GET", "/api/reports/yearly?year=2025", nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var report models.YearlyReport
+		decodeData(t, w.Body.Bytes(), &report)
+		assert.Equal(t, money.New(9277), report.TotalAmount)
+		require.Len(t, report.MonthlyData, 5)
+		assert.Equal(t, 3, report.MonthlyData[0].Month)
+		assert.Equal(t, money.New(4000), report.MonthlyData[2].TotalAmount)
+	})
+}
+
+func TestReportAPI_RangeValidation(t *testing.T) {
+	server := SetupTestServer(t)
+	defer server.CleanupTestServer()
+
+	tests := []struct {
+		name         string
+		query        string
+		expectedCode string
+	}{
+		{name: "Missing to", query: "from=2025-04-01", expectedCode: "MISSING_DATE_RANGE"},
+		{name: "Invalid from", query: "from=2025/04/01&to=2025-04-30", expectedCode: "INVALID_DATE"},
+		{name: "Invalid groupBy", query: "from=2025-04-01&to=2025-04-30&groupBy=year", expectedCode: "INVALID_GROUP_BY"},
+		{name: "End before start", query: "from=2025-04-30&to=2025-04-01", expectedCode: "INVALID_DATE_RANGE"},
+		{name: "Too many periods", query: "from=2000-01-01&to=2025-12-31&groupBy=day", expectedCode: "INVALID_DATE_RANGE"},
+		{name: "Invalid card", query: "from=2025-04-01&to=2025-04-30&cardId=abc", expectedCode: "INVALID_CARD_ID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := server.MakeRequest("GET", "/api/reports/range?"+tt.query, nil)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var response models.ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedCode, response.Error.Code)
+		})
+	}
+}
+
+func TestReportAPI_Compare(t *testing.T) {
+	server := SetupTestServer(t)
+	defer server.CleanupTestServer()
+
+	card := server.CreateTestCard(t, "テストカード", "#3B82F6")
+	food := server.CreateTestCategory(t, "食費", "#10B981", true)
+	travel := server.CreateTestCategory(t, "旅行", "#F59E0B", false)
+	books := server.CreateTestCategory(t, "書籍", "#6366F1", false)
+
+	createDatedExpense(t, server, time.April, 10, 3000, card.ID, food.ID)
+	createDatedExpense(t, server, time.April, 20, 1200, card.ID, books.ID)
+	createDatedExpense(t, server, time.May, 10, 3600, card.ID, food.ID)
+	createDatedExpense(t, server, time.May, 20, 10000, card.ID, travel.ID)
+
+	getReport := func(t *testing.T, query string) models.ComparisonReport {
+		w := server.MakeRequest("GET", "/api/reports/compare?"+query, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var report models.ComparisonReport
+		decodeData(t, w.Body.Bytes(), &report)
+		return report
+	}
+
+	t.Run("month vs previous month", func(t *testing.T) {
+		report := getReport(t, "year=2025&month=5")
+
+		assert.Equal(t, models.ReportPeriod{From: "2025-05-01", To: "2025-05-31", TotalAmount: money.New(13600)}, report.Current)
+		assert.Equal(t, models.ReportPeriod{From: "2025-04-01", To: "2025-04-30", TotalAmount: money.New(4200)}, report.Previous)
+		assert.Equal(t, money.New(9400), report.Change)
+		require.NotNil(t, report.ChangePercent)
+		assert.Equal(t, 223.8, *report.ChangePercent)
+
+		require.Len(t, report.ByCategory, 3)
+		assert.Equal(t, travel.ID, report.ByCategory[0].CategoryID)
+		assert.Equal(t, food.ID, report.ByCategory[1].CategoryID)
+		assert.Equal(t, money.New(600), report.ByCategory[1].Change)
+		require.NotNil(t, report.ByCategory[1].ChangePercent)
+		assert.Equal(t, 20.0, *report.ByCategory[1].ChangePercent)
+
+		require.Len(t, report.NewCategories, 1)
+		assert.Equal(t, travel.ID, report.NewCategories[0].CategoryID)
+		require.Len(t, report.DisappearedCategories, 1)
+		assert.Equal(t, books.ID, report.DisappearedCategories[0].CategoryID)
+
+		require.Len(t, report.ByCard, 1)
+		assert.Equal(t, money.New(9400), report.ByCard[0].Change)
+	})
+
+	t.Run("month vs same month last year", func(t *testing.T) {
+		report := getReport(t, "year=2025&month=5&against=lastYear")
+
+		assert.Equal(t, "2024-05-01", report.Previous.From)
+		assert.Zero(t, report.Previous.TotalAmount)
+		assert.Nil(t, report.ChangePercent)
+		assert.Len(t, report.NewCategories, 2)
+	})
+
+	t.Run("arbitrary ranges", func(t *testing.T) {
+		report := getReport(t, "from=2025-05-15&to=2025-05-31&compareFrom=2025-04-15&compareTo=2025-04-30")
+
+		assert.Equal(t, money.New(10000), report.Current.TotalAmount)
+		assert.Equal(t, money.New(1200), report.Previous.TotalAmount)
+	})
+
+	invalid := []struct {
+		name         string
+		query        string
+		expectedCode string
+	}{
+		{name: "Unknown comparison", query: "year=2025&month=5&against=nextYear", expectedCode: "INVALID_COMPARISON"},
+		{name: "Missing to", query: "from=2025-05-01", expectedCode: "MISSING_DATE_RANGE"},
+		{name: "Invalid month", query: "year=2025&month=13", expectedCode: "INVALID_MONTH"},
+		{name: "Comparison ends before it starts", query: "year=2025&compareFrom=2024-12-31&compareTo=2024-01-01", expectedCode: "INVALID_DATE_RANGE"},
+	}
+
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			w := server.MakeRequest("GET", "/api/reports/compare?"+tt.query, nil)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var response models.ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedCode, response.Error.Code)
+		})
+	}
+}
+
+func TestReportAPI_Forecast(t *testing.T) {
+	server := SetupTestServer(t)
+	defer server.CleanupTestServer()
+
+	card := server.CreateTestCard(t, "テストカード", "#3B82F6")
+	food := server.CreateTestCategory(t, "食費", "#10B981", true)
+	subscriptions := server.CreateTestCategory(t, "サブスク", "#6366F1", false)
+
+	// 1,000 yen a day over the 91 days up to May 15
+	for day := time.Date(2025, time.February, 14, 0, 0, 0, 0, time.UTC); !day.After(time.Date(2025, time.May, 15, 0, 0, 0, 0, time.UTC)); day = day.AddDate(0, 0, 1) {
+		createDatedExpense(t, server, day.Month(), day.Day(), 1000, card.ID, food.ID)
+	}
+	// A subscription charged on the 20th of each of the last three months
+	for _, month := range []time.Month{time.February, time.March, time.April} {
+		expense := createDatedExpense(t, server, month, 20, 1490, card.ID, subscriptions.ID)
+		expense.Description = "動画配信"
+		require.NoError(t, server.Repository.Expense.Update(context.Background(), expense))
+	}
+
+	w := server.MakeRequest("GET", "/api/reports/forecast?date=2025-05-15", nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var report models.ForecastReport
+	decodeData(t, w.Body.Bytes(), &report)
+
+	assert.Equal(t, "2025-05-15", report.AsOf)
+	assert.Equal(t, models.ForecastTotals{
+		From: "2025-05-01", To: "2025-05-31",
+		Actual: money.New(15000), Recurring: money.New(1490), Projected: money.New(16000),
+		Forecast: money.New(32490), Low: money.New(32490), High: money.New(32490),
+	}, report.Month)
+	assert.Equal(t, "2025-12-31", report.Year.To)
+	// May through December
+	assert.Equal(t, money.New(8*1490), report.Year.Recurring)
+
+	require.Len(t, report.ByCategory, 2)
+	assert.Equal(t, food.ID, report.ByCategory[0].CategoryID)
+	assert.Equal(t, money.New(31000), report.ByCategory[0].Month.Forecast)
+	assert.Equal(t, subscriptions.ID, report.ByCategory[1].CategoryID)
+	assert.Equal(t, money.New(1490), report.ByCategory[1].Month.Forecast)
+
+	for _, query := range []string{"date=2025-5-15", "date=1999-01-01"} {
+		w := server.MakeRequest("GET", "/api/reports/forecast?"+query, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var response models.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "INVALID_DATE", response.Error.Code)
+	}
+}
+
+func TestReportAPI_Daily(t *testing.T) {
+	server := SetupTestServer(t)
+	defer server.CleanupTestServer()
+
+	cardA := server.CreateTestCard(t, "カードA", "#3B82F6")
+	cardB := server.CreateTestCard(t, "カードB", "#EF4444")
+	food := server.CreateTestCategory(t, "食費", "#10B981", true)
+	travel := server.CreateTestCategory(t, "旅行", "#F59E0B", false)
+
+	createDatedExpense(t, server, time.May, 3, 1000, cardA.ID, food.ID)
+	createDatedExpense(t, server, time.May, 3, 8000, cardB.ID, travel.ID)
+	createDatedExpense(t, server, time.May, 31, 1500, cardA.ID, food.ID)
+	createDatedExpense(t, server, time.June, 1, 700, cardA.ID, food.ID)
+
+	getReport := func(t *testing.T, query string) models.DailyReport {
+		w := server.MakeRequest("GET", "/api/reports/daily?"+query, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var report models.DailyReport
+		decodeData(t, w.Body.Bytes(), &report)
+		return report
+	}
+
+	t.Run("month", func(t *testing.T) {
+		report := getReport(t, "year=2025&month=5")
+
+		assert.Equal(t, "2025-05-01", report.From)
+		assert.Equal(t, "2025-05-31", report.To)
+		assert.Equal(t, money.New(10500), report.TotalAmount)
+		assert.Equal(t, money.New(9000), report.MaxAmount)
+		require.Len(t, report.Days, 31)
+
+		may3 := report.Days[2]
+		assert.Equal(t, "2025-05-03", may3.Date)
+		assert.Equal(t, 6, may3.Weekday)
+		assert.Equal(t, 2, may3.Count)
+		require.NotNil(t, may3.TopCategory)
+		assert.Equal(t, travel.ID, may3.TopCategory.CategoryID)
+
+		require.Len(t, report.ByWeekday, 7)
+		assert.Equal(t, money.New(10500), report.ByWeekday[time.Saturday].TotalAmount)
+		assert.Len(t, report.ByDayOfMonth, 31)
+	})
+
+	t.Run("card and category filters", func(t *testing.T) {
+		report := getReport(t, "year=2025&month=5&cardId="+cardA.ID.String())
+		assert.Equal(t, money.New(2500), report.TotalAmount)
+
+		report = getReport(t, "year=2025&month=5&categoryId="+travel.ID.String())
+		assert.Equal(t, money.New(8000), report.TotalAmount)
+		assert.Zero(t, report.Days[30].TotalAmount)
+	})
+
+	t.Run("whole year", func(t *testing.T) {
+		report := getReport(t, "year=2025")
+
+		assert.Nil(t, report.Month)
+		assert.Len(t, report.Days, 365)
+		assert.Equal(t, money.New(11200), report.TotalAmount)
+		assert.Equal(t, 12, report.ByDayOfMonth[0].Days)
+		assert.Equal(t, money.New(700), report.ByDayOfMonth[0].TotalAmount)
+		assert.Equal(t, 2, report.ByDayOfMonth[2].Count)
+	})
+
+	invalid := []struct {
+		name         string
+		query        string
+		expectedCode string
+	}{
+		{name: "Invalid year", query: "year=1999", expectedCode: "INVALID_YEAR"},
+		{name: "Invalid month", query: "year=2025&month=0", expectedCode: "INVALID_MONTH"},
+		{name: "Invalid card ID", query: "cardId=invalid", expectedCode: "INVALID_CARD_ID"},
+		{name: "Invalid category ID", query: "categoryId=invalid", expectedCode: "INVALID_CATEGORY_ID"},
+	}
+
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			w := server.MakeRequest("GET", "/api/reports/daily?"+tt.query, nil)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var response models.ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedCode, response.Error.Code)
+		})
+	}
+}
+
+func TestReportAPI_Timeout(t *testing.T) {
+	server := SetupTestServer(t)
+	defer server.CleanupTestServer()
+
+	card := server.CreateTestCard(t, "カードA", "#3B82F6")
+	food := server.CreateTestCategory(t, "食費", "#10B981", true)
+	createDatedExpense(t, server, time.April, 1, 1000, card.ID, food.ID)
+
+	// A timeout no query can meet
+	router := gin.New()
+	router.Use(middleware.ErrorMiddleware())
+	router.Use(middleware.TimeoutMiddleware(time.Nanosecond))
+	reportHandler := handlers.NewReportHandler(server.Repository.Expense, server.Repository.Book)
+	router.GET("/api/reports/range", reportHandler.GetRangeReport)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/reports/range?from=2025-04-01&to=2025-04-30", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusGatewayTimeout, w.Code, w.Body.String())
+	var response models.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "REQUEST_TIMEOUT", response.Error.Code)
+}
@@ -0,0 +1,72 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
+	"kakeibo-tanuki/internal/repositories"
+)
+
+func TestRepositoryErrors(t *testing.T) {
+	server := SetupTestServer(t)
+	defer server.CleanupTestServer()
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := server.Repository.Card.GetByID(context.Background(), uuid.New())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, repositories.ErrNotFound)
+
+		var dbErr *repositories.Error
+		require.True(t, errors.As(err, &dbErr))
+		assert.Equal(t, "Card", dbErr.Model)
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		server.CreateTestCategory(t, "食費", "#10B981", false)
+		err := server.Repository.Category.Create(context.Background(), &models.Category{ID: uuid.New(), Name: "食費", Color: "#10B981"})
+		assert.ErrorIs(t, err, repositories.ErrConflict)
+	})
+
+	t.Run("foreign key", func(t *testing.T) {
+		category := server.CreateTestCategory(t, "交通費", "#3B82F6", false)
+		err := server.Repository.Expense.Create(context.Background(), &models.Expense{
+			ID:         uuid.New(),
+			Amount:     money.FromFloat(1000),
+			Currency:   "JPY",
+			Date:       time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+			CardID:     uuid.New(),
+			CategoryID: category.ID,
+		})
+		assert.ErrorIs(t, err, repositories.ErrForeignKey)
+	})
+
+	t.Run("check constraint", func(t *testing.T) {
+		card := server.CreateTestCard(t, "テストカード", "#3B82F6")
+		category := server.CreateTestCategory(t, "娯楽費", "#8B5CF6", false)
+		err := server.Repository.Expense.Create(context.Background(), &models.Expense{
+			ID:         uuid.New(),
+			Amount:     money.FromFloat(-1),
+			Currency:   "JPY",
+			Date:       time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+			CardID:     card.ID,
+			CategoryID: category.ID,
+		})
+		assert.ErrorIs(t, err, repositories.ErrCheckViolation)
+	})
+
+	t.Run("other errors are left as they are", func(t *testing.T) {
+		err := server.DB.Exec("SELECT * FROM missing_table").Error
+		require.Error(t, err)
+		assert.False(t, errors.Is(err, repositories.ErrNotFound))
+		var dbErr *repositories.Error
+		assert.False(t, errors.As(err, &dbErr))
+	})
+}
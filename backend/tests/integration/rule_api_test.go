@@ -0,0 +1,222 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
+)
+
+func TestRuleAPI(t *testing.T) {
+	server := SetupTestServer(t)
+	defer server.CleanupTestServer()
+
+	card := server.CreateTestCard(t, "テストカード", "#3B82F6")
+	food := server.CreateTestCategory(t, "食費", "#10B981", false)
+	daily := server.CreateTestCategory(t, "日用品", "#F59E0B", true)
+
+	createExpense := func(day int, amount int64, description string) *models.Expense {
+		expense := &models.Expense{
+			ID:          uuid.New(),
+			Amount:      money.New(amount),
+			Currency:    "JPY",
+			Date:        time.Date(2025, time.March, day, 0, 0, 0, 0, time.UTC),
+			Description: description,
+			CardID:      card.ID,
+			CategoryID:  food.ID,
+		}
+		require.NoError(t, server.Repository.Expense.Create(context.Background(), expense))
+		return expense
+	}
+	market := createExpense(10, 3000, "SEIYU 渋谷店")
+	books := createExpense(12, 1000, "本屋")
+
+	createRule := func(t *testing.T, req models.CreateRuleRequest) models.Rule {
+		w := server.MakeRequest("POST", "/api/rules", req)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var rule models.Rule
+		decodeData(t, w.Body.Bytes(), &rule)
+		return rule
+	}
+
+	shared := true
+	supermarket := createRule(t, models.CreateRuleRequest{
+		Name:               "Supermarket",
+		Priority:           1,
+		DescriptionPattern: "^seiyu",
+		CategoryID:         daily.ID.String(),
+		Tags:               []string{"groceries"},
+		IsShared:           &shared,
+	})
+	assert.True(t, supermarket.Enabled)
+
+	run := func(t *testing.T, path string, req models.RuleRunRequest) models.RuleRunResult {
+		w := server.MakeRequest("POST", path, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var result models.RuleRunResult
+		decodeData(t, w.Body.Bytes(), &result)
+		return result
+	}
+	march := models.RuleRunRequest{StartDate: "2025-03-01", EndDate: "2025-03-31"}
+
+	t.Run("dry run changes nothing", func(t *testing.T) {
+		result := run(t, "/api/rules/dry-run", march)
+		assert.True(t, result.DryRun)
+		assert.Equal(t, 2, result.Checked)
+		require.Equal(t, 1, result.Changed)
+
+		change := result.Changes[0]
+		assert.Equal(t, market.ID, change.ExpenseID)
+		assert.Equal(t, []uuid.UUID{supermarket.ID}, change.RuleIDs)
+		assert.Equal(t, food.ID, change.PreviousCategoryID)
+		require.NotNil(t, change.CategoryID)
+		assert.Equal(t, daily.ID, *change.CategoryID)
+		assert.Equal(t, []string{"groceries"}, change.AddedTags)
+
+		expense, err := server.Repository.Expense.GetByID(context.Background(), market.ID)
+		require.NoError(t, err)
+		assert.Equal(t, food.ID, expense.CategoryID)
+		assert.Empty(t, expense.Tags)
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		result := run(t, "/api/rules/apply", march)
+		assert.False(t, result.DryRun)
+		assert.Equal(t, 1, result.Changed)
+
+		expense, err := server.Repository.Expense.GetByID(context.Background(), market.ID)
+		require.NoError(t, err)
+		assert.Equal(t, daily.ID, expense.CategoryID)
+		assert.Equal(t, []string{"groceries"}, expense.Tags)
+		require.NotNil(t, expense.IsShared)
+		assert.True(t, *expense.IsShared)
+
+		// Applying again changes nothing
+		result = run(t, "/api/rules/apply", march)
+		assert.Equal(t, 0, result.Changed)
+		assert.Empty(t, result.Changes)
+	})
+
+	t.Run("expenses marked as shared count in reports", func(t *testing.T) {
+		bookRule := createRule(t, models.CreateRuleRequest{Name: "Books", Priority: 2, DescriptionPattern: "本屋", IsShared: &shared})
+		result := run(t, "/api/rules/apply", models.RuleRunRequest{RuleIDs: []string{bookRule.ID.String()}})
+		require.Equal(t, 1, result.Changed)
+		assert.Equal(t, books.ID, result.Changes[0].ExpenseID)
+		assert.Nil(t, result.Changes[0].CategoryID)
+
+		w := server.MakeRequest("GET", "/api/reports/monthly?year=2025&month=3", nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var report models.MonthlyReport
+		decodeData(t, w.Body.Bytes(), &report)
+
+		assert.Equal(t, money.New(4000), report.SharedExpenses.TotalSharedAmount)
+		require.Len(t, report.SharedExpenses.Categories, 2)
+		for _, category := range report.SharedExpenses.Categories {
+			if category.CategoryID == food.ID {
+				assert.False(t, category.IsShared)
+				assert.Equal(t, money.New(1000), category.SharedAmount)
+			} else {
+				assert.Equal(t, money.New(3000), category.SharedAmount)
+			}
+		}
+	})
+
+	createExpenseRequest := func(description string, isShared *bool) models.CreateExpenseRequest {
+		return models.CreateExpenseRequest{
+			Amount:      money.New(2000),
+			Date:        "2025-03-20",
+			Description: description,
+			CardID:      card.ID.String(),
+			IsShared:    isShared,
+		}
+	}
+
+	t.Run("rules categorize new expenses without a category", func(t *testing.T) {
+		w := server.MakeRequest("POST", "/api/expenses", createExpenseRequest("ｾｲﾕｰ 品川", nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = server.MakeRequest("POST", "/api/expenses", createExpenseRequest("Seiyu 品川", nil))
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var expense models.Expense
+		decodeData(t, w.Body.Bytes(), &expense)
+		assert.Equal(t, daily.ID, expense.CategoryID)
+		assert.Equal(t, []string{"groceries"}, expense.Tags)
+		require.NotNil(t, expense.IsShared)
+		assert.True(t, *expense.IsShared)
+
+		// What the request gives wins over the rules
+		notShared := false
+		w = server.MakeRequest("POST", "/api/expenses", createExpenseRequest("SEIYU 目黒", &notShared))
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		decodeData(t, w.Body.Bytes(), &expense)
+		require.NotNil(t, expense.IsShared)
+		assert.False(t, *expense.IsShared)
+	})
+
+	t.Run("update and delete", func(t *testing.T) {
+		disabled := false
+		w := server.MakeRequest("PUT", "/api/rules/"+supermarket.ID.String(), models.UpdateRuleRequest{
+			Name:       "Supermarket",
+			Enabled:    &disabled,
+			CategoryID: food.ID.String(),
+		})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var updated models.Rule
+		decodeData(t, w.Body.Bytes(), &updated)
+		assert.False(t, updated.Enabled)
+		assert.Empty(t, updated.DescriptionPattern)
+
+		// A disabled rule matches nothing
+		result := run(t, "/api/rules/dry-run", models.RuleRunRequest{RuleIDs: []string{supermarket.ID.String()}})
+		assert.Equal(t, 0, result.Changed)
+
+		w = server.MakeRequest("DELETE", "/api/rules/"+supermarket.ID.String(), nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = server.MakeRequest("GET", "/api/rules", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		var list []models.Rule
+		decodeData(t, w.Body.Bytes(), &list)
+		require.Len(t, list, 1)
+		assert.Equal(t, "Books", list[0].Name)
+	})
+
+	minAmount, maxAmount := money.New(5000), money.New(1000)
+	invalid := []struct {
+		name         string
+		method       string
+		path         string
+		body         interface{}
+		expectedCode string
+		expectedHTTP int
+	}{
+		{name: "No action", method: "POST", path: "/api/rules", body: models.CreateRuleRequest{Name: "Empty", DescriptionPattern: "seiyu"}, expectedCode: "VALIDATION_ERROR", expectedHTTP: http.StatusBadRequest},
+		{name: "Invalid pattern", method: "POST", path: "/api/rules", body: models.CreateRuleRequest{Name: "Broken", DescriptionPattern: "(seiyu", Tags: []string{"x"}}, expectedCode: "INVALID_PATTERN", expectedHTTP: http.StatusBadRequest},
+		{name: "Amount range", method: "POST", path: "/api/rules", body: models.CreateRuleRequest{Name: "Range", MinAmount: &minAmount, MaxAmount: &maxAmount, Tags: []string{"x"}}, expectedCode: "INVALID_AMOUNT_RANGE", expectedHTTP: http.StatusBadRequest},
+		{name: "Invalid card ID", method: "POST", path: "/api/rules", body: models.CreateRuleRequest{Name: "Card", CardID: "invalid", Tags: []string{"x"}}, expectedCode: "INVALID_CARD_ID", expectedHTTP: http.StatusBadRequest},
+		{name: "Unknown category", method: "POST", path: "/api/rules", body: models.CreateRuleRequest{Name: "Category", CategoryID: card.ID.String()}, expectedCode: "CATEGORY_NOT_FOUND", expectedHTTP: http.StatusBadRequest},
+		{name: "Unknown rule", method: "GET", path: "/api/rules/" + card.ID.String(), expectedCode: "RULE_NOT_FOUND", expectedHTTP: http.StatusNotFound},
+		{name: "Unknown rule in run", method: "POST", path: "/api/rules/dry-run", body: models.RuleRunRequest{RuleIDs: []string{card.ID.String()}}, expectedCode: "RULE_NOT_FOUND", expectedHTTP: http.StatusNotFound},
+		{name: "Invalid run date", method: "POST", path: "/api/rules/apply", body: models.RuleRunRequest{StartDate: "2025/03/01"}, expectedCode: "INVALID_DATE", expectedHTTP: http.StatusBadRequest},
+		{name: "Inverted run dates", method: "POST", path: "/api/rules/apply", body: models.RuleRunRequest{StartDate: "2025-03-31", EndDate: "2025-03-01"}, expectedCode: "INVALID_DATE_RANGE", expectedHTTP: http.StatusBadRequest},
+	}
+
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			w := server.MakeRequest(tt.method, tt.path, tt.body)
+			assert.Equal(t, tt.expectedHTTP, w.Code)
+
+			var response models.ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedCode, response.Error.Code)
+		})
+	}
+}
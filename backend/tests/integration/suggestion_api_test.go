@@ -0,0 +1,93 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
+)
+
+func TestSuggestionAPI(t *testing.T) {
+	server := SetupTestServer(t)
+	defer server.CleanupTestServer()
+
+	card := server.CreateTestCard(t, "テストカード", "#3B82F6")
+	food := server.CreateTestCategory(t, "食費", "#10B981", false)
+	transport := server.CreateTestCategory(t, "交通費", "#F59E0B", false)
+	books := server.CreateTestCategory(t, "書籍", "#8B5CF6", false)
+
+	server.CreateTestExpense(t, 480, "セブンイレブン 渋谷店", card.ID, food.ID)
+	server.CreateTestExpense(t, 650, "セブンイレブン 新宿店", card.ID, food.ID)
+	server.CreateTestExpense(t, 3000, "モバイルSuica チャージ", card.ID, transport.ID)
+
+	suggest := func(t *testing.T, req models.SuggestCategoryRequest) []models.CategorySuggestion {
+		w := server.MakeRequest("POST", "/api/expenses/suggest-category", req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var ranked []models.CategorySuggestion
+		decodeData(t, w.Body.Bytes(), &ranked)
+		return ranked
+	}
+
+	t.Run("ranked by past expenses", func(t *testing.T) {
+		ranked := suggest(t, models.SuggestCategoryRequest{Description: "ｾﾌﾞﾝｲﾚﾌﾞﾝ", Amount: money.New(500), CardID: card.ID.String()})
+		require.Len(t, ranked, 2)
+		assert.Equal(t, food.ID, ranked[0].CategoryID)
+		assert.Equal(t, "食費", ranked[0].CategoryName)
+		assert.Equal(t, "#10B981", ranked[0].Color)
+		assert.Greater(t, ranked[0].Confidence, ranked[1].Confidence)
+
+		ranked = suggest(t, models.SuggestCategoryRequest{Description: "Suica", Limit: 1})
+		require.Len(t, ranked, 1)
+		assert.Equal(t, transport.ID, ranked[0].CategoryID)
+	})
+
+	t.Run("updated as expenses change", func(t *testing.T) {
+		w := server.MakeRequest("POST", "/api/expenses", models.CreateExpenseRequest{
+			Amount:      money.New(1800),
+			Date:        "2025-03-01",
+			Description: "紀伊國屋書店",
+			CardID:      card.ID.String(),
+			CategoryID:  books.ID.String(),
+		})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var expense models.Expense
+		decodeData(t, w.Body.Bytes(), &expense)
+
+		ranked := suggest(t, models.SuggestCategoryRequest{Description: "紀伊國屋書店 新宿本店"})
+		require.Len(t, ranked, 3)
+		assert.Equal(t, books.ID, ranked[0].CategoryID)
+
+		w = server.MakeRequest("DELETE", "/api/expenses/"+expense.ID.String(), nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		ranked = suggest(t, models.SuggestCategoryRequest{Description: "紀伊國屋書店 新宿本店"})
+		assert.Len(t, ranked, 2)
+	})
+
+	invalid := []struct {
+		name         string
+		body         models.SuggestCategoryRequest
+		expectedCode string
+	}{
+		{name: "Missing description", body: models.SuggestCategoryRequest{Amount: money.New(500)}, expectedCode: "VALIDATION_ERROR"},
+		{name: "Limit too large", body: models.SuggestCategoryRequest{Description: "セブン", Limit: 11}, expectedCode: "VALIDATION_ERROR"},
+		{name: "Invalid card ID", body: models.SuggestCategoryRequest{Description: "セブン", CardID: "invalid"}, expectedCode: "INVALID_CARD_ID"},
+		{name: "Invalid currency", body: models.SuggestCategoryRequest{Description: "セブン", Amount: money.New(5), Currency: "XXY"}, expectedCode: "INVALID_CURRENCY"},
+	}
+
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			w := server.MakeRequest("POST", "/api/expenses/suggest-category", tt.body)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var response models.ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedCode, response.Error.Code)
+		})
+	}
+}
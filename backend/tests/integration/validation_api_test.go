@@ -0,0 +1,78 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
+)
+
+func TestValidationErrors(t *testing.T) {
+	server := SetupTestServer(t)
+	defer server.CleanupTestServer()
+
+	post := func(t *testing.T, url string, body interface{}, language string) []models.FieldError {
+		jsonBody, err := json.Marshal(body)
+		require.NoError(t, err)
+		req, _ := http.NewRequest("POST", url, bytes.NewReader(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		if language != "" {
+			req.Header.Set("Accept-Language", language)
+		}
+		w := httptest.NewRecorder()
+		server.Router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+		var response struct {
+			Error struct {
+				Code    string              `json:"code"`
+				Details []models.FieldError `json:"details"`
+			} `json:"error"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "VALIDATION_ERROR", response.Error.Code)
+		return response.Error.Details
+	}
+	invalidCard := models.CreateCardRequest{Name: "", Color: "blue"}
+
+	t.Run("english by default", func(t *testing.T) {
+		fields := post(t, "/api/cards", invalidCard, "")
+		assert.Equal(t, []models.FieldError{
+			{Field: "name", Rule: "required", Message: "name is a required field"},
+			{Field: "color", Rule: "hexcolor", Message: "color must be a valid HEX color"},
+		}, fields)
+	})
+
+	t.Run("japanese", func(t *testing.T) {
+		fields := post(t, "/api/cards", invalidCard, "ja-JP,ja;q=0.9,en;q=0.8")
+		require.Len(t, fields, 2)
+		assert.Equal(t, "name", fields[0].Field)
+		assert.Equal(t, "nameは必須フィールドです", fields[0].Message)
+		assert.Equal(t, "colorは正しいHEXカラーコードでなければなりません", fields[1].Message)
+	})
+
+	t.Run("checks outside of the tags", func(t *testing.T) {
+		fields := post(t, "/api/rules", models.CreateRuleRequest{Name: "何もしない"}, "en")
+		require.Len(t, fields, 1)
+		assert.Equal(t, "categoryId", fields[0].Field)
+		assert.Equal(t, "required_without_all", fields[0].Rule)
+		assert.Equal(t, "tags isShared", fields[0].Param)
+
+		fields = post(t, "/api/alerts", models.CreateAlertRequest{
+			Name:     "食費",
+			Period:   "month",
+			Amount:   money.New(10000),
+			Channels: []string{models.ChannelWebhook},
+		}, "ja")
+		require.Len(t, fields, 1)
+		assert.Equal(t, "webhookUrl", fields[0].Field)
+		assert.Equal(t, "webhookUrlは必須フィールドです", fields[0].Message)
+	})
+}
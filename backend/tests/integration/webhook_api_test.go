@@ -0,0 +1,236 @@
+package integration
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
+	"kakeibo-tanuki/internal/webhooks"
+)
+
+// webhookReceiver records the events posted to it and answers with the
+// statuses given, then 200.
+type webhookReceiver struct {
+	mu       sync.Mutex
+	secret   string
+	statuses []int
+	received []models.WebhookPayload
+	invalid  int
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	body, _ := io.ReadAll(req.Body)
+	timestamp, _ := strconv.ParseInt(req.Header.Get(webhooks.HeaderTimestamp), 10, 64)
+	if !webhooks.Verify(r.secret, timestamp, body, req.Header.Get(webhooks.HeaderSignature)) {
+		r.invalid++
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	if status == http.StatusOK {
+		var payload models.WebhookPayload
+		if err := json.Unmarshal(body, &payload); err == nil && payload.Type == req.Header.Get(webhooks.HeaderEvent) {
+			r.received = append(r.received, payload)
+		}
+	}
+	w.WriteHeader(status)
+}
+
+func (r *webhookReceiver) events() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	types := []string{}
+	for _, payload := range r.received {
+		types = append(types, payload.Type)
+	}
+	return types
+}
+
+func TestWebhookAPI(t *testing.T) {
+	server := SetupTestServer(t)
+	defer server.CleanupTestServer()
+
+	receiver := &webhookReceiver{secret: "0123456789abcdef", statuses: []int{http.StatusServiceUnavailable}}
+	endpoint := httptest.NewServer(receiver)
+	defer endpoint.Close()
+
+	w := server.MakeRequest("POST", "/api/webhooks", models.CreateWebhookRequest{
+		URL:    endpoint.URL,
+		Secret: receiver.secret,
+		Events: []string{"expense.*", "card.created"},
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.NotContains(t, w.Body.String(), receiver.secret)
+	var webhook models.Webhook
+	decodeData(t, w.Body.Bytes(), &webhook)
+	assert.True(t, webhook.Enabled)
+
+	deliveries := func(t *testing.T, query string) []models.WebhookDelivery {
+		w := server.MakeRequest("GET", "/api/webhooks/"+webhook.ID.String()+"/deliveries"+query, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var list []models.WebhookDelivery
+		decodeData(t, w.Body.Bytes(), &list)
+		return list
+	}
+	process := func(t *testing.T, now time.Time) int {
+		n, err := server.Webhooks.ProcessDue(now)
+		require.NoError(t, err)
+		return n
+	}
+
+	t.Run("events are queued for subscribed webhooks", func(t *testing.T) {
+		// Created through the API so that the events are published
+		w := server.MakeRequest("POST", "/api/cards", models.CreateCardRequest{Name: "楽天カード", Color: "#BF0000"})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var card models.Card
+		decodeData(t, w.Body.Bytes(), &card)
+		category := server.CreateTestCategory(t, "食費", "#10B981", false)
+
+		w = server.MakeRequest("POST", "/api/expenses", models.CreateExpenseRequest{
+			Amount:      money.New(1200),
+			Date:        "2025-03-01",
+			Description: "スーパー",
+			CardID:      card.ID.String(),
+			CategoryID:  category.ID.String(),
+		})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		w = server.MakeRequest("PUT", "/api/cards/"+card.ID.String(), models.UpdateCardRequest{Name: "楽天カード", Color: "#000000"})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		queued := deliveries(t, "?status=pending")
+		require.Len(t, queued, 2)
+		for _, delivery := range queued {
+			assert.Equal(t, 0, delivery.Attempts)
+		}
+		assert.Empty(t, receiver.events())
+	})
+
+	t.Run("failed deliveries are retried with backoff", func(t *testing.T) {
+		now := time.Now().UTC()
+		assert.Equal(t, 2, process(t, now))
+		assert.Len(t, receiver.events(), 1)
+
+		retried := deliveries(t, "?status=pending")
+		require.Len(t, retried, 1)
+		assert.Equal(t, 1, retried[0].Attempts)
+		assert.Equal(t, http.StatusServiceUnavailable, retried[0].ResponseStatus)
+		assert.Contains(t, retried[0].LastError, "503")
+		require.NotNil(t, retried[0].NextAttemptAt)
+		assert.WithinDuration(t, now.Add(webhooks.BaseDelay), *retried[0].NextAttemptAt, time.Second)
+
+		// Not due yet
+		assert.Equal(t, 0, process(t, now.Add(webhooks.BaseDelay/2)))
+
+		assert.Equal(t, 1, process(t, now.Add(webhooks.BaseDelay)))
+		assert.ElementsMatch(t, []string{"card.created", "expense.created"}, receiver.events())
+		assert.Equal(t, 0, receiver.invalid)
+
+		succeeded := deliveries(t, "?status=succeeded")
+		require.Len(t, succeeded, 2)
+		for _, delivery := range succeeded {
+			assert.Equal(t, http.StatusOK, delivery.ResponseStatus)
+			assert.Nil(t, delivery.NextAttemptAt)
+			assert.Empty(t, delivery.LastError)
+		}
+		assert.Len(t, deliveries(t, "?limit=1"), 1)
+	})
+
+	t.Run("deliveries fail after the last attempt", func(t *testing.T) {
+		failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer failing.Close()
+
+		w := server.MakeRequest("PUT", "/api/webhooks/"+webhook.ID.String(), models.UpdateWebhookRequest{
+			URL:    failing.URL,
+			Events: []string{"category.*"},
+		})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		server.CreateTestCategory(t, "交通費", "#F59E0B", false)
+		w = server.MakeRequest("POST", "/api/categories", models.CreateCategoryRequest{Name: "書籍", Color: "#8B5CF6"})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		at := time.Now().UTC()
+		for attempt := 1; attempt <= webhooks.MaxAttempts; attempt++ {
+			require.Equal(t, 1, process(t, at), "attempt %d", attempt)
+			at = at.Add(webhooks.Backoff(attempt))
+		}
+		assert.Equal(t, 0, process(t, at.Add(webhooks.MaxDelay)))
+
+		failed := deliveries(t, "?status=failed")
+		require.Len(t, failed, 1)
+		assert.Equal(t, "category.created", failed[0].EventType)
+		assert.Equal(t, webhooks.MaxAttempts, failed[0].Attempts)
+		assert.Equal(t, http.StatusInternalServerError, failed[0].ResponseStatus)
+	})
+
+	t.Run("disabled webhooks receive nothing", func(t *testing.T) {
+		disabled := false
+		w := server.MakeRequest("PUT", "/api/webhooks/"+webhook.ID.String(), models.UpdateWebhookRequest{
+			URL:     endpoint.URL,
+			Events:  []string{"*"},
+			Enabled: &disabled,
+		})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		before := len(deliveries(t, ""))
+
+		server.MakeRequest("POST", "/api/categories", models.CreateCategoryRequest{Name: "医療費", Color: "#EF4444"})
+		assert.Len(t, deliveries(t, ""), before)
+	})
+
+	invalid := []struct {
+		name         string
+		body         models.CreateWebhookRequest
+		expectedCode string
+	}{
+		{name: "Invalid URL", body: models.CreateWebhookRequest{URL: "not a url", Secret: receiver.secret, Events: []string{"*"}}, expectedCode: "VALIDATION_ERROR"},
+		{name: "Short secret", body: models.CreateWebhookRequest{URL: endpoint.URL, Secret: "short", Events: []string{"*"}}, expectedCode: "VALIDATION_ERROR"},
+		{name: "No events", body: models.CreateWebhookRequest{URL: endpoint.URL, Secret: receiver.secret}, expectedCode: "VALIDATION_ERROR"},
+		{name: "Unknown event", body: models.CreateWebhookRequest{URL: endpoint.URL, Secret: receiver.secret, Events: []string{"payee.created"}}, expectedCode: "INVALID_EVENT_TYPE"},
+	}
+
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			w := server.MakeRequest("POST", "/api/webhooks", tt.body)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var response models.ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedCode, response.Error.Code)
+		})
+	}
+
+	t.Run("Invalid delivery status", func(t *testing.T) {
+		w := server.MakeRequest("GET", "/api/webhooks/"+webhook.ID.String()+"/deliveries?status=lost", nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("deleted with its deliveries", func(t *testing.T) {
+		w := server.MakeRequest("DELETE", "/api/webhooks/"+webhook.ID.String(), nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		w = server.MakeRequest("GET", "/api/webhooks/"+webhook.ID.String()+"/deliveries", nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		var count int64
+		server.DB.Model(&models.WebhookDelivery{}).Count(&count)
+		assert.Zero(t, count)
+	})
+}
@@ -0,0 +1,179 @@
+package unit
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kakeibo-tanuki/internal/alerts"
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
+	"kakeibo-tanuki/internal/notify"
+)
+
+func TestAlertPeriodBounds(t *testing.T) {
+	// A Wednesday
+	day := time.Date(2025, 5, 14, 15, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		period   string
+		from, to string
+	}{
+		{period: models.AlertPeriodWeek, from: "2025-05-12", to: "2025-05-18"},
+		{period: models.AlertPeriodMonth, from: "2025-05-01", to: "2025-05-31"},
+		{period: models.AlertPeriodQuarter, from: "2025-04-01", to: "2025-06-30"},
+		{period: models.AlertPeriodYear, from: "2025-01-01", to: "2025-12-31"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.period, func(t *testing.T) {
+			from, to := alerts.PeriodBounds(day, tt.period)
+			assert.Equal(t, tt.from, from.Format("2006-01-02"))
+			assert.Equal(t, tt.to, to.Format("2006-01-02"))
+		})
+	}
+}
+
+func TestAlertThreshold(t *testing.T) {
+	assert.Equal(t, money.New(40000), alerts.Threshold(&models.Alert{Amount: money.New(50000), Percent: 80}))
+	assert.Equal(t, money.New(50000), alerts.Threshold(&models.Alert{Amount: money.New(50000), Percent: 100}))
+	assert.Equal(t, money.New(60000), alerts.Threshold(&models.Alert{Amount: money.New(50000), Percent: 120}))
+}
+
+func testAlertEvent() (*models.Alert, *models.AlertEvent) {
+	alert := &models.Alert{ID: uuid.New(), Name: "食費", Email: "tanuki@example.com"}
+	event := &models.AlertEvent{
+		AlertID:   alert.ID,
+		AlertName: alert.Name,
+		From:      "2025-05-01",
+		To:        "2025-05-31",
+		Currency:  "JPY",
+		Spent:     money.New(42000),
+		Amount:    money.New(50000),
+		Threshold: money.New(40000),
+		Percent:   84,
+		Message:   "42000 JPY of 50000 JPY spent from 2025-05-01 to 2025-05-31 (84%).",
+	}
+	return alert, event
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	alert, event := testAlertEvent()
+
+	var received models.AlertEvent
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	notifier := notify.NewWebhookNotifier()
+	alert.WebhookURL = server.URL
+	require.NoError(t, notifier.Notify(alert, event))
+	assert.Equal(t, alert.ID, received.AlertID)
+	assert.Equal(t, money.New(42000), received.Spent)
+	assert.Equal(t, event.Message, received.Message)
+
+	status = http.StatusInternalServerError
+	assert.Error(t, notifier.Notify(alert, event))
+
+	alert.WebhookURL = ""
+	assert.Error(t, notifier.Notify(alert, event))
+}
+
+// fakeSMTPServer accepts one mail on a local port and sends its envelope and
+// data to the returned channel.
+func fakeSMTPServer(t *testing.T) (string, <-chan []string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+		var lines []string
+		reply("220 localhost ESMTP")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+			switch command {
+			case "EHLO", "HELO":
+				reply("250 localhost")
+			case "MAIL", "RCPT":
+				lines = append(lines, line)
+				reply("250 OK")
+			case "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+				for {
+					data, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					data = strings.TrimRight(data, "\r\n")
+					if data == "." {
+						break
+					}
+					lines = append(lines, data)
+				}
+				reply("250 OK")
+			case "QUIT":
+				reply("221 Bye")
+				received <- lines
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+	return listener.Addr().String(), received
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	alert, event := testAlertEvent()
+	addr, received := fakeSMTPServer(t)
+	host, portStr, err := net.SplitHostPort(addr)
+	require.NoError(t, err)
+	port, err := net.LookupPort("tcp", portStr)
+	require.NoError(t, err)
+
+	notifier := notify.NewSMTPNotifier(notify.SMTPConfig{Host: host, Port: port, From: "kakeibo@example.com"})
+	require.NoError(t, notifier.Notify(alert, event))
+
+	var lines []string
+	select {
+	case lines = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no mail received")
+	}
+	assert.Contains(t, lines, "MAIL FROM:<kakeibo@example.com>")
+	assert.Contains(t, lines, "RCPT TO:<tanuki@example.com>")
+	assert.Contains(t, lines, "To: tanuki@example.com")
+	assert.Contains(t, lines, event.Message)
+
+	alert.Email = ""
+	assert.Error(t, notifier.Notify(alert, event))
+}
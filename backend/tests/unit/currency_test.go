@@ -0,0 +1,92 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kakeibo-tanuki/internal/currency"
+)
+
+func TestCurrencyNormalize(t *testing.T) {
+	code, err := currency.Normalize(" usd ")
+	require.NoError(t, err)
+	assert.Equal(t, "USD", code)
+
+	_, err = currency.Normalize("ZZZ")
+	assert.Error(t, err)
+
+	_, err = currency.Normalize("")
+	assert.Error(t, err)
+}
+
+func TestCurrencyMinorUnits(t *testing.T) {
+	tests := []struct {
+		code       string
+		minorUnits int
+	}{
+		{code: "JPY", minorUnits: 0},
+		{code: "USD", minorUnits: 2},
+		{code: "EUR", minorUnits: 2},
+		{code: "KRW", minorUnits: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			assert.Equal(t, tt.minorUnits, currency.MinorUnits(tt.code))
+		})
+	}
+
+	assert.Contains(t, currency.WithMinorUnits(0), "JPY")
+	assert.Contains(t, currency.WithMinorUnits(2), "USD")
+	assert.NotContains(t, currency.WithMinorUnits(0), "USD")
+
+	// Amounts keep two decimal places, so three-decimal currencies are rejected
+	_, err := currency.Normalize("BHD")
+	assert.Error(t, err)
+}
+
+func TestParseRatesCSV(t *testing.T) {
+	input := "\xef\xbb\xbfDate,Rate,Currency,Base\n" +
+		"2025-03-01,149.5,usd,\n" +
+		"2025-03-01, 162.25 ,EUR,JPY\n" +
+		"2025-03-01,1.09,EUR,USD\n"
+
+	rates, err := currency.ParseRatesCSV(strings.NewReader(input), "JPY")
+	require.NoError(t, err)
+	require.Len(t, rates, 3)
+
+	assert.Equal(t, "USD", rates[0].Currency)
+	assert.Equal(t, "JPY", rates[0].BaseCurrency)
+	assert.Equal(t, 149.5, rates[0].Rate)
+	assert.Equal(t, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), rates[0].Date)
+
+	assert.Equal(t, 162.25, rates[1].Rate)
+	assert.Equal(t, "USD", rates[2].BaseCurrency)
+}
+
+func TestParseRatesCSV_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		message string
+	}{
+		{name: "Empty", input: "", message: "empty"},
+		{name: "Missing rate column", input: "date,currency\n2025-03-01,USD\n", message: `"rate"`},
+		{name: "Invalid date", input: "date,currency,rate\n2025-03-01,USD,150\n03/02/2025,USD,150\n", message: "line 3"},
+		{name: "Unknown currency", input: "date,currency,rate\n2025-03-01,ZZZ,150\n", message: "line 2"},
+		{name: "Negative rate", input: "date,currency,rate\n2025-03-01,USD,-1\n", message: "positive"},
+		{name: "Same currency", input: "date,currency,rate\n2025-03-01,JPY,1\n", message: "differ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := currency.ParseRatesCSV(strings.NewReader(tt.input), "JPY")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"kakeibo-tanuki/internal/middleware"
+	"kakeibo-tanuki/internal/openapi"
+	"kakeibo-tanuki/internal/repositories"
+	"kakeibo-tanuki/internal/services"
+)
+
+func TestErrorResponse(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		status    int
+		code      string
+		message   string
+		noDetails bool
+	}{
+		{
+			name:    "missing record",
+			err:     &repositories.Error{Kind: repositories.ErrNotFound, Model: "InstallmentPlan", Err: gorm.ErrRecordNotFound},
+			status:  http.StatusNotFound,
+			code:    "INSTALLMENT_PLAN_NOT_FOUND",
+			message: "Installment plan not found",
+		},
+		{
+			name:    "missing record of raw SQL",
+			err:     &repositories.Error{Kind: repositories.ErrNotFound, Err: gorm.ErrRecordNotFound},
+			status:  http.StatusNotFound,
+			code:    "RECORD_NOT_FOUND",
+			message: "Record not found",
+		},
+		{
+			name:      "duplicate",
+			err:       &repositories.Error{Kind: repositories.ErrConflict, Model: "Payee", Err: gorm.ErrDuplicatedKey},
+			status:    http.StatusConflict,
+			code:      "DUPLICATE_PAYEE",
+			message:   "Payee already exists",
+			noDetails: true,
+		},
+		{
+			name:      "foreign key",
+			err:       &repositories.Error{Kind: repositories.ErrForeignKey, Model: "Expense", Err: gorm.ErrForeignKeyViolated},
+			status:    http.StatusUnprocessableEntity,
+			code:      "REFERENCE_NOT_FOUND",
+			noDetails: true,
+		},
+		{
+			name:      "check constraint",
+			err:       &repositories.Error{Kind: repositories.ErrCheckViolation, Model: "Refund", Err: gorm.ErrCheckConstraintViolated},
+			status:    http.StatusUnprocessableEntity,
+			code:      "CONSTRAINT_VIOLATION",
+			noDetails: true,
+		},
+		{
+			name:    "rejected by a service",
+			err:     &services.Error{Status: http.StatusUnprocessableEntity, Code: "CARD_NOT_FOUND", Message: "Card not found"},
+			status:  http.StatusUnprocessableEntity,
+			code:    "CARD_NOT_FOUND",
+			message: "Card not found",
+		},
+		{
+			name:   "query past the deadline of the request",
+			err:    &repositories.Error{Kind: context.DeadlineExceeded, Model: "Expense", Err: errors.New("interrupted")},
+			status: http.StatusGatewayTimeout,
+			code:   "REQUEST_TIMEOUT",
+		},
+		{
+			name:   "request canceled by the client",
+			err:    context.Canceled,
+			status: http.StatusServiceUnavailable,
+			code:   "REQUEST_CANCELED",
+		},
+		{
+			name:    "other errors",
+			err:     errors.New("connection refused"),
+			status:  http.StatusInternalServerError,
+			code:    "INTERNAL_ERROR",
+			message: "Internal server error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, response := middleware.ErrorResponse(tt.err, "/api/test")
+			assert.Equal(t, tt.status, status)
+			assert.Equal(t, tt.code, response.Error.Code)
+			assert.Contains(t, openapi.ErrorCodes, response.Error.Code)
+			if tt.message != "" {
+				assert.Equal(t, tt.message, response.Error.Message)
+			}
+			if tt.noDetails {
+				assert.Nil(t, response.Error.Details)
+			}
+			assert.Equal(t, "/api/test", response.Path)
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	
 	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
 )
 
 func TestExpenseValidation(t *testing.T) {
@@ -25,7 +26,7 @@ func TestExpenseValidation(t *testing.T) {
 		{
 			name: "Valid expense",
 			expense: models.Expense{
-				Amount:      1000.50,
+				Amount:      money.FromFloat(1000.50),
 				Date:        time.Now(),
 				Description: "Test expense",
 				CardID:      cardID,
@@ -38,7 +39,7 @@ func TestExpenseValidation(t *testing.T) {
 		{
 			name: "Valid expense without description",
 			expense: models.Expense{
-				Amount:     500.0,
+				Amount:     money.New(500),
 				Date:       time.Now(),
 				CardID:     cardID,
 				CategoryID: categoryID,
@@ -62,7 +63,7 @@ func TestExpenseValidation(t *testing.T) {
 		{
 			name: "Negative amount",
 			expense: models.Expense{
-				Amount:      -100.0,
+				Amount:      money.New(-100),
 				Date:        time.Now(),
 				Description: "Test expense",
 				CardID:      cardID,
@@ -74,7 +75,7 @@ func TestExpenseValidation(t *testing.T) {
 		{
 			name: "Missing card ID",
 			expense: models.Expense{
-				Amount:      1000.0,
+				Amount:      money.New(1000),
 				Date:        time.Now(),
 				Description: "Test expense",
 				CategoryID:  categoryID,
@@ -85,7 +86,7 @@ func TestExpenseValidation(t *testing.T) {
 		{
 			name: "Missing category ID",
 			expense: models.Expense{
-				Amount:      1000.0,
+				Amount:      money.New(1000),
 				Date:        time.Now(),
 				Description: "Test expense",
 				CardID:      cardID,
@@ -96,7 +97,7 @@ func TestExpenseValidation(t *testing.T) {
 		{
 			name: "Missing date",
 			expense: models.Expense{
-				Amount:      1000.0,
+				Amount:      money.New(1000),
 				Description: "Test expense",
 				CardID:      cardID,
 				CategoryID:  categoryID,
@@ -145,7 +146,7 @@ func TestCreateExpenseRequestValidation(t *testing.T) {
 		{
 			name: "Valid create request",
 			request: models.CreateExpenseRequest{
-				Amount:      1500.75,
+				Amount:      money.FromFloat(1500.75),
 				Date:        "2025-01-15T10:30:00Z",
 				Description: "新しい支出",
 				CardID:      cardIDStr,
@@ -156,7 +157,7 @@ func TestCreateExpenseRequestValidation(t *testing.T) {
 		{
 			name: "Valid request without description",
 			request: models.CreateExpenseRequest{
-				Amount:     750.0,
+				Amount:     money.New(750),
 				Date:       "2025-01-15T10:30:00Z",
 				CardID:     cardIDStr,
 				CategoryID: categoryIDStr,
@@ -178,7 +179,7 @@ func TestCreateExpenseRequestValidation(t *testing.T) {
 		{
 			name: "Negative amount",
 			request: models.CreateExpenseRequest{
-				Amount:      -500.0,
+				Amount:      money.New(-500),
 				Date:        "2025-01-15T10:30:00Z",
 				Description: "Invalid expense",
 				CardID:      cardIDStr,
@@ -190,7 +191,7 @@ func TestCreateExpenseRequestValidation(t *testing.T) {
 		{
 			name: "Empty date",
 			request: models.CreateExpenseRequest{
-				Amount:      1000.0,
+				Amount:      money.New(1000),
 				Date:        "",
 				Description: "Invalid expense",
 				CardID:      cardIDStr,
@@ -202,7 +203,7 @@ func TestCreateExpenseRequestValidation(t *testing.T) {
 		{
 			name: "Empty card ID",
 			request: models.CreateExpenseRequest{
-				Amount:      1000.0,
+				Amount:      money.New(1000),
 				Date:        "2025-01-15T10:30:00Z",
 				Description: "Invalid expense",
 				CardID:      "",
@@ -212,16 +213,45 @@ func TestCreateExpenseRequestValidation(t *testing.T) {
 			wantErrors: []string{"CardID"},
 		},
 		{
+			// Rules choose the category when none is given
 			name: "Empty category ID",
 			request: models.CreateExpenseRequest{
-				Amount:      1000.0,
+				Amount:      money.New(1000),
 				Date:        "2025-01-15T10:30:00Z",
-				Description: "Invalid expense",
+				Description: "Categorized by rules",
 				CardID:      cardIDStr,
 				CategoryID:  "",
 			},
+			wantValid: true,
+		},
+		{
+			name: "Split expense without category ID",
+			request: models.CreateExpenseRequest{
+				Amount:      money.New(3000),
+				Date:        "2025-01-15T10:30:00Z",
+				Description: "スーパー",
+				CardID:      cardIDStr,
+				Items: []models.ExpenseItemRequest{
+					{Amount: money.New(2000), CategoryID: categoryIDStr},
+					{Amount: money.New(1000), CategoryID: uuid.New().String(), Note: "日用品"},
+				},
+			},
+			wantValid: true,
+		},
+		{
+			name: "Split expense with invalid item",
+			request: models.CreateExpenseRequest{
+				Amount:      money.New(3000),
+				Date:        "2025-01-15T10:30:00Z",
+				Description: "スーパー",
+				CardID:      cardIDStr,
+				Items: []models.ExpenseItemRequest{
+					{Amount: 0, CategoryID: categoryIDStr},
+					{Amount: money.New(3000), CategoryID: ""},
+				},
+			},
 			wantValid:  false,
-			wantErrors: []string{"CategoryID"},
+			wantErrors: []string{"Amount", "CategoryID"},
 		},
 	}
 
@@ -264,7 +294,7 @@ func TestUpdateExpenseRequestValidation(t *testing.T) {
 		{
 			name: "Valid update request",
 			request: models.UpdateExpenseRequest{
-				Amount:      2000.25,
+				Amount:      money.FromFloat(2000.25),
 				Date:        "2025-01-16T15:45:00Z",
 				Description: "更新された支出",
 				CardID:      cardIDStr,
@@ -275,7 +305,7 @@ func TestUpdateExpenseRequestValidation(t *testing.T) {
 		{
 			name: "Valid update without description",
 			request: models.UpdateExpenseRequest{
-				Amount:     1250.0,
+				Amount:     money.New(1250),
 				Date:       "2025-01-16T15:45:00Z",
 				CardID:     cardIDStr,
 				CategoryID: categoryIDStr,
@@ -297,7 +327,7 @@ func TestUpdateExpenseRequestValidation(t *testing.T) {
 		{
 			name: "Empty date",
 			request: models.UpdateExpenseRequest{
-				Amount:      1500.0,
+				Amount:      money.New(1500),
 				Date:        "",
 				Description: "Invalid update",
 				CardID:      cardIDStr,
@@ -0,0 +1,146 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kakeibo-tanuki/internal/insights"
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
+)
+
+func anomaliesOfType(anomalies []models.Anomaly, anomalyType string) []models.Anomaly {
+	var found []models.Anomaly
+	for _, anomaly := range anomalies {
+		if anomaly.Type == anomalyType {
+			found = append(found, anomaly)
+		}
+	}
+	return found
+}
+
+func TestDetectOutliers(t *testing.T) {
+	cardA, cardB := uuid.New(), uuid.New()
+	food, hobby := uuid.New(), uuid.New()
+
+	charge := func(day int, cardID, categoryID uuid.UUID, amount int64) insights.Charge {
+		return insights.Charge{
+			ExpenseID:    uuid.New(),
+			Date:         date(2025, 1, 1).AddDate(0, 0, day),
+			CardID:       cardID,
+			CategoryID:   categoryID,
+			CategoryName: "食費",
+			Amount:       money.New(amount),
+		}
+	}
+
+	var charges []insights.Charge
+	for i, amount := range []int64{1000, 1200, 1100, 1100, 1050, 1150} {
+		charges = append(charges, charge(i, cardA, food, amount))
+	}
+	// Hobby spending varies a lot, so three times the median is not unusual
+	for i, amount := range []int64{1000, 5000, 2000, 8000, 3000} {
+		charges = append(charges, charge(i, cardA, hobby, amount))
+	}
+
+	typo := charge(40, cardA, food, 11000)
+	regular := charge(41, cardA, food, 1500)
+	otherCard := charge(42, cardB, food, 10000)
+	expensiveHobby := charge(43, cardA, hobby, 9000)
+	charges = append(charges, typo, regular, otherCard, expensiveHobby)
+
+	anomalies := insights.Detect(charges, nil, date(2025, 2, 1), date(2025, 2, 28), "JPY")
+	outliers := anomaliesOfType(anomalies, models.AnomalyTypeOutlier)
+	require.Len(t, outliers, 2)
+
+	// Most recent first; the other card has no history of its own, so the
+	// category on all cards is used
+	assert.Equal(t, otherCard.ExpenseID, *outliers[0].ExpenseID)
+	assert.Equal(t, typo.ExpenseID, *outliers[1].ExpenseID)
+	assert.Equal(t, "2025-02-10", outliers[1].Date)
+	assert.Equal(t, money.New(1100), outliers[1].Expected)
+	assert.Equal(t, 10.0, outliers[1].Ratio)
+	assert.Equal(t, "11000 JPY is 10.0 times the usual 1100 JPY for 食費", outliers[1].Message)
+
+	// Charges before the range are only history
+	anomalies = insights.Detect(charges, nil, date(2025, 2, 11), date(2025, 2, 28), "JPY")
+	assert.Len(t, anomaliesOfType(anomalies, models.AnomalyTypeOutlier), 1)
+}
+
+func TestDetectOutliersNeedHistory(t *testing.T) {
+	card, category := uuid.New(), uuid.New()
+
+	var charges []insights.Charge
+	for i, amount := range []int64{1000, 1000, 1000, 50000} {
+		charges = append(charges, insights.Charge{
+			ExpenseID:  uuid.New(),
+			Date:       date(2025, 3, 1+i),
+			CardID:     card,
+			CategoryID: category,
+			Amount:     money.New(amount),
+		})
+	}
+
+	assert.Empty(t, insights.Detect(charges, nil, date(2025, 3, 1), date(2025, 3, 31), "JPY"))
+}
+
+func TestDetectNewMerchants(t *testing.T) {
+	card, category := uuid.New(), uuid.New()
+
+	var charges []insights.Charge
+	for i, description := range []string{"Super Mart", "ドラッグストア", "Super Mart", "書店", "カフェ", "Super Mart"} {
+		charges = append(charges, insights.Charge{
+			ExpenseID:   uuid.New(),
+			Date:        date(2025, 4, 1+i),
+			Description: description,
+			CardID:      card,
+			CategoryID:  uuid.New(),
+			Amount:      money.New(2000),
+		})
+	}
+
+	newShop := insights.Charge{ExpenseID: uuid.New(), Date: date(2025, 4, 20), Description: "家電量販店", CardID: card, CategoryID: category, Amount: money.New(60000)}
+	// The same merchant in full-width letters and other case
+	knownShop := insights.Charge{ExpenseID: uuid.New(), Date: date(2025, 4, 21), Description: "ＳＵＰＥＲ  ＭＡＲＴ", CardID: card, CategoryID: category, Amount: money.New(60000)}
+	smallNewShop := insights.Charge{ExpenseID: uuid.New(), Date: date(2025, 4, 22), Description: "パン屋", CardID: card, CategoryID: category, Amount: money.New(3000)}
+	charges = append(charges, newShop, knownShop, smallNewShop)
+
+	anomalies := insights.Detect(charges, nil, date(2025, 4, 20), date(2025, 4, 30), "JPY")
+	merchants := anomaliesOfType(anomalies, models.AnomalyTypeNewMerchant)
+	require.Len(t, merchants, 1)
+	assert.Equal(t, newShop.ExpenseID, *merchants[0].ExpenseID)
+	assert.Equal(t, money.New(2000), merchants[0].Expected)
+	assert.Equal(t, 30.0, merchants[0].Ratio)
+}
+
+func TestDetectCategorySpikes(t *testing.T) {
+	travel, food := uuid.New(), uuid.New()
+
+	spend := func(month, day int, categoryID uuid.UUID, amount int64) insights.Spending {
+		return insights.Spending{Date: date(2025, 1, 1).AddDate(0, month-1, day-1), CategoryID: categoryID, Amount: money.New(amount)}
+	}
+
+	spending := []insights.Spending{
+		spend(1, 10, travel, 10000), spend(2, 10, travel, 12000), spend(3, 10, travel, 8000),
+		spend(4, 5, travel, 15000), spend(4, 20, travel, 10000),
+		// A refund brings food back to normal
+		spend(1, 10, food, 30000), spend(2, 10, food, 30000), spend(3, 10, food, 30000),
+		spend(4, 5, food, 70000), spend(4, 8, food, -40000),
+	}
+
+	anomalies := insights.Detect(nil, spending, date(2025, 4, 1), date(2025, 4, 30), "JPY")
+	require.Len(t, anomalies, 1)
+	assert.Equal(t, models.AnomalyTypeCategorySpike, anomalies[0].Type)
+	assert.Equal(t, travel, anomalies[0].CategoryID)
+	assert.Equal(t, "2025-04-01", anomalies[0].Date)
+	assert.Equal(t, money.New(25000), anomalies[0].Amount)
+	assert.Equal(t, money.New(10000), anomalies[0].Expected)
+	assert.Equal(t, 2.5, anomalies[0].Ratio)
+	assert.Nil(t, anomalies[0].ExpenseID)
+
+	// Up to April 10 the month is not a spike yet
+	assert.Empty(t, insights.Detect(nil, spending, date(2025, 4, 1), date(2025, 4, 10), "JPY"))
+}
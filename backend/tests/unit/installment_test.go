@@ -0,0 +1,195 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kakeibo-tanuki/internal/installments"
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
+)
+
+func sumPayments(plan *models.InstallmentPlan) (principal, interest, amount money.Amount) {
+	for _, payment := range plan.Payments {
+		principal += payment.Principal
+		interest += payment.Interest
+		amount += payment.Amount
+	}
+	return principal, interest, amount
+}
+
+func TestBuildPlan_InterestFreeInstallments(t *testing.T) {
+	purchaseDate := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	req := &models.InstallmentPlanRequest{
+		PaymentType:      models.PaymentTypeInstallment,
+		NumberOfPayments: 3,
+	}
+
+	plan, err := installments.BuildPlan(uuid.New(), money.New(100000), "JPY", purchaseDate, req, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, plan.Payments, 3)
+
+	// The odd yen goes into the first payment
+	assert.Equal(t, money.New(33334), plan.Payments[0].Amount)
+	assert.Equal(t, money.New(33333), plan.Payments[1].Amount)
+	assert.Equal(t, money.New(33333), plan.Payments[2].Amount)
+
+	// Due dates are clamped to the end of shorter months
+	assert.Equal(t, time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC), plan.Payments[0].DueDate)
+	assert.Equal(t, time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC), plan.Payments[1].DueDate)
+	assert.Equal(t, time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC), plan.Payments[2].DueDate)
+
+	principal, interest, amount := sumPayments(plan)
+	assert.Equal(t, money.New(100000), principal)
+	assert.Zero(t, interest)
+	assert.Equal(t, money.New(100000), amount)
+	assert.Zero(t, plan.TotalInterest)
+
+	for i, payment := range plan.Payments {
+		assert.Equal(t, i+1, payment.Sequence)
+		assert.Equal(t, plan.ID, payment.PlanID)
+		assert.Equal(t, plan.ExpenseID, payment.ExpenseID)
+	}
+}
+
+func TestBuildPlan_InstallmentsWithInterest(t *testing.T) {
+	purchaseDate := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	req := &models.InstallmentPlanRequest{
+		PaymentType:        models.PaymentTypeInstallment,
+		NumberOfPayments:   12,
+		AnnualInterestRate: 15,
+	}
+
+	plan, err := installments.BuildPlan(uuid.New(), money.New(120000), "JPY", purchaseDate, req, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, plan.Payments, 12)
+
+	principal, interest, amount := sumPayments(plan)
+	assert.Equal(t, money.New(120000), principal)
+	assert.Equal(t, plan.TotalInterest, interest)
+	assert.Equal(t, principal+interest, amount)
+	assert.Positive(t, int64(interest))
+
+	// Equal total payments except for the rounding absorbed by the last one
+	for _, payment := range plan.Payments[:11] {
+		assert.Equal(t, plan.Payments[0].Amount, payment.Amount)
+	}
+	assert.InDelta(t, plan.Payments[0].Amount.Float64(), plan.Payments[11].Amount.Float64(), 12)
+
+	// Interest is charged on a shrinking balance
+	assert.Greater(t, plan.Payments[0].Interest, plan.Payments[11].Interest)
+}
+
+func TestBuildPlan_Revolving(t *testing.T) {
+	purchaseDate := time.Date(2025, 5, 20, 0, 0, 0, 0, time.UTC)
+	firstPayment := time.Date(2025, 6, 27, 0, 0, 0, 0, time.UTC)
+	req := &models.InstallmentPlanRequest{
+		PaymentType:        models.PaymentTypeRevolving,
+		MonthlyPayment:     money.New(10000),
+		AnnualInterestRate: 12,
+	}
+
+	plan, err := installments.BuildPlan(uuid.New(), money.New(25000), "JPY", purchaseDate, req, firstPayment)
+	require.NoError(t, err)
+	require.Len(t, plan.Payments, 3)
+	assert.Equal(t, 3, plan.NumberOfPayments)
+
+	assert.Equal(t, money.New(10000), plan.Payments[0].Principal)
+	assert.Equal(t, money.New(250), plan.Payments[0].Interest)
+	assert.Equal(t, money.New(10000), plan.Payments[1].Principal)
+	assert.Equal(t, money.New(150), plan.Payments[1].Interest)
+	assert.Equal(t, money.New(5000), plan.Payments[2].Principal)
+	assert.Equal(t, money.New(50), plan.Payments[2].Interest)
+	assert.Equal(t, money.New(450), plan.TotalInterest)
+
+	assert.Equal(t, firstPayment, plan.Payments[0].DueDate)
+	assert.Equal(t, time.Date(2025, 8, 27, 0, 0, 0, 0, time.UTC), plan.Payments[2].DueDate)
+}
+
+func TestBuildPlan_Errors(t *testing.T) {
+	purchaseDate := time.Date(2025, 5, 20, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		req          models.InstallmentPlanRequest
+		firstPayment time.Time
+	}{
+		{
+			name: "Single installment",
+			req:  models.InstallmentPlanRequest{PaymentType: models.PaymentTypeInstallment, NumberOfPayments: 1},
+		},
+		{
+			name: "Revolving without monthly payment",
+			req:  models.InstallmentPlanRequest{PaymentType: models.PaymentTypeRevolving},
+		},
+		{
+			name: "Revolving payment too small",
+			req:  models.InstallmentPlanRequest{PaymentType: models.PaymentTypeRevolving, MonthlyPayment: money.New(10)},
+		},
+		{
+			name:         "First payment before purchase",
+			req:          models.InstallmentPlanRequest{PaymentType: models.PaymentTypeInstallment, NumberOfPayments: 3},
+			firstPayment: time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := installments.BuildPlan(uuid.New(), money.New(50000), "JPY", purchaseDate, &tt.req, tt.firstPayment)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestInstallmentPlanRequestValidation(t *testing.T) {
+	validate := validator.New()
+
+	tests := []struct {
+		name      string
+		request   models.InstallmentPlanRequest
+		wantValid bool
+	}{
+		{
+			name:      "Valid installment",
+			request:   models.InstallmentPlanRequest{PaymentType: "installment", NumberOfPayments: 6},
+			wantValid: true,
+		},
+		{
+			name:      "Valid revolving",
+			request:   models.InstallmentPlanRequest{PaymentType: "revolving", MonthlyPayment: money.New(5000), AnnualInterestRate: 15},
+			wantValid: true,
+		},
+		{
+			name:    "Unknown payment type",
+			request: models.InstallmentPlanRequest{PaymentType: "bonus", NumberOfPayments: 2},
+		},
+		{
+			name:    "Installment without number of payments",
+			request: models.InstallmentPlanRequest{PaymentType: "installment"},
+		},
+		{
+			name:    "Revolving without monthly payment",
+			request: models.InstallmentPlanRequest{PaymentType: "revolving"},
+		},
+		{
+			name:    "Interest rate too high",
+			request: models.InstallmentPlanRequest{PaymentType: "installment", NumberOfPayments: 3, AnnualInterestRate: 29.2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validate.Struct(tt.request)
+			if tt.wantValid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
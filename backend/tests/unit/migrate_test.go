@@ -0,0 +1,82 @@
+package unit
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kakeibo-tanuki/internal/migrate"
+	"kakeibo-tanuki/migrations"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	t.Run("embedded migrations", func(t *testing.T) {
+		fsys, err := migrations.For("postgres")
+		require.NoError(t, err)
+		postgres, err := migrate.Load(fsys)
+		require.NoError(t, err)
+		require.NotEmpty(t, postgres)
+		for i, migration := range postgres {
+			assert.Equal(t, i+1, migration.Version, "versions follow each other")
+			assert.NotEmpty(t, migration.Up)
+			assert.NotEmpty(t, migration.Down)
+			assert.Len(t, migration.Checksum, 64)
+		}
+
+		// SQLite starts from a later version, then has every version
+		// PostgreSQL has
+		fsys, err = migrations.For("sqlite")
+		require.NoError(t, err)
+		sqlite, err := migrate.Load(fsys)
+		require.NoError(t, err)
+		require.NotEmpty(t, sqlite)
+		offset := len(postgres) - len(sqlite)
+		require.GreaterOrEqual(t, offset, 0)
+		for i, migration := range sqlite {
+			assert.Equal(t, postgres[offset+i].Version, migration.Version, "versions follow each other")
+			assert.NotEmpty(t, migration.Up)
+			assert.NotEmpty(t, migration.Down)
+		}
+
+		_, err = migrations.For("mysql")
+		assert.Error(t, err)
+	})
+
+	t.Run("ordered by version", func(t *testing.T) {
+		loaded, err := migrate.Load(fstest.MapFS{
+			"010_b.up.sql":   {Data: []byte("SELECT 10;")},
+			"010_b.down.sql": {Data: []byte("SELECT 10;")},
+			"002_a.up.sql":   {Data: []byte("SELECT 2;")},
+			"002_a.down.sql": {Data: []byte("SELECT 2;")},
+		})
+		require.NoError(t, err)
+		require.Len(t, loaded, 2)
+		assert.Equal(t, 2, loaded[0].Version)
+		assert.Equal(t, "a", loaded[0].Name)
+		assert.Equal(t, 10, loaded[1].Version)
+	})
+
+	invalid := map[string]fstest.MapFS{
+		"missing down migration": {
+			"001_a.up.sql": {Data: []byte("SELECT 1;")},
+		},
+		"missing up migration": {
+			"001_a.down.sql": {Data: []byte("SELECT 1;")},
+		},
+		"unversioned file": {
+			"seed.sql": {Data: []byte("SELECT 1;")},
+		},
+		"names differ": {
+			"001_a.up.sql":   {Data: []byte("SELECT 1;")},
+			"001_b.down.sql": {Data: []byte("SELECT 1;")},
+		},
+	}
+	for name, fsys := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := migrate.Load(fsys)
+			assert.Error(t, err)
+		})
+	}
+}
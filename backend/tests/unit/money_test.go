@@ -0,0 +1,190 @@
+package unit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kakeibo-tanuki/internal/money"
+)
+
+func TestMoneyParse(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected money.Amount
+	}{
+		{input: "1500", expected: money.New(1500)},
+		{input: "0.1", expected: 10},
+		{input: "12.345", expected: 1235},
+		{input: "-12.345", expected: -1235},
+		{input: "12.344", expected: 1234},
+		{input: "1e3", expected: money.New(1000)},
+		{input: " 7.5 ", expected: 750},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			amount, err := money.Parse(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, amount)
+		})
+	}
+
+	_, err := money.Parse("abc")
+	assert.Error(t, err)
+}
+
+func TestMoneySumIsExact(t *testing.T) {
+	var total money.Amount
+	for i := 0; i < 10; i++ {
+		total += money.FromFloat(0.1)
+	}
+	assert.Equal(t, money.New(1), total)
+	assert.Equal(t, "1", total.String())
+}
+
+func TestMoneyJSON(t *testing.T) {
+	var payload struct {
+		Amount money.Amount `json:"amount"`
+	}
+
+	require.NoError(t, json.Unmarshal([]byte(`{"amount": 1234.5}`), &payload))
+	assert.Equal(t, money.Amount(123450), payload.Amount)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"amount": "99.99"}`), &payload))
+	assert.Equal(t, money.Amount(9999), payload.Amount)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"amount": "ten"}`), &payload))
+
+	// Clients still receive plain JSON numbers
+	for amount, expected := range map[money.Amount]string{
+		money.New(1500):       `{"amount":1500}`,
+		money.FromFloat(12.5): `{"amount":12.5}`,
+		-5:                    `{"amount":-0.05}`,
+	} {
+		payload.Amount = amount
+		data, err := json.Marshal(payload)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(data))
+	}
+}
+
+func TestMoneyScan(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      interface{}
+		expected money.Amount
+	}{
+		{name: "nil", src: nil, expected: 0},
+		{name: "integer", src: int64(1500), expected: money.New(1500)},
+		{name: "real", src: 0.3, expected: 30},
+		{name: "decimal string", src: "1234.56", expected: 123456},
+		{name: "decimal bytes", src: []byte("1234.50"), expected: 123450},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var amount money.Amount
+			require.NoError(t, amount.Scan(tt.src))
+			assert.Equal(t, tt.expected, amount)
+		})
+	}
+
+	var amount money.Amount
+	assert.Error(t, amount.Scan(true))
+
+	value, err := money.FromFloat(12.5).Value()
+	require.NoError(t, err)
+	assert.Equal(t, "12.5", value)
+}
+
+func TestMoneyRound(t *testing.T) {
+	tests := []struct {
+		code     string
+		amount   money.Amount
+		expected money.Amount
+	}{
+		{code: "JPY", amount: money.FromFloat(1500.5), expected: money.New(1501)},
+		{code: "JPY", amount: money.FromFloat(1500.4), expected: money.New(1500)},
+		{code: "JPY", amount: money.FromFloat(-1500.5), expected: money.New(-1501)},
+		{code: "USD", amount: money.FromFloat(12.35), expected: money.FromFloat(12.35)},
+		{code: "KRW", amount: money.FromFloat(10000.7), expected: money.New(10001)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code+" "+tt.amount.String(), func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.amount.Round(tt.code))
+		})
+	}
+}
+
+func TestMoneySplit(t *testing.T) {
+	tests := []struct {
+		name        string
+		amount      money.Amount
+		code        string
+		n           int
+		remainderTo int
+		expected    []money.Amount
+	}{
+		{
+			name:     "Even split",
+			amount:   money.New(1000),
+			code:     "JPY",
+			n:        2,
+			expected: []money.Amount{money.New(500), money.New(500)},
+		},
+		{
+			name:     "Odd yen to the first member",
+			amount:   money.New(1001),
+			code:     "JPY",
+			n:        2,
+			expected: []money.Amount{money.New(501), money.New(500)},
+		},
+		{
+			name:        "Odd yen to the second member",
+			amount:      money.New(1001),
+			code:        "JPY",
+			n:           2,
+			remainderTo: 1,
+			expected:    []money.Amount{money.New(500), money.New(501)},
+		},
+		{
+			name:     "Remainder in cents",
+			amount:   money.New(10),
+			code:     "USD",
+			n:        3,
+			expected: []money.Amount{334, 333, 333},
+		},
+		{
+			name:     "Fractional yen stay with the remainder",
+			amount:   money.FromFloat(1001.5),
+			code:     "JPY",
+			n:        2,
+			expected: []money.Amount{money.FromFloat(501.5), money.New(500)},
+		},
+		{
+			name:        "Out of range member falls back to the first",
+			amount:      money.New(5),
+			code:        "JPY",
+			n:           2,
+			remainderTo: 3,
+			expected:    []money.Amount{money.New(3), money.New(2)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shares := tt.amount.Split(tt.n, tt.code, tt.remainderTo)
+			assert.Equal(t, tt.expected, shares)
+
+			var total money.Amount
+			for _, share := range shares {
+				total += share
+			}
+			assert.Equal(t, tt.amount, total)
+		})
+	}
+}
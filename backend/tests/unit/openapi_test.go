@@ -0,0 +1,216 @@
+package unit
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kakeibo-tanuki/internal/openapi"
+)
+
+// mainRoutes returns the routes registered in cmd/api/main.go as "METHOD
+// path", following the prefixes of router groups.
+func mainRoutes(t *testing.T) []string {
+	file, err := parser.ParseFile(token.NewFileSet(), "../../cmd/api/main.go", nil, 0)
+	require.NoError(t, err)
+
+	methods := map[string]bool{"GET": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true}
+	prefixes := map[string]string{}
+	var routes []string
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.AssignStmt:
+			if len(node.Lhs) != 1 || len(node.Rhs) != 1 {
+				return true
+			}
+			group, prefix, ok := routerCall(node.Rhs[0])
+			if ok && group.Sel.Name == "Group" {
+				name := node.Lhs[0].(*ast.Ident).Name
+				prefixes[name] = prefixes[group.X.(*ast.Ident).Name] + prefix
+			}
+		case *ast.CallExpr:
+			call, path, ok := routerCall(node)
+			if ok && methods[call.Sel.Name] {
+				routes = append(routes, call.Sel.Name+" "+prefixes[call.X.(*ast.Ident).Name]+path)
+			}
+		}
+		return true
+	})
+	return routes
+}
+
+// routerCall matches a call such as api.GET("/path", ...) and returns the
+// method called and the path.
+func routerCall(expr ast.Expr) (*ast.SelectorExpr, string, bool) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) == 0 {
+		return nil, "", false
+	}
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return nil, "", false
+	}
+	if _, ok := selector.X.(*ast.Ident); !ok {
+		return nil, "", false
+	}
+	literal, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || literal.Kind != token.STRING {
+		return nil, "", false
+	}
+	path, err := strconv.Unquote(literal.Value)
+	if err != nil {
+		return nil, "", false
+	}
+	return selector, path, true
+}
+
+func TestOpenAPIDocumentsEveryRoute(t *testing.T) {
+	routes := mainRoutes(t)
+	require.NotEmpty(t, routes)
+	assert.Contains(t, routes, "GET /api/cards/:id")
+	assert.Contains(t, routes, "GET /api/expenses/:id/attachments/:attachmentId/thumbnail")
+	assert.Contains(t, routes, "GET /health")
+
+	doc := openapi.Build()
+	registered := map[string]bool{}
+	for _, route := range routes {
+		registered[route] = true
+
+		method, path, _ := strings.Cut(route, " ")
+		item, ok := doc.Paths[openapi.Path(path)]
+		if assert.True(t, ok, "%s is not documented in internal/openapi", route) {
+			assert.NotNil(t, item[strings.ToLower(method)], "%s is not documented in internal/openapi", route)
+		}
+	}
+
+	// Endpoints of removed routes are not left behind
+	ids := map[string]bool{}
+	for _, endpoint := range openapi.Endpoints {
+		assert.True(t, registered[endpoint.String()], "%s is documented but not registered", endpoint)
+		assert.False(t, ids[endpoint.ID], "operation ID %s is not unique", endpoint.ID)
+		ids[endpoint.ID] = true
+	}
+}
+
+func TestOpenAPIErrorCodes(t *testing.T) {
+	for _, endpoint := range openapi.Endpoints {
+		for status, codes := range endpoint.Errors {
+			assert.GreaterOrEqual(t, status, http.StatusBadRequest, endpoint.String())
+			for _, code := range codes {
+				assert.Contains(t, openapi.ErrorCodes, code, "%s responds with an undocumented code", endpoint)
+			}
+		}
+	}
+
+	// Every code the handlers respond with is in the catalog
+	files, err := filepath.Glob("../../internal/handlers/*.go")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	for _, name := range files {
+		file, err := parser.ParseFile(token.NewFileSet(), name, nil, 0)
+		require.NoError(t, err)
+		ast.Inspect(file, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				return true
+			}
+			selector, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || selector.Sel.Name != "NewErrorResponse" {
+				return true
+			}
+			if literal, ok := call.Args[0].(*ast.BasicLit); ok {
+				code, _ := strconv.Unquote(literal.Value)
+				assert.Contains(t, openapi.ErrorCodes, code, "%s responds with a code missing from openapi.ErrorCodes", filepath.Base(name))
+			}
+			return true
+		})
+	}
+
+	doc := openapi.Build()
+	code := doc.Components.Schemas["ErrorResponse"].Properties["error"].Properties["code"]
+	assert.Len(t, code.Enum, len(openapi.ErrorCodes))
+}
+
+func TestOpenAPISchemas(t *testing.T) {
+	doc := openapi.Build()
+	schemas := doc.Components.Schemas
+	assert.Equal(t, openapi.Version, doc.OpenAPI)
+	for _, name := range []string{"SuccessResponse", "PaginatedResponse", "ErrorResponse", "Pagination"} {
+		assert.Contains(t, schemas, name)
+	}
+
+	t.Run("validation rules", func(t *testing.T) {
+		card := schemas["CreateCardRequest"]
+		require.NotNil(t, card)
+		assert.ElementsMatch(t, []string{"name", "color"}, card.Required)
+		assert.Equal(t, 100, *card.Properties["name"].MaxLength)
+		assert.NotEmpty(t, card.Properties["color"].Pattern)
+
+		alert := schemas["CreateAlertRequest"]
+		require.NotNil(t, alert)
+		assert.Equal(t, []string{"week", "month", "quarter", "year"}, alert.Properties["period"].Enum)
+		assert.Equal(t, 1, *alert.Properties["channels"].MinItems)
+		assert.Equal(t, []string{"email", "webhook", "inApp"}, alert.Properties["channels"].Items.Enum)
+		assert.Equal(t, "email", alert.Properties["email"].Format)
+		amount := alert.Properties["amount"]
+		assert.Equal(t, "number", amount.Type)
+		assert.Equal(t, 0.0, *amount.Minimum)
+		assert.True(t, amount.ExclusiveMinimum)
+	})
+
+	t.Run("models", func(t *testing.T) {
+		expense := schemas["Expense"]
+		require.NotNil(t, expense)
+		assert.Equal(t, "uuid", expense.Properties["id"].Format)
+		assert.Equal(t, "date-time", expense.Properties["date"].Format)
+		assert.True(t, expense.Properties["payeeId"].Nullable)
+		assert.Equal(t, "#/components/schemas/Card", expense.Properties["card"].Ref)
+		assert.Equal(t, "#/components/schemas/ExpenseItem", expense.Properties["items"].Items.Ref)
+
+		// Embedded fields are promoted and hidden fields left out
+		payee := schemas["PayeeResult"]
+		require.NotNil(t, payee)
+		assert.Contains(t, payee.Properties, "name")
+		assert.Contains(t, payee.Properties, "matchedExpenses")
+		assert.NotContains(t, schemas["Webhook"].Properties, "secret")
+		assert.NotContains(t, schemas["Attachment"].Properties, "expense")
+	})
+
+	t.Run("operations", func(t *testing.T) {
+		list := doc.Paths["/api/expenses"]["get"]
+		require.NotNil(t, list)
+		body := list.Responses["200"].Content["application/json"].Schema
+		assert.Equal(t, "#/components/schemas/PaginatedResponse", body.AllOf[0].Ref)
+
+		get := doc.Paths["/api/cards/{id}"]["get"]
+		require.NotNil(t, get)
+		require.Len(t, get.Parameters, 1)
+		assert.Equal(t, "path", get.Parameters[0].In)
+		assert.Equal(t, "#/components/schemas/SuccessResponse", get.Responses["200"].Content["application/json"].Schema.AllOf[0].Ref)
+		notFound := get.Responses["404"].Content["application/json"].Schema
+		assert.Equal(t, []string{"CARD_NOT_FOUND"}, notFound.AllOf[1].Properties["error"].Properties["code"].Enum)
+
+		create := doc.Paths["/api/cards"]["post"]
+		require.NotNil(t, create)
+		assert.Equal(t, "#/components/schemas/CreateCardRequest", create.RequestBody.Content["application/json"].Schema.Ref)
+		assert.Contains(t, create.Responses, "201")
+
+		upload := doc.Paths["/api/expenses/{id}/attachments"]["post"]
+		require.NotNil(t, upload)
+		assert.Contains(t, upload.RequestBody.Content, "multipart/form-data")
+		assert.Contains(t, doc.Paths["/api/events"]["get"].Responses["200"].Content, "text/event-stream")
+	})
+}
+
+func TestOpenAPIPath(t *testing.T) {
+	assert.Equal(t, "/api/cards", openapi.Path("/api/cards"))
+	assert.Equal(t, "/api/expenses/{id}/refunds/{refundId}", openapi.Path("/api/expenses/:id/refunds/:refundId"))
+}
@@ -0,0 +1,58 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/payees"
+)
+
+func TestNormalizePayee(t *testing.T) {
+	assert.Equal(t, "amazon.co.jp", payees.Normalize("  AMAZON.CO.JP "))
+	assert.Equal(t, "amazon", payees.Normalize("ＡＭＡＺＯＮ"))
+	assert.Equal(t, "セブン イレブン", payees.Normalize("ｾﾌﾞﾝ　　ｲﾚﾌﾞﾝ"))
+}
+
+func TestPayeeMatcher(t *testing.T) {
+	amazon := models.Payee{ID: uuid.New(), Name: "Amazon", Aliases: []string{"アマゾン"}, Patterns: []string{`^amazon\.co\.jp`, `^amzn`}}
+	market := models.Payee{ID: uuid.New(), Name: "Amazon Fresh", Patterns: []string{`fresh`}}
+	conbini := models.Payee{ID: uuid.New(), Name: "セブンイレブン", Patterns: []string{`^セブン`}}
+
+	matcher, err := payees.NewMatcher([]models.Payee{conbini, market, amazon})
+	require.NoError(t, err)
+
+	tests := []struct {
+		description string
+		expected    *uuid.UUID
+	}{
+		{description: "Amazon", expected: &amazon.ID},
+		{description: "アマゾン", expected: &amazon.ID},
+		{description: "AMAZON.CO.JP*2X4AB1", expected: &amazon.ID},
+		{description: "ＡＭＺＮ Mktp", expected: &amazon.ID},
+		// The name of a payee wins over a pattern of another one
+		{description: "amazon fresh", expected: &market.ID},
+		// Among patterns, the payee first by name wins
+		{description: "AMAZON.CO.JP FRESH", expected: &amazon.ID},
+		{description: "ｾﾌﾞﾝｲﾚﾌﾞﾝ 渋谷店", expected: &conbini.ID},
+		{description: "スーパー", expected: nil},
+		{description: "", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			assert.Equal(t, tt.expected, matcher.Match(tt.description))
+		})
+	}
+}
+
+func TestPayeeInvalidPattern(t *testing.T) {
+	_, err := payees.CompilePattern("amazon(")
+	assert.Error(t, err)
+
+	_, err = payees.NewMatcher([]models.Payee{{ID: uuid.New(), Name: "Broken", Patterns: []string{"[a-"}}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,134 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
+	"kakeibo-tanuki/internal/reports"
+)
+
+func TestPreviousPeriod(t *testing.T) {
+	tests := []struct {
+		name         string
+		from, to     time.Time
+		against      string
+		expectedFrom time.Time
+		expectedTo   time.Time
+	}{
+		{
+			name:         "Month vs previous month",
+			from:         date(2025, 3, 1),
+			to:           date(2025, 3, 31),
+			against:      models.CompareAgainstPrevious,
+			expectedFrom: date(2025, 2, 1),
+			expectedTo:   date(2025, 2, 28),
+		},
+		{
+			name:         "Month vs same month last year",
+			from:         date(2025, 2, 1),
+			to:           date(2025, 2, 28),
+			against:      models.CompareAgainstLastYear,
+			expectedFrom: date(2024, 2, 1),
+			expectedTo:   date(2024, 2, 29),
+		},
+		{
+			name:         "Fiscal year vs previous fiscal year",
+			from:         date(2025, 4, 1),
+			to:           date(2026, 3, 31),
+			against:      models.CompareAgainstPrevious,
+			expectedFrom: date(2024, 4, 1),
+			expectedTo:   date(2025, 3, 31),
+		},
+		{
+			name:         "Trip vs the days before",
+			from:         date(2025, 5, 3),
+			to:           date(2025, 5, 6),
+			against:      models.CompareAgainstPrevious,
+			expectedFrom: date(2025, 4, 29),
+			expectedTo:   date(2025, 5, 2),
+		},
+		{
+			name:         "Leap day last year",
+			from:         date(2024, 2, 20),
+			to:           date(2024, 2, 29),
+			against:      models.CompareAgainstLastYear,
+			expectedFrom: date(2023, 2, 20),
+			expectedTo:   date(2023, 2, 28),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, err := reports.PreviousPeriod(tt.from, tt.to, tt.against)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedFrom, from)
+			assert.Equal(t, tt.expectedTo, to)
+		})
+	}
+
+	_, _, err := reports.PreviousPeriod(date(2025, 3, 1), date(2025, 3, 31), "nextYear")
+	assert.Error(t, err)
+}
+
+func TestChangePercent(t *testing.T) {
+	assert.Nil(t, reports.ChangePercent(money.New(100), 0))
+
+	percent := reports.ChangePercent(money.New(1500), money.New(1000))
+	require.NotNil(t, percent)
+	assert.Equal(t, 50.0, *percent)
+
+	percent = reports.ChangePercent(money.New(2000), money.New(3000))
+	require.NotNil(t, percent)
+	assert.Equal(t, -33.3, *percent)
+}
+
+func TestCompareCategories(t *testing.T) {
+	food, travel, books := uuid.New(), uuid.New(), uuid.New()
+
+	current := []models.CategoryExpenseSum{
+		{CategoryID: food, CategoryName: "食費", TotalAmount: money.New(30000)},
+		{CategoryID: travel, CategoryName: "旅行", TotalAmount: money.New(50000)},
+	}
+	previous := []models.CategoryExpenseSum{
+		{CategoryID: food, CategoryName: "食費", TotalAmount: money.New(25000)},
+		{CategoryID: books, CategoryName: "書籍", TotalAmount: money.New(3000)},
+	}
+
+	comparisons, added, removed := reports.CompareCategories(current, previous)
+	require.Len(t, comparisons, 3)
+
+	// Sorted by current spending
+	assert.Equal(t, travel, comparisons[0].CategoryID)
+	assert.Equal(t, money.New(50000), comparisons[0].Change)
+	assert.Nil(t, comparisons[0].ChangePercent)
+
+	assert.Equal(t, food, comparisons[1].CategoryID)
+	assert.Equal(t, money.New(5000), comparisons[1].Change)
+	require.NotNil(t, comparisons[1].ChangePercent)
+	assert.Equal(t, 20.0, *comparisons[1].ChangePercent)
+
+	assert.Equal(t, books, comparisons[2].CategoryID)
+	assert.Equal(t, money.New(-3000), comparisons[2].Change)
+
+	require.Len(t, added, 1)
+	assert.Equal(t, travel, added[0].CategoryID)
+	require.Len(t, removed, 1)
+	assert.Equal(t, books, removed[0].CategoryID)
+}
+
+func TestCompareCards(t *testing.T) {
+	card := uuid.New()
+
+	comparisons := reports.CompareCards(nil, []models.CardExpenseSum{{CardID: card, CardName: "カード", TotalAmount: money.New(1000)}})
+	require.Len(t, comparisons, 1)
+	assert.Zero(t, comparisons[0].CurrentAmount)
+	assert.Equal(t, money.New(-1000), comparisons[0].Change)
+	require.NotNil(t, comparisons[0].ChangePercent)
+	assert.Equal(t, -100.0, *comparisons[0].ChangePercent)
+}
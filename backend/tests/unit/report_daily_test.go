@@ -0,0 +1,84 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kakeibo-tanuki/internal/money"
+	"kakeibo-tanuki/internal/reports"
+)
+
+func TestDaily(t *testing.T) {
+	food, travel := uuid.New(), uuid.New()
+	expenseA, expenseB, expenseC, expenseD := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	rows := []reports.DailyRow{
+		// 2025-02-03 is a Monday
+		{ExpenseID: &expenseA, Date: date(2025, 2, 3), CategoryID: food, CategoryName: "食費", Amount: money.New(1000)},
+		{ExpenseID: &expenseB, Date: date(2025, 2, 3), CategoryID: food, CategoryName: "食費", Amount: money.New(500)},
+		{ExpenseID: &expenseB, Date: date(2025, 2, 3), CategoryID: travel, CategoryName: "旅行", Amount: money.New(2000)},
+		// A refund
+		{Date: date(2025, 2, 10), CategoryID: food, CategoryName: "食費", Amount: money.New(-300)},
+		{ExpenseID: &expenseC, Date: date(2025, 2, 25), CategoryID: food, CategoryName: "食費", Amount: money.New(1200)},
+		{ExpenseID: &expenseD, Date: date(2025, 2, 25), CategoryID: travel, CategoryName: "旅行", Amount: money.New(1200)},
+		// Outside the range
+		{ExpenseID: &expenseD, Date: date(2025, 3, 1), CategoryID: food, CategoryName: "食費", Amount: money.New(9999)},
+	}
+
+	days, byWeekday, byDayOfMonth := reports.Daily(date(2025, 2, 1), date(2025, 2, 28), rows, "JPY")
+	require.Len(t, days, 28)
+
+	t.Run("days", func(t *testing.T) {
+		assert.Equal(t, "2025-02-01", days[0].Date)
+		assert.Equal(t, 6, days[0].Weekday)
+		assert.Zero(t, days[0].TotalAmount)
+		assert.Nil(t, days[0].TopCategory)
+
+		// The split expense counts once
+		assert.Equal(t, money.New(3500), days[2].TotalAmount)
+		assert.Equal(t, 2, days[2].Count)
+		require.NotNil(t, days[2].TopCategory)
+		assert.Equal(t, travel, days[2].TopCategory.CategoryID)
+		assert.Equal(t, money.New(2000), days[2].TopCategory.TotalAmount)
+
+		assert.Equal(t, money.New(-300), days[9].TotalAmount)
+		assert.Zero(t, days[9].Count)
+		assert.Nil(t, days[9].TopCategory)
+
+		// Ties go to the category first by name
+		require.NotNil(t, days[24].TopCategory)
+		assert.Equal(t, "旅行", days[24].TopCategory.CategoryName)
+	})
+
+	t.Run("by weekday", func(t *testing.T) {
+		require.Len(t, byWeekday, 7)
+		monday := byWeekday[1]
+		assert.Equal(t, 1, monday.Weekday)
+		assert.Equal(t, 4, monday.Days)
+		assert.Equal(t, money.New(3200), monday.TotalAmount)
+		assert.Equal(t, money.New(800), monday.AverageAmount)
+		assert.Equal(t, 2, monday.Count)
+
+		tuesday := byWeekday[2]
+		assert.Equal(t, money.New(2400), tuesday.TotalAmount)
+		assert.Equal(t, money.New(600), tuesday.AverageAmount)
+	})
+
+	t.Run("by day of month", func(t *testing.T) {
+		require.Len(t, byDayOfMonth, 28)
+		assert.Equal(t, 3, byDayOfMonth[2].Day)
+		assert.Equal(t, 1, byDayOfMonth[2].Days)
+		assert.Equal(t, money.New(3500), byDayOfMonth[2].AverageAmount)
+	})
+
+	t.Run("a year has every day of the month", func(t *testing.T) {
+		days, _, byDayOfMonth := reports.Daily(date(2024, 1, 1), date(2024, 12, 31), nil, "JPY")
+		assert.Len(t, days, 366)
+		require.Len(t, byDayOfMonth, 31)
+		assert.Equal(t, 12, byDayOfMonth[0].Days)
+		assert.Equal(t, 7, byDayOfMonth[30].Days)
+	})
+}
@@ -0,0 +1,152 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
+	"kakeibo-tanuki/internal/reports"
+)
+
+type forecastFixture struct {
+	card  uuid.UUID
+	rows  []reports.ForecastRow
+	names map[uuid.UUID]string
+}
+
+func (f *forecastFixture) add(category string, day time.Time, amount int64, description string) {
+	id := uuid.New()
+	f.addRow(category, reports.ForecastRow{ExpenseID: &id, Date: day, PurchaseDate: day, Description: description, Amount: money.New(amount)})
+}
+
+func (f *forecastFixture) addRow(category string, row reports.ForecastRow) {
+	for id, name := range f.names {
+		if name == category {
+			row.CategoryID = id
+		}
+	}
+	if row.CategoryID == uuid.Nil {
+		row.CategoryID = uuid.New()
+		f.names[row.CategoryID] = category
+	}
+	row.CategoryName = category
+	row.CardID = f.card
+	f.rows = append(f.rows, row)
+}
+
+func findForecast(t *testing.T, categories []models.CategoryForecast, name string) models.CategoryForecast {
+	for _, category := range categories {
+		if category.CategoryName == name {
+			return category
+		}
+	}
+	require.Failf(t, "category not found", "%s", name)
+	return models.CategoryForecast{}
+}
+
+func TestForecast(t *testing.T) {
+	// 2025-06-15 is a Sunday
+	asOf := date(2025, 6, 15)
+	f := &forecastFixture{card: uuid.New(), names: map[uuid.UUID]string{}}
+
+	// 1,000 yen of food every day of the year so far
+	for day := date(2025, 1, 1); !day.After(asOf); day = day.AddDate(0, 0, 1) {
+		f.add("食費", day, 1000, "")
+	}
+	// A subscription on the 20th of every month, not yet charged in June
+	for month := time.January; month <= time.May; month++ {
+		f.add("サブスク", date(2025, month, 20), 1490, "動画配信")
+	}
+	// 700 yen of household goods every Saturday
+	for day := date(2025, 3, 22); !day.After(asOf); day = day.AddDate(0, 0, 7) {
+		f.add("日用品", day, 700, "")
+	}
+	// A single large dinner
+	f.add("外食", date(2025, 6, 1), 13000, "")
+	// An installment payment already recorded for later this month
+	id := uuid.New()
+	f.addRow("家電", reports.ForecastRow{ExpenseID: &id, Date: date(2025, 6, 27), PurchaseDate: date(2025, 5, 1), Amount: money.New(5000)})
+
+	month, year, byCategory := reports.Forecast(asOf, f.rows, "JPY")
+	require.Len(t, byCategory, 5)
+
+	t.Run("daily spending", func(t *testing.T) {
+		food := findForecast(t, byCategory, "食費")
+		assert.Equal(t, models.ForecastTotals{
+			From: "2025-06-01", To: "2025-06-30",
+			Actual: money.New(15000), Projected: money.New(15000), Forecast: money.New(30000),
+			Low: money.New(30000), High: money.New(30000),
+		}, food.Month)
+		assert.Equal(t, money.New(166000), food.Year.Actual)
+		assert.Equal(t, money.New(199000), food.Year.Projected)
+		assert.Equal(t, money.New(365000), food.Year.Forecast)
+	})
+
+	t.Run("recurring charges", func(t *testing.T) {
+		subscription := findForecast(t, byCategory, "サブスク")
+		assert.Equal(t, money.New(1490), subscription.Month.Recurring)
+		assert.Zero(t, subscription.Month.Projected)
+		assert.Equal(t, money.New(1490), subscription.Month.Forecast)
+
+		// June through December
+		assert.Equal(t, money.New(7450), subscription.Year.Actual)
+		assert.Equal(t, money.New(10430), subscription.Year.Recurring)
+	})
+
+	t.Run("day of the week pattern", func(t *testing.T) {
+		household := findForecast(t, byCategory, "日用品")
+		// June 21 and 28 are the Saturdays left
+		assert.Equal(t, money.New(1400), household.Month.Projected)
+	})
+
+	t.Run("scheduled installment payments", func(t *testing.T) {
+		appliance := findForecast(t, byCategory, "家電")
+		assert.Equal(t, money.New(5000), appliance.Month.Scheduled)
+		assert.Zero(t, appliance.Month.Projected)
+		assert.Equal(t, money.New(5000), appliance.Month.Forecast)
+	})
+
+	t.Run("irregular spending has a range", func(t *testing.T) {
+		dining := findForecast(t, byCategory, "外食")
+		assert.Equal(t, money.New(13000), dining.Month.Actual)
+		assert.Equal(t, dining.Month.Actual, dining.Month.Low)
+		assert.Greater(t, int64(dining.Month.Forecast), int64(dining.Month.Low))
+		assert.Greater(t, int64(dining.Month.High), int64(dining.Month.Forecast))
+	})
+
+	t.Run("totals add up the categories", func(t *testing.T) {
+		var forecast, actual money.Amount
+		for _, category := range byCategory {
+			forecast += category.Month.Forecast
+			actual += category.Year.Actual
+		}
+		assert.Equal(t, forecast, month.Forecast)
+		assert.Equal(t, actual, year.Actual)
+		assert.LessOrEqual(t, int64(month.Low), int64(month.Forecast))
+		assert.GreaterOrEqual(t, int64(month.High), int64(month.Forecast))
+	})
+
+	t.Run("deterministic", func(t *testing.T) {
+		reversed := make([]reports.ForecastRow, len(f.rows))
+		for i, row := range f.rows {
+			reversed[len(f.rows)-1-i] = row
+		}
+
+		againMonth, againYear, againByCategory := reports.Forecast(asOf, reversed, "JPY")
+		assert.Equal(t, month, againMonth)
+		assert.Equal(t, year, againYear)
+		assert.Equal(t, byCategory, againByCategory)
+	})
+}
+
+func TestForecastStart(t *testing.T) {
+	// The history reaches back into the previous year early in the year
+	assert.Equal(t, date(2024, 11, 1), reports.ForecastStart(date(2025, 2, 10)))
+	assert.Equal(t, date(2025, 1, 1), reports.ForecastStart(date(2025, 8, 10)))
+	assert.Equal(t, date(2025, 12, 31), reports.ForecastEnd(date(2025, 8, 10)))
+}
@@ -0,0 +1,72 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/reports"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestPeriodStart(t *testing.T) {
+	// 2025-04-16 is a Wednesday
+	day := time.Date(2025, 4, 16, 18, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, date(2025, 4, 16), reports.PeriodStart(day, models.ReportGroupByDay))
+	assert.Equal(t, date(2025, 4, 14), reports.PeriodStart(day, models.ReportGroupByWeek))
+	assert.Equal(t, date(2025, 4, 1), reports.PeriodStart(day, models.ReportGroupByMonth))
+	assert.Equal(t, date(2025, 4, 1), reports.PeriodStart(day, models.ReportGroupByQuarter))
+	assert.Equal(t, date(2025, 10, 1), reports.PeriodStart(date(2025, 12, 31), models.ReportGroupByQuarter))
+
+	// Sundays belong to the week that started on the previous Monday
+	assert.Equal(t, date(2025, 4, 14), reports.PeriodStart(date(2025, 4, 20), models.ReportGroupByWeek))
+}
+
+func TestPeriods(t *testing.T) {
+	t.Run("fiscal year in quarters", func(t *testing.T) {
+		periods, err := reports.Periods(date(2025, 4, 1), date(2026, 3, 31), models.ReportGroupByQuarter)
+		require.NoError(t, err)
+		require.Len(t, periods, 4)
+		assert.Equal(t, reports.Period{Start: date(2025, 4, 1), End: date(2025, 6, 30)}, periods[0])
+		assert.Equal(t, reports.Period{Start: date(2026, 1, 1), End: date(2026, 3, 31)}, periods[3])
+	})
+
+	t.Run("partial months are clipped", func(t *testing.T) {
+		periods, err := reports.Periods(date(2025, 1, 20), date(2025, 3, 5), models.ReportGroupByMonth)
+		require.NoError(t, err)
+		require.Len(t, periods, 3)
+		assert.Equal(t, reports.Period{Start: date(2025, 1, 20), End: date(2025, 1, 31)}, periods[0])
+		assert.Equal(t, reports.Period{Start: date(2025, 2, 1), End: date(2025, 2, 28)}, periods[1])
+		assert.Equal(t, reports.Period{Start: date(2025, 3, 1), End: date(2025, 3, 5)}, periods[2])
+	})
+
+	t.Run("single day", func(t *testing.T) {
+		periods, err := reports.Periods(date(2025, 4, 1), date(2025, 4, 1), models.ReportGroupByWeek)
+		require.NoError(t, err)
+		assert.Equal(t, []reports.Period{{Start: date(2025, 4, 1), End: date(2025, 4, 1)}}, periods)
+	})
+
+	t.Run("days", func(t *testing.T) {
+		periods, err := reports.Periods(date(2024, 2, 27), date(2024, 3, 1), models.ReportGroupByDay)
+		require.NoError(t, err)
+		assert.Len(t, periods, 4)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := reports.Periods(date(2025, 4, 2), date(2025, 4, 1), models.ReportGroupByDay)
+		assert.Error(t, err)
+
+		_, err = reports.Periods(date(2025, 4, 1), date(2025, 4, 30), "year")
+		assert.Error(t, err)
+
+		_, err = reports.Periods(date(2000, 1, 1), date(2025, 1, 1), models.ReportGroupByDay)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,131 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
+	"kakeibo-tanuki/internal/rules"
+)
+
+func TestRuleEngine(t *testing.T) {
+	card := uuid.New()
+	payee := uuid.New()
+	groceries, dining, travel := uuid.New(), uuid.New(), uuid.New()
+	shared, notShared := true, false
+	minAmount, maxAmount := money.New(1000), money.New(5000)
+
+	market := models.Rule{ID: uuid.New(), Name: "Supermarket", Priority: 1, Enabled: true,
+		DescriptionPattern: `^(seiyu|イオン)`, CategoryID: &groceries, Tags: []string{"food"}, IsShared: &shared}
+	lunch := models.Rule{ID: uuid.New(), Name: "Lunch", Priority: 2, Enabled: true,
+		MinAmount: &minAmount, MaxAmount: &maxAmount, CardID: &card, CategoryID: &dining, Tags: []string{"food", "work"}, IsShared: &notShared}
+	airline := models.Rule{ID: uuid.New(), Name: "Airline", Priority: 2, Enabled: true,
+		PayeeID: &payee, CategoryID: &travel}
+	disabled := models.Rule{ID: uuid.New(), Name: "Disabled", Priority: 0, Enabled: false, CategoryID: &travel}
+
+	engine, err := rules.NewEngine([]models.Rule{lunch, disabled, airline, market})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		expense  models.Expense
+		ruleIDs  []uuid.UUID
+		category *uuid.UUID
+		tags     []string
+		isShared *bool
+	}{
+		{
+			name:     "pattern on normalized description",
+			expense:  models.Expense{Description: "SEIYU 渋谷店", Amount: money.New(800)},
+			ruleIDs:  []uuid.UUID{market.ID},
+			category: &groceries, tags: []string{"food"}, isShared: &shared,
+		},
+		{
+			name:     "lower priority first, tags of all rules",
+			expense:  models.Expense{Description: "ｲｵﾝ 品川", Amount: money.New(1200), CardID: card},
+			ruleIDs:  []uuid.UUID{market.ID, lunch.ID},
+			category: &groceries, tags: []string{"food", "work"}, isShared: &shared,
+		},
+		{
+			name:     "amount range and card",
+			expense:  models.Expense{Description: "定食屋", Amount: money.New(5000), CardID: card},
+			ruleIDs:  []uuid.UUID{lunch.ID},
+			category: &dining, tags: []string{"food", "work"}, isShared: &notShared,
+		},
+		{
+			name:    "amount above range",
+			expense: models.Expense{Description: "定食屋", Amount: money.New(5001), CardID: card},
+		},
+		{
+			name:    "other card",
+			expense: models.Expense{Description: "定食屋", Amount: money.New(1500), CardID: uuid.New()},
+		},
+		{
+			name:     "payee",
+			expense:  models.Expense{Description: "JAL", Amount: money.New(30000), PayeeID: &payee},
+			ruleIDs:  []uuid.UUID{airline.ID},
+			category: &travel,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actions := engine.Evaluate(&tt.expense)
+			assert.Equal(t, tt.ruleIDs, actions.RuleIDs)
+			assert.Equal(t, tt.category, actions.CategoryID)
+			assert.Equal(t, tt.tags, actions.Tags)
+			assert.Equal(t, tt.isShared, actions.IsShared)
+		})
+	}
+}
+
+func TestRuleEngineInvalidPattern(t *testing.T) {
+	_, err := rules.NewEngine([]models.Rule{{ID: uuid.New(), Name: "Broken", Enabled: true, DescriptionPattern: "(seiyu"}})
+	assert.Error(t, err)
+}
+
+func TestRuleChange(t *testing.T) {
+	groceries, dining := uuid.New(), uuid.New()
+	shared := true
+	actions := rules.Actions{RuleIDs: []uuid.UUID{uuid.New()}, CategoryID: &groceries, Tags: []string{"food", "weekly"}, IsShared: &shared}
+
+	expense := models.Expense{
+		ID:         uuid.New(),
+		Date:       time.Date(2025, 3, 8, 0, 0, 0, 0, time.UTC),
+		Amount:     money.New(2400),
+		Currency:   "JPY",
+		CategoryID: dining,
+		Tags:       []string{"food"},
+	}
+
+	change, ok := rules.Change(&expense, actions)
+	require.True(t, ok)
+	assert.Equal(t, "2025-03-08", change.Date)
+	assert.Equal(t, dining, change.PreviousCategoryID)
+	assert.Equal(t, &groceries, change.CategoryID)
+	assert.Equal(t, []string{"weekly"}, change.AddedTags)
+	assert.Equal(t, &shared, change.IsShared)
+
+	rules.Apply(&expense, change)
+	assert.Equal(t, groceries, expense.CategoryID)
+	assert.Equal(t, []string{"food", "weekly"}, expense.Tags)
+	require.NotNil(t, expense.IsShared)
+	assert.True(t, *expense.IsShared)
+
+	t.Run("nothing left to change", func(t *testing.T) {
+		_, ok := rules.Change(&expense, actions)
+		assert.False(t, ok)
+	})
+
+	t.Run("split expenses keep their category", func(t *testing.T) {
+		split := models.Expense{CategoryID: dining, Items: []models.ExpenseItem{{CategoryID: dining}}}
+		change, ok := rules.Change(&split, rules.Actions{CategoryID: &groceries})
+		assert.False(t, ok)
+		assert.Nil(t, change.CategoryID)
+	})
+}
@@ -0,0 +1,393 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
+	"kakeibo-tanuki/internal/repositories"
+	"kakeibo-tanuki/internal/services"
+)
+
+// fakeUnitOfWork runs operations on fake repositories and counts the
+// transactions committed and rolled back.
+type fakeUnitOfWork struct {
+	repo       *repositories.Repository
+	committed  int
+	rolledBack int
+}
+
+func (u *fakeUnitOfWork) Repository() *repositories.Repository {
+	return u.repo
+}
+
+func (u *fakeUnitOfWork) Do(ctx context.Context, fn func(repo *repositories.Repository) error) error {
+	err := fn(u.repo)
+	if err != nil {
+		u.rolledBack++
+	} else {
+		u.committed++
+	}
+	return err
+}
+
+func notFoundError(model string) error {
+	return &repositories.Error{Kind: repositories.ErrNotFound, Model: model, Err: errors.New("record not found")}
+}
+
+// The fakes embed the repository interfaces and implement only the methods
+// the services call.
+type fakeCards struct {
+	repositories.CardRepository
+	cards       map[uuid.UUID]bool
+	hasExpenses bool
+	deleted     []uuid.UUID
+	err         error
+}
+
+func (f *fakeCards) GetByID(ctx context.Context, id uuid.UUID) (*models.Card, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	if !f.cards[id] {
+		return nil, notFoundError("Card")
+	}
+	return &models.Card{ID: id}, nil
+}
+
+func (f *fakeCards) Lock(ctx context.Context, id uuid.UUID) (*models.Card, error) {
+	return f.GetByID(ctx, id)
+}
+
+func (f *fakeCards) HasExpenses(ctx context.Context, id uuid.UUID) (bool, error) {
+	return f.hasExpenses, nil
+}
+
+func (f *fakeCards) Delete(ctx context.Context, id uuid.UUID) error {
+	f.deleted = append(f.deleted, id)
+	return nil
+}
+
+type fakeCategories struct {
+	repositories.CategoryRepository
+	categories map[uuid.UUID]bool
+	lookups    int
+	createErr  error
+}
+
+func (f *fakeCategories) GetByID(ctx context.Context, id uuid.UUID) (*models.Category, error) {
+	f.lookups++
+	if !f.categories[id] {
+		return nil, notFoundError("Category")
+	}
+	return &models.Category{ID: id}, nil
+}
+
+func (f *fakeCategories) Create(ctx context.Context, category *models.Category) error {
+	return f.createErr
+}
+
+type fakeExpenses struct {
+	repositories.ExpenseRepository
+	saved  []*models.Expense
+	locked []uuid.UUID
+}
+
+func (f *fakeExpenses) Create(ctx context.Context, expense *models.Expense) error {
+	f.saved = append(f.saved, expense)
+	return nil
+}
+
+func (f *fakeExpenses) Update(ctx context.Context, expense *models.Expense) error {
+	f.saved = append(f.saved, expense)
+	return nil
+}
+
+func (f *fakeExpenses) GetByID(ctx context.Context, id uuid.UUID) (*models.Expense, error) {
+	for _, expense := range f.saved {
+		if expense.ID == id {
+			return expense, nil
+		}
+	}
+	return nil, notFoundError("Expense")
+}
+
+func (f *fakeExpenses) Lock(ctx context.Context, id uuid.UUID) (*models.Expense, error) {
+	f.locked = append(f.locked, id)
+	if expense, err := f.GetByID(ctx, id); err == nil {
+		return expense, nil
+	}
+	return &models.Expense{ID: id}, nil
+}
+
+type fakeRefunds struct {
+	repositories.RefundRepository
+	total   money.Amount
+	created []*models.Refund
+}
+
+func (f *fakeRefunds) GetTotalByExpenseID(ctx context.Context, expenseID uuid.UUID) (money.Amount, error) {
+	return f.total, nil
+}
+
+func (f *fakeRefunds) Create(ctx context.Context, refund *models.Refund) error {
+	f.created = append(f.created, refund)
+	return nil
+}
+
+type fakeInstallments struct {
+	repositories.InstallmentRepository
+}
+
+func (f *fakeInstallments) GetByExpenseID(ctx context.Context, expenseID uuid.UUID) (*models.InstallmentPlan, error) {
+	return nil, notFoundError("InstallmentPlan")
+}
+
+type fakeBooks struct {
+	repositories.BookRepository
+}
+
+func (f *fakeBooks) Get(ctx context.Context) (*models.Book, error) {
+	return &models.Book{BaseCurrency: "JPY"}, nil
+}
+
+type fakePayees struct {
+	repositories.PayeeRepository
+}
+
+func (f *fakePayees) GetAll(ctx context.Context) ([]models.Payee, error) {
+	return nil, nil
+}
+
+type fakeRules struct {
+	repositories.RuleRepository
+}
+
+func (f *fakeRules) GetAll(ctx context.Context) ([]models.Rule, error) {
+	return nil, nil
+}
+
+// fakeRepository returns a unit of work with a card and two categories.
+func fakeRepository() (*fakeUnitOfWork, uuid.UUID, uuid.UUID, uuid.UUID) {
+	cardID, categoryID, otherCategoryID := uuid.New(), uuid.New(), uuid.New()
+	uow := &fakeUnitOfWork{repo: &repositories.Repository{
+		Card:        &fakeCards{cards: map[uuid.UUID]bool{cardID: true}},
+		Category:    &fakeCategories{categories: map[uuid.UUID]bool{categoryID: true, otherCategoryID: true}},
+		Payee:       &fakePayees{},
+		Rule:        &fakeRules{},
+		Expense:     &fakeExpenses{},
+		Refund:      &fakeRefunds{},
+		Installment: &fakeInstallments{},
+		Book:        &fakeBooks{},
+	}}
+	return uow, cardID, categoryID, otherCategoryID
+}
+
+func serviceError(t *testing.T, err error) *services.Error {
+	t.Helper()
+	var serviceErr *services.Error
+	require.True(t, errors.As(err, &serviceErr), "%v is not a services.Error", err)
+	return serviceErr
+}
+
+func TestParseDate(t *testing.T) {
+	date, err := services.ParseDate("2024-03-15")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), date)
+
+	date, err = services.ParseDate("2024-03-15T12:30:00+09:00")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 15, 3, 30, 0, 0, time.UTC), date.UTC())
+
+	_, err = services.ParseDate("15/03/2024")
+	assert.Equal(t, "INVALID_DATE", serviceError(t, err).Code)
+	assert.Equal(t, http.StatusBadRequest, serviceError(t, err).Status)
+
+	_, err = services.ParseDate(time.Now().AddDate(0, 0, 2).Format("2006-01-02"))
+	assert.Equal(t, "FUTURE_DATE", serviceError(t, err).Code)
+}
+
+func TestExpenseServiceCreate(t *testing.T) {
+	newRequest := func(cardID, categoryID uuid.UUID) *models.CreateExpenseRequest {
+		return &models.CreateExpenseRequest{
+			Amount:     money.New(300),
+			Date:       "2024-03-15",
+			CardID:     cardID.String(),
+			CategoryID: categoryID.String(),
+		}
+	}
+
+	t.Run("existing card and categories", func(t *testing.T) {
+		uow, cardID, categoryID, otherCategoryID := fakeRepository()
+		service := services.NewExpenseService(uow)
+
+		req := newRequest(cardID, categoryID)
+		req.Items = []models.ExpenseItemRequest{
+			{Amount: money.New(100), CategoryID: categoryID.String()},
+			{Amount: money.New(100), CategoryID: otherCategoryID.String()},
+			{Amount: money.New(100), CategoryID: otherCategoryID.String()},
+		}
+		created, err := service.Create(context.Background(), req)
+		require.NoError(t, err)
+		assert.Equal(t, "JPY", created.Currency, "the base currency of the book")
+		assert.Equal(t, 1, uow.committed)
+		assert.Equal(t, 2, uow.repo.Category.(*fakeCategories).lookups, "each category is looked up once")
+	})
+
+	t.Run("unknown card", func(t *testing.T) {
+		uow, _, categoryID, _ := fakeRepository()
+		service := services.NewExpenseService(uow)
+
+		_, err := service.Create(context.Background(), newRequest(uuid.New(), categoryID))
+		serviceErr := serviceError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, serviceErr.Status)
+		assert.Equal(t, "CARD_NOT_FOUND", serviceErr.Code)
+		assert.Equal(t, 1, uow.rolledBack)
+		assert.Empty(t, uow.repo.Expense.(*fakeExpenses).saved)
+	})
+
+	t.Run("unknown category", func(t *testing.T) {
+		uow, cardID, _, _ := fakeRepository()
+		service := services.NewExpenseService(uow)
+
+		_, err := service.Create(context.Background(), newRequest(cardID, uuid.New()))
+		assert.Equal(t, "CATEGORY_NOT_FOUND", serviceError(t, err).Code)
+	})
+
+	t.Run("unknown item category", func(t *testing.T) {
+		uow, cardID, categoryID, _ := fakeRepository()
+		service := services.NewExpenseService(uow)
+
+		req := newRequest(cardID, categoryID)
+		req.Items = []models.ExpenseItemRequest{
+			{Amount: money.New(100), CategoryID: categoryID.String()},
+			{Amount: money.New(200), CategoryID: uuid.New().String()},
+		}
+		_, err := service.Create(context.Background(), req)
+		serviceErr := serviceError(t, err)
+		assert.Equal(t, "CATEGORY_NOT_FOUND", serviceErr.Code)
+		assert.Contains(t, serviceErr.Details, "items[1].categoryId")
+	})
+
+	t.Run("items that do not add up", func(t *testing.T) {
+		uow, cardID, categoryID, _ := fakeRepository()
+		service := services.NewExpenseService(uow)
+
+		req := newRequest(cardID, categoryID)
+		req.Items = []models.ExpenseItemRequest{{Amount: money.New(100), CategoryID: categoryID.String()}}
+		_, err := service.Create(context.Background(), req)
+		assert.Equal(t, "ITEMS_AMOUNT_MISMATCH", serviceError(t, err).Code)
+		assert.Equal(t, 1, uow.rolledBack)
+	})
+
+	t.Run("no category and no rule that sets one", func(t *testing.T) {
+		uow, cardID, _, _ := fakeRepository()
+		service := services.NewExpenseService(uow)
+
+		req := newRequest(cardID, uuid.Nil)
+		req.CategoryID = ""
+		_, err := service.Create(context.Background(), req)
+		var fieldErr *services.FieldError
+		require.True(t, errors.As(err, &fieldErr), "%v is not a services.FieldError", err)
+		assert.Equal(t, "categoryId", fieldErr.Field)
+	})
+
+	t.Run("database errors are returned as they are", func(t *testing.T) {
+		uow, cardID, categoryID, _ := fakeRepository()
+		uow.repo.Card.(*fakeCards).err = errors.New("connection refused")
+		service := services.NewExpenseService(uow)
+
+		_, err := service.Create(context.Background(), newRequest(cardID, categoryID))
+		require.Error(t, err)
+		var serviceErr *services.Error
+		assert.False(t, errors.As(err, &serviceErr))
+	})
+}
+
+func TestExpenseServiceUpdate(t *testing.T) {
+	uow, cardID, categoryID, _ := fakeRepository()
+	uow.repo.Refund.(*fakeRefunds).total = money.New(800)
+	service := services.NewExpenseService(uow)
+
+	id := uuid.New()
+	req := &models.UpdateExpenseRequest{
+		Amount:     money.New(500),
+		Currency:   "JPY",
+		Date:       "2024-03-15",
+		CardID:     cardID.String(),
+		CategoryID: categoryID.String(),
+	}
+	_, err := service.Update(context.Background(), id, req)
+	assert.Equal(t, "REFUND_EXCEEDS_AMOUNT", serviceError(t, err).Code)
+	assert.Empty(t, uow.repo.Expense.(*fakeExpenses).saved)
+	assert.Equal(t, []uuid.UUID{id}, uow.repo.Expense.(*fakeExpenses).locked, "refunds are checked with the expense locked")
+
+	req.Amount = money.New(1000)
+	updated, err := service.Update(context.Background(), id, req)
+	require.NoError(t, err)
+	assert.Equal(t, money.New(1000), updated.Amount)
+}
+
+func TestRefundServiceCreate(t *testing.T) {
+	uow, _, _, _ := fakeRepository()
+	expenses := uow.repo.Expense.(*fakeExpenses)
+	refunds := uow.repo.Refund.(*fakeRefunds)
+	expense := &models.Expense{ID: uuid.New(), Amount: money.New(1000), Currency: "JPY", Date: time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)}
+	expenses.saved = append(expenses.saved, expense)
+	refunds.total = money.New(800)
+	service := services.NewRefundService(uow)
+
+	_, err := service.Create(context.Background(), expense.ID, &models.CreateRefundRequest{Amount: money.New(300), Date: "2025-01-20"})
+	assert.Equal(t, "REFUND_EXCEEDS_AMOUNT", serviceError(t, err).Code)
+	assert.Empty(t, refunds.created)
+
+	_, err = service.Create(context.Background(), expense.ID, &models.CreateRefundRequest{Amount: money.New(200), Date: "2025-01-05"})
+	assert.Equal(t, "INVALID_REFUND_DATE", serviceError(t, err).Code)
+
+	refund, err := service.Create(context.Background(), expense.ID, &models.CreateRefundRequest{Amount: money.New(200), Date: "2025-01-20"})
+	require.NoError(t, err)
+	assert.Equal(t, money.New(200), refund.Amount)
+	assert.Equal(t, []*models.Refund{refund}, refunds.created)
+	assert.Equal(t, []uuid.UUID{expense.ID, expense.ID, expense.ID}, expenses.locked, "refunds are added up with the expense locked")
+}
+
+func TestCardServiceDelete(t *testing.T) {
+	uow, cardID, _, _ := fakeRepository()
+	cards := uow.repo.Card.(*fakeCards)
+	service := services.NewCardService(uow)
+
+	cards.hasExpenses = true
+	_, err := service.Delete(context.Background(), cardID)
+	serviceErr := serviceError(t, err)
+	assert.Equal(t, http.StatusConflict, serviceErr.Status)
+	assert.Equal(t, "CARD_HAS_EXPENSES", serviceErr.Code)
+	assert.Empty(t, cards.deleted)
+
+	_, err = service.Delete(context.Background(), uuid.New())
+	assert.ErrorIs(t, err, repositories.ErrNotFound)
+
+	cards.hasExpenses = false
+	card, err := service.Delete(context.Background(), cardID)
+	require.NoError(t, err)
+	assert.Equal(t, cardID, card.ID)
+	assert.Equal(t, []uuid.UUID{cardID}, cards.deleted)
+}
+
+func TestCategoryServiceCreate(t *testing.T) {
+	uow, _, _, _ := fakeRepository()
+	uow.repo.Category.(*fakeCategories).createErr = &repositories.Error{Kind: repositories.ErrConflict, Model: "Category", Err: errors.New("UNIQUE constraint failed")}
+	service := services.NewCategoryService(uow)
+
+	_, err := service.Create(context.Background(), &models.CreateCategoryRequest{Name: "食費", Color: "#10B981"})
+	serviceErr := serviceError(t, err)
+	assert.Equal(t, http.StatusConflict, serviceErr.Status)
+	assert.Equal(t, "DUPLICATE_CATEGORY", serviceErr.Code)
+}
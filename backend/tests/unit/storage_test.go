@@ -0,0 +1,183 @@
+package unit
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kakeibo-tanuki/internal/storage"
+)
+
+// fakeS3Server is a minimal in-memory stand-in for an S3-compatible server
+type fakeS3Server struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	authz   []string
+}
+
+func (f *fakeS3Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.authz = append(f.authz, r.Header.Get("Authorization"))
+
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.Path] = body
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		body, ok := f.objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(body)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func testStorageRoundTrip(t *testing.T, store storage.Storage) {
+	err := store.Put("expenses/abc/receipt", strings.NewReader("receipt-data"), 12, "image/png")
+	require.NoError(t, err)
+
+	object, err := store.Get("expenses/abc/receipt")
+	require.NoError(t, err)
+	data, err := io.ReadAll(object)
+	object.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "receipt-data", string(data))
+
+	err = store.Delete("expenses/abc/receipt")
+	require.NoError(t, err)
+
+	_, err = store.Get("expenses/abc/receipt")
+	assert.ErrorIs(t, err, storage.ErrObjectNotFound)
+
+	// Deleting a missing object is not an error
+	assert.NoError(t, store.Delete("expenses/abc/receipt"))
+}
+
+func TestLocalStorage(t *testing.T) {
+	store, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+
+	testStorageRoundTrip(t, store)
+}
+
+func TestLocalStorage_RejectsPathTraversal(t *testing.T) {
+	store, err := storage.NewLocalStorage(t.TempDir())
+	require.NoError(t, err)
+
+	_, err = store.Get("../../etc/passwd")
+	assert.Error(t, err)
+}
+
+func TestS3Storage(t *testing.T) {
+	fake := &fakeS3Server{objects: map[string][]byte{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	store, err := storage.NewS3Storage(storage.S3Config{
+		Endpoint:        server.URL,
+		Bucket:          "receipts",
+		Region:          "ap-northeast-1",
+		AccessKeyID:     "test-key",
+		SecretAccessKey: "test-secret",
+	})
+	require.NoError(t, err)
+
+	testStorageRoundTrip(t, store)
+
+	// Objects are addressed path-style under the bucket
+	require.NotEmpty(t, fake.authz)
+	for _, authz := range fake.authz {
+		assert.True(t, strings.HasPrefix(authz, "AWS4-HMAC-SHA256 Credential=test-key/"))
+		assert.Contains(t, authz, "/ap-northeast-1/s3/aws4_request")
+	}
+}
+
+func TestS3Storage_RequiresConfig(t *testing.T) {
+	_, err := storage.NewS3Storage(storage.S3Config{Bucket: "receipts"})
+	assert.Error(t, err)
+}
+
+func TestGenerateThumbnail(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 1024, 512))
+	for y := 0; y < 512; y++ {
+		for x := 0; x < 1024; x++ {
+			src.Set(x, y, color.RGBA{R: 200, G: 100, B: 50, A: 255})
+		}
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		encode      func(io.Writer, image.Image) error
+	}{
+		{
+			name:        "JPEG",
+			contentType: "image/jpeg",
+			encode: func(w io.Writer, m image.Image) error {
+				return jpeg.Encode(w, m, nil)
+			},
+		},
+		{
+			name:        "PNG",
+			contentType: "image/png",
+			encode:      png.Encode,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, tt.encode(&buf, src))
+
+			thumbnail, err := storage.GenerateThumbnail(&buf, tt.contentType, 256)
+			require.NoError(t, err)
+
+			decoded, err := jpeg.Decode(bytes.NewReader(thumbnail))
+			require.NoError(t, err)
+			assert.Equal(t, 256, decoded.Bounds().Dx())
+			assert.Equal(t, 128, decoded.Bounds().Dy())
+		})
+	}
+
+	t.Run("unsupported type", func(t *testing.T) {
+		_, err := storage.GenerateThumbnail(strings.NewReader("%PDF-1.4"), "application/pdf", 256)
+		assert.Error(t, err)
+	})
+
+	t.Run("image declaring too many pixels", func(t *testing.T) {
+		// The header of a 30000x30000 PNG, which is rejected before its
+		// pixels are allocated
+		var header bytes.Buffer
+		header.WriteString("\x89PNG\r\n\x1a\n")
+		ihdr := []byte("IHDR")
+		ihdr = binary.BigEndian.AppendUint32(ihdr, 30000)
+		ihdr = binary.BigEndian.AppendUint32(ihdr, 30000)
+		ihdr = append(ihdr, 8, 6, 0, 0, 0)
+		binary.Write(&header, binary.BigEndian, uint32(len(ihdr)-4))
+		header.Write(ihdr)
+		binary.Write(&header, binary.BigEndian, crc32.ChecksumIEEE(ihdr))
+
+		_, err := storage.GenerateThumbnail(&header, "image/png", 256)
+		assert.ErrorIs(t, err, storage.ErrImageTooLarge)
+	})
+}
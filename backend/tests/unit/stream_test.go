@@ -0,0 +1,115 @@
+package unit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kakeibo-tanuki/internal/events"
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/stream"
+)
+
+func publishCards(broker *stream.Broker, n int) {
+	for i := 0; i < n; i++ {
+		broker.HandleEvent(events.Event{Type: events.CardCreated, Card: &models.Card{ID: uuid.New(), Name: "カード"}})
+	}
+}
+
+func messageIDs(messages []stream.Message) []uint64 {
+	ids := []uint64{}
+	for _, message := range messages {
+		ids = append(ids, message.ID)
+	}
+	return ids
+}
+
+func TestBrokerFanOut(t *testing.T) {
+	broker := stream.NewBroker(10)
+	_, first, cancelFirst := broker.Subscribe(0, false)
+	_, second, cancelSecond := broker.Subscribe(0, false)
+	assert.Equal(t, 2, broker.Clients())
+
+	card := &models.Card{ID: uuid.New(), Name: "楽天カード"}
+	broker.HandleEvent(events.Event{Type: events.CardUpdated, Card: card})
+
+	for _, client := range []<-chan stream.Message{first, second} {
+		message := <-client
+		assert.Equal(t, uint64(1), message.ID)
+		assert.Equal(t, events.CardUpdated, message.Event)
+
+		var data struct {
+			Type string      `json:"type"`
+			Data models.Card `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(message.Data, &data))
+		assert.Equal(t, events.CardUpdated, data.Type)
+		assert.Equal(t, card.ID, data.Data.ID)
+	}
+
+	cancelFirst()
+	cancelFirst()
+	assert.Equal(t, 1, broker.Clients())
+	_, open := <-first
+	assert.False(t, open)
+	cancelSecond()
+	assert.Equal(t, 0, broker.Clients())
+}
+
+func TestBrokerResume(t *testing.T) {
+	broker := stream.NewBroker(5)
+
+	// Nothing to resume before any event
+	replay, _, cancel := broker.Subscribe(0, true)
+	assert.Empty(t, replay)
+	cancel()
+
+	publishCards(broker, 8)
+
+	replay, _, cancel = broker.Subscribe(5, true)
+	assert.Equal(t, []uint64{6, 7, 8}, messageIDs(replay))
+	cancel()
+
+	// The buffer holds the last five messages, 4 through 8
+	replay, _, cancel = broker.Subscribe(3, true)
+	assert.Equal(t, []uint64{4, 5, 6, 7, 8}, messageIDs(replay))
+	cancel()
+
+	replay, _, cancel = broker.Subscribe(8, true)
+	assert.Empty(t, replay)
+	cancel()
+
+	// Missed messages that are no longer buffered, and IDs from before a
+	// restart, reset the client
+	for _, lastID := range []uint64{2, 0, 42} {
+		replay, _, cancel = broker.Subscribe(lastID, true)
+		require.Len(t, replay, 1, "last ID %d", lastID)
+		assert.Equal(t, stream.ResetEvent, replay[0].Event)
+		assert.Equal(t, uint64(8), replay[0].ID)
+		cancel()
+	}
+
+	// New clients start with new events
+	replay, _, cancel = broker.Subscribe(0, false)
+	assert.Empty(t, replay)
+	cancel()
+}
+
+func TestBrokerDisconnectsSlowClients(t *testing.T) {
+	broker := stream.NewBroker(1000)
+	_, slow, cancel := broker.Subscribe(0, false)
+	defer cancel()
+
+	// The slow client never reads, so publishing must not block
+	publishCards(broker, 500)
+	assert.Equal(t, 0, broker.Clients())
+
+	received := 0
+	for range slow {
+		received++
+	}
+	assert.Less(t, received, 500)
+}
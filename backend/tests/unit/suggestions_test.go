@@ -0,0 +1,116 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
+	"kakeibo-tanuki/internal/suggestions"
+)
+
+func TestCategorySuggestions(t *testing.T) {
+	card, otherCard := uuid.New(), uuid.New()
+	food, transport, books := uuid.New(), uuid.New(), uuid.New()
+
+	expense := func(description string, amount int64, cardID, categoryID uuid.UUID) models.Expense {
+		return models.Expense{ID: uuid.New(), Description: description, Amount: money.New(amount), Currency: "JPY", CardID: cardID, CategoryID: categoryID}
+	}
+	history := []models.Expense{
+		expense("セブンイレブン 渋谷店", 480, card, food),
+		expense("セブンイレブン 新宿店", 650, card, food),
+		expense("ローソン", 320, card, food),
+		expense("スーパー 西友", 2800, card, food),
+		expense("JR東日本 モバイルSuica", 3000, otherCard, transport),
+		expense("モバイルSuica チャージ", 5000, otherCard, transport),
+		expense("東京メトロ", 3000, otherCard, transport),
+		// Not a category to suggest
+		expense("セブンイレブン 池袋店", 500, card, uuid.Nil),
+	}
+
+	loads := 0
+	model := suggestions.NewModel(func(context.Context) ([]models.Expense, error) {
+		loads++
+		return history, nil
+	})
+
+	t.Run("learned from history", func(t *testing.T) {
+		// Half-width katakana is normalized before n-grams are taken
+		ranked, err := model.Suggest(suggestions.Query{Description: "ｾﾌﾞﾝｲﾚﾌﾞﾝ 池袋店", Amount: money.New(500), Currency: "JPY", CardID: card}, 3)
+		require.NoError(t, err)
+		require.Len(t, ranked, 2)
+		assert.Equal(t, food, ranked[0].CategoryID)
+		assert.Greater(t, ranked[0].Probability, 0.9)
+		assert.InDelta(t, 1.0, ranked[0].Probability+ranked[1].Probability, 1e-9)
+
+		ranked, err = model.Suggest(suggestions.Query{Description: "Suica", Amount: money.New(3000), Currency: "JPY"}, 1)
+		require.NoError(t, err)
+		require.Len(t, ranked, 1)
+		assert.Equal(t, transport, ranked[0].CategoryID)
+	})
+
+	t.Run("card and amount break ties", func(t *testing.T) {
+		ranked, err := model.Suggest(suggestions.Query{Description: "不明", Amount: money.New(3000), Currency: "JPY", CardID: otherCard}, 3)
+		require.NoError(t, err)
+		assert.Equal(t, transport, ranked[0].CategoryID)
+	})
+
+	t.Run("learns and forgets incrementally", func(t *testing.T) {
+		bookstore := expense("紀伊國屋書店", 1800, card, books)
+		model.Learn(&bookstore)
+
+		ranked, err := model.Suggest(suggestions.Query{Description: "紀伊國屋書店 新宿本店"}, 3)
+		require.NoError(t, err)
+		assert.Equal(t, books, ranked[0].CategoryID)
+		assert.Len(t, ranked, 3)
+
+		// Recategorizing replaces what was learned
+		bookstore.CategoryID = food
+		model.Learn(&bookstore)
+		ranked, err = model.Suggest(suggestions.Query{Description: "紀伊國屋書店 新宿本店"}, 3)
+		require.NoError(t, err)
+		assert.Equal(t, food, ranked[0].CategoryID)
+		assert.Len(t, ranked, 2)
+
+		// Losing the category forgets the expense
+		bookstore.CategoryID = uuid.Nil
+		model.Learn(&bookstore)
+		ranked, err = model.Suggest(suggestions.Query{Description: "紀伊國屋書店 新宿本店"}, 3)
+		require.NoError(t, err)
+		assert.Len(t, ranked, 2)
+
+		model.Forget(bookstore.ID)
+		model.Forget(uuid.New())
+		ranked, err = model.Suggest(suggestions.Query{Description: "紀伊國屋書店 新宿本店"}, 3)
+		require.NoError(t, err)
+		assert.Len(t, ranked, 2)
+	})
+
+	assert.Equal(t, 1, loads)
+}
+
+func TestCategorySuggestionsWithoutHistory(t *testing.T) {
+	failing := true
+	model := suggestions.NewModel(func(context.Context) ([]models.Expense, error) {
+		if failing {
+			return nil, errors.New("database is down")
+		}
+		return nil, nil
+	})
+
+	_, err := model.Suggest(suggestions.Query{Description: "セブンイレブン"}, 3)
+	assert.Error(t, err)
+
+	// Expenses recorded before the model is loaded are learned when it is
+	model.Learn(&models.Expense{ID: uuid.New(), Description: "セブンイレブン", CategoryID: uuid.New()})
+
+	failing = false
+	ranked, err := model.Suggest(suggestions.Query{Description: "セブンイレブン"}, 3)
+	require.NoError(t, err)
+	assert.Empty(t, ranked)
+}
@@ -0,0 +1,78 @@
+package unit
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kakeibo-tanuki/internal/middleware"
+	"kakeibo-tanuki/internal/models"
+)
+
+func TestTimeoutMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	deadlines := map[string]bool{}
+	record := func(c *gin.Context) {
+		_, ok := c.Request.Context().Deadline()
+		deadlines[c.Request.URL.Path] = ok
+		c.Status(http.StatusNoContent)
+	}
+
+	router := gin.New()
+	router.Use(middleware.TimeoutMiddleware(time.Minute, "/api/events"))
+	router.GET("/api/reports/range", record)
+	router.GET("/api/events", record)
+
+	for _, path := range []string{"/api/reports/range", "/api/events"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.True(t, deadlines["/api/reports/range"], "requests have a deadline")
+	assert.False(t, deadlines["/api/events"], "the event stream stays open")
+}
+
+func TestTimeoutMiddleware_Errors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(middleware.ErrorMiddleware())
+	router.Use(middleware.TimeoutMiddleware(20 * time.Millisecond))
+	router.GET("/api/fails", func(c *gin.Context) {
+		c.Error(errors.New("boom")).SetMeta("Failed to retrieve card")
+	})
+	router.GET("/api/slow", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		// Drivers do not always wrap the error of the context
+		c.Error(errors.New("interrupted"))
+	})
+
+	t.Run("error of the handler", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/fails", nil))
+
+		require.Equal(t, http.StatusInternalServerError, w.Code, w.Body.String())
+		var response models.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "INTERNAL_ERROR", response.Error.Code)
+		assert.Equal(t, "Failed to retrieve card", response.Error.Message)
+		assert.Equal(t, "boom", response.Error.Details)
+	})
+
+	t.Run("handler past the deadline", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/slow", nil))
+
+		require.Equal(t, http.StatusGatewayTimeout, w.Code, w.Body.String())
+		var response models.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "REQUEST_TIMEOUT", response.Error.Code)
+	})
+}
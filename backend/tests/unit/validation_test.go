@@ -0,0 +1,91 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"kakeibo-tanuki/internal/models"
+	"kakeibo-tanuki/internal/money"
+	"kakeibo-tanuki/internal/validation"
+)
+
+func TestValidationLanguages(t *testing.T) {
+	assert.Equal(t, []string{"ja", "en"}, validation.Languages("ja-JP,ja;q=0.9,en;q=0.8"))
+	assert.Equal(t, []string{"en", "ja"}, validation.Languages("ja;q=0.5, en-US"))
+	assert.Equal(t, []string{"fr"}, validation.Languages("fr-FR, *;q=0.1, de;q=0"))
+	assert.Empty(t, validation.Languages(""))
+
+	assert.Equal(t, "ja", validation.Translator("ja-JP").Locale())
+	assert.Equal(t, "ja", validation.Translator("fr, ja;q=0.5").Locale())
+	assert.Equal(t, "en", validation.Translator("de").Locale())
+}
+
+func TestValidationFields(t *testing.T) {
+	req := models.CreateCardRequest{Name: "", Color: "blue"}
+	err := validation.Struct(req)
+	require.Error(t, err)
+
+	t.Run("english", func(t *testing.T) {
+		fields := validation.Fields(err, "")
+		assert.Equal(t, []models.FieldError{
+			{Field: "name", Rule: "required", Message: "name is a required field"},
+			{Field: "color", Rule: "hexcolor", Message: "color must be a valid HEX color"},
+		}, fields)
+	})
+
+	t.Run("japanese", func(t *testing.T) {
+		fields := validation.Fields(err, "ja-JP,ja;q=0.9")
+		require.Len(t, fields, 2)
+		assert.Equal(t, "nameは必須フィールドです", fields[0].Message)
+		assert.Equal(t, "colorは正しいHEXカラーコードでなければなりません", fields[1].Message)
+	})
+
+	t.Run("params", func(t *testing.T) {
+		fields := validation.Fields(validation.Struct(models.CreateCardRequest{
+			Name:  string(make([]byte, 101)),
+			Color: "#3B82F6",
+		}), "en")
+		require.Len(t, fields, 1)
+		assert.Equal(t, "max", fields[0].Rule)
+		assert.Equal(t, "100", fields[0].Param)
+		assert.Equal(t, "name must be a maximum of 100 characters in length", fields[0].Message)
+	})
+
+	t.Run("nested fields", func(t *testing.T) {
+		fields := validation.Fields(validation.Struct(models.UpdateExpenseRequest{
+			Amount: money.New(1000),
+			Date:   "2024-01-15",
+			CardID: "card",
+			Items:  []models.ExpenseItemRequest{{Amount: money.New(1000)}},
+		}), "en")
+		require.Len(t, fields, 1)
+		assert.Equal(t, "items[0].categoryId", fields[0].Field)
+		assert.Equal(t, "required", fields[0].Rule)
+
+		fields = validation.Fields(validation.Struct(models.UpdateExpenseRequest{
+			Amount: money.New(1000),
+			Date:   "2024-01-15",
+			CardID: "card",
+		}), "ja")
+		require.Len(t, fields, 1)
+		assert.Equal(t, models.FieldError{
+			Field:   "categoryId",
+			Rule:    "required_without",
+			Param:   "items",
+			Message: "categoryIdはitemsが指定されていない場合に必須です",
+		}, fields[0])
+	})
+
+	assert.Nil(t, validation.Fields(assert.AnError, "en"))
+}
+
+func TestValidationField(t *testing.T) {
+	field := validation.Field("en", "email", "required_if", "channels email")
+	assert.Equal(t, "email is a required field", field.Message)
+	assert.Equal(t, "channels email", field.Param)
+
+	field = validation.Field("ja", "categoryId", "required_without_all", "tags isShared")
+	assert.Equal(t, "categoryIdはtags isSharedのいずれも指定されていない場合に必須です", field.Message)
+}
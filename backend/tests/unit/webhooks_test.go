@@ -0,0 +1,45 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"kakeibo-tanuki/internal/webhooks"
+)
+
+func TestWebhookSignature(t *testing.T) {
+	body := []byte(`{"type":"expense.created"}`)
+	// echo -n '1700000000.{"type":"expense.created"}' | openssl dgst -sha256 -hmac secret
+	signature := webhooks.Sign("secret", 1700000000, body)
+	assert.Equal(t, "sha256=cd85b6fc85e92e015bc9bc2db8bef3980b7dabfb763de3f75fe9bb97c3c570d7", signature)
+
+	assert.True(t, webhooks.Verify("secret", 1700000000, body, signature))
+	assert.False(t, webhooks.Verify("other", 1700000000, body, signature))
+	assert.False(t, webhooks.Verify("secret", 1700000001, body, signature))
+	assert.False(t, webhooks.Verify("secret", 1700000000, []byte(`{}`), signature))
+}
+
+func TestWebhookPatterns(t *testing.T) {
+	for _, pattern := range []string{"*", "expense.created", "card.deleted", "category.*"} {
+		assert.True(t, webhooks.ValidPattern(pattern), pattern)
+	}
+	for _, pattern := range []string{"", "expense", "expense.paid", "payee.*", "*.created"} {
+		assert.False(t, webhooks.ValidPattern(pattern), pattern)
+	}
+
+	assert.True(t, webhooks.Matches([]string{"card.*"}, "card.updated"))
+	assert.True(t, webhooks.Matches([]string{"expense.deleted", "*"}, "category.created"))
+	assert.True(t, webhooks.Matches([]string{"expense.created"}, "expense.created"))
+	assert.False(t, webhooks.Matches([]string{"expense.created"}, "expense.updated"))
+	assert.False(t, webhooks.Matches([]string{"card.*"}, "category.created"))
+	assert.False(t, webhooks.Matches(nil, "card.created"))
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, webhooks.Backoff(1))
+	assert.Equal(t, time.Minute, webhooks.Backoff(2))
+	assert.Equal(t, 4*time.Minute, webhooks.Backoff(4))
+	assert.Equal(t, webhooks.MaxDelay, webhooks.Backoff(20))
+}
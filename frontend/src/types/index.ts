@@ -85,6 +85,7 @@ export interface CategoryExpenseSum {
 export interface SharedExpensesSummary {
   totalSharedAmount: number;
   splitAmount: number;
+  shares: number[];
   categories: CategoryExpenseSum[];
 }
 